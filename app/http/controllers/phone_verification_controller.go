@@ -1,24 +1,75 @@
 package controllers
 
 import (
+	"context"
+	"crypto/hmac"
 	crand "crypto/rand"
+	"crypto/sha256"
 	"database/sql"
 	"encoding/hex"
-	mrand "math/rand"
+	"fmt"
+	"math/big"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/example/Yamato-Go-Gin-API/internal/http/validation"
+	"github.com/example/Yamato-Go-Gin-API/internal/i18n"
+	"github.com/example/Yamato-Go-Gin-API/internal/phone"
+	"github.com/example/Yamato-Go-Gin-API/internal/queue"
+)
+
+// 1.- Defaults applied when OTPPolicy leaves a field unset.
+const (
+	DefaultPhoneCodeTTL        = 10 * time.Minute
+	DefaultPhoneMaxAttempts    = 5
+	DefaultPhoneResendCooldown = time.Minute
 )
 
-// 1.- PhoneVerification models a DB row for JSON responses.
+// 1.- phoneCodeLength is the number of digits texted to the phone.
+const phoneCodeLength = 6
+
+// 1.- fallbackVerificationSMS is used when the translation catalogue cannot be loaded.
+const fallbackVerificationSMS = "Your Yamato verification code is {code}. It expires in {minutes} minutes. Do not share it with anyone."
+
+// 1.- SMSQueue enqueues queue.SMSSendJobName deliveries for the worker.
+type SMSQueue interface {
+	Enqueue(ctx context.Context, jobName string, payload map[string]any) (queue.Message, error)
+}
+
+// 1.- OTPPolicy bounds how long codes live, how often they can be guessed, and how often they can be resent.
+type OTPPolicy struct {
+	TTL time.Duration
+	// 2.- MaxAttempts wrong guesses lock the verification; the phone must request a new code.
+	MaxAttempts int
+	// 3.- ResendCooldown is the minimum gap between two codes for the same phone.
+	ResendCooldown time.Duration
+}
+
+// 1.- withDefaults fills unset limits with the package defaults.
+func (p OTPPolicy) withDefaults() OTPPolicy {
+	if p.TTL <= 0 {
+		p.TTL = DefaultPhoneCodeTTL
+	}
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultPhoneMaxAttempts
+	}
+	if p.ResendCooldown <= 0 {
+		p.ResendCooldown = DefaultPhoneResendCooldown
+	}
+	return p
+}
+
+// 1.- PhoneVerification models a DB row for JSON responses; codes are stored hashed and never listed.
 type PhoneVerification struct {
 	ID        int64     `json:"id"`
 	Phone     string    `json:"phone"`
-	Code      string    `json:"code"`
 	Status    string    `json:"status"`
 	Name      string    `json:"name"`
+	Attempts  int       `json:"attempts"`
 	ExpiresAt time.Time `json:"expires_at"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -26,32 +77,168 @@ type PhoneVerification struct {
 
 // 2.- PhoneVerificationController exposes HTTP handlers for phone verification.
 type PhoneVerificationController struct {
-	db *sql.DB
+	db  *sql.DB
+	sms SMSQueue
+	// 2.1.- devCodes echoes codes in responses so local setups work without an SMS gateway.
+	devCodes bool
+	// 2.2.- secret keys the code digests so a leaked table cannot be brute-forced offline.
+	secret string
+	policy OTPPolicy
+	// 2.3.- defaultRegion reads numbers written without a country code, e.g. "MX"; empty requires "+".
+	defaultRegion string
+	// 2.4.- validator runs the shared `phone` rule with defaultRegion.
+	validator *validation.Validator
+}
+
+// 2.5.- PhoneVerificationOption customises the controller dependencies.
+type PhoneVerificationOption func(*PhoneVerificationController)
+
+// 2.6.- WithSMSQueue delivers verification codes through the sms_send job.
+func WithSMSQueue(sms SMSQueue) PhoneVerificationOption {
+	return func(c *PhoneVerificationController) {
+		c.sms = sms
+	}
+}
+
+// 2.7.- WithDevCodes exposes codes in API responses; never enable it outside local development.
+func WithDevCodes(enabled bool) PhoneVerificationOption {
+	return func(c *PhoneVerificationController) {
+		c.devCodes = enabled
+	}
+}
+
+// 2.8.- WithCodeSecret sets the HMAC key used to store codes.
+func WithCodeSecret(secret string) PhoneVerificationOption {
+	return func(c *PhoneVerificationController) {
+		c.secret = secret
+	}
+}
+
+// 2.9.- WithOTPPolicy overrides the code lifetime, attempt limit, and resend cooldown.
+func WithOTPPolicy(policy OTPPolicy) PhoneVerificationOption {
+	return func(c *PhoneVerificationController) {
+		c.policy = policy.withDefaults()
+	}
+}
+
+// 2.10.- WithDefaultRegion accepts national-format numbers for the given ISO 3166-1 region.
+func WithDefaultRegion(region string) PhoneVerificationOption {
+	return func(c *PhoneVerificationController) {
+		c.defaultRegion = strings.ToUpper(strings.TrimSpace(region))
+	}
 }
 
 // 3.- NewPhoneVerificationController creates a new instance of PhoneVerificationController.
-func NewPhoneVerificationController(db *sql.DB) PhoneVerificationController {
-	return PhoneVerificationController{db: db}
+func NewPhoneVerificationController(db *sql.DB, opts ...PhoneVerificationOption) PhoneVerificationController {
+	c := PhoneVerificationController{db: db, policy: OTPPolicy{}.withDefaults()}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	validator, err := validation.New(validation.WithPhoneRegion(c.defaultRegion))
+	if err != nil {
+		panic(err)
+	}
+	c.validator = validator
+	return c
 }
 
-// 4.- RequestCode handles POST /api/phone-verifications.
+// 4.- RequestCode handles POST /api/phone-verifications and texts the code to the phone.
 //     Body: { "phone": "+52...", "name": "John Doe" }.
 func (c PhoneVerificationController) RequestCode(ctx *gin.Context) {
 	var req struct {
-		Phone string `json:"phone" binding:"required"`
+		Phone string `json:"phone" binding:"required" validate:"phone"`
 		Name  string `json:"name"  binding:"required"`
 	}
 	if err := ctx.ShouldBindJSON(&req); err != nil || req.Phone == "" || req.Name == "" {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "phone and name are required"})
 		return
 	}
+	if !c.validatePhone(ctx, &req) {
+		return
+	}
+
+	// 4.1.- Key every verification by the E.164 form so formatting variants share one history.
+	number, ok := c.normalizePhone(ctx, req.Phone)
+	if !ok {
+		return
+	}
+	req.Phone = number.E164
 
-	code := generateCode(6)
-	expiresAt := time.Now().Add(10 * time.Minute)
+	// 4.2.- Without a delivery channel the code could only leak through the response, so refuse outside dev mode.
+	if c.sms == nil && !c.devCodes {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "sms_delivery_unavailable"})
+		return
+	}
+
+	code, err := generateCode(phoneCodeLength)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":  "could_not_generate_code",
+			"detail": err.Error(),
+		})
+		return
+	}
+	now := time.Now().UTC()
+	expiresAt := now.Add(c.policy.TTL)
+
+	tx, err := c.db.BeginTx(ctx.Request.Context(), nil)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":  "could_not_create_verification",
+			"detail": err.Error(),
+		})
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// 4.3.- Serialise requests for the same phone so the cooldown cannot be raced.
+	if _, err := tx.ExecContext(ctx.Request.Context(), `SELECT pg_advisory_xact_lock(hashtext('phone_verifications:' || $1))`, req.Phone); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":  "could_not_create_verification",
+			"detail": err.Error(),
+		})
+		return
+	}
+
+	// 4.4.- Enforce the per-phone resend cooldown against the most recent code.
+	var lastCreated time.Time
+	err = tx.QueryRowContext(ctx.Request.Context(), `
+SELECT created_at
+FROM phone_verifications
+WHERE phone = $1
+ORDER BY created_at DESC
+LIMIT 1`, req.Phone).Scan(&lastCreated)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":  "could_not_create_verification",
+			"detail": err.Error(),
+		})
+		return
+	}
+	if err == nil {
+		if wait := lastCreated.Add(c.policy.ResendCooldown).Sub(now); wait > 0 {
+			retryAfter := int((wait + time.Second - 1) / time.Second)
+			ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+			ctx.JSON(http.StatusTooManyRequests, gin.H{"error": "resend_cooldown", "retry_after": retryAfter})
+			return
+		}
+	}
+
+	// 4.5.- A new code supersedes every pending one for the phone.
+	if _, err := tx.ExecContext(ctx.Request.Context(), `
+UPDATE phone_verifications
+SET status = 'expired', updated_at = $2
+WHERE phone = $1 AND status = 'pending'`, req.Phone, now); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":  "could_not_create_verification",
+			"detail": err.Error(),
+		})
+		return
+	}
 
 	const q = `
-INSERT INTO phone_verifications (phone, code, status, name, expires_at)
-VALUES ($1, $2, 'pending', $3, $4)
+INSERT INTO phone_verifications (phone, code_hash, status, name, expires_at, created_at, updated_at)
+VALUES ($1, $2, 'pending', $3, $4, $5, $5)
 RETURNING id, created_at, updated_at`
 
 	var (
@@ -60,57 +247,105 @@ RETURNING id, created_at, updated_at`
 		updatedAt time.Time
 	)
 
-	if err := c.db.QueryRowContext(ctx.Request.Context(), q,
+	if err := tx.QueryRowContext(ctx.Request.Context(), q,
 		req.Phone,
-		code,
+		hashCode(c.secret, req.Phone, code),
 		req.Name,
 		expiresAt,
+		now,
 	).Scan(&id, &createdAt, &updatedAt); err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"error":  "could_not_create_verification",
-			"detail": err.Error(), // debug
+			"detail": err.Error(),
 		})
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, gin.H{
+	// 4.6.- Hand the localized message to the worker before committing so a failed enqueue leaves no orphan code.
+	if c.sms != nil {
+		if _, err := c.sms.Enqueue(ctx.Request.Context(), queue.SMSSendJobName, map[string]any{
+			"to":   req.Phone,
+			"body": verificationSMS(requestLocale(ctx), code, c.policy.TTL),
+		}); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":  "could_not_send_code",
+				"detail": err.Error(),
+			})
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":  "could_not_create_verification",
+			"detail": err.Error(),
+		})
+		return
+	}
+
+	resp := gin.H{
 		"id":         id,
 		"phone":      req.Phone,
+		"region":     number.Region,
+		"country":    number.Country,
 		"name":       req.Name,
 		"status":     "pending",
-		"code":       code, // para que puedas leerlo y enviarlo manualmente
 		"expires_at": expiresAt,
 		"created_at": createdAt,
 		"updated_at": updatedAt,
-	})
+	}
+	if c.devCodes {
+		resp["code"] = code
+	}
+	ctx.JSON(http.StatusCreated, resp)
 }
 
 // 5.- ConfirmCode handles POST /api/phone-verifications/confirm.
 //     Body: { "phone": "+52...", "code": "123456" }.
 func (c PhoneVerificationController) ConfirmCode(ctx *gin.Context) {
 	var req struct {
-		Phone string `json:"phone" binding:"required"`
+		Phone string `json:"phone" binding:"required" validate:"phone"`
 		Code  string `json:"code"  binding:"required"`
 	}
 	if err := ctx.ShouldBindJSON(&req); err != nil || req.Phone == "" || req.Code == "" {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "phone and code are required"})
 		return
 	}
+	if !c.validatePhone(ctx, &req) {
+		return
+	}
+	number, ok := c.normalizePhone(ctx, req.Phone)
+	if !ok {
+		return
+	}
+	req.Phone = number.E164
 
+	tx, err := c.db.BeginTx(ctx.Request.Context(), nil)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":  "could_not_verify_code",
+			"detail": err.Error(),
+		})
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// 5.1.- Lock the only pending verification for the phone so concurrent guesses are counted one by one.
 	const selectQ = `
-SELECT id, status, expires_at
+SELECT id, code_hash, attempts, expires_at
 FROM phone_verifications
-WHERE phone = $1 AND code = $2
+WHERE phone = $1 AND status = 'pending'
 ORDER BY created_at DESC
-LIMIT 1`
+LIMIT 1
+FOR UPDATE`
 
 	var (
 		id        int64
-		status    string
+		codeHash  sql.NullString
+		attempts  int
 		expiresAt time.Time
 	)
 
-	err := c.db.QueryRowContext(ctx.Request.Context(), selectQ, req.Phone, req.Code).Scan(&id, &status, &expiresAt)
+	err = tx.QueryRowContext(ctx.Request.Context(), selectQ, req.Phone).Scan(&id, &codeHash, &attempts, &expiresAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusConflict, gin.H{"error": "invalid_or_expired_code"})
@@ -123,18 +358,54 @@ LIMIT 1`
 		return
 	}
 
-	if status != "pending" || time.Now().After(expiresAt) {
+	if time.Now().After(expiresAt) {
 		ctx.JSON(http.StatusConflict, gin.H{"error": "invalid_or_expired_code"})
 		return
 	}
 
-	// 5.1.- Mark this verification as verified.
+	// 5.2.- Count wrong guesses and lock the verification once the limit is reached.
+	expected := hashCode(c.secret, req.Phone, strings.TrimSpace(req.Code))
+	if !codeHash.Valid || !hmac.Equal([]byte(expected), []byte(codeHash.String)) {
+		attempts++
+		status := "pending"
+		if attempts >= c.policy.MaxAttempts {
+			status = "locked"
+		}
+		if _, err := tx.ExecContext(ctx.Request.Context(), `
+UPDATE phone_verifications
+SET attempts = $2, status = $3, updated_at = NOW()
+WHERE id = $1`, id, attempts, status); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":  "could_not_verify_code",
+				"detail": err.Error(),
+			})
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":  "could_not_verify_code",
+				"detail": err.Error(),
+			})
+			return
+		}
+		if status == "locked" {
+			ctx.JSON(http.StatusTooManyRequests, gin.H{"error": "too_many_attempts"})
+			return
+		}
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error":              "invalid_or_expired_code",
+			"attempts_remaining": c.policy.MaxAttempts - attempts,
+		})
+		return
+	}
+
+	// 5.3.- Mark this verification as verified.
 	const updateQ = `
 UPDATE phone_verifications
 SET status = 'verified', updated_at = NOW()
 WHERE id = $1`
 
-	if _, err := c.db.ExecContext(ctx.Request.Context(), updateQ, id); err != nil {
+	if _, err := tx.ExecContext(ctx.Request.Context(), updateQ, id); err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"error":  "could_not_mark_verified",
 			"detail": err.Error(),
@@ -142,7 +413,7 @@ WHERE id = $1`
 		return
 	}
 
-	// 5.2.- Generate a long-lived device token for passwordless usage.
+	// 5.4.- Generate a long-lived device token for passwordless usage.
 	deviceToken, err := generateDeviceToken()
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
@@ -163,7 +434,7 @@ RETURNING id, created_at, last_used_at`
 		lastUsed  time.Time
 	)
 
-	if err := c.db.QueryRowContext(ctx.Request.Context(), insertTokenQ,
+	if err := tx.QueryRowContext(ctx.Request.Context(), insertTokenQ,
 		req.Phone,
 		deviceToken,
 	).Scan(&tokenID, &createdAt, &lastUsed); err != nil {
@@ -173,8 +444,15 @@ RETURNING id, created_at, last_used_at`
 		})
 		return
 	}
+	if err := tx.Commit(); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":  "could_not_mark_verified",
+			"detail": err.Error(),
+		})
+		return
+	}
 
-	// 5.3.- Return success + device_token to the client.
+	// 5.5.- Return success + device_token to the client.
 	ctx.JSON(http.StatusOK, gin.H{
 		"phone":        req.Phone,
 		"verified":     true,
@@ -188,7 +466,7 @@ func (c PhoneVerificationController) ListUnverified(ctx *gin.Context) {
 	offset := parsePositiveInt(ctx.Query("offset"), 0)
 
 	const q = `
-SELECT id, phone, code, status, name, expires_at, created_at, updated_at
+SELECT id, phone, status, name, attempts, expires_at, created_at, updated_at
 FROM phone_verifications
 WHERE status = 'pending' AND expires_at > NOW()
 ORDER BY created_at DESC
@@ -210,9 +488,9 @@ LIMIT $1 OFFSET $2`
 		if err := rows.Scan(
 			&v.ID,
 			&v.Phone,
-			&v.Code,
 			&v.Status,
 			&v.Name,
+			&v.Attempts,
 			&v.ExpiresAt,
 			&v.CreatedAt,
 			&v.UpdatedAt,
@@ -241,6 +519,67 @@ LIMIT $1 OFFSET $2`
 	})
 }
 
+// 6.1.- validatePhone runs the payload's `phone` rule and answers 400 with the validator's field error.
+func (c PhoneVerificationController) validatePhone(ctx *gin.Context, payload interface{}) bool {
+	if c.validator == nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "validation_unavailable"})
+		return false
+	}
+	errs, err := c.validator.ValidateStruct(payload)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":  "validation_unavailable",
+			"detail": err.Error(),
+		})
+		return false
+	}
+	if fields := errs.Fields["phone"]; len(fields) > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":  "invalid_phone",
+			"detail": fields[0].Message,
+			"field":  fields[0],
+		})
+		return false
+	}
+	return true
+}
+
+// 6.1.1.- normalizePhone maps a validated number to its E.164 key, region and country.
+func (c PhoneVerificationController) normalizePhone(ctx *gin.Context, raw string) (phone.Number, bool) {
+	number, err := phone.Parse(raw, c.defaultRegion)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":  "invalid_phone",
+			"detail": err.Error(),
+		})
+		return phone.Number{}, false
+	}
+	return number, true
+}
+
+// 6.2.- verificationSMS renders the sms.phone_verification template in the requested locale.
+func verificationSMS(locale string, code string, ttl time.Duration) string {
+	text := fallbackVerificationSMS
+	if translator, err := i18n.New(locale); err == nil {
+		if translated := translator.Translate("sms.phone_verification"); translated != "sms.phone_verification" {
+			text = translated
+		}
+	}
+	return strings.NewReplacer("{code}", code, "{minutes}", strconv.Itoa(int(ttl.Minutes()))).Replace(text)
+}
+
+// 6.3.- requestLocale picks the first language tag from Accept-Language.
+func requestLocale(ctx *gin.Context) string {
+	header := ctx.GetHeader("Accept-Language")
+	if comma := strings.IndexByte(header, ','); comma >= 0 {
+		header = header[:comma]
+	}
+	if semicolon := strings.IndexByte(header, ';'); semicolon >= 0 {
+		header = header[:semicolon]
+	}
+	return strings.TrimSpace(header)
+}
+
 // 7.- parsePositiveInt converts a query parameter to a positive int with fallback.
 func parsePositiveInt(raw string, def int) int {
 	if raw == "" {
@@ -253,23 +592,24 @@ func parsePositiveInt(raw string, def int) int {
 	return n
 }
 
-// 8.- generateCode returns a numeric code of the given length.
-func generateCode(length int) string {
+// 8.- generateCode returns a uniformly random numeric code of the given length, keeping leading zeros.
+func generateCode(length int) (string, error) {
 	if length <= 0 {
-		length = 6
-	}
-	n := mrand.Intn(900000) + 100000
-	s := strconv.Itoa(n)
-	if length == 6 {
-		return s
+		length = phoneCodeLength
 	}
-	if len(s) > length {
-		return s[:length]
-	}
-	for len(s) < length {
-		s = "0" + s
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(length)), nil)
+	n, err := crand.Int(crand.Reader, limit)
+	if err != nil {
+		return "", err
 	}
-	return s
+	return fmt.Sprintf("%0*d", length, n.Int64()), nil
+}
+
+// 8.1.- hashCode binds the digest to the phone so a stored hash cannot confirm another number.
+func hashCode(secret string, phone string, code string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(phone + "|" + code))
+	return hex.EncodeToString(mac.Sum(nil))
 }
 
 // 9.- generateDeviceToken returns a cryptographically-strong random token.
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 1.- TestGenerateCodeIsNumericAndPadded checks the code shape across many draws.
+func TestGenerateCodeIsNumericAndPadded(t *testing.T) {
+	seen := make(map[string]struct{})
+	for i := 0; i < 200; i++ {
+		code, err := generateCode(phoneCodeLength)
+		if err != nil {
+			t.Fatalf("generateCode returned error: %v", err)
+		}
+		if len(code) != phoneCodeLength || strings.Trim(code, "0123456789") != "" {
+			t.Fatalf("unexpected code %q", code)
+		}
+		seen[code] = struct{}{}
+	}
+	if len(seen) < 190 {
+		t.Fatalf("expected mostly distinct codes, got %d of 200", len(seen))
+	}
+}
+
+// 2.- TestHashCodeBindsPhoneAndSecret ensures digests differ per phone and per key and never contain the code.
+func TestHashCodeBindsPhoneAndSecret(t *testing.T) {
+	digest := hashCode("secret", "+5215550000000", "123456")
+	if digest != hashCode("secret", "+5215550000000", "123456") {
+		t.Fatal("expected deterministic digest")
+	}
+	if digest == hashCode("secret", "+5215550000001", "123456") {
+		t.Fatal("expected digest to depend on the phone")
+	}
+	if digest == hashCode("other", "+5215550000000", "123456") {
+		t.Fatal("expected digest to depend on the secret")
+	}
+	if strings.Contains(digest, "123456") {
+		t.Fatal("digest must not embed the code")
+	}
+}
+
+// 3.- TestVerificationSMSIsLocalized renders the template in English and Mexican Spanish.
+func TestVerificationSMSIsLocalized(t *testing.T) {
+	english := verificationSMS("en", "042137", 10*time.Minute)
+	if !strings.Contains(english, "042137") || !strings.Contains(english, "10 minutes") {
+		t.Fatalf("unexpected english message %q", english)
+	}
+	spanish := verificationSMS("es-MX", "042137", 5*time.Minute)
+	if !strings.Contains(spanish, "042137") || !strings.Contains(spanish, "5 minutos") {
+		t.Fatalf("unexpected spanish message %q", spanish)
+	}
+}
+
+// 4.- TestRequestCodeRejectsInvalidPhones answers 400 before touching storage or the SMS queue.
+func TestRequestCodeRejectsInvalidPhones(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/phone-verifications", NewPhoneVerificationController(nil, WithDefaultRegion("MX")).RequestCode)
+
+	for _, raw := range []string{"+52 55-1234", "+999 5512340000", "phone"} {
+		recorder := httptest.NewRecorder()
+		body := `{"phone":"` + raw + `","name":"Ana"}`
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/phone-verifications", strings.NewReader(body)))
+		if recorder.Code != http.StatusBadRequest {
+			t.Fatalf("%q: expected 400, got %d", raw, recorder.Code)
+		}
+		var payload struct {
+			Error string `json:"error"`
+			Field struct {
+				Field string `json:"field"`
+				Rule  string `json:"rule"`
+			} `json:"field"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &payload); err != nil || payload.Error != "invalid_phone" || payload.Field.Field != "phone" || payload.Field.Rule != "phone" {
+			t.Fatalf("%q: unexpected body %s", raw, recorder.Body.String())
+		}
+	}
+
+	// 4.1.- A national number passes the rule under the default region and reaches the delivery check.
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/phone-verifications", strings.NewReader(`{"phone":"55 1234 5678","name":"Ana"}`)))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected the valid number to pass validation, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+}
@@ -72,8 +72,8 @@ func resolveCORSConfig() internalconfig.CORSConfig {
         return internalconfig.CORSConfig{
                 AllowOrigins:     allowedOrigins,
                 AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-                AllowHeaders:     []string{"Authorization", "Content-Type"},
-                ExposeHeaders:    []string{},
+                AllowHeaders:     []string{"Authorization", "Content-Type", "X-CSRF-Token", "X-Session-Mode"},
+                ExposeHeaders:    []string{"X-CSRF-Token"},
                 AllowCredentials: true,
                 MaxAge:           12 * time.Hour,
         }
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/example/Yamato-Go-Gin-API/internal/http/auth/passwordpolicy"
+)
+
+func main() {
+	// 1.- Declare command-line flags describing the source list and the filter to write.
+	in := flag.String("in", "", "password list, one entry per line; lines starting with # are ignored")
+	out := flag.String("out", "", "destination for the serialized bloom filter")
+	format := flag.String("format", "plain", "input format: plain passwords or sha1 digests (HEX or HEX:count)")
+	rate := flag.Float64("fp", 0.001, "target false-positive rate")
+	flag.Parse()
+	if *in == "" || *out == "" {
+		log.Fatal("-in and -out are required")
+	}
+	if *format != "plain" && *format != "sha1" {
+		log.Fatalf("unsupported -format %q", *format)
+	}
+
+	// 2.- Count entries first so the filter is sized without holding large corpora in memory.
+	entries := 0
+	if err := eachEntry(*in, func(string) error {
+		entries++
+		return nil
+	}); err != nil {
+		log.Fatalf("failed to read %s: %v", *in, err)
+	}
+
+	// 3.- Add every entry on a second pass.
+	filter := passwordpolicy.NewBloomFilter(entries, *rate)
+	if err := eachEntry(*in, func(entry string) error {
+		if *format == "sha1" {
+			return filter.AddHexDigest(entry)
+		}
+		filter.Add(entry)
+		return nil
+	}); err != nil {
+		log.Fatalf("failed to build filter: %v", err)
+	}
+
+	// 4.- Persist the filter for embedding or for PASSWORD_BREACHED_FILTER.
+	file, err := os.Create(*out)
+	if err != nil {
+		log.Fatalf("failed to create %s: %v", *out, err)
+	}
+	written, err := filter.WriteTo(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Fatalf("failed to write filter: %v", err)
+	}
+	fmt.Printf("wrote %d entries (%d bytes) to %s\n", entries, written, *out)
+}
+
+// 1.- eachEntry streams the non-empty, non-comment lines of the list.
+func eachEntry(path string, fn func(string) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
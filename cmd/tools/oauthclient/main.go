@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+
+	_ "github.com/lib/pq"
+
+	oauthhttp "github.com/example/Yamato-Go-Gin-API/internal/http/oauth"
+	"github.com/example/Yamato-Go-Gin-API/internal/storage/oauthclients"
+	"github.com/example/Yamato-Go-Gin-API/internal/tooling/db"
+)
+
+func main() {
+	// 1.- Declare command-line flags describing the client to register.
+	clientID := flag.String("id", "", "client identifier presented as client_id")
+	name := flag.String("name", "", "human readable client name")
+	scopes := flag.String("scopes", "", "comma or space separated scopes the client may request")
+	timeout := flag.Duration("timeout", time.Minute, "maximum time to wait for database operations")
+	flag.Parse()
+	if *clientID == "" {
+		log.Fatal("-id is required")
+	}
+
+	// 2.- Build the PostgreSQL connection string using shared helpers.
+	dsn, err := db.BuildPostgresDSNFromEnv()
+	if err != nil {
+		log.Fatalf("failed to build postgres dsn: %v", err)
+	}
+
+	// 3.- Open the database connection and ensure resources are released on exit.
+	conn, err := sql.Open("postgres", dsn)
+	if err != nil {
+		log.Fatalf("failed to open database: %v", err)
+	}
+	defer func() {
+		if closeErr := conn.Close(); closeErr != nil {
+			log.Printf("failed to close database connection: %v", closeErr)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	// 4.- Register the client; the secret is only ever shown here.
+	store, err := oauthclients.NewStore(conn)
+	if err != nil {
+		log.Fatalf("failed to prepare client store: %v", err)
+	}
+	client, secret, err := oauthhttp.NewClientRegistry(store).Register(ctx, *clientID, *name, strings.FieldsFunc(*scopes, isScopeSeparator))
+	if err != nil {
+		log.Fatalf("failed to register client: %v", err)
+	}
+
+	// 5.- Print the credentials for the operator to hand to the client.
+	fmt.Printf("client_id=%s\nclient_secret=%s\nscopes=%s\n", client.ClientID, secret, strings.Join(client.Scopes, " "))
+}
+
+// 1.- isScopeSeparator splits the -scopes flag on commas and whitespace.
+func isScopeSeparator(r rune) bool {
+	return r == ',' || unicode.IsSpace(r)
+}
@@ -2,16 +2,26 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
-	redis "github.com/redis/go-redis/v9"
+	_ "github.com/lib/pq"
 	"github.com/robfig/cron/v3"
 
+	appconfig "github.com/example/Yamato-Go-Gin-API/config"
+	"github.com/example/Yamato-Go-Gin-API/internal/config"
+	privacyhttp "github.com/example/Yamato-Go-Gin-API/internal/http/privacy"
+	"github.com/example/Yamato-Go-Gin-API/internal/platform/redisclient"
 	"github.com/example/Yamato-Go-Gin-API/internal/queue"
+	"github.com/example/Yamato-Go-Gin-API/internal/storage"
+	storageprivacy "github.com/example/Yamato-Go-Gin-API/internal/storage/privacy"
+	dbtooling "github.com/example/Yamato-Go-Gin-API/internal/tooling/db"
 )
 
 // stdoutNotifier is a demo implementation that writes fan-out results to stdout.
@@ -30,6 +40,14 @@ func (stdoutEmailSender) Send(ctx context.Context, to string, subject string, bo
 	return nil
 }
 
+// stdoutSMSSender is the local SMS transport: it prints messages, including codes, instead of texting them.
+type stdoutSMSSender struct{}
+
+func (stdoutSMSSender) Send(ctx context.Context, to string, body string) error {
+	fmt.Printf("sms -> to=%s body=%q\n", to, body)
+	return nil
+}
+
 // stdoutWebhookDispatcher mimics HTTP dispatch for local testing.
 type stdoutWebhookDispatcher struct{}
 
@@ -43,12 +61,13 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
 	defer cancel()
 
-	// 2.- Connect to Redis using environment-provided configuration.
-	redisAddr := os.Getenv("REDIS_ADDR")
-	if redisAddr == "" {
-		redisAddr = "localhost:6379"
+	// 2.- Connect to Redis with the same REDIS_* settings as the API; the worker always needs a real server.
+	redisCfg, err := config.LoadRedis("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load redis configuration: %v\n", err)
+		os.Exit(1)
 	}
-	client := redis.NewClient(&redis.Options{Addr: redisAddr})
+	client := redisclient.NewClient(redisCfg)
 	defer client.Close()
 
 	// 3.- Build the queue and register all job handlers.
@@ -56,8 +75,24 @@ func main() {
 	cronEngine := cron.New()
 	_ = q.Register(queue.NewNotificationFanoutJob(stdoutNotifier{}))
 	_ = q.Register(queue.NewEmailSendJob(stdoutEmailSender{}))
+	_ = q.Register(queue.NewSMSSendJob(stdoutSMSSender{}))
 	_ = q.Register(queue.NewWebhookDispatchJob(stdoutWebhookDispatcher{}))
-	_ = q.Register(queue.NewSchedulerBootstrapJob(cronEngine, nil, q.Enqueue))
+	_ = q.Register(queue.NewSchedulerBootstrapJob(cronEngine, loadJobsConfig, q.Enqueue))
+
+	// 3.1.- Personal data exports and account purges read Postgres and the export directory shared with the API.
+	db, err := openDatabase(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to connect to postgres: %v\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+	privacySvc, err := newPrivacyService(db, q)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to configure privacy jobs: %v\n", err)
+		os.Exit(1)
+	}
+	_ = q.Register(queue.NewUserExportJob(privacySvc))
+	_ = q.Register(queue.NewUserPurgeJob(privacySvc))
 
 	// 4.- Enqueue the scheduler bootstrapper so cron entries are loaded.
 	if _, err := q.Enqueue(ctx, "scheduler_bootstrap", map[string]any{}); err != nil {
@@ -78,3 +113,62 @@ func main() {
 	cronEngine.Stop()
 	time.Sleep(500 * time.Millisecond)
 }
+
+// openDatabase connects to Postgres using the same DB_* settings as the API.
+func openDatabase(ctx context.Context) (*sql.DB, error) {
+	dsn, err := dbtooling.BuildPostgresDSNFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := db.PingContext(pingCtx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// newPrivacyService mirrors the API wiring so both processes agree on storage paths and the grace period.
+func newPrivacyService(db *sql.DB, q *queue.RedisQueue) (*privacyhttp.Service, error) {
+	store, err := storageprivacy.NewStore(db)
+	if err != nil {
+		return nil, err
+	}
+	exportPath := strings.TrimSpace(os.Getenv("DATA_EXPORT_PATH"))
+	if exportPath == "" {
+		exportPath = "storage/exports"
+	}
+	files, err := storage.NewFileService("local", exportPath, storage.StaticSettingsProvider{Settings: storage.FileUploadSettings{
+		AllowedMIMETypes: []string{"application/zip"},
+	}})
+	if err != nil {
+		return nil, err
+	}
+	grace := 30 * 24 * time.Hour
+	if days, convErr := strconv.Atoi(os.Getenv("ACCOUNT_PURGE_GRACE_DAYS")); convErr == nil && days > 0 {
+		grace = time.Duration(days) * 24 * time.Hour
+	}
+	return privacyhttp.NewService(store, store, store, files, q, privacyhttp.Config{GracePeriod: grace}), nil
+}
+
+// loadJobsConfig adds an hourly account purge unless JOB_CRON_ENTRIES already schedules one.
+func loadJobsConfig() appconfig.JobsConfig {
+	cfg := appconfig.LoadJobsConfig()
+	for _, entry := range cfg.CronEntries {
+		if entry.Job == queue.UserPurgeJobName {
+			return cfg
+		}
+	}
+	cfg.CronEntries = append(cfg.CronEntries, appconfig.CronEntry{
+		Name:    "purge_deleted_accounts",
+		Spec:    "@hourly",
+		Job:     queue.UserPurgeJobName,
+		Payload: map[string]any{},
+	})
+	return cfg
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 1.- Access lists the role names and permission slugs granted to a subject.
+type Access struct {
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+}
+
+// 1.- Revision fingerprints the grant set so caches keyed by subject can detect changes.
+func (a Access) Revision() string {
+	digest := sha256.New()
+	for _, role := range a.Roles {
+		digest.Write([]byte("r:" + role + "\n"))
+	}
+	for _, permission := range a.Permissions {
+		digest.Write([]byte("p:" + permission + "\n"))
+	}
+	return hex.EncodeToString(digest.Sum(nil)[:8])
+}
+
+// 1.- AccessLoader resolves a subject's current roles and permissions from the system of record.
+type AccessLoader interface {
+	LoadAccess(ctx context.Context, subject string) (Access, error)
+}
+
+// 1.- accessVersionKey holds a counter bumped whenever role or permission definitions change.
+const accessVersionKey = "auth:access-version"
+
+// 1.- AccessCache memoizes an AccessLoader in Redis so each request avoids the RBAC joins.
+type AccessCache struct {
+	loader AccessLoader
+	redis  RedisCommander
+	ttl    time.Duration
+}
+
+// 1.- NewAccessCache wraps the loader with a TTL-bound Redis cache.
+func NewAccessCache(loader AccessLoader, client RedisCommander, ttl time.Duration) (*AccessCache, error) {
+	if loader == nil {
+		return nil, errors.New("auth: access loader is required")
+	}
+	if client == nil {
+		return nil, errors.New("auth: redis client is required")
+	}
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+	return &AccessCache{loader: loader, redis: client, ttl: ttl}, nil
+}
+
+// 1.- LoadAccess serves the cached entry for the current version or reloads it.
+func (c *AccessCache) LoadAccess(ctx context.Context, subject string) (Access, error) {
+	key, err := c.key(ctx, subject)
+	if err != nil {
+		return Access{}, err
+	}
+
+	raw, err := c.redis.Get(ctx, key).Result()
+	switch {
+	case err == nil:
+		var access Access
+		if json.Unmarshal([]byte(raw), &access) == nil {
+			return access, nil
+		}
+	case !errors.Is(err, redis.Nil):
+		return Access{}, fmt.Errorf("auth: load cached access: %w", err)
+	}
+
+	access, err := c.loader.LoadAccess(ctx, subject)
+	if err != nil {
+		return Access{}, err
+	}
+	if access.Roles == nil {
+		access.Roles = []string{}
+	}
+	if access.Permissions == nil {
+		access.Permissions = []string{}
+	}
+	payload, err := json.Marshal(access)
+	if err != nil {
+		return Access{}, fmt.Errorf("auth: encode access: %w", err)
+	}
+	if err := c.redis.Set(ctx, key, string(payload), c.ttl).Err(); err != nil {
+		return Access{}, fmt.Errorf("auth: cache access: %w", err)
+	}
+	return access, nil
+}
+
+// 1.- InvalidateSubject drops the cached entry after the subject's role assignments change.
+func (c *AccessCache) InvalidateSubject(ctx context.Context, subject string) error {
+	key, err := c.key(ctx, subject)
+	if err != nil {
+		return err
+	}
+	if err := c.redis.Del(ctx, key).Err(); err != nil {
+		return fmt.Errorf("auth: invalidate access: %w", err)
+	}
+	return nil
+}
+
+// 1.- InvalidateAll bumps the version so every cached entry is ignored; stale keys age out with the TTL.
+func (c *AccessCache) InvalidateAll(ctx context.Context) error {
+	if err := c.redis.Incr(ctx, accessVersionKey).Err(); err != nil {
+		return fmt.Errorf("auth: bump access version: %w", err)
+	}
+	return nil
+}
+
+// 1.- key builds the versioned cache key for the subject.
+func (c *AccessCache) key(ctx context.Context, subject string) (string, error) {
+	version, err := c.redis.Get(ctx, accessVersionKey).Result()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			return "", fmt.Errorf("auth: load access version: %w", err)
+		}
+		version = "0"
+	}
+	return "auth:access:" + version + ":" + subject, nil
+}
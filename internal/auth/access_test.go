@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// 1.- countingLoader serves a mutable grant set and counts how often it is consulted.
+type countingLoader struct {
+	access Access
+	calls  int
+}
+
+func (l *countingLoader) LoadAccess(_ context.Context, _ string) (Access, error) {
+	l.calls++
+	return l.access, nil
+}
+
+func TestAccessCacheServesCachedGrantsUntilInvalidated(t *testing.T) {
+	//1.- Prime the cache with the editor role.
+	_, client := newTestService(t)
+	loader := &countingLoader{access: Access{Roles: []string{"editor"}, Permissions: []string{"posts.write"}}}
+	cache, err := NewAccessCache(loader, client, time.Minute)
+	if err != nil {
+		t.Fatalf("NewAccessCache returned error: %v", err)
+	}
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		access, err := cache.LoadAccess(ctx, "42")
+		if err != nil {
+			t.Fatalf("LoadAccess returned error: %v", err)
+		}
+		if len(access.Permissions) != 1 || access.Permissions[0] != "posts.write" {
+			t.Fatalf("unexpected permissions: %v", access.Permissions)
+		}
+	}
+	if loader.calls != 1 {
+		t.Fatalf("expected a single load, got %d", loader.calls)
+	}
+	if ttl := client.TTL(ctx, "auth:access:0:42").Val(); ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("expected cache entry to carry the ttl, got %s", ttl)
+	}
+
+	//1.- Subject invalidation reloads only that subject.
+	loader.access = Access{Roles: []string{"admin"}, Permissions: []string{"admin.users.manage"}}
+	if err := cache.InvalidateSubject(ctx, "42"); err != nil {
+		t.Fatalf("InvalidateSubject returned error: %v", err)
+	}
+	access, err := cache.LoadAccess(ctx, "42")
+	if err != nil || len(access.Roles) != 1 || access.Roles[0] != "admin" {
+		t.Fatalf("expected reloaded admin role, got %v (%v)", access.Roles, err)
+	}
+	if _, err := cache.LoadAccess(ctx, "7"); err != nil {
+		t.Fatalf("LoadAccess returned error: %v", err)
+	}
+	if loader.calls != 3 {
+		t.Fatalf("expected three loads, got %d", loader.calls)
+	}
+
+	//1.- Global invalidation forces every subject to reload.
+	if err := cache.InvalidateAll(ctx); err != nil {
+		t.Fatalf("InvalidateAll returned error: %v", err)
+	}
+	for _, subject := range []string{"42", "7"} {
+		if _, err := cache.LoadAccess(ctx, subject); err != nil {
+			t.Fatalf("LoadAccess returned error: %v", err)
+		}
+	}
+	if loader.calls != 5 {
+		t.Fatalf("expected every subject to reload after InvalidateAll, got %d loads", loader.calls)
+	}
+}
@@ -1,6 +1,10 @@
 package auth
 
-import "github.com/gin-gonic/gin"
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
 
 const principalContextKey = "auth.principal"
 
@@ -9,6 +13,28 @@ type Principal struct {
 	Subject     string
 	Roles       []string
 	Permissions []string
+	// 2.- SessionID identifies the refresh family behind the presented access token.
+	SessionID string
+	// 3.- TokenID identifies the personal access token when the request used one instead of a JWT.
+	TokenID string
+	// 4.- AccessRevision fingerprints the loaded grants so policy caches notice role changes.
+	AccessRevision string
+	// 5.- Actor names the administrator acting as Subject when the request used an impersonation token.
+	Actor string
+	// 6.- ClientID names the OAuth client when the principal is a service account rather than a user.
+	ClientID string
+	// 7.- AuthenticatedAt is when the user last signed in; it survives refreshes and is zero for other token kinds.
+	AuthenticatedAt time.Time
+}
+
+// 1.- AuthenticatedWithin reports whether the principal signed in no longer than window before now.
+func (p Principal) AuthenticatedWithin(window time.Duration, now time.Time) bool {
+	return !p.AuthenticatedAt.IsZero() && now.Sub(p.AuthenticatedAt) <= window
+}
+
+// 1.- IsServiceAccount reports whether the principal authenticated through the client_credentials grant.
+func (p Principal) IsServiceAccount() bool {
+	return p.ClientID != ""
 }
 
 // 1.- HasRole verifies whether the principal owns the provided role slug.
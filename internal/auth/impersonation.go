@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// 1.- ErrSelfImpersonation rejects attempts to impersonate the acting account itself.
+var ErrSelfImpersonation = errors.New("auth: cannot impersonate yourself")
+
+// 1.- ErrImpersonationNotFound reports an impersonation token that was never issued, already ended, or expired.
+var ErrImpersonationNotFound = errors.New("auth: impersonation session not found")
+
+// 1.- PermissionImpersonate is the grant an actor must still hold for its impersonation tokens to be honoured.
+const PermissionImpersonate = "admin.users.impersonate"
+
+// 1.- Impersonation tokens default to a quarter hour and can never outlive an hour.
+const (
+	impersonationDefaultTTL = 15 * time.Minute
+	impersonationMaxTTL     = time.Hour
+)
+
+// 1.- ActorClaim identifies the party actually presenting a token issued for another subject.
+type ActorClaim struct {
+	Subject string `json:"sub"`
+}
+
+// 1.- ImpersonationSession names both parties behind an issued impersonation token.
+type ImpersonationSession struct {
+	TokenID string `json:"-"`
+	Actor   string `json:"actor"`
+	Subject string `json:"subject"`
+}
+
+// 1.- ImpersonationToken describes an issued impersonation access token.
+type ImpersonationToken struct {
+	AccessToken string
+	TokenID     string
+	ExpiresAt   time.Time
+}
+
+// 1.- Impersonate mints a short-lived access token for subject carrying actor in its act claim.
+func (s *Service) Impersonate(ctx context.Context, actor string, subject string, ttl time.Duration) (ImpersonationToken, error) {
+	if actor == "" || subject == "" {
+		return ImpersonationToken{}, ErrInvalidToken
+	}
+	if actor == subject {
+		return ImpersonationToken{}, ErrSelfImpersonation
+	}
+	if ttl <= 0 {
+		ttl = impersonationDefaultTTL
+	}
+	if ttl > impersonationMaxTTL {
+		ttl = impersonationMaxTTL
+	}
+
+	//1.- No refresh token or session family is created; the token dies at expiry unless it is ended first.
+	now := s.now()
+	claims := accessClaims{
+		Actor: &ActorClaim{Subject: actor},
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			Issuer:    s.cfg.Issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			ID:        uuid.NewString(),
+		},
+	}
+	if s.cfg.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{s.cfg.Audience}
+	}
+
+	token, err := s.keys.sign(claims)
+	if err != nil {
+		return ImpersonationToken{}, fmt.Errorf("auth: sign impersonation token: %w", err)
+	}
+
+	//2.- Record the token ID under both parties so ending the session or RevokeAll can blacklist it.
+	record, err := json.Marshal(ImpersonationSession{Actor: actor, Subject: subject})
+	if err != nil {
+		return ImpersonationToken{}, fmt.Errorf("auth: encode impersonation session: %w", err)
+	}
+	if err := s.redis.Set(ctx, impersonationKey(claims.ID), string(record), ttl).Err(); err != nil {
+		return ImpersonationToken{}, fmt.Errorf("auth: persist impersonation session: %w", err)
+	}
+	for _, party := range []string{actor, subject} {
+		if err := s.redis.SAdd(ctx, userImpersonationsKey(party), claims.ID).Err(); err != nil {
+			return ImpersonationToken{}, fmt.Errorf("auth: index impersonation session: %w", err)
+		}
+		if err := s.redis.Expire(ctx, userImpersonationsKey(party), impersonationMaxTTL).Err(); err != nil {
+			return ImpersonationToken{}, fmt.Errorf("auth: expire impersonation index: %w", err)
+		}
+	}
+
+	return ImpersonationToken{AccessToken: token, TokenID: claims.ID, ExpiresAt: claims.ExpiresAt.Time}, nil
+}
+
+// 1.- EndImpersonation blacklists a live impersonation token and returns who it belonged to.
+func (s *Service) EndImpersonation(ctx context.Context, tokenID string) (ImpersonationSession, error) {
+	raw, err := s.redis.Get(ctx, impersonationKey(tokenID)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return ImpersonationSession{}, ErrImpersonationNotFound
+		}
+		return ImpersonationSession{}, fmt.Errorf("auth: load impersonation session: %w", err)
+	}
+	var session ImpersonationSession
+	if err := json.Unmarshal([]byte(raw), &session); err != nil {
+		return ImpersonationSession{}, fmt.Errorf("auth: decode impersonation session: %w", err)
+	}
+	session.TokenID = tokenID
+
+	if err := s.blacklistImpersonation(ctx, tokenID); err != nil {
+		return ImpersonationSession{}, err
+	}
+	for _, party := range []string{session.Actor, session.Subject} {
+		if err := s.redis.SRem(ctx, userImpersonationsKey(party), tokenID).Err(); err != nil && !errors.Is(err, redis.Nil) {
+			return ImpersonationSession{}, fmt.Errorf("auth: unindex impersonation session: %w", err)
+		}
+	}
+	return session, nil
+}
+
+// 1.- revokeImpersonations blacklists every impersonation token issued to or by the subject.
+func (s *Service) revokeImpersonations(ctx context.Context, subject string) error {
+	tokenIDs, err := s.redis.SMembers(ctx, userImpersonationsKey(subject)).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("auth: list impersonation sessions: %w", err)
+	}
+	for _, tokenID := range tokenIDs {
+		if err := s.blacklistImpersonation(ctx, tokenID); err != nil {
+			return err
+		}
+	}
+	if _, err := s.redis.Del(ctx, userImpersonationsKey(subject)).Result(); err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("auth: clear impersonation sessions: %w", err)
+	}
+	return nil
+}
+
+// 1.- blacklistImpersonation rejects the token for the longest lifetime an impersonation token can have.
+func (s *Service) blacklistImpersonation(ctx context.Context, tokenID string) error {
+	if err := s.redis.Set(ctx, accessBlacklistKey(tokenID), "1", impersonationMaxTTL).Err(); err != nil {
+		return fmt.Errorf("auth: blacklist impersonation token: %w", err)
+	}
+	if _, err := s.redis.Del(ctx, impersonationKey(tokenID)).Result(); err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("auth: delete impersonation session: %w", err)
+	}
+	return nil
+}
+
+// 1.- impersonationKey stores the parties behind a live impersonation token.
+func impersonationKey(tokenID string) string {
+	return fmt.Sprintf("auth:impersonation:%s", tokenID)
+}
+
+// 1.- userImpersonationsKey indexes the impersonation tokens a user issued or is the subject of.
+func userImpersonationsKey(subject string) string {
+	return fmt.Sprintf("auth:user-impersonations:%s", subject)
+}
@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 1.- Token type identifiers used as RFC 7662/7009 token_type_hint values.
+const (
+	TokenTypeAccess  = "access_token"
+	TokenTypeRefresh = "refresh_token"
+)
+
+// 1.- TokenInfo is the RFC 7662 view of a token; only Active is meaningful when the token is not active.
+type TokenInfo struct {
+	Active    bool
+	TokenType string
+	Subject   string
+	Scope     string
+	Issuer    string
+	Audience  []string
+	JTI       string
+	SessionID string
+	Actor     string
+	ClientID  string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// 1.- Introspect reports whether a JWT is currently usable; invalid or revoked tokens are inactive, not errors.
+func (s *Service) Introspect(ctx context.Context, token string, hint string) (TokenInfo, error) {
+	for _, tokenType := range tokenTypeOrder(hint) {
+		var (
+			info TokenInfo
+			err  error
+		)
+		if tokenType == TokenTypeRefresh {
+			info, err = s.introspectRefresh(ctx, token)
+		} else {
+			info, err = s.introspectAccess(ctx, token)
+		}
+		if err != nil || info.Active {
+			return info, err
+		}
+	}
+	return TokenInfo{}, nil
+}
+
+// 1.- Revoke invalidates an access token by JTI or a refresh token together with its family.
+// Tokens that do not parse are ignored, as RFC 7009 requires.
+func (s *Service) Revoke(ctx context.Context, token string, hint string) error {
+	for _, tokenType := range tokenTypeOrder(hint) {
+		if tokenType == TokenTypeRefresh {
+			claims, err := s.parseRefreshClaims(token)
+			if err != nil {
+				continue
+			}
+			return s.blacklistFamily(ctx, claims.FamilyID)
+		}
+
+		claims, err := s.parseAccessClaims(token)
+		if err != nil {
+			continue
+		}
+		ttl := time.Until(claims.ExpiresAt.Time)
+		if ttl <= 0 {
+			return nil
+		}
+		if err := s.redis.Set(ctx, accessBlacklistKey(claims.ID), "1", ttl).Err(); err != nil {
+			return fmt.Errorf("auth: blacklist access token: %w", err)
+		}
+		return nil
+	}
+	return nil
+}
+
+// 1.- introspectAccess validates an access token with the same checks the middleware applies.
+func (s *Service) introspectAccess(ctx context.Context, token string) (TokenInfo, error) {
+	claims, err := s.ValidateAccessToken(ctx, token)
+	if err != nil {
+		if isTokenRejection(err) {
+			return TokenInfo{}, nil
+		}
+		return TokenInfo{}, err
+	}
+	info := TokenInfo{
+		Active:    true,
+		TokenType: TokenTypeAccess,
+		Subject:   claims.Subject,
+		Scope:     claims.Scope,
+		Issuer:    claims.Issuer,
+		Audience:  claims.Audience,
+		JTI:       claims.ID,
+		SessionID: claims.FamilyID,
+		ClientID:  claims.ClientID,
+	}
+	if claims.Actor != nil {
+		info.Actor = claims.Actor.Subject
+	}
+	if claims.IssuedAt != nil {
+		info.IssuedAt = claims.IssuedAt.Time
+	}
+	if claims.ExpiresAt != nil {
+		info.ExpiresAt = claims.ExpiresAt.Time
+	}
+	return info, nil
+}
+
+// 1.- introspectRefresh treats a refresh token as active only while it is the family's current rotation.
+func (s *Service) introspectRefresh(ctx context.Context, token string) (TokenInfo, error) {
+	claims, err := s.parseRefreshClaims(token)
+	if err != nil {
+		return TokenInfo{}, nil
+	}
+	revoked, err := s.isFamilyBlacklisted(ctx, claims.FamilyID)
+	if err != nil {
+		return TokenInfo{}, err
+	}
+	if revoked {
+		return TokenInfo{}, nil
+	}
+	current, err := s.redis.Get(ctx, refreshFamilyKey(claims.FamilyID)).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return TokenInfo{}, fmt.Errorf("auth: lookup refresh family: %w", err)
+	}
+	if current != claims.ID {
+		return TokenInfo{}, nil
+	}
+	info := TokenInfo{
+		Active:    true,
+		TokenType: TokenTypeRefresh,
+		Subject:   claims.Subject,
+		Issuer:    claims.Issuer,
+		Audience:  claims.Audience,
+		JTI:       claims.ID,
+		SessionID: claims.FamilyID,
+	}
+	if claims.IssuedAt != nil {
+		info.IssuedAt = claims.IssuedAt.Time
+	}
+	if claims.ExpiresAt != nil {
+		info.ExpiresAt = claims.ExpiresAt.Time
+	}
+	return info, nil
+}
+
+// 1.- tokenTypeOrder tries the hinted type first; unknown hints fall back to the default order.
+func tokenTypeOrder(hint string) []string {
+	if strings.TrimSpace(hint) == TokenTypeRefresh {
+		return []string{TokenTypeRefresh, TokenTypeAccess}
+	}
+	return []string{TokenTypeAccess, TokenTypeRefresh}
+}
+
+// 1.- isTokenRejection separates "this token is not valid" from backend failures.
+func isTokenRejection(err error) bool {
+	return errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrBlacklisted)
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"testing"
+)
+
+func TestIntrospectReportsActiveTokensUntilRevoked(t *testing.T) {
+	//1.- Issue a session and introspect both tokens.
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+
+	pair, err := svc.Login(ctx, "user-123")
+	if err != nil {
+		t.Fatalf("Login returned error: %v", err)
+	}
+	access, err := svc.Introspect(ctx, pair.AccessToken, "")
+	if err != nil {
+		t.Fatalf("Introspect returned error: %v", err)
+	}
+	if !access.Active || access.TokenType != TokenTypeAccess || access.Subject != "user-123" || access.JTI == "" || access.ExpiresAt.IsZero() {
+		t.Fatalf("unexpected access token info: %#v", access)
+	}
+	refresh, err := svc.Introspect(ctx, pair.RefreshToken, TokenTypeRefresh)
+	if err != nil {
+		t.Fatalf("Introspect returned error: %v", err)
+	}
+	if !refresh.Active || refresh.TokenType != TokenTypeRefresh || refresh.SessionID != access.SessionID {
+		t.Fatalf("unexpected refresh token info: %#v", refresh)
+	}
+
+	//2.- Garbage is inactive rather than an error, and revoking it is a no-op.
+	if info, err := svc.Introspect(ctx, "not-a-token", ""); err != nil || info.Active {
+		t.Fatalf("expected inactive garbage token, got %#v (%v)", info, err)
+	}
+	if err := svc.Revoke(ctx, "not-a-token", ""); err != nil {
+		t.Fatalf("expected revoking garbage to succeed, got %v", err)
+	}
+
+	//3.- Revoking the access token leaves the refresh token usable.
+	if err := svc.Revoke(ctx, pair.AccessToken, TokenTypeAccess); err != nil {
+		t.Fatalf("Revoke returned error: %v", err)
+	}
+	if info, _ := svc.Introspect(ctx, pair.AccessToken, ""); info.Active {
+		t.Fatal("expected revoked access token to be inactive")
+	}
+	if info, _ := svc.Introspect(ctx, pair.RefreshToken, TokenTypeRefresh); !info.Active {
+		t.Fatal("expected refresh token to remain active")
+	}
+
+	//4.- Revoking the refresh token ends the whole family, including fresh access tokens.
+	rotated, err := svc.Refresh(ctx, pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh returned error: %v", err)
+	}
+	if info, _ := svc.Introspect(ctx, pair.RefreshToken, TokenTypeRefresh); info.Active {
+		t.Fatal("expected rotated-out refresh token to be inactive")
+	}
+	if err := svc.Revoke(ctx, rotated.RefreshToken, TokenTypeRefresh); err != nil {
+		t.Fatalf("Revoke returned error: %v", err)
+	}
+	if info, _ := svc.Introspect(ctx, rotated.AccessToken, ""); info.Active {
+		t.Fatal("expected access token of revoked family to be inactive")
+	}
+	if _, err := svc.Refresh(ctx, rotated.RefreshToken); err == nil {
+		t.Fatal("expected refresh after revocation to fail")
+	}
+}
@@ -0,0 +1,346 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/example/Yamato-Go-Gin-API/internal/config"
+)
+
+// 1.- Supported signing algorithms for access and refresh tokens.
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// 1.- JWK describes a single public key using the RFC 7517 JSON representation.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// 1.- JWKS is the key set published at /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// 1.- verificationKey binds a public key to the algorithm it is allowed to verify.
+type verificationKey struct {
+	id     string
+	method jwt.SigningMethod
+	key    crypto.PublicKey
+}
+
+// 1.- keyRing holds the active signing key plus every key still accepted for verification.
+type keyRing struct {
+	method    jwt.SigningMethod
+	keyID     string
+	signKey   interface{}
+	secret    []byte
+	verifiers map[string]verificationKey
+}
+
+// 1.- newKeyRing loads signing and verification material described by the JWT configuration.
+func newKeyRing(cfg config.JWTConfig) (*keyRing, error) {
+	ring := &keyRing{verifiers: map[string]verificationKey{}}
+
+	//1.- Resolve the signing key according to the configured algorithm.
+	switch strings.ToUpper(strings.TrimSpace(cfg.Algorithm)) {
+	case "", strings.ToUpper(AlgorithmHS256):
+		if cfg.Secret == "" {
+			return nil, errors.New("auth: jwt secret must be configured")
+		}
+		ring.secret = []byte(cfg.Secret)
+		ring.method = jwt.SigningMethodHS256
+		ring.keyID = cfg.KeyID
+		ring.signKey = ring.secret
+	case strings.ToUpper(AlgorithmRS256), strings.ToUpper(AlgorithmEdDSA):
+		if strings.TrimSpace(cfg.PrivateKeyFile) == "" {
+			return nil, errors.New("auth: jwt private key file must be configured")
+		}
+		pemBytes, err := os.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("auth: read private key: %w", err)
+		}
+		private, public, method, err := parsePrivateKey(pemBytes)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(method.Alg(), cfg.Algorithm) {
+			return nil, fmt.Errorf("auth: private key does not match algorithm %s", cfg.Algorithm)
+		}
+		ring.method = method
+		ring.signKey = private
+		ring.keyID = cfg.KeyID
+		if ring.keyID == "" {
+			ring.keyID = thumbprint(public)
+		}
+		ring.verifiers[ring.keyID] = verificationKey{id: ring.keyID, method: method, key: public}
+		//2.- A shared secret only verifies HS256 tokens here when explicitly allowed.
+		if cfg.AcceptHS256 {
+			if cfg.Secret == "" {
+				return nil, errors.New("auth: jwt secret must be configured to accept HS256 tokens")
+			}
+			ring.secret = []byte(cfg.Secret)
+		}
+	default:
+		return nil, fmt.Errorf("auth: unsupported jwt algorithm %q", cfg.Algorithm)
+	}
+
+	//1.- Register retired public keys so tokens signed before a rotation keep validating.
+	for kid, path := range cfg.VerificationKeys {
+		if _, exists := ring.verifiers[kid]; exists {
+			continue
+		}
+		pemBytes, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("auth: read verification key %s: %w", kid, err)
+		}
+		public, method, err := parsePublicKey(pemBytes)
+		if err != nil {
+			return nil, fmt.Errorf("auth: verification key %s: %w", kid, err)
+		}
+		ring.verifiers[kid] = verificationKey{id: kid, method: method, key: public}
+	}
+
+	return ring, nil
+}
+
+// 1.- sign serializes the claims with the active key and stamps the kid header when known.
+func (k *keyRing) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.method, claims)
+	if k.keyID != "" {
+		token.Header["kid"] = k.keyID
+	}
+	return token.SignedString(k.signKey)
+}
+
+// 1.- keyFunc selects the verification key matching the token's kid and algorithm.
+func (k *keyRing) keyFunc(token *jwt.Token) (interface{}, error) {
+	//1.- Shared secrets only ever verify HMAC tokens to prevent algorithm confusion.
+	if token.Method.Alg() == jwt.SigningMethodHS256.Alg() {
+		if k.secret == nil {
+			return nil, ErrInvalidToken
+		}
+		return k.secret, nil
+	}
+
+	//1.- Asymmetric tokens must reference a known kid, defaulting to the active key.
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = k.keyID
+	}
+	verifier, ok := k.verifiers[kid]
+	if !ok || verifier.method.Alg() != token.Method.Alg() {
+		return nil, ErrInvalidToken
+	}
+	return verifier.key, nil
+}
+
+// 1.- methods lists every algorithm accepted while parsing tokens.
+func (k *keyRing) methods() []string {
+	seen := map[string]struct{}{}
+	if k.secret != nil {
+		seen[jwt.SigningMethodHS256.Alg()] = struct{}{}
+	}
+	for _, verifier := range k.verifiers {
+		seen[verifier.method.Alg()] = struct{}{}
+	}
+	methods := make([]string, 0, len(seen))
+	for alg := range seen {
+		methods = append(methods, alg)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// 1.- jwks renders every asymmetric verification key as a public JWK.
+func (k *keyRing) jwks() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, verifier := range k.verifiers {
+		if jwk, ok := toJWK(verifier.id, verifier.method, verifier.key); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	//1.- Keep the active key first and the rest ordered for stable responses.
+	sort.Slice(set.Keys, func(i, j int) bool {
+		if set.Keys[i].Kid == k.keyID || set.Keys[j].Kid == k.keyID {
+			return set.Keys[i].Kid == k.keyID
+		}
+		return set.Keys[i].Kid < set.Keys[j].Kid
+	})
+	return set
+}
+
+// 1.- parsePrivateKey decodes an RSA or Ed25519 private key in PEM form.
+func parsePrivateKey(pemBytes []byte) (interface{}, crypto.PublicKey, jwt.SigningMethod, error) {
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes); err == nil {
+		return key, &key.PublicKey, jwt.SigningMethodRS256, nil
+	}
+	if key, err := jwt.ParseEdPrivateKeyFromPEM(pemBytes); err == nil {
+		edKey, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, nil, nil, errors.New("auth: unsupported private key type")
+		}
+		return edKey, edKey.Public(), jwt.SigningMethodEdDSA, nil
+	}
+	return nil, nil, nil, errors.New("auth: private key must be an RSA or Ed25519 PEM block")
+}
+
+// 1.- parsePublicKey decodes an RSA or Ed25519 public key in PEM form.
+func parsePublicKey(pemBytes []byte) (crypto.PublicKey, jwt.SigningMethod, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(pemBytes); err == nil {
+		return key, jwt.SigningMethodRS256, nil
+	}
+	if key, err := jwt.ParseEdPublicKeyFromPEM(pemBytes); err == nil {
+		return key, jwt.SigningMethodEdDSA, nil
+	}
+	return nil, nil, errors.New("auth: public key must be an RSA or Ed25519 PEM block")
+}
+
+// 1.- toJWK converts a supported public key to its JWK representation.
+func toJWK(kid string, method jwt.SigningMethod, key crypto.PublicKey) (JWK, bool) {
+	switch public := key.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			Alg: method.Alg(),
+			N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Kid: kid,
+			Use: "sig",
+			Alg: method.Alg(),
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(public),
+		}, true
+	default:
+		return JWK{}, false
+	}
+}
+
+// 1.- ParseJWK converts a published RSA, EC P-256, or Ed25519 JWK into a verification key and its algorithm.
+func ParseJWK(jwk JWK) (crypto.PublicKey, jwt.SigningMethod, error) {
+	decode := func(field string, value string) ([]byte, error) {
+		raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+		if err != nil || len(raw) == 0 {
+			return nil, fmt.Errorf("auth: jwk %q has invalid %s", jwk.Kid, field)
+		}
+		return raw, nil
+	}
+
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decode("n", jwk.N)
+		if err != nil {
+			return nil, nil, err
+		}
+		e, err := decode("e", jwk.E)
+		if err != nil {
+			return nil, nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > math.MaxInt32 {
+			return nil, nil, fmt.Errorf("auth: jwk %q has invalid e", jwk.Kid)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, jwt.SigningMethodRS256, nil
+	case "EC":
+		if jwk.Crv != "P-256" {
+			return nil, nil, fmt.Errorf("auth: jwk %q uses unsupported curve %q", jwk.Kid, jwk.Crv)
+		}
+		x, err := decode("x", jwk.X)
+		if err != nil {
+			return nil, nil, err
+		}
+		y, err := decode("y", jwk.Y)
+		if err != nil {
+			return nil, nil, err
+		}
+		//1.- Reject points off the curve so forged keys cannot reach signature checks.
+		point := append([]byte{0x04}, append(leftPad(x, 32), leftPad(y, 32)...)...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, nil, fmt.Errorf("auth: jwk %q is not on curve P-256", jwk.Kid)
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, jwt.SigningMethodES256, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, nil, fmt.Errorf("auth: jwk %q uses unsupported curve %q", jwk.Kid, jwk.Crv)
+		}
+		x, err := decode("x", jwk.X)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, nil, fmt.Errorf("auth: jwk %q has invalid x", jwk.Kid)
+		}
+		return ed25519.PublicKey(x), jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, nil, fmt.Errorf("auth: jwk %q uses unsupported key type %q", jwk.Kid, jwk.Kty)
+	}
+}
+
+// 1.- leftPad widens a big-endian coordinate to the curve's fixed byte length.
+func leftPad(value []byte, size int) []byte {
+	if len(value) >= size {
+		return value
+	}
+	padded := make([]byte, size)
+	copy(padded[size-len(value):], value)
+	return padded
+}
+
+// 1.- thumbprint derives an RFC 7638 key identifier when none was configured.
+func thumbprint(key crypto.PublicKey) string {
+	var canonical []byte
+	switch public := key.(type) {
+	case *rsa.PublicKey:
+		canonical, _ = json.Marshal(struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+		})
+	case ed25519.PublicKey:
+		canonical, _ = json.Marshal(struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{Crv: "Ed25519", Kty: "OKP", X: base64.RawURLEncoding.EncodeToString(public)})
+	default:
+		return ""
+	}
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,271 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/example/Yamato-Go-Gin-API/internal/config"
+)
+
+// 1.- writeKeyPair stores a PKCS#8 private key and its PKIX public key in the temp directory.
+func writeKeyPair(t *testing.T, name string, private interface{}, public interface{}) (string, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatalf("failed to marshal private key: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatalf("failed to marshal public key: %v", err)
+	}
+
+	privatePath := filepath.Join(dir, name+".pem")
+	publicPath := filepath.Join(dir, name+".pub.pem")
+	if err := os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600); err != nil {
+		t.Fatalf("failed to write private key: %v", err)
+	}
+	if err := os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o600); err != nil {
+		t.Fatalf("failed to write public key: %v", err)
+	}
+	return privatePath, publicPath
+}
+
+// 1.- newKeyedService builds a Service for the provided JWT configuration backed by miniredis.
+func newKeyedService(t *testing.T, cfg config.JWTConfig) *Service {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+
+	cfg.Issuer = "unit-test-issuer"
+	cfg.AccessExpiration = 2 * time.Minute
+	cfg.RefreshExpiration = 10 * time.Minute
+	svc, err := NewService(cfg, client)
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	return svc
+}
+
+func TestRS256TokensCarryKidAndValidate(t *testing.T) {
+	//1.- Generate an RSA key pair and configure the service to sign with it.
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	privatePath, _ := writeKeyPair(t, "rsa", private, &private.PublicKey)
+	svc := newKeyedService(t, config.JWTConfig{Algorithm: AlgorithmRS256, KeyID: "rsa-2024", PrivateKeyFile: privatePath})
+
+	ctx := context.Background()
+	pair, err := svc.Login(ctx, "user-rsa")
+	if err != nil {
+		t.Fatalf("Login returned error: %v", err)
+	}
+
+	//1.- Inspect the header to confirm the algorithm and kid are advertised.
+	parsed, _, err := jwt.NewParser().ParseUnverified(pair.AccessToken, &accessClaims{})
+	if err != nil {
+		t.Fatalf("failed to parse token header: %v", err)
+	}
+	if parsed.Method.Alg() != AlgorithmRS256 || parsed.Header["kid"] != "rsa-2024" {
+		t.Fatalf("unexpected token header: %#v", parsed.Header)
+	}
+
+	claims, err := svc.ValidateAccessToken(ctx, pair.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken returned error: %v", err)
+	}
+	if claims.Subject != "user-rsa" {
+		t.Fatalf("unexpected subject: %s", claims.Subject)
+	}
+
+	//1.- The published key set must expose the RSA modulus and exponent.
+	set := svc.JWKS()
+	if len(set.Keys) != 1 || set.Keys[0].Kid != "rsa-2024" || set.Keys[0].Kty != "RSA" || set.Keys[0].N == "" || set.Keys[0].E != "AQAB" {
+		t.Fatalf("unexpected jwks: %#v", set)
+	}
+}
+
+func TestEdDSARotationAcceptsRetiredKeys(t *testing.T) {
+	//1.- Sign a token with the key that is about to be retired.
+	oldPublic, oldPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate old key: %v", err)
+	}
+	oldPrivatePath, oldPublicPath := writeKeyPair(t, "old", oldPrivate, oldPublic)
+	oldSvc := newKeyedService(t, config.JWTConfig{Algorithm: AlgorithmEdDSA, KeyID: "old", PrivateKeyFile: oldPrivatePath})
+
+	ctx := context.Background()
+	legacy, err := oldSvc.Login(ctx, "user-ed")
+	if err != nil {
+		t.Fatalf("Login returned error: %v", err)
+	}
+
+	//1.- Rotate to a new signing key while keeping the old public key for verification.
+	newPublic, newPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate new key: %v", err)
+	}
+	newPrivatePath, _ := writeKeyPair(t, "new", newPrivate, newPublic)
+	rotated := newKeyedService(t, config.JWTConfig{
+		Algorithm:        AlgorithmEdDSA,
+		KeyID:            "new",
+		PrivateKeyFile:   newPrivatePath,
+		VerificationKeys: map[string]string{"old": oldPublicPath},
+	})
+
+	if _, err := rotated.ValidateAccessToken(ctx, legacy.AccessToken); err != nil {
+		t.Fatalf("expected retired key to validate legacy token, got %v", err)
+	}
+
+	fresh, err := rotated.Login(ctx, "user-ed")
+	if err != nil {
+		t.Fatalf("Login returned error: %v", err)
+	}
+	if _, err := oldSvc.ValidateAccessToken(ctx, fresh.AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected unknown kid to be rejected, got %v", err)
+	}
+
+	set := rotated.JWKS()
+	if len(set.Keys) != 2 || set.Keys[0].Kid != "new" || set.Keys[1].Kid != "old" || set.Keys[0].Crv != "Ed25519" {
+		t.Fatalf("unexpected jwks ordering: %#v", set)
+	}
+}
+
+func TestAsymmetricServiceRejectsForgedHMACTokens(t *testing.T) {
+	//1.- An attacker signing with HS256 must not be accepted when no shared secret exists.
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	privatePath, _ := writeKeyPair(t, "rsa", private, &private.PublicKey)
+	svc := newKeyedService(t, config.JWTConfig{Algorithm: AlgorithmRS256, PrivateKeyFile: privatePath})
+
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "attacker",
+			Issuer:    "unit-test-issuer",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}).SignedString([]byte("guess"))
+	if err != nil {
+		t.Fatalf("failed to sign forged token: %v", err)
+	}
+	if _, err := svc.ValidateAccessToken(context.Background(), forged); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected forged token to be rejected, got %v", err)
+	}
+
+	//1.- Without an explicit kid the service derives an RFC 7638 thumbprint.
+	if kid := svc.JWKS().Keys[0].Kid; kid == "" || kid != thumbprint(&private.PublicKey) {
+		t.Fatalf("expected thumbprint kid, got %q", kid)
+	}
+}
+
+func TestAsymmetricServiceAcceptsHS256OnlyWhenEnabled(t *testing.T) {
+	//1.- Mint a token the way the service did before switching to RS256.
+	legacy := newKeyedService(t, config.JWTConfig{Secret: "legacy-secret"})
+	pair, err := legacy.Login(context.Background(), "user-legacy")
+	if err != nil {
+		t.Fatalf("Login returned error: %v", err)
+	}
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	privatePath, _ := writeKeyPair(t, "rsa", private, &private.PublicKey)
+
+	//1.- A leftover JWT_SECRET alone must not keep HS256 tokens valid.
+	strict := newKeyedService(t, config.JWTConfig{Algorithm: AlgorithmRS256, PrivateKeyFile: privatePath, Secret: "legacy-secret"})
+	if _, err := strict.ValidateAccessToken(context.Background(), pair.AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected HS256 token to be rejected, got %v", err)
+	}
+
+	//1.- The explicit opt-in verifies them during a migration.
+	migrating := newKeyedService(t, config.JWTConfig{Algorithm: AlgorithmRS256, PrivateKeyFile: privatePath, Secret: "legacy-secret", AcceptHS256: true})
+	if claims, err := migrating.ValidateAccessToken(context.Background(), pair.AccessToken); err != nil || claims.Subject != "user-legacy" {
+		t.Fatalf("expected HS256 token to validate, got %v", err)
+	}
+
+	//1.- Opting in without a secret is a configuration error.
+	if _, err := NewService(config.JWTConfig{Algorithm: AlgorithmRS256, PrivateKeyFile: privatePath, AcceptHS256: true}, nil); err == nil {
+		t.Fatal("expected AcceptHS256 without a secret to fail")
+	}
+}
+
+func TestHS256ServicePublishesEmptyKeySet(t *testing.T) {
+	//1.- Shared secrets must never leak through the JWKS endpoint.
+	svc, _ := newTestService(t)
+	if keys := svc.JWKS().Keys; len(keys) != 0 {
+		t.Fatalf("expected no public keys for HS256, got %#v", keys)
+	}
+}
+
+func TestParseJWKRoundTripsPublishedKeys(t *testing.T) {
+	//1.- Keys rendered for our own JWKS parse back to the same material.
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	edPublic, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ed25519 key: %v", err)
+	}
+	for _, tc := range []struct {
+		method jwt.SigningMethod
+		key    interface{}
+	}{
+		{method: jwt.SigningMethodRS256, key: &rsaKey.PublicKey},
+		{method: jwt.SigningMethodEdDSA, key: edPublic},
+	} {
+		jwk, ok := toJWK("kid", tc.method, tc.key)
+		if !ok {
+			t.Fatalf("toJWK rejected %T", tc.key)
+		}
+		parsed, method, err := ParseJWK(jwk)
+		if err != nil || method.Alg() != tc.method.Alg() {
+			t.Fatalf("ParseJWK returned %v (%v)", method, err)
+		}
+		if thumbprint(parsed) != thumbprint(tc.key) {
+			t.Fatalf("round-tripped %s key differs", tc.method.Alg())
+		}
+	}
+
+	//1.- EC keys must lie on P-256 and unknown key types are refused.
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ec key: %v", err)
+	}
+	ecJWK := JWK{Kty: "EC", Kid: "ec", Crv: "P-256", X: base64.RawURLEncoding.EncodeToString(ecKey.X.Bytes()), Y: base64.RawURLEncoding.EncodeToString(ecKey.Y.Bytes())}
+	parsed, method, err := ParseJWK(ecJWK)
+	if err != nil || method != jwt.SigningMethodES256 || !parsed.(*ecdsa.PublicKey).Equal(&ecKey.PublicKey) {
+		t.Fatalf("unexpected EC parse result: %v %v", method, err)
+	}
+	ecJWK.Y = ecJWK.X
+	if _, _, err := ParseJWK(ecJWK); err == nil {
+		t.Fatalf("expected off-curve point to be rejected")
+	}
+	if _, _, err := ParseJWK(JWK{Kty: "oct", Kid: "hmac"}); err == nil {
+		t.Fatalf("expected symmetric JWK to be rejected")
+	}
+}
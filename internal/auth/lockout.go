@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 1.- ErrLoginLocked indicates that the account or client IP is temporarily barred from logging in.
+var ErrLoginLocked = errors.New("auth: login temporarily locked")
+
+// 1.- LockoutConfig tunes how many failures are tolerated before progressive back-off applies.
+type LockoutConfig struct {
+	// 2.- AccountThreshold is the number of consecutive failures per account before locking.
+	AccountThreshold int
+	// 3.- IPThreshold is the number of failures per client IP before locking; it is higher to tolerate NAT.
+	IPThreshold int
+	// 4.- BaseDelay is the first lockout duration, doubled for every further failure.
+	BaseDelay time.Duration
+	// 5.- MaxDelay caps the exponential back-off.
+	MaxDelay time.Duration
+	// 6.- Window is how long failure counters survive without new failures.
+	Window time.Duration
+}
+
+// 1.- LoginThrottle tracks failed logins in Redis and enforces exponential lockouts.
+type LoginThrottle struct {
+	redis RedisCommander
+	cfg   LockoutConfig
+	now   func() time.Time
+}
+
+// 1.- NewLoginThrottle builds a throttle, filling unset configuration with conservative defaults.
+func NewLoginThrottle(client RedisCommander, cfg LockoutConfig) (*LoginThrottle, error) {
+	if client == nil {
+		return nil, errors.New("auth: redis client is required")
+	}
+	if cfg.AccountThreshold <= 0 {
+		cfg.AccountThreshold = 5
+	}
+	if cfg.IPThreshold <= 0 {
+		cfg.IPThreshold = 20
+	}
+	if cfg.BaseDelay <= 0 {
+		cfg.BaseDelay = 30 * time.Second
+	}
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = time.Hour
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 24 * time.Hour
+	}
+	return &LoginThrottle{redis: client, cfg: cfg, now: time.Now}, nil
+}
+
+// 1.- CheckLogin reports ErrLoginLocked with the remaining wait when the account or IP is locked.
+func (t *LoginThrottle) CheckLogin(ctx context.Context, account string, ip string) (time.Duration, error) {
+	wait := time.Duration(0)
+	for _, key := range t.lockKeys(account, ip) {
+		remaining, err := t.lockRemaining(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		if remaining > wait {
+			wait = remaining
+		}
+	}
+	if wait > 0 {
+		return wait, ErrLoginLocked
+	}
+	return 0, nil
+}
+
+// 1.- RecordLoginFailure counts a failed attempt and returns the lockout it triggered, if any.
+func (t *LoginThrottle) RecordLoginFailure(ctx context.Context, account string, ip string) (time.Duration, error) {
+	lockout := time.Duration(0)
+	scopes := []struct {
+		value     string
+		counter   string
+		lock      string
+		threshold int
+	}{
+		{value: account, counter: loginFailuresKey("account", account), lock: loginLockKey("account", account), threshold: t.cfg.AccountThreshold},
+		{value: ip, counter: loginFailuresKey("ip", ip), lock: loginLockKey("ip", ip), threshold: t.cfg.IPThreshold},
+	}
+	for _, scope := range scopes {
+		if scope.value == "" {
+			continue
+		}
+
+		//1.- Extend the counter window on every failure so slow guessing still accumulates.
+		count, err := t.redis.Incr(ctx, scope.counter).Result()
+		if err != nil {
+			return 0, fmt.Errorf("auth: count login failure: %w", err)
+		}
+		if err := t.redis.Expire(ctx, scope.counter, t.cfg.Window).Err(); err != nil {
+			return 0, fmt.Errorf("auth: expire login failures: %w", err)
+		}
+		if count < int64(scope.threshold) {
+			continue
+		}
+
+		//2.- Double the lockout for each failure beyond the threshold.
+		delay := t.backoff(count - int64(scope.threshold))
+		until := t.now().Add(delay)
+		if err := t.redis.Set(ctx, scope.lock, strconv.FormatInt(until.Unix(), 10), delay).Err(); err != nil {
+			return 0, fmt.Errorf("auth: store login lock: %w", err)
+		}
+		if delay > lockout {
+			lockout = delay
+		}
+	}
+	return lockout, nil
+}
+
+// 1.- RecordLoginSuccess clears the account counters; IP counters keep decaying on their own window.
+func (t *LoginThrottle) RecordLoginSuccess(ctx context.Context, account string) error {
+	return t.UnlockAccount(ctx, account)
+}
+
+// 1.- UnlockAccount removes the lock and failure history for an account.
+func (t *LoginThrottle) UnlockAccount(ctx context.Context, account string) error {
+	if err := t.redis.Del(ctx, loginFailuresKey("account", account), loginLockKey("account", account)).Err(); err != nil {
+		return fmt.Errorf("auth: unlock account: %w", err)
+	}
+	return nil
+}
+
+// 1.- backoff returns BaseDelay * 2^excess capped at MaxDelay.
+func (t *LoginThrottle) backoff(excess int64) time.Duration {
+	delay := t.cfg.BaseDelay
+	for i := int64(0); i < excess; i++ {
+		delay *= 2
+		if delay >= t.cfg.MaxDelay {
+			return t.cfg.MaxDelay
+		}
+	}
+	if delay > t.cfg.MaxDelay {
+		return t.cfg.MaxDelay
+	}
+	return delay
+}
+
+// 1.- lockRemaining reads the unlock deadline stored under key.
+func (t *LoginThrottle) lockRemaining(ctx context.Context, key string) (time.Duration, error) {
+	value, err := t.redis.Get(ctx, key).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("auth: load login lock: %w", err)
+	}
+	unix, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, nil
+	}
+	remaining := time.Unix(unix, 0).Sub(t.now())
+	if remaining <= 0 {
+		return 0, nil
+	}
+	//1.- Round up so clients never retry a fraction of a second too early.
+	return remaining.Truncate(time.Second) + time.Second, nil
+}
+
+// 1.- lockKeys lists the lock keys relevant to an attempt, skipping an unknown IP.
+func (t *LoginThrottle) lockKeys(account string, ip string) []string {
+	keys := []string{loginLockKey("account", account)}
+	if ip != "" {
+		keys = append(keys, loginLockKey("ip", ip))
+	}
+	return keys
+}
+
+// 1.- loginFailuresKey namespaces failure counters by scope.
+func loginFailuresKey(scope string, value string) string {
+	return "auth:login-failures:" + scope + ":" + value
+}
+
+// 1.- loginLockKey namespaces lockout markers by scope.
+func loginLockKey(scope string, value string) string {
+	return "auth:login-lock:" + scope + ":" + value
+}
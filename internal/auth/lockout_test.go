@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLoginThrottleLocksAccountWithExponentialBackoff(t *testing.T) {
+	//1.- Lock after three failures, starting at ten seconds and capping at forty.
+	_, client := newTestService(t)
+	throttle, err := NewLoginThrottle(client, LockoutConfig{AccountThreshold: 3, IPThreshold: 100, BaseDelay: 10 * time.Second, MaxDelay: 40 * time.Second})
+	if err != nil {
+		t.Fatalf("NewLoginThrottle returned error: %v", err)
+	}
+	ctx := context.Background()
+
+	expected := []time.Duration{0, 0, 10 * time.Second, 20 * time.Second, 40 * time.Second, 40 * time.Second}
+	for i, want := range expected {
+		got, err := throttle.RecordLoginFailure(ctx, "user@example.com", "10.0.0.1")
+		if err != nil {
+			t.Fatalf("RecordLoginFailure returned error: %v", err)
+		}
+		if got != want {
+			t.Fatalf("failure %d: expected lockout %s, got %s", i+1, want, got)
+		}
+	}
+
+	//1.- The account is locked regardless of the IP the next attempt comes from.
+	wait, err := throttle.CheckLogin(ctx, "user@example.com", "10.0.0.2")
+	if !errors.Is(err, ErrLoginLocked) || wait <= 0 || wait > 41*time.Second {
+		t.Fatalf("expected locked account, got %s (%v)", wait, err)
+	}
+	if _, err := throttle.CheckLogin(ctx, "other@example.com", "10.0.0.1"); err != nil {
+		t.Fatalf("expected other accounts to stay open, got %v", err)
+	}
+
+	//1.- Unlocking clears both the lock and the counter.
+	if err := throttle.UnlockAccount(ctx, "user@example.com"); err != nil {
+		t.Fatalf("UnlockAccount returned error: %v", err)
+	}
+	if _, err := throttle.CheckLogin(ctx, "user@example.com", "10.0.0.2"); err != nil {
+		t.Fatalf("expected unlocked account, got %v", err)
+	}
+	if got, _ := throttle.RecordLoginFailure(ctx, "user@example.com", "10.0.0.2"); got != 0 {
+		t.Fatalf("expected counter reset after unlock, got lockout %s", got)
+	}
+}
+
+func TestLoginThrottleLocksClientIPAcrossAccounts(t *testing.T) {
+	//1.- Spray distinct accounts from one address until the IP threshold trips.
+	_, client := newTestService(t)
+	throttle, err := NewLoginThrottle(client, LockoutConfig{AccountThreshold: 10, IPThreshold: 3, BaseDelay: time.Minute})
+	if err != nil {
+		t.Fatalf("NewLoginThrottle returned error: %v", err)
+	}
+	ctx := context.Background()
+	for _, account := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		if _, err := throttle.RecordLoginFailure(ctx, account, "10.0.0.9"); err != nil {
+			t.Fatalf("RecordLoginFailure returned error: %v", err)
+		}
+	}
+
+	if _, err := throttle.CheckLogin(ctx, "d@example.com", "10.0.0.9"); !errors.Is(err, ErrLoginLocked) {
+		t.Fatalf("expected IP lock, got %v", err)
+	}
+	if _, err := throttle.CheckLogin(ctx, "d@example.com", "10.0.0.10"); err != nil {
+		t.Fatalf("expected other IPs to stay open, got %v", err)
+	}
+
+	//1.- A successful login on one account does not lift the IP lock.
+	if err := throttle.RecordLoginSuccess(ctx, "a@example.com"); err != nil {
+		t.Fatalf("RecordLoginSuccess returned error: %v", err)
+	}
+	if _, err := throttle.CheckLogin(ctx, "a@example.com", "10.0.0.9"); !errors.Is(err, ErrLoginLocked) {
+		t.Fatalf("expected IP lock to persist, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// 1.- PurposeMFAPending marks the short-lived token issued between password and second factor checks.
+const PurposeMFAPending = "mfa_pending"
+
+// 1.- MFA pending tokens expire quickly and tolerate only a handful of wrong codes.
+const (
+	mfaPendingTTL         = 5 * time.Minute
+	mfaPendingMaxAttempts = 5
+)
+
+// 1.- IssueMFAPending mints a single-use token proving the password step succeeded for subject.
+func (s *Service) IssueMFAPending(ctx context.Context, subject string) (string, time.Time, error) {
+	now := s.now()
+	claims := accessClaims{
+		Purpose: PurposeMFAPending,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			Issuer:    s.cfg.Issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(mfaPendingTTL)),
+			ID:        uuid.NewString(),
+		},
+	}
+	if s.cfg.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{s.cfg.Audience}
+	}
+
+	token, err := s.keys.sign(claims)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("auth: sign mfa token: %w", err)
+	}
+	//1.- Track the token server-side so it can be burned after use or too many failures.
+	if err := s.redis.Set(ctx, mfaPendingKey(claims.ID), subject, mfaPendingTTL).Err(); err != nil {
+		return "", time.Time{}, fmt.Errorf("auth: persist mfa token: %w", err)
+	}
+	return token, claims.ExpiresAt.Time, nil
+}
+
+// 1.- ExchangeMFAPending runs verify for the pending subject and, on success, burns the token and logs in.
+func (s *Service) ExchangeMFAPending(ctx context.Context, token string, verify func(subject string) error) (TokenPair, string, error) {
+	claims := &accessClaims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, s.keyFunc(), s.jwtOptions()...)
+	if err != nil || !parsed.Valid || claims.Purpose != PurposeMFAPending {
+		return TokenPair{}, "", ErrInvalidToken
+	}
+
+	//1.- Reject tokens that were already exchanged or burned.
+	if _, err := s.redis.Get(ctx, mfaPendingKey(claims.ID)).Result(); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return TokenPair{}, "", ErrInvalidToken
+		}
+		return TokenPair{}, "", fmt.Errorf("auth: lookup mfa token: %w", err)
+	}
+
+	//1.- Count attempts so a stolen pending token cannot brute-force the six digit code.
+	attempts, err := s.redis.Incr(ctx, mfaAttemptsKey(claims.ID)).Result()
+	if err != nil {
+		return TokenPair{}, "", fmt.Errorf("auth: count mfa attempts: %w", err)
+	}
+	if attempts == 1 {
+		if err := s.redis.Expire(ctx, mfaAttemptsKey(claims.ID), mfaPendingTTL).Err(); err != nil {
+			return TokenPair{}, "", fmt.Errorf("auth: expire mfa attempts: %w", err)
+		}
+	}
+	if attempts > mfaPendingMaxAttempts {
+		_, _ = s.redis.Del(ctx, mfaPendingKey(claims.ID)).Result()
+		return TokenPair{}, "", ErrInvalidToken
+	}
+
+	if err := verify(claims.Subject); err != nil {
+		return TokenPair{}, "", err
+	}
+
+	//1.- Deleting the marker is the single-use gate; a concurrent exchange sees zero deletions.
+	removed, err := s.redis.Del(ctx, mfaPendingKey(claims.ID)).Result()
+	if err != nil {
+		return TokenPair{}, "", fmt.Errorf("auth: burn mfa token: %w", err)
+	}
+	if removed == 0 {
+		return TokenPair{}, "", ErrInvalidToken
+	}
+	_, _ = s.redis.Del(ctx, mfaAttemptsKey(claims.ID)).Result()
+
+	pair, err := s.Login(ctx, claims.Subject)
+	if err != nil {
+		return TokenPair{}, "", err
+	}
+	return pair, claims.Subject, nil
+}
+
+// 1.- mfaPendingKey builds the Redis key marking an unexchanged mfa_pending token.
+func mfaPendingKey(jti string) string {
+	return fmt.Sprintf("auth:mfa-pending:%s", jti)
+}
+
+// 1.- mfaAttemptsKey builds the Redis key counting second factor attempts for a pending token.
+func mfaAttemptsKey(jti string) string {
+	return fmt.Sprintf("auth:mfa-pending:attempts:%s", jti)
+}
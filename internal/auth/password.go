@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/example/Yamato-Go-Gin-API/internal/config"
+)
+
+// 1.- ErrPasswordMismatch reports a password that does not match the stored hash.
+var ErrPasswordMismatch = errors.New("auth: password mismatch")
+
+// 1.- ErrUnsupportedHash reports a stored hash whose algorithm no hasher recognises.
+var ErrUnsupportedHash = errors.New("auth: unsupported password hash")
+
+// 1.- PasswordHasher hashes new passwords and verifies stored ones, including hashes from older algorithms.
+type PasswordHasher interface {
+	// 2.- Hash produces a self-describing digest using the configured algorithm and parameters.
+	Hash(password string) (string, error)
+	// 3.- Verify returns ErrPasswordMismatch when the password does not match the stored hash.
+	Verify(hash string, password string) error
+	// 4.- NeedsRehash reports whether the hash uses another algorithm or weaker parameters than configured.
+	NeedsRehash(hash string) bool
+}
+
+// 1.- Argon2Params are the Argon2id cost parameters encoded into every hash.
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// 1.- DefaultArgon2Params follow the OWASP minimum of 19 MiB, two passes, and one lane.
+var DefaultArgon2Params = Argon2Params{Memory: 19456, Iterations: 2, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+// 1.- argon2idPrefix starts every PHC-formatted Argon2id hash.
+const argon2idPrefix = "$argon2id$"
+
+// 1.- NewPasswordHasher builds the hasher selected by configuration.
+func NewPasswordHasher(cfg config.PasswordConfig) (PasswordHasher, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Algorithm)) {
+	case "", config.PasswordArgon2id:
+		params := DefaultArgon2Params
+		if cfg.Argon2Memory > 0 {
+			params.Memory = cfg.Argon2Memory
+		}
+		if cfg.Argon2Iterations > 0 {
+			params.Iterations = cfg.Argon2Iterations
+		}
+		if cfg.Argon2Parallelism > 0 {
+			params.Parallelism = cfg.Argon2Parallelism
+		}
+		return NewArgon2idHasher(params), nil
+	case config.PasswordBcrypt:
+		return NewBcryptHasher(cfg.BcryptCost), nil
+	default:
+		return nil, fmt.Errorf("auth: unsupported password hasher %q", cfg.Algorithm)
+	}
+}
+
+// 1.- Argon2idHasher hashes with Argon2id and still verifies legacy bcrypt hashes.
+type Argon2idHasher struct {
+	params Argon2Params
+}
+
+// 1.- NewArgon2idHasher fills unset parameters from DefaultArgon2Params.
+func NewArgon2idHasher(params Argon2Params) *Argon2idHasher {
+	if params.Memory == 0 {
+		params.Memory = DefaultArgon2Params.Memory
+	}
+	if params.Iterations == 0 {
+		params.Iterations = DefaultArgon2Params.Iterations
+	}
+	if params.Parallelism == 0 {
+		params.Parallelism = DefaultArgon2Params.Parallelism
+	}
+	if params.SaltLength == 0 {
+		params.SaltLength = DefaultArgon2Params.SaltLength
+	}
+	if params.KeyLength == 0 {
+		params.KeyLength = DefaultArgon2Params.KeyLength
+	}
+	return &Argon2idHasher{params: params}
+}
+
+// 1.- Hash derives a key from a random salt and encodes it in the PHC string format.
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("auth: argon2id salt: %w", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version, h.params.Memory, h.params.Iterations, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// 1.- Verify accepts Argon2id and bcrypt hashes.
+func (h *Argon2idHasher) Verify(hash string, password string) error {
+	return verifyPassword(hash, password)
+}
+
+// 1.- NeedsRehash is true for bcrypt hashes and Argon2id hashes weaker than the configured parameters.
+func (h *Argon2idHasher) NeedsRehash(hash string) bool {
+	params, _, _, err := decodeArgon2id(hash)
+	if err != nil {
+		return true
+	}
+	return params.Memory < h.params.Memory ||
+		params.Iterations < h.params.Iterations ||
+		params.Parallelism < h.params.Parallelism ||
+		params.KeyLength < h.params.KeyLength
+}
+
+// 1.- BcryptHasher keeps bcrypt as the primary algorithm for deployments that are not ready to switch.
+type BcryptHasher struct {
+	cost int
+}
+
+// 1.- NewBcryptHasher clamps the cost into bcrypt's valid range, defaulting to 12.
+func NewBcryptHasher(cost int) *BcryptHasher {
+	if cost == 0 {
+		cost = 12
+	}
+	if cost < bcrypt.MinCost {
+		cost = bcrypt.MinCost
+	}
+	if cost > bcrypt.MaxCost {
+		cost = bcrypt.MaxCost
+	}
+	return &BcryptHasher{cost: cost}
+}
+
+// 1.- Hash produces a bcrypt digest at the configured cost.
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", fmt.Errorf("auth: bcrypt hash: %w", err)
+	}
+	return string(hashed), nil
+}
+
+// 1.- Verify accepts bcrypt and Argon2id hashes so switching algorithms back never locks users out.
+func (h *BcryptHasher) Verify(hash string, password string) error {
+	return verifyPassword(hash, password)
+}
+
+// 1.- NeedsRehash is true for non-bcrypt hashes and bcrypt hashes below the configured cost.
+func (h *BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost < h.cost
+}
+
+// 1.- verifyPassword picks the algorithm from the hash prefix.
+func verifyPassword(hash string, password string) error {
+	if strings.HasPrefix(hash, argon2idPrefix) {
+		params, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return err
+		}
+		candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+		if subtle.ConstantTimeCompare(candidate, key) != 1 {
+			return ErrPasswordMismatch
+		}
+		return nil
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return ErrPasswordMismatch
+		}
+		return fmt.Errorf("%w: %v", ErrUnsupportedHash, err)
+	}
+	return nil
+}
+
+// 1.- decodeArgon2id parses $argon2id$v=19$m=...,t=...,p=...$salt$key.
+func decodeArgon2id(hash string) (Argon2Params, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return Argon2Params{}, nil, nil, ErrUnsupportedHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2Params{}, nil, nil, ErrUnsupportedHash
+	}
+	var params Argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil ||
+		params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 {
+		return Argon2Params{}, nil, nil, ErrUnsupportedHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2Params{}, nil, nil, ErrUnsupportedHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return Argon2Params{}, nil, nil, ErrUnsupportedHash
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
+}
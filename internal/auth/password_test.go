@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/example/Yamato-Go-Gin-API/internal/config"
+)
+
+func TestArgon2idHasherVerifiesAndFlagsOutdatedHashes(t *testing.T) {
+	//1.- Fresh Argon2id hashes verify, reject wrong passwords, and need no rehash.
+	hasher := NewArgon2idHasher(Argon2Params{Memory: 1024, Iterations: 2, Parallelism: 1})
+	hash, err := hasher.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash returned error: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=2,p=1$") {
+		t.Fatalf("unexpected hash format %q", hash)
+	}
+	if err := hasher.Verify(hash, "correct horse"); err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+	if err := hasher.Verify(hash, "wrong"); !errors.Is(err, ErrPasswordMismatch) {
+		t.Fatalf("expected mismatch, got %v", err)
+	}
+	if hasher.NeedsRehash(hash) {
+		t.Fatal("expected current hash not to need a rehash")
+	}
+
+	//2.- Stronger settings flag the old hash, which still verifies.
+	stronger := NewArgon2idHasher(Argon2Params{Memory: 2048, Iterations: 2, Parallelism: 1})
+	if !stronger.NeedsRehash(hash) {
+		t.Fatal("expected weaker parameters to need a rehash")
+	}
+	if err := stronger.Verify(hash, "correct horse"); err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+
+	//3.- Legacy bcrypt hashes verify but always need a rehash.
+	legacy, err := NewBcryptHasher(4).Hash("correct horse")
+	if err != nil {
+		t.Fatalf("bcrypt Hash returned error: %v", err)
+	}
+	if err := hasher.Verify(legacy, "correct horse"); err != nil {
+		t.Fatalf("expected bcrypt hash to verify, got %v", err)
+	}
+	if !hasher.NeedsRehash(legacy) {
+		t.Fatal("expected bcrypt hash to need a rehash")
+	}
+
+	//4.- Garbage is reported as unsupported rather than a mismatch.
+	if err := hasher.Verify("$argon2id$v=19$m=0,t=0,p=0$x$y", "x"); !errors.Is(err, ErrUnsupportedHash) {
+		t.Fatalf("expected unsupported hash, got %v", err)
+	}
+}
+
+func TestNewPasswordHasherFollowsConfiguration(t *testing.T) {
+	//1.- bcrypt stays selectable and flags Argon2id or cheaper bcrypt hashes for replacement.
+	hasher, err := NewPasswordHasher(config.PasswordConfig{Algorithm: config.PasswordBcrypt, BcryptCost: 5})
+	if err != nil {
+		t.Fatalf("NewPasswordHasher returned error: %v", err)
+	}
+	cheap, _ := NewBcryptHasher(4).Hash("pw")
+	argon, _ := NewArgon2idHasher(Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1}).Hash("pw")
+	if !hasher.NeedsRehash(cheap) || !hasher.NeedsRehash(argon) {
+		t.Fatal("expected cheaper bcrypt and argon2id hashes to need a rehash")
+	}
+	if err := hasher.Verify(argon, "pw"); err != nil {
+		t.Fatalf("expected argon2id hash to verify under bcrypt, got %v", err)
+	}
+
+	//2.- Unknown algorithms are rejected.
+	if _, err := NewPasswordHasher(config.PasswordConfig{Algorithm: "md5"}); err == nil {
+		t.Fatal("expected unknown algorithm to fail")
+	}
+}
+
+func TestServiceCheckPasswordKeepsMismatchError(t *testing.T) {
+	//1.- Callers still receive ErrInvalidToken for a wrong password.
+	svc, _ := newTestService(t)
+	svc.UsePasswordHasher(NewArgon2idHasher(Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1}))
+	hash, err := svc.HashPassword("secret")
+	if err != nil {
+		t.Fatalf("HashPassword returned error: %v", err)
+	}
+	if err := svc.CheckPassword(hash, "nope"); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken, got %v", err)
+	}
+	if svc.PasswordNeedsRehash(hash) {
+		t.Fatal("expected fresh hash not to need a rehash")
+	}
+	svc.EqualizePasswordTiming("secret")
+}
@@ -4,12 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
-	"golang.org/x/crypto/bcrypt"
 
 	"github.com/example/Yamato-Go-Gin-API/internal/config"
 )
@@ -28,14 +28,23 @@ type RedisCommander interface {
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
 	Get(ctx context.Context, key string) *redis.StringCmd
 	Del(ctx context.Context, keys ...string) *redis.IntCmd
+	SAdd(ctx context.Context, key string, members ...interface{}) *redis.IntCmd
+	SMembers(ctx context.Context, key string) *redis.StringSliceCmd
+	SRem(ctx context.Context, key string, members ...interface{}) *redis.IntCmd
+	Expire(ctx context.Context, key string, expiration time.Duration) *redis.BoolCmd
+	Incr(ctx context.Context, key string) *redis.IntCmd
 }
 
 // 1.- Service coordinates password hashing, token issuance, and revocation bookkeeping.
 type Service struct {
-	cfg    config.JWTConfig
-	redis  RedisCommander
-	now    func() time.Time
-	secret []byte
+	cfg       config.JWTConfig
+	redis     RedisCommander
+	now       func() time.Time
+	keys      *keyRing
+	passwords PasswordHasher
+	// 2.- equalizer caches a hash made with the current hasher for unknown-account timing checks.
+	equalizer     string
+	equalizerOnce sync.Once
 }
 
 // 1.- TokenPair captures the issued tokens alongside their expiration timestamps.
@@ -49,19 +58,39 @@ type TokenPair struct {
 // 1.- accessClaims extends registered JWT claims with the owning refresh family identifier.
 type accessClaims struct {
 	FamilyID string `json:"fam"`
+	// 2.- Purpose marks special-use tokens (such as mfa_pending) that never grant API access.
+	Purpose string `json:"purpose,omitempty"`
+	// 3.- Actor names the administrator behind an impersonation token (RFC 8693 "act").
+	Actor *ActorClaim `json:"act,omitempty"`
+	// 4.- Scope lists space-separated grants for tokens narrower than a full user session.
+	Scope string `json:"scope,omitempty"`
+	// 5.- TokenUse is only set on refresh tokens; access parsing rejects it so the two cannot be swapped.
+	TokenUse string `json:"token_use,omitempty"`
+	// 6.- ClientID names the OAuth client behind a client_credentials token; such tokens have no user.
+	ClientID string `json:"client_id,omitempty"`
+	// 7.- AuthTime is when the user last signed in (OIDC "auth_time"); refreshes carry it forward unchanged.
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
 	jwt.RegisteredClaims
 }
 
 // 1.- refreshClaims embeds the refresh family identifier for reuse detection.
 type refreshClaims struct {
-	FamilyID string `json:"fam"`
+	FamilyID string           `json:"fam"`
+	Purpose  string           `json:"purpose,omitempty"`
+	TokenUse string           `json:"token_use,omitempty"`
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// 1.- tokenUseRefresh marks refresh tokens in the token_use claim.
+const tokenUseRefresh = "refresh"
+
 // 1.- NewService constructs a Service with sane defaults for token lifetimes and clock source.
 func NewService(cfg config.JWTConfig, redis RedisCommander) (*Service, error) {
-	if cfg.Secret == "" {
-		return nil, errors.New("auth: jwt secret must be configured")
+	//1.- Load the signing key and every verification key that remains valid during rotation.
+	keys, err := newKeyRing(cfg)
+	if err != nil {
+		return nil, err
 	}
 
 	//1.- Adopt opinionated defaults when durations were not loaded from configuration.
@@ -73,37 +102,59 @@ func NewService(cfg config.JWTConfig, redis RedisCommander) (*Service, error) {
 	}
 
 	return &Service{
-		cfg:    cfg,
-		redis:  redis,
-		now:    time.Now,
-		secret: []byte(cfg.Secret),
+		cfg:       cfg,
+		redis:     redis,
+		now:       time.Now,
+		keys:      keys,
+		passwords: NewArgon2idHasher(DefaultArgon2Params),
 	}, nil
 }
 
-// 1.- HashPassword hashes a plaintext password using bcrypt with cost 12 for storage.
-func (s *Service) HashPassword(password string) (string, error) {
-	hashed, err := bcrypt.GenerateFromPassword([]byte(password), 12)
-	if err != nil {
-		return "", fmt.Errorf("auth: bcrypt hash: %w", err)
+// 1.- UsePasswordHasher replaces the default Argon2id hasher; call it before serving requests.
+func (s *Service) UsePasswordHasher(hasher PasswordHasher) {
+	if hasher != nil {
+		s.passwords = hasher
 	}
-	return string(hashed), nil
 }
 
-// 1.- CheckPassword compares a stored bcrypt hash against a provided password candidate.
+// 1.- JWKS publishes the public verification keys so other services can validate tokens.
+func (s *Service) JWKS() JWKS {
+	return s.keys.jwks()
+}
+
+// 1.- HashPassword hashes a plaintext password with the configured hasher for storage.
+func (s *Service) HashPassword(password string) (string, error) {
+	return s.passwords.Hash(password)
+}
+
+// 1.- CheckPassword compares a stored Argon2id or bcrypt hash against a provided password candidate.
 func (s *Service) CheckPassword(hash string, password string) error {
-	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
-		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+	if err := s.passwords.Verify(hash, password); err != nil {
+		if errors.Is(err, ErrPasswordMismatch) {
 			return ErrInvalidToken
 		}
-		return fmt.Errorf("auth: bcrypt compare: %w", err)
+		return err
 	}
 	return nil
 }
 
+// 1.- PasswordNeedsRehash reports whether a verified hash should be replaced with a fresh one.
+func (s *Service) PasswordNeedsRehash(hash string) bool {
+	return s.passwords.NeedsRehash(hash)
+}
+
+// 1.- EqualizePasswordTiming spends as long as a real check so unknown accounts cannot be told apart by latency.
+func (s *Service) EqualizePasswordTiming(password string) {
+	s.equalizerOnce.Do(func() {
+		s.equalizer, _ = s.passwords.Hash(uuid.NewString())
+	})
+	_ = s.passwords.Verify(s.equalizer, password)
+}
+
 // 1.- Login issues a new access and refresh token pair, tracking refresh state in Redis.
 func (s *Service) Login(ctx context.Context, subject string) (TokenPair, error) {
 	familyID := uuid.NewString()
-	return s.issueTokens(ctx, subject, familyID)
+	return s.issueTokens(ctx, subject, familyID, jwt.NewNumericDate(s.now()))
 }
 
 // 1.- Refresh validates a refresh token, rotates it, and revokes the old instance.
@@ -137,7 +188,7 @@ func (s *Service) Refresh(ctx context.Context, refreshToken string) (TokenPair,
 		return TokenPair{}, ErrReuseDetected
 	}
 
-	pair, err := s.issueTokens(ctx, claims.Subject, claims.FamilyID)
+	pair, err := s.issueTokens(ctx, claims.Subject, claims.FamilyID, claims.AuthTime)
 	if err != nil {
 		return TokenPair{}, err
 	}
@@ -170,6 +221,23 @@ func (s *Service) Logout(ctx context.Context, refreshToken string, accessToken s
 	return nil
 }
 
+// 1.- RevokeAll blacklists every refresh family issued to the subject and every impersonation token it issued or received.
+func (s *Service) RevokeAll(ctx context.Context, subject string) error {
+	families, err := s.redis.SMembers(ctx, userFamiliesKey(subject)).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("auth: list user families: %w", err)
+	}
+	for _, familyID := range families {
+		if err := s.blacklistFamily(ctx, familyID); err != nil {
+			return err
+		}
+	}
+	if _, err := s.redis.Del(ctx, userFamiliesKey(subject)).Result(); err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("auth: clear user families: %w", err)
+	}
+	return s.revokeImpersonations(ctx, subject)
+}
+
 // 1.- ValidateAccessToken verifies signature, expiry, and blacklist state of an access token.
 func (s *Service) ValidateAccessToken(ctx context.Context, token string) (*accessClaims, error) {
 	claims, err := s.parseAccessClaims(token)
@@ -186,17 +254,29 @@ func (s *Service) ValidateAccessToken(ctx context.Context, token string) (*acces
 		return nil, fmt.Errorf("auth: access blacklist lookup: %w", err)
 	}
 
+	//1.- Access tokens die together with the refresh family that minted them.
+	if claims.FamilyID != "" {
+		revoked, err := s.isFamilyBlacklisted(ctx, claims.FamilyID)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, ErrBlacklisted
+		}
+	}
+
 	return claims, nil
 }
 
 // 1.- issueTokens mints signed JWT access and refresh tokens and persists refresh metadata.
-func (s *Service) issueTokens(ctx context.Context, subject string, familyID string) (TokenPair, error) {
+func (s *Service) issueTokens(ctx context.Context, subject string, familyID string, authTime *jwt.NumericDate) (TokenPair, error) {
 	now := s.now()
 	accessID := uuid.NewString()
 	refreshID := uuid.NewString()
 
 	aClaims := accessClaims{
 		FamilyID: familyID,
+		AuthTime: authTime,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   subject,
 			Issuer:    s.cfg.Issuer,
@@ -208,6 +288,8 @@ func (s *Service) issueTokens(ctx context.Context, subject string, familyID stri
 	}
 	rClaims := refreshClaims{
 		FamilyID: familyID,
+		TokenUse: tokenUseRefresh,
+		AuthTime: authTime,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   subject,
 			Issuer:    s.cfg.Issuer,
@@ -223,11 +305,11 @@ func (s *Service) issueTokens(ctx context.Context, subject string, familyID stri
 		rClaims.Audience = jwt.ClaimStrings{s.cfg.Audience}
 	}
 
-	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, aClaims).SignedString(s.secret)
+	accessToken, err := s.keys.sign(aClaims)
 	if err != nil {
 		return TokenPair{}, fmt.Errorf("auth: sign access token: %w", err)
 	}
-	refreshToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, rClaims).SignedString(s.secret)
+	refreshToken, err := s.keys.sign(rClaims)
 	if err != nil {
 		return TokenPair{}, fmt.Errorf("auth: sign refresh token: %w", err)
 	}
@@ -236,6 +318,19 @@ func (s *Service) issueTokens(ctx context.Context, subject string, familyID stri
 		return TokenPair{}, fmt.Errorf("auth: persist refresh family: %w", err)
 	}
 
+	//1.- Record device metadata so users can review and end their sessions.
+	if err := s.touchSession(ctx, subject, familyID, now); err != nil {
+		return TokenPair{}, err
+	}
+
+	//1.- Index the family under its subject so every session can be listed and revoked.
+	if err := s.redis.SAdd(ctx, userFamiliesKey(subject), familyID).Err(); err != nil {
+		return TokenPair{}, fmt.Errorf("auth: index refresh family: %w", err)
+	}
+	if err := s.redis.Expire(ctx, userFamiliesKey(subject), s.cfg.RefreshExpiration).Err(); err != nil {
+		return TokenPair{}, fmt.Errorf("auth: expire family index: %w", err)
+	}
+
 	return TokenPair{
 		AccessToken:      accessToken,
 		RefreshToken:     refreshToken,
@@ -248,7 +343,7 @@ func (s *Service) issueTokens(ctx context.Context, subject string, familyID stri
 func (s *Service) parseAccessClaims(token string) (*accessClaims, error) {
 	claims := &accessClaims{}
 	parsed, err := jwt.ParseWithClaims(token, claims, s.keyFunc(), s.jwtOptions()...)
-	if err != nil || !parsed.Valid {
+	if err != nil || !parsed.Valid || claims.Purpose != "" || claims.TokenUse == tokenUseRefresh {
 		return nil, ErrInvalidToken
 	}
 	return claims, nil
@@ -258,22 +353,20 @@ func (s *Service) parseAccessClaims(token string) (*accessClaims, error) {
 func (s *Service) parseRefreshClaims(token string) (*refreshClaims, error) {
 	claims := &refreshClaims{}
 	parsed, err := jwt.ParseWithClaims(token, claims, s.keyFunc(), s.jwtOptions()...)
-	if err != nil || !parsed.Valid {
+	if err != nil || !parsed.Valid || claims.Purpose != "" {
 		return nil, ErrInvalidToken
 	}
 	return claims, nil
 }
 
-// 1.- keyFunc supplies the signing secret to the JWT parser.
+// 1.- keyFunc resolves the verification key for the token's algorithm and kid header.
 func (s *Service) keyFunc() jwt.Keyfunc {
-	return func(_ *jwt.Token) (interface{}, error) {
-		return s.secret, nil
-	}
+	return s.keys.keyFunc
 }
 
 // 1.- jwtOptions returns reusable options for JWT parsing (audience enforcement when set).
 func (s *Service) jwtOptions() []jwt.ParserOption {
-	opts := []jwt.ParserOption{jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()})}
+	opts := []jwt.ParserOption{jwt.WithValidMethods(s.keys.methods())}
 	if s.cfg.Audience != "" {
 		opts = append(opts, jwt.WithAudience(s.cfg.Audience))
 	}
@@ -288,7 +381,7 @@ func (s *Service) blacklistFamily(ctx context.Context, familyID string) error {
 	if err := s.redis.Set(ctx, familyBlacklistKey(familyID), "1", s.cfg.RefreshExpiration).Err(); err != nil {
 		return fmt.Errorf("auth: blacklist family: %w", err)
 	}
-	if _, err := s.redis.Del(ctx, refreshFamilyKey(familyID)).Result(); err != nil && !errors.Is(err, redis.Nil) {
+	if _, err := s.redis.Del(ctx, refreshFamilyKey(familyID), sessionKey(familyID)).Result(); err != nil && !errors.Is(err, redis.Nil) {
 		return fmt.Errorf("auth: delete refresh family: %w", err)
 	}
 	return nil
@@ -320,3 +413,8 @@ func familyBlacklistKey(familyID string) string {
 func accessBlacklistKey(jti string) string {
 	return fmt.Sprintf("auth:blacklist:access:%s", jti)
 }
+
+// 1.- userFamiliesKey constructs the Redis set key listing the refresh families of a subject.
+func userFamiliesKey(subject string) string {
+	return fmt.Sprintf("auth:user-families:%s", subject)
+}
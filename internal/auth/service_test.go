@@ -95,6 +95,32 @@ func TestRefreshRotationAndReuseDetection(t *testing.T) {
 	}
 }
 
+func TestRefreshKeepsSignInTime(t *testing.T) {
+	//1.- Sign in at a fixed instant and refresh a few minutes later.
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+	signedIn := time.Now().Add(-5 * time.Minute).Truncate(time.Second)
+	svc.now = func() time.Time { return signedIn }
+	initial, err := svc.Login(ctx, "user-auth-time")
+	if err != nil {
+		t.Fatalf("Login returned error: %v", err)
+	}
+	svc.now = time.Now
+	rotated, err := svc.Refresh(ctx, initial.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh returned error: %v", err)
+	}
+
+	//1.- The refreshed access token still reports the original sign-in, not the refresh.
+	claims, err := svc.parseAccessClaims(rotated.AccessToken)
+	if err != nil {
+		t.Fatalf("parseAccessClaims returned error: %v", err)
+	}
+	if claims.AuthTime == nil || !claims.AuthTime.Time.Equal(signedIn) {
+		t.Fatalf("expected auth_time %v, got %v", signedIn, claims.AuthTime)
+	}
+}
+
 func TestLogoutBlacklistsTokens(t *testing.T) {
 	//1.- Acquire tokens and then invoke logout for revocation coverage.
 	ctx := context.Background()
@@ -117,3 +143,250 @@ func TestLogoutBlacklistsTokens(t *testing.T) {
 		t.Fatalf("expected family blacklist after logout, got %v", err)
 	}
 }
+
+func TestRevokeAllBlacklistsEveryFamily(t *testing.T) {
+	//1.- Open two independent sessions for the same subject plus one for another subject.
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+
+	first, err := svc.Login(ctx, "user-reset")
+	if err != nil {
+		t.Fatalf("Login returned error: %v", err)
+	}
+	second, err := svc.Login(ctx, "user-reset")
+	if err != nil {
+		t.Fatalf("Login returned error: %v", err)
+	}
+	other, err := svc.Login(ctx, "user-other")
+	if err != nil {
+		t.Fatalf("Login returned error: %v", err)
+	}
+
+	if err := svc.RevokeAll(ctx, "user-reset"); err != nil {
+		t.Fatalf("RevokeAll returned error: %v", err)
+	}
+
+	//1.- Both sessions of the subject must be dead for refresh and access alike.
+	for _, pair := range []TokenPair{first, second} {
+		if _, err := svc.Refresh(ctx, pair.RefreshToken); !errors.Is(err, ErrReuseDetected) {
+			t.Fatalf("expected revoked family to be rejected, got %v", err)
+		}
+		if _, err := svc.ValidateAccessToken(ctx, pair.AccessToken); !errors.Is(err, ErrBlacklisted) {
+			t.Fatalf("expected access token of revoked family to be rejected, got %v", err)
+		}
+	}
+
+	//1.- Other subjects keep their sessions.
+	if _, err := svc.Refresh(ctx, other.RefreshToken); err != nil {
+		t.Fatalf("expected unrelated session to survive, got %v", err)
+	}
+}
+
+func TestMFAPendingTokenIsSingleUseAndNotAnAccessToken(t *testing.T) {
+	//1.- Issue a pending token and make sure it cannot be used as API credentials.
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+
+	pending, expiresAt, err := svc.IssueMFAPending(ctx, "user-mfa")
+	if err != nil {
+		t.Fatalf("IssueMFAPending returned error: %v", err)
+	}
+	if time.Until(expiresAt) > mfaPendingTTL {
+		t.Fatalf("unexpected pending expiry: %v", expiresAt)
+	}
+	if _, err := svc.ValidateAccessToken(ctx, pending); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected pending token to be rejected as access token, got %v", err)
+	}
+	if _, err := svc.Refresh(ctx, pending); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected pending token to be rejected as refresh token, got %v", err)
+	}
+
+	//1.- A failed verification keeps the token alive for another attempt.
+	wrongCode := errors.New("wrong code")
+	if _, _, err := svc.ExchangeMFAPending(ctx, pending, func(string) error { return wrongCode }); !errors.Is(err, wrongCode) {
+		t.Fatalf("expected verifier error to propagate, got %v", err)
+	}
+
+	pair, subject, err := svc.ExchangeMFAPending(ctx, pending, func(got string) error {
+		if got != "user-mfa" {
+			t.Fatalf("unexpected subject passed to verifier: %s", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExchangeMFAPending returned error: %v", err)
+	}
+	if subject != "user-mfa" || pair.AccessToken == "" {
+		t.Fatalf("unexpected exchange result: %s %#v", subject, pair)
+	}
+
+	//1.- The token is burned after a successful exchange.
+	if _, _, err := svc.ExchangeMFAPending(ctx, pending, func(string) error { return nil }); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected replay to be rejected, got %v", err)
+	}
+
+	//1.- Real access tokens are never accepted as pending tokens.
+	if _, _, err := svc.ExchangeMFAPending(ctx, pair.AccessToken, func(string) error { return nil }); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected access token to be rejected, got %v", err)
+	}
+}
+
+func TestMFAPendingTokenBurnsAfterTooManyAttempts(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+
+	pending, _, err := svc.IssueMFAPending(ctx, "user-mfa")
+	if err != nil {
+		t.Fatalf("IssueMFAPending returned error: %v", err)
+	}
+
+	//1.- Exhaust the attempt budget with wrong codes.
+	wrongCode := errors.New("wrong code")
+	for i := 0; i < mfaPendingMaxAttempts; i++ {
+		if _, _, err := svc.ExchangeMFAPending(ctx, pending, func(string) error { return wrongCode }); !errors.Is(err, wrongCode) {
+			t.Fatalf("attempt %d: expected verifier error, got %v", i, err)
+		}
+	}
+	if _, _, err := svc.ExchangeMFAPending(ctx, pending, func(string) error { return nil }); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected exhausted token to be rejected, got %v", err)
+	}
+}
+
+func TestSessionsTrackMetadataAndRevocation(t *testing.T) {
+	//1.- Open two sessions from different devices.
+	svc, _ := newTestService(t)
+	base := time.Now().UTC().Truncate(time.Second)
+	svc.now = func() time.Time { return base }
+
+	laptop := WithClientInfo(context.Background(), ClientInfo{UserAgent: "laptop", IP: "10.0.0.1"})
+	phone := WithClientInfo(context.Background(), ClientInfo{UserAgent: "phone", IP: "10.0.0.2"})
+	first, err := svc.Login(laptop, "user-sessions")
+	if err != nil {
+		t.Fatalf("Login returned error: %v", err)
+	}
+	second, err := svc.Login(phone, "user-sessions")
+	if err != nil {
+		t.Fatalf("Login returned error: %v", err)
+	}
+
+	//1.- Refreshing the laptop session bumps its last-refresh time and keeps its creation time.
+	svc.now = func() time.Time { return base.Add(time.Minute) }
+	if _, err := svc.Refresh(laptop, first.RefreshToken); err != nil {
+		t.Fatalf("Refresh returned error: %v", err)
+	}
+
+	ctx := context.Background()
+	sessions, err := svc.ListSessions(ctx, "user-sessions")
+	if err != nil {
+		t.Fatalf("ListSessions returned error: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("expected two sessions, got %#v", sessions)
+	}
+	if sessions[0].UserAgent != "laptop" || sessions[0].IP != "10.0.0.1" || !sessions[0].CreatedAt.Equal(base) || !sessions[0].LastRefreshAt.Equal(base.Add(time.Minute)) {
+		t.Fatalf("unexpected laptop session: %#v", sessions[0])
+	}
+
+	//1.- Other subjects cannot revoke the session.
+	phoneClaims, err := svc.ValidateAccessToken(ctx, second.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken returned error: %v", err)
+	}
+	if err := svc.RevokeSession(ctx, "intruder", phoneClaims.FamilyID); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expected foreign revoke to fail, got %v", err)
+	}
+
+	//1.- Revoking all other sessions from the phone leaves only the phone alive.
+	revoked, err := svc.RevokeOtherSessions(ctx, "user-sessions", phoneClaims.FamilyID)
+	if err != nil || revoked != 1 {
+		t.Fatalf("expected one revoked session, got %d (%v)", revoked, err)
+	}
+	sessions, err = svc.ListSessions(ctx, "user-sessions")
+	if err != nil || len(sessions) != 1 || sessions[0].ID != phoneClaims.FamilyID {
+		t.Fatalf("unexpected sessions after revoke: %#v (%v)", sessions, err)
+	}
+
+	//1.- Revoking the last session kills its access token as well.
+	if err := svc.RevokeSession(ctx, "user-sessions", phoneClaims.FamilyID); err != nil {
+		t.Fatalf("RevokeSession returned error: %v", err)
+	}
+	if _, err := svc.ValidateAccessToken(ctx, second.AccessToken); !errors.Is(err, ErrBlacklisted) {
+		t.Fatalf("expected revoked session access token to fail, got %v", err)
+	}
+}
+
+func TestImpersonationTokenCarriesActorWithoutRefresh(t *testing.T) {
+	svc, client := newTestService(t)
+	ctx := context.Background()
+
+	//1.- Administrators cannot impersonate themselves.
+	if _, err := svc.Impersonate(ctx, "1", "1", time.Minute); !errors.Is(err, ErrSelfImpersonation) {
+		t.Fatalf("expected ErrSelfImpersonation, got %v", err)
+	}
+
+	//1.- The lifetime is capped and the act claim survives validation.
+	token, err := svc.Impersonate(ctx, "1", "2", 24*time.Hour)
+	if err != nil {
+		t.Fatalf("Impersonate returned error: %v", err)
+	}
+	if ttl := time.Until(token.ExpiresAt); ttl > time.Hour || ttl < 59*time.Minute {
+		t.Fatalf("expected lifetime capped at one hour, got %s", ttl)
+	}
+	claims, err := svc.ValidateAccessToken(ctx, token.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken returned error: %v", err)
+	}
+	if claims.Subject != "2" || claims.Actor == nil || claims.Actor.Subject != "1" || claims.ID != token.TokenID {
+		t.Fatalf("unexpected impersonation claims: %+v", claims)
+	}
+
+	//1.- No session family is opened for the impersonated user.
+	if members := client.SMembers(ctx, userFamiliesKey("2")).Val(); len(members) != 0 {
+		t.Fatalf("expected no session families, got %v", members)
+	}
+
+	//1.- Regular tokens carry no actor.
+	pair, err := svc.Login(ctx, "2")
+	if err != nil {
+		t.Fatalf("Login returned error: %v", err)
+	}
+	regular, err := svc.ValidateAccessToken(ctx, pair.AccessToken)
+	if err != nil || regular.Actor != nil {
+		t.Fatalf("expected regular token without actor, got %+v (%v)", regular, err)
+	}
+}
+
+func TestImpersonationTokensAreRevocable(t *testing.T) {
+	svc, _ := newTestService(t)
+	ctx := context.Background()
+
+	//1.- Ending a session blacklists its token and reports both parties.
+	token, err := svc.Impersonate(ctx, "1", "2", time.Minute)
+	if err != nil {
+		t.Fatalf("Impersonate returned error: %v", err)
+	}
+	session, err := svc.EndImpersonation(ctx, token.TokenID)
+	if err != nil || session.Actor != "1" || session.Subject != "2" || session.TokenID != token.TokenID {
+		t.Fatalf("unexpected ended session: %+v (%v)", session, err)
+	}
+	if _, err := svc.ValidateAccessToken(ctx, token.AccessToken); !errors.Is(err, ErrBlacklisted) {
+		t.Fatalf("expected ended impersonation token to fail, got %v", err)
+	}
+	if _, err := svc.EndImpersonation(ctx, token.TokenID); !errors.Is(err, ErrImpersonationNotFound) {
+		t.Fatalf("expected ErrImpersonationNotFound, got %v", err)
+	}
+
+	//1.- RevokeAll on either party blacklists the tokens between them.
+	for _, party := range []string{"1", "2"} {
+		token, err := svc.Impersonate(ctx, "1", "2", time.Minute)
+		if err != nil {
+			t.Fatalf("Impersonate returned error: %v", err)
+		}
+		if err := svc.RevokeAll(ctx, party); err != nil {
+			t.Fatalf("RevokeAll returned error: %v", err)
+		}
+		if _, err := svc.ValidateAccessToken(ctx, token.AccessToken); !errors.Is(err, ErrBlacklisted) {
+			t.Fatalf("expected impersonation token revoked with user %s, got %v", party, err)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 1.- ErrSessionNotFound indicates that a session does not exist or belongs to another subject.
+var ErrSessionNotFound = errors.New("auth: session not found")
+
+// 1.- ClientInfo describes the device that opened or refreshed a session.
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// 1.- clientInfoKey is the private context key carrying ClientInfo into the service.
+type clientInfoKey struct{}
+
+// 1.- WithClientInfo attaches request metadata so issued token families can record it.
+func WithClientInfo(ctx context.Context, info ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, info)
+}
+
+// 1.- ClientInfoFromContext returns the metadata attached by WithClientInfo.
+func ClientInfoFromContext(ctx context.Context) (ClientInfo, bool) {
+	info, ok := ctx.Value(clientInfoKey{}).(ClientInfo)
+	return info, ok
+}
+
+// 1.- Session exposes the metadata tracked for a refresh family.
+type Session struct {
+	ID            string
+	Subject       string
+	UserAgent     string
+	IP            string
+	CreatedAt     time.Time
+	LastRefreshAt time.Time
+}
+
+// 1.- sessionRecord is the JSON document persisted in Redis for each refresh family.
+type sessionRecord struct {
+	Subject       string    `json:"sub"`
+	UserAgent     string    `json:"ua"`
+	IP            string    `json:"ip"`
+	CreatedAt     time.Time `json:"created_at"`
+	LastRefreshAt time.Time `json:"last_refresh_at"`
+}
+
+// 1.- ListSessions returns the live sessions of a subject ordered by most recent activity.
+func (s *Service) ListSessions(ctx context.Context, subject string) ([]Session, error) {
+	families, err := s.redis.SMembers(ctx, userFamiliesKey(subject)).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("auth: list user families: %w", err)
+	}
+
+	sessions := make([]Session, 0, len(families))
+	for _, familyID := range families {
+		record, found, err := s.loadSession(ctx, familyID)
+		if err != nil {
+			return nil, err
+		}
+		//1.- Drop index entries whose family expired or was revoked elsewhere.
+		if !found || record.Subject != subject {
+			_, _ = s.redis.SRem(ctx, userFamiliesKey(subject), familyID).Result()
+			continue
+		}
+		sessions = append(sessions, Session{
+			ID:            familyID,
+			Subject:       record.Subject,
+			UserAgent:     record.UserAgent,
+			IP:            record.IP,
+			CreatedAt:     record.CreatedAt,
+			LastRefreshAt: record.LastRefreshAt,
+		})
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastRefreshAt.After(sessions[j].LastRefreshAt)
+	})
+	return sessions, nil
+}
+
+// 1.- RevokeSession ends a single session after checking that it belongs to the subject.
+func (s *Service) RevokeSession(ctx context.Context, subject string, familyID string) error {
+	record, found, err := s.loadSession(ctx, familyID)
+	if err != nil {
+		return err
+	}
+	if !found || record.Subject != subject {
+		return ErrSessionNotFound
+	}
+	return s.endSession(ctx, subject, familyID)
+}
+
+// 1.- RevokeOtherSessions ends every session of the subject except keepFamilyID and reports how many ended.
+func (s *Service) RevokeOtherSessions(ctx context.Context, subject string, keepFamilyID string) (int, error) {
+	sessions, err := s.ListSessions(ctx, subject)
+	if err != nil {
+		return 0, err
+	}
+	revoked := 0
+	for _, session := range sessions {
+		if session.ID == keepFamilyID {
+			continue
+		}
+		if err := s.endSession(ctx, subject, session.ID); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+// 1.- endSession blacklists the family and removes its index entry and metadata.
+func (s *Service) endSession(ctx context.Context, subject string, familyID string) error {
+	if err := s.blacklistFamily(ctx, familyID); err != nil {
+		return err
+	}
+	if _, err := s.redis.SRem(ctx, userFamiliesKey(subject), familyID).Result(); err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("auth: unindex refresh family: %w", err)
+	}
+	return nil
+}
+
+// 1.- touchSession creates or refreshes the metadata document of a family.
+func (s *Service) touchSession(ctx context.Context, subject string, familyID string, now time.Time) error {
+	record, found, err := s.loadSession(ctx, familyID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		record = sessionRecord{Subject: subject, CreatedAt: now}
+	}
+	record.LastRefreshAt = now
+	if info, ok := ClientInfoFromContext(ctx); ok {
+		if info.UserAgent != "" {
+			record.UserAgent = info.UserAgent
+		}
+		if info.IP != "" {
+			record.IP = info.IP
+		}
+	}
+
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("auth: encode session: %w", err)
+	}
+	if err := s.redis.Set(ctx, sessionKey(familyID), string(encoded), s.cfg.RefreshExpiration).Err(); err != nil {
+		return fmt.Errorf("auth: persist session: %w", err)
+	}
+	return nil
+}
+
+// 1.- loadSession reads the metadata document for a family, reporting whether it exists.
+func (s *Service) loadSession(ctx context.Context, familyID string) (sessionRecord, bool, error) {
+	raw, err := s.redis.Get(ctx, sessionKey(familyID)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return sessionRecord{}, false, nil
+		}
+		return sessionRecord{}, false, fmt.Errorf("auth: load session: %w", err)
+	}
+	var record sessionRecord
+	if err := json.Unmarshal([]byte(raw), &record); err != nil {
+		return sessionRecord{}, false, fmt.Errorf("auth: decode session: %w", err)
+	}
+	return record, true, nil
+}
+
+// 1.- sessionKey builds the Redis key storing metadata for a refresh family.
+func sessionKey(familyID string) string {
+	return fmt.Sprintf("auth:session:%s", familyID)
+}
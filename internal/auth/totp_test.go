@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestTOTPMatchesRFC6238Vectors(t *testing.T) {
+	//1.- Use the SHA1 seed from RFC 6238 Appendix B truncated to six digits.
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1234567890:  "005924",
+		20000000000: "353130",
+	}
+	for unix, expected := range vectors {
+		code, err := TOTPCode(secret, time.Unix(unix, 0))
+		if err != nil {
+			t.Fatalf("TOTPCode returned error: %v", err)
+		}
+		if code != expected {
+			t.Fatalf("unexpected code at %d: got %s want %s", unix, code, expected)
+		}
+	}
+}
+
+func TestValidateTOTPToleratesOneStepOfDrift(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret returned error: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+
+	//1.- Codes from the previous step are accepted and report their own step.
+	previous, _ := TOTPCode(secret, now.Add(-30*time.Second))
+	step, ok := ValidateTOTP(secret, previous, now)
+	if !ok || step != totpStep(now)-1 {
+		t.Fatalf("expected previous step to validate, got step=%d ok=%v", step, ok)
+	}
+
+	//1.- Codes two steps away are rejected.
+	stale, _ := TOTPCode(secret, now.Add(-90*time.Second))
+	if _, ok := ValidateTOTP(secret, stale, now); ok {
+		t.Fatalf("expected stale code to be rejected")
+	}
+	if _, ok := ValidateTOTP("not base32!", "123456", now); ok {
+		t.Fatalf("expected malformed secret to be rejected")
+	}
+}
+
+func TestTOTPURIIncludesIssuerAndSecret(t *testing.T) {
+	uri := TOTPURI("Yamato", "admin@example.com", "JBSWY3DPEHPK3PXP")
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("failed to parse uri: %v", err)
+	}
+	if parsed.Scheme != "otpauth" || parsed.Host != "totp" || parsed.Path != "/Yamato:admin@example.com" {
+		t.Fatalf("unexpected uri shape: %s", uri)
+	}
+	if parsed.Query().Get("secret") != "JBSWY3DPEHPK3PXP" || parsed.Query().Get("issuer") != "Yamato" {
+		t.Fatalf("unexpected uri query: %s", uri)
+	}
+}
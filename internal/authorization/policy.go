@@ -2,6 +2,7 @@ package authorization
 
 import (
 	"errors"
+	"strings"
 	"sync"
 
 	"github.com/example/Yamato-Go-Gin-API/internal/auth"
@@ -19,13 +20,19 @@ type Gate struct {
 // 1.- Policy caches compiled permission sets and evaluates gates for principals.
 type Policy struct {
 	mu          sync.RWMutex
-	permissions map[string]map[string]struct{}
+	permissions map[string]permissionEntry
+}
+
+// 1.- permissionEntry pairs a compiled permission set with the access revision it was built from.
+type permissionEntry struct {
+	revision string
+	set      map[string]struct{}
 }
 
 // 1.- NewPolicy constructs a Policy with empty caches ready for use by middleware.
 func NewPolicy() *Policy {
 	return &Policy{
-		permissions: make(map[string]map[string]struct{}),
+		permissions: make(map[string]permissionEntry),
 	}
 }
 
@@ -60,20 +67,57 @@ func (p *Policy) Authorize(principal auth.Principal, gate Gate) error {
 
 // 1.- Invalidate clears the cached permission set for the given principal subject.
 func (p *Policy) Invalidate(subject string) {
-	//2.- Remove the cache entry so the next check rebuilds it from the principal payload.
+	//2.- Remove the subject's entries, including per-token ones, so the next check rebuilds them.
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	delete(p.permissions, subject)
+	for key := range p.permissions {
+		if key == subject || strings.HasPrefix(key, subject+tokenKeySeparator) {
+			delete(p.permissions, key)
+		}
+	}
+}
+
+// 1.- InvalidateAll clears every cached permission set after role or permission definitions change.
+func (p *Policy) InvalidateAll() {
+	//2.- Swap in an empty map so the next checks rebuild from fresh principals.
+	p.mu.Lock()
+	p.permissions = make(map[string]permissionEntry)
+	p.mu.Unlock()
+}
+
+// 1.- InvalidateClient clears the cached permission set of a service account after its scopes change.
+func (p *Policy) InvalidateClient(clientID string) {
+	p.mu.Lock()
+	delete(p.permissions, clientKeyPrefix+clientID)
+	p.mu.Unlock()
+}
+
+// 1.- tokenKeySeparator splits subject and access token id in cache keys.
+const tokenKeySeparator = "\x00token:"
+
+// 1.- clientKeyPrefix keeps service accounts apart from users whose ids happen to equal a client id.
+const clientKeyPrefix = "\x00client:"
+
+// 1.- cacheKey scopes cached permissions per credential because access tokens carry narrower scopes.
+func cacheKey(principal auth.Principal) string {
+	if principal.IsServiceAccount() {
+		return clientKeyPrefix + principal.ClientID
+	}
+	if principal.TokenID == "" {
+		return principal.Subject
+	}
+	return principal.Subject + tokenKeySeparator + principal.TokenID
 }
 
 // 1.- permissionSet converts the principal's permissions slice into a cached lookup map.
 func (p *Policy) permissionSet(principal auth.Principal) map[string]struct{} {
 	//2.- Attempt a fast read through the cache without blocking writers.
 	p.mu.RLock()
-	cached, ok := p.permissions[principal.Subject]
+	key := cacheKey(principal)
+	cached, ok := p.permissions[key]
 	p.mu.RUnlock()
-	if ok {
-		return cached
+	if ok && cached.revision == principal.AccessRevision {
+		return cached.set
 	}
 
 	//2.- Build a new lookup map from the principal's permissions slice.
@@ -84,7 +128,7 @@ func (p *Policy) permissionSet(principal auth.Principal) map[string]struct{} {
 
 	//2.- Store the map for subsequent requests before returning it.
 	p.mu.Lock()
-	p.permissions[principal.Subject] = built
+	p.permissions[key] = permissionEntry{revision: principal.AccessRevision, set: built}
 	p.mu.Unlock()
 
 	return built
@@ -143,3 +143,75 @@ func TestPolicyInvalidateRefreshesCachedPermissions(t *testing.T) {
 		t.Fatalf("expected forbidden after invalidation, got %v", err)
 	}
 }
+
+func TestPolicyCachesAccessTokenPermissionsSeparately(t *testing.T) {
+	//1.- A narrow token and the full session of the same subject must not share cache entries.
+	policy := authorization.NewPolicy()
+	gate := authorization.Gate{AllPermissions: []string{"pipelines.write"}}
+	token := auth.Principal{Subject: "user-6", TokenID: "7", Permissions: []string{"pipelines.read"}}
+	session := auth.Principal{Subject: "user-6", Permissions: []string{"pipelines.read", "pipelines.write"}}
+
+	if err := policy.Authorize(token, gate); !errors.Is(err, authorization.ErrForbidden) {
+		t.Fatalf("expected token to be forbidden, got %v", err)
+	}
+	if err := policy.Authorize(session, gate); err != nil {
+		t.Fatalf("expected session to pass: %v", err)
+	}
+
+	//2.- Invalidating the subject also drops its token entries.
+	token.Permissions = []string{"pipelines.write"}
+	policy.Invalidate("user-6")
+	if err := policy.Authorize(token, gate); err != nil {
+		t.Fatalf("expected refreshed token permissions to pass: %v", err)
+	}
+}
+
+func TestPolicyRebuildsWhenAccessRevisionChanges(t *testing.T) {
+	//1.- A principal built from freshly loaded grants carries a new revision and bypasses the stale entry.
+	policy := authorization.NewPolicy()
+	gate := authorization.Gate{AllPermissions: []string{"pipelines.write"}}
+	principal := auth.Principal{Subject: "user-7", Permissions: []string{"pipelines.write"}, AccessRevision: "a"}
+	if err := policy.Authorize(principal, gate); err != nil {
+		t.Fatalf("expected initial authorization to pass: %v", err)
+	}
+
+	principal.Permissions = nil
+	principal.AccessRevision = "b"
+	if err := policy.Authorize(principal, gate); !errors.Is(err, authorization.ErrForbidden) {
+		t.Fatalf("expected forbidden after revision change, got %v", err)
+	}
+
+	//2.- InvalidateAll drops every entry regardless of revision.
+	principal.Permissions = []string{"pipelines.write"}
+	if err := policy.Authorize(principal, gate); !errors.Is(err, authorization.ErrForbidden) {
+		t.Fatalf("expected cached denial for unchanged revision, got %v", err)
+	}
+	policy.InvalidateAll()
+	if err := policy.Authorize(principal, gate); err != nil {
+		t.Fatalf("expected authorization to pass after InvalidateAll: %v", err)
+	}
+}
+
+func TestPolicyKeepsServiceAccountsApartFromUsers(t *testing.T) {
+	//1.- A client whose id equals a user id must not reuse that user's cached permissions.
+	policy := authorization.NewPolicy()
+	gate := authorization.Gate{AllPermissions: []string{"pipelines.write"}}
+	user := auth.Principal{Subject: "billing", Permissions: []string{"pipelines.write"}}
+	service := auth.Principal{Subject: "billing", ClientID: "billing", Permissions: []string{"pipelines.read"}, AccessRevision: "pipelines:read"}
+	if err := policy.Authorize(user, gate); err != nil {
+		t.Fatalf("expected user to pass: %v", err)
+	}
+	if err := policy.Authorize(service, gate); !errors.Is(err, authorization.ErrForbidden) {
+		t.Fatalf("expected service account to be forbidden, got %v", err)
+	}
+
+	//2.- Service accounts hold no roles, and InvalidateClient drops their entry.
+	if err := policy.Authorize(service, authorization.Gate{AnyRoles: []string{"member"}}); !errors.Is(err, authorization.ErrForbidden) {
+		t.Fatalf("expected role gate to reject service account, got %v", err)
+	}
+	service.Permissions = []string{"pipelines.write"}
+	policy.InvalidateClient("billing")
+	if err := policy.Authorize(service, gate); err != nil {
+		t.Fatalf("expected refreshed service permissions to pass: %v", err)
+	}
+}
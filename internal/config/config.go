@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"strconv"
 	"strings"
@@ -20,9 +21,13 @@ type Config struct {
 	Locale   LocaleConfig
 	CORS     CORSConfig
 	Storage  StorageConfig
+	Password PasswordConfig
 }
 
 // 1.- JWTConfig stores token-related configuration.
+// 2.- Algorithm selects HS256 (shared Secret) or RS256/EdDSA (PrivateKeyFile) signing.
+// 3.- VerificationKeys maps retired key identifiers to PEM public key files kept during rotation.
+// 4.- AcceptHS256 keeps verifying Secret-signed HS256 tokens under RS256/EdDSA, e.g. while migrating; it is off by default.
 type JWTConfig struct {
 	Secret            string
 	Issuer            string
@@ -30,17 +35,62 @@ type JWTConfig struct {
 	Expiration        time.Duration
 	AccessExpiration  time.Duration
 	RefreshExpiration time.Duration
+	Algorithm         string
+	KeyID             string
+	PrivateKeyFile    string
+	VerificationKeys  map[string]string
+	AcceptHS256       bool
 }
 
+// 1.- PasswordConfig selects the password hashing algorithm, its cost parameters, and the password policy.
+// 2.- Argon2Memory is expressed in KiB; hashes made with weaker settings are upgraded on the next login.
+// 3.- BreachedFilter points at a filter built by cmd/tools/breachfilter; empty uses the bundled list.
+type PasswordConfig struct {
+	Algorithm         string
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	BcryptCost        int
+	MinLength         int
+	MaxLength         int
+	RequireUpper      bool
+	RequireLower      bool
+	RequireDigit      bool
+	RequireSymbol     bool
+	RejectSimilar     bool
+	CheckBreached     bool
+	BreachedFilter    string
+}
+
+// 1.- Password hashing algorithms accepted by PasswordConfig.Algorithm.
+const (
+	PasswordArgon2id = "argon2id"
+	PasswordBcrypt   = "bcrypt"
+)
+
 // 1.- RedisConfig stores cache connection parameters.
+// 2.- Mode selects a networked server (RedisModeServer) or the in-process emulator (RedisModeMemory) for development and tests.
 type RedisConfig struct {
+	Mode     string
 	Host     string
 	Port     int
+	Username string
 	Password string
 	DB       int
 	TLS      bool
 }
 
+// 1.- Redis modes accepted by RedisConfig.Mode.
+const (
+	RedisModeServer = "server"
+	RedisModeMemory = "memory"
+)
+
+// 1.- Addr joins host and port into the dial address.
+func (c RedisConfig) Addr() string {
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
 // 1.- PostgresConfig keeps the SQL database connection details.
 type PostgresConfig struct {
 	Host           string
@@ -111,14 +161,14 @@ func Load(path string) (Config, error) {
 			Expiration:        getDuration("JWT_EXPIRATION", envMap, time.Hour*24),
 			AccessExpiration:  getDuration("JWT_ACCESS_EXPIRATION", envMap, 15*time.Minute),
 			RefreshExpiration: getDuration("JWT_REFRESH_EXPIRATION", envMap, 30*24*time.Hour),
+			Algorithm:         getString("JWT_ALGORITHM", envMap, "HS256"),
+			KeyID:             getString("JWT_KEY_ID", envMap, ""),
+			PrivateKeyFile:    getString("JWT_PRIVATE_KEY_FILE", envMap, ""),
+			VerificationKeys:  ParseKeyValueList(getString("JWT_VERIFICATION_KEYS", envMap, "")),
+			AcceptHS256:       getBool("JWT_ACCEPT_HS256", envMap, false),
 		},
-		Redis: RedisConfig{
-			Host:     getString("REDIS_HOST", envMap, "127.0.0.1"),
-			Port:     getInt("REDIS_PORT", envMap, 6379),
-			Password: getString("REDIS_PASSWORD", envMap, ""),
-			DB:       getInt("REDIS_DB", envMap, 0),
-			TLS:      getBool("REDIS_TLS", envMap, false),
-		},
+		Redis:    redisConfig(envMap),
+		Password: passwordConfig(envMap),
 		Postgres: PostgresConfig{
 			Host:           getString("POSTGRES_HOST", envMap, "127.0.0.1"),
 			Port:           getInt("POSTGRES_PORT", envMap, 5432),
@@ -163,13 +213,101 @@ func Load(path string) (Config, error) {
 	}
 
 	//1.- Perform sanity checks for required fields that lack sensible defaults.
-	if cfg.JWT.Secret == "" {
+	if strings.EqualFold(cfg.JWT.Algorithm, "HS256") && cfg.JWT.Secret == "" {
 		return Config{}, errors.New("jwt secret must not be empty")
 	}
+	if !strings.EqualFold(cfg.JWT.Algorithm, "HS256") && cfg.JWT.PrivateKeyFile == "" {
+		return Config{}, errors.New("jwt private key file must be configured for asymmetric signing")
+	}
+	if cfg.Redis.Mode != RedisModeServer && cfg.Redis.Mode != RedisModeMemory {
+		return Config{}, fmt.Errorf("unsupported redis mode %q", cfg.Redis.Mode)
+	}
+	if err := cfg.Password.validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// 1.- LoadPassword reads only the password hashing section for processes that hash credentials.
+func LoadPassword(path string) (PasswordConfig, error) {
+	envMap, err := readEnvFile(path)
+	if err != nil {
+		return PasswordConfig{}, err
+	}
+	cfg := passwordConfig(envMap)
+	if err := cfg.validate(); err != nil {
+		return PasswordConfig{}, err
+	}
+	return cfg, nil
+}
+
+// 1.- passwordConfig assembles the password section with OWASP-recommended Argon2id defaults.
+func passwordConfig(envMap map[string]string) PasswordConfig {
+	return PasswordConfig{
+		Algorithm:         strings.ToLower(getString("PASSWORD_HASHER", envMap, PasswordArgon2id)),
+		Argon2Memory:      uint32(getInt("PASSWORD_ARGON2_MEMORY_KIB", envMap, 19456)),
+		Argon2Iterations:  uint32(getInt("PASSWORD_ARGON2_ITERATIONS", envMap, 2)),
+		Argon2Parallelism: uint8(getInt("PASSWORD_ARGON2_PARALLELISM", envMap, 1)),
+		BcryptCost:        getInt("PASSWORD_BCRYPT_COST", envMap, 12),
+		MinLength:         getInt("PASSWORD_MIN_LENGTH", envMap, 8),
+		MaxLength:         getInt("PASSWORD_MAX_LENGTH", envMap, 128),
+		RequireUpper:      getBool("PASSWORD_REQUIRE_UPPERCASE", envMap, false),
+		RequireLower:      getBool("PASSWORD_REQUIRE_LOWERCASE", envMap, false),
+		RequireDigit:      getBool("PASSWORD_REQUIRE_DIGIT", envMap, false),
+		RequireSymbol:     getBool("PASSWORD_REQUIRE_SYMBOL", envMap, false),
+		RejectSimilar:     getBool("PASSWORD_REJECT_SIMILAR", envMap, true),
+		CheckBreached:     getBool("PASSWORD_CHECK_BREACHED", envMap, true),
+		BreachedFilter:    getString("PASSWORD_BREACHED_FILTER", envMap, ""),
+	}
+}
 
+// 1.- validate rejects unknown algorithms and parameters too small to be meaningful.
+func (c PasswordConfig) validate() error {
+	switch c.Algorithm {
+	case PasswordArgon2id:
+		if c.Argon2Memory < 8*uint32(c.Argon2Parallelism) || c.Argon2Iterations < 1 || c.Argon2Parallelism < 1 {
+			return errors.New("argon2id parameters must be positive and memory at least 8 KiB per lane")
+		}
+	case PasswordBcrypt:
+		if c.BcryptCost < 4 || c.BcryptCost > 31 {
+			return fmt.Errorf("bcrypt cost %d out of range", c.BcryptCost)
+		}
+	default:
+		return fmt.Errorf("unsupported password hasher %q", c.Algorithm)
+	}
+	if c.MinLength < 1 || c.MaxLength < c.MinLength {
+		return fmt.Errorf("password length bounds %d-%d are invalid", c.MinLength, c.MaxLength)
+	}
+	return nil
+}
+
+// 1.- LoadRedis reads only the Redis section so processes without JWT settings can share the connection rules.
+func LoadRedis(path string) (RedisConfig, error) {
+	envMap, err := readEnvFile(path)
+	if err != nil {
+		return RedisConfig{}, err
+	}
+	cfg := redisConfig(envMap)
+	if cfg.Mode != RedisModeServer && cfg.Mode != RedisModeMemory {
+		return RedisConfig{}, fmt.Errorf("unsupported redis mode %q", cfg.Mode)
+	}
 	return cfg, nil
 }
 
+// 1.- redisConfig assembles the Redis section; REDIS_TLS_ENABLED is honoured as an alias of REDIS_TLS.
+func redisConfig(envMap map[string]string) RedisConfig {
+	return RedisConfig{
+		Mode:     strings.ToLower(getString("REDIS_MODE", envMap, RedisModeServer)),
+		Host:     getString("REDIS_HOST", envMap, "127.0.0.1"),
+		Port:     getInt("REDIS_PORT", envMap, 6379),
+		Username: getString("REDIS_USERNAME", envMap, ""),
+		Password: getString("REDIS_PASSWORD", envMap, ""),
+		DB:       getInt("REDIS_DB", envMap, 0),
+		TLS:      getBool("REDIS_TLS", envMap, getBool("REDIS_TLS_ENABLED", envMap, false)),
+	}
+}
+
 // 1.- readEnvFile parses a .env file if it exists and returns the key-value map.
 func readEnvFile(path string) (map[string]string, error) {
 	//1.- Determine the target path, falling back to the default .env.
@@ -265,3 +403,20 @@ func getStringSlice(key string, envMap map[string]string, def []string) []string
 	}
 	return trimmed
 }
+
+// 1.- ParseKeyValueList converts "key=value" pairs separated by commas or semicolons into a map.
+func ParseKeyValueList(raw string) map[string]string {
+	parsed := map[string]string{}
+	for _, part := range strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == ';'
+	}) {
+		key, value, found := strings.Cut(part, "=")
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if !found || key == "" || value == "" {
+			continue
+		}
+		parsed[key] = value
+	}
+	return parsed
+}
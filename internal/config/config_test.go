@@ -172,3 +172,108 @@ func TestLoadRateLimitFromEnvironment(t *testing.T) {
 		t.Fatalf("expected settings key override, got %q", cfg.Rate.SettingsKey)
 	}
 }
+
+// 1.- TestLoadAsymmetricJWTSettings ensures key material references are parsed for RS256/EdDSA signing.
+func TestLoadAsymmetricJWTSettings(t *testing.T) {
+	unsetEnv(t, "JWT_SECRET")
+	t.Setenv("JWT_ALGORITHM", "RS256")
+	t.Setenv("JWT_KEY_ID", "2024-06")
+	t.Setenv("JWT_PRIVATE_KEY_FILE", "/run/secrets/jwt.pem")
+	t.Setenv("JWT_VERIFICATION_KEYS", "2024-01=/run/secrets/old.pem; broken, 2023-12=/run/secrets/older.pem")
+
+	cfg, err := Load(filepath.Join(t.TempDir(), "absent.env"))
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if cfg.JWT.Algorithm != "RS256" || cfg.JWT.KeyID != "2024-06" {
+		t.Fatalf("unexpected jwt algorithm settings: %#v", cfg.JWT)
+	}
+	if cfg.JWT.PrivateKeyFile != "/run/secrets/jwt.pem" {
+		t.Fatalf("unexpected private key file: %q", cfg.JWT.PrivateKeyFile)
+	}
+	if len(cfg.JWT.VerificationKeys) != 2 || cfg.JWT.VerificationKeys["2024-01"] != "/run/secrets/old.pem" || cfg.JWT.VerificationKeys["2023-12"] != "/run/secrets/older.pem" {
+		t.Fatalf("unexpected verification keys: %#v", cfg.JWT.VerificationKeys)
+	}
+
+	t.Setenv("JWT_PRIVATE_KEY_FILE", "")
+	if _, err := Load(filepath.Join(t.TempDir(), "absent.env")); err == nil {
+		t.Fatalf("expected error when asymmetric signing lacks a private key")
+	}
+}
+
+// 1.- TestLoadRedisSettings covers the Redis-only loader, the TLS alias, and mode validation.
+func TestLoadRedisSettings(t *testing.T) {
+	dir := t.TempDir()
+	envPath := filepath.Join(dir, ".env")
+	envContent := "" +
+		"REDIS_HOST=cache.internal\n" +
+		"REDIS_PORT=6380\n" +
+		"REDIS_USERNAME=api\n" +
+		"REDIS_DB=3\n" +
+		"REDIS_TLS_ENABLED=true\n"
+	if err := os.WriteFile(envPath, []byte(envContent), 0o600); err != nil {
+		t.Fatalf("failed to create env file: %v", err)
+	}
+
+	cfg, err := LoadRedis(envPath)
+	if err != nil {
+		t.Fatalf("LoadRedis returned error: %v", err)
+	}
+	if cfg.Mode != RedisModeServer {
+		t.Fatalf("expected default redis mode %q, got %q", RedisModeServer, cfg.Mode)
+	}
+	if cfg.Addr() != "cache.internal:6380" || cfg.Username != "api" || cfg.DB != 3 || !cfg.TLS {
+		t.Fatalf("unexpected redis config: %+v", cfg)
+	}
+
+	t.Setenv("REDIS_MODE", "Memory")
+	cfg, err = LoadRedis(envPath)
+	if err != nil {
+		t.Fatalf("LoadRedis returned error: %v", err)
+	}
+	if cfg.Mode != RedisModeMemory {
+		t.Fatalf("expected memory mode, got %q", cfg.Mode)
+	}
+
+	t.Setenv("REDIS_MODE", "cluster")
+	if _, err := LoadRedis(envPath); err == nil {
+		t.Fatal("expected unsupported redis mode to fail")
+	}
+}
+
+// 1.- TestLoadPasswordSettings covers the Argon2id defaults, overrides, and algorithm validation.
+func TestLoadPasswordSettings(t *testing.T) {
+	envPath := filepath.Join(t.TempDir(), "absent.env")
+	cfg, err := LoadPassword(envPath)
+	if err != nil {
+		t.Fatalf("LoadPassword returned error: %v", err)
+	}
+	if cfg.Algorithm != PasswordArgon2id || cfg.Argon2Memory != 19456 || cfg.Argon2Iterations != 2 || cfg.Argon2Parallelism != 1 {
+		t.Fatalf("unexpected password defaults: %+v", cfg)
+	}
+	if cfg.MinLength != 8 || cfg.MaxLength != 128 || !cfg.RejectSimilar || !cfg.CheckBreached || cfg.RequireSymbol {
+		t.Fatalf("unexpected password policy defaults: %+v", cfg)
+	}
+
+	t.Setenv("PASSWORD_HASHER", "BCRYPT")
+	t.Setenv("PASSWORD_BCRYPT_COST", "11")
+	cfg, err = LoadPassword(envPath)
+	if err != nil {
+		t.Fatalf("LoadPassword returned error: %v", err)
+	}
+	if cfg.Algorithm != PasswordBcrypt || cfg.BcryptCost != 11 {
+		t.Fatalf("unexpected bcrypt config: %+v", cfg)
+	}
+
+	t.Setenv("PASSWORD_MIN_LENGTH", "12")
+	t.Setenv("PASSWORD_MAX_LENGTH", "10")
+	if _, err := LoadPassword(envPath); err == nil {
+		t.Fatal("expected inverted length bounds to fail")
+	}
+	t.Setenv("PASSWORD_MAX_LENGTH", "64")
+
+	t.Setenv("PASSWORD_HASHER", "scrypt")
+	if _, err := LoadPassword(envPath); err == nil {
+		t.Fatal("expected unsupported hasher to fail")
+	}
+}
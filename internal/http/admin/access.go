@@ -0,0 +1,90 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 1.- AccessInvalidator drops cached role and permission grants after admin mutations.
+type AccessInvalidator interface {
+	InvalidateSubject(ctx context.Context, subject string) error
+	InvalidateAll(ctx context.Context) error
+}
+
+// 1.- HandlerOption customises optional Handler dependencies.
+type HandlerOption func(*Handler)
+
+// 1.- WithAccessInvalidator refreshes cached principals whenever users, roles, or permissions change.
+func WithAccessInvalidator(invalidator AccessInvalidator) HandlerOption {
+	return func(h *Handler) {
+		h.access = invalidator
+	}
+}
+
+// 1.- SessionRevoker terminates every refresh family issued to a subject.
+type SessionRevoker interface {
+	RevokeAll(ctx context.Context, subject string) error
+}
+
+// 1.- WithSessionRevoker signs deleted users out of every session instead of waiting for their tokens to expire.
+func WithSessionRevoker(sessions SessionRevoker) HandlerOption {
+	return func(h *Handler) {
+		h.sessions = sessions
+	}
+}
+
+// 1.- subjectInvalidator is implemented by policies that memoize permission sets per subject.
+type subjectInvalidator interface {
+	Invalidate(subject string)
+}
+
+// 1.- globalInvalidator is implemented by policies that can drop every memoized permission set.
+type globalInvalidator interface {
+	InvalidateAll()
+}
+
+// 1.- invalidateSubject clears one user's cached grants and reports whether the response may proceed.
+func (h Handler) invalidateSubject(ctx *gin.Context, subject string) bool {
+	//2.- Drop the in-process policy cache first; it cannot fail.
+	if policy, ok := h.authorizer.(subjectInvalidator); ok {
+		policy.Invalidate(subject)
+	}
+	if h.access == nil {
+		return true
+	}
+	if err := h.access.InvalidateSubject(ctx.Request.Context(), subject); err != nil {
+		writeError(ctx, http.StatusInternalServerError, "could not refresh cached permissions", nil)
+		return false
+	}
+	return true
+}
+
+// 1.- revokeSessions ends the subject's sessions and reports whether the response may proceed.
+func (h Handler) revokeSessions(ctx *gin.Context, subject string) bool {
+	if h.sessions == nil {
+		return true
+	}
+	if err := h.sessions.RevokeAll(ctx.Request.Context(), subject); err != nil {
+		writeError(ctx, http.StatusInternalServerError, "could not revoke user sessions", nil)
+		return false
+	}
+	return true
+}
+
+// 1.- invalidateAll clears every cached grant after a role or permission definition changes.
+func (h Handler) invalidateAll(ctx *gin.Context) bool {
+	//2.- Drop the in-process policy cache first; it cannot fail.
+	if policy, ok := h.authorizer.(globalInvalidator); ok {
+		policy.InvalidateAll()
+	}
+	if h.access == nil {
+		return true
+	}
+	if err := h.access.InvalidateAll(ctx.Request.Context()); err != nil {
+		writeError(ctx, http.StatusInternalServerError, "could not refresh cached permissions", nil)
+		return false
+	}
+	return true
+}
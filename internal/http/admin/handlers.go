@@ -19,6 +19,17 @@ const (
 	PermissionManageRoles       = "admin.roles.manage"
 	PermissionManagePermissions = "admin.permissions.manage"
 	PermissionManageTeams       = "admin.teams.manage"
+	PermissionImpersonateUsers  = internalauth.PermissionImpersonate
+)
+
+// 1.- Sentinel errors services return so handlers can answer with precise status codes.
+var (
+	// 2.- ErrNotFound reports an unknown or deleted record.
+	ErrNotFound = errors.New("http/admin: record not found")
+	// 3.- ErrConflict reports a unique name or email that is already taken.
+	ErrConflict = errors.New("http/admin: record already exists")
+	// 4.- ErrUnknownReference reports role, permission, or team names in a payload that do not exist.
+	ErrUnknownReference = errors.New("http/admin: unknown reference")
 )
 
 // 1.- Pagination carries common paging parameters shared across listing handlers.
@@ -98,17 +109,23 @@ type Handler struct {
 	roles       RoleService
 	permissions PermissionService
 	teams       TeamService
+	access      AccessInvalidator
+	sessions    SessionRevoker
 }
 
 // 1.- NewHandler wires the admin services and policy into a reusable Handler.
-func NewHandler(authorizer Authorizer, users UserService, roles RoleService, permissions PermissionService, teams TeamService) Handler {
-	return Handler{
+func NewHandler(authorizer Authorizer, users UserService, roles RoleService, permissions PermissionService, teams TeamService, opts ...HandlerOption) Handler {
+	handler := Handler{
 		authorizer:  authorizer,
 		users:       users,
 		roles:       roles,
 		permissions: permissions,
 		teams:       teams,
 	}
+	for _, opt := range opts {
+		opt(&handler)
+	}
+	return handler
 }
 
 // 1.- successEnvelope implements the standard success payload wrapper.
@@ -145,6 +162,20 @@ func writeError(ctx *gin.Context, status int, message string, errs map[string]in
 	ctx.JSON(status, errorEnvelope{Message: message, Errors: errs})
 }
 
+// 1.- writeServiceError maps service sentinels to 404, 409, and 422, and anything else to 500 with the given message.
+func writeServiceError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		writeError(ctx, http.StatusNotFound, "not found", nil)
+	case errors.Is(err, ErrConflict):
+		writeError(ctx, http.StatusConflict, "already exists", nil)
+	case errors.Is(err, ErrUnknownReference):
+		writeError(ctx, http.StatusUnprocessableEntity, "validation failed", map[string]interface{}{"references": err.Error()})
+	default:
+		writeError(ctx, http.StatusInternalServerError, message, nil)
+	}
+}
+
 // 1.- requirePrincipal fetches the current principal or responds with 401.
 func requirePrincipal(ctx *gin.Context) (internalauth.Principal, bool) {
 	// 2.- Attempt to extract the principal from the Gin context bag.
@@ -302,7 +333,7 @@ func (h Handler) CreateUser(ctx *gin.Context) {
 	// 4.- Delegate to the user service and handle unexpected failures.
 	created, err := h.users.Create(ctx.Request.Context(), payload)
 	if err != nil {
-		writeError(ctx, http.StatusInternalServerError, "could not create user", nil)
+		writeServiceError(ctx, err, "could not create user")
 		return
 	}
 
@@ -333,7 +364,12 @@ func (h Handler) UpdateUser(ctx *gin.Context) {
 	// 4.- Persist the update through the user service.
 	updated, err := h.users.Update(ctx.Request.Context(), id, payload)
 	if err != nil {
-		writeError(ctx, http.StatusInternalServerError, "could not update user", nil)
+		writeServiceError(ctx, err, "could not update user")
+		return
+	}
+
+	// 5.- Role assignments may have changed, so drop the user's cached grants.
+	if !h.invalidateSubject(ctx, id) {
 		return
 	}
 
@@ -350,7 +386,11 @@ func (h Handler) DeleteUser(ctx *gin.Context) {
 	}
 
 	if err := h.users.Delete(ctx.Request.Context(), id); err != nil {
-		writeError(ctx, http.StatusInternalServerError, "could not delete user", nil)
+		writeServiceError(ctx, err, "could not delete user")
+		return
+	}
+	// 3.- A deleted account must not keep working through sessions issued before the deletion.
+	if !h.revokeSessions(ctx, id) || !h.invalidateSubject(ctx, id) {
 		return
 	}
 
@@ -365,7 +405,7 @@ func (h Handler) ListUsers(ctx *gin.Context) {
 	// 3.- Fetch the paginated records from the user service.
 	users, total, err := h.users.List(ctx.Request.Context(), pagination)
 	if err != nil {
-		writeError(ctx, http.StatusInternalServerError, "could not list users", nil)
+		writeServiceError(ctx, err, "could not list users")
 		return
 	}
 
@@ -387,7 +427,7 @@ func (h Handler) CreateRole(ctx *gin.Context) {
 	}
 	created, err := h.roles.Create(ctx.Request.Context(), payload)
 	if err != nil {
-		writeError(ctx, http.StatusInternalServerError, "could not create role", nil)
+		writeServiceError(ctx, err, "could not create role")
 		return
 	}
 	writeSuccess(ctx, http.StatusCreated, created, map[string]any{})
@@ -412,7 +452,10 @@ func (h Handler) UpdateRole(ctx *gin.Context) {
 	}
 	updated, err := h.roles.Update(ctx.Request.Context(), id, payload)
 	if err != nil {
-		writeError(ctx, http.StatusInternalServerError, "could not update role", nil)
+		writeServiceError(ctx, err, "could not update role")
+		return
+	}
+	if !h.invalidateAll(ctx) {
 		return
 	}
 	writeSuccess(ctx, http.StatusOK, updated, map[string]any{})
@@ -426,7 +469,10 @@ func (h Handler) DeleteRole(ctx *gin.Context) {
 		return
 	}
 	if err := h.roles.Delete(ctx.Request.Context(), id); err != nil {
-		writeError(ctx, http.StatusInternalServerError, "could not delete role", nil)
+		writeServiceError(ctx, err, "could not delete role")
+		return
+	}
+	if !h.invalidateAll(ctx) {
 		return
 	}
 	writeSuccess(ctx, http.StatusOK, gin.H{}, map[string]any{})
@@ -437,7 +483,7 @@ func (h Handler) ListRoles(ctx *gin.Context) {
 	pagination := paginationFromContext(ctx)
 	roles, total, err := h.roles.List(ctx.Request.Context(), pagination)
 	if err != nil {
-		writeError(ctx, http.StatusInternalServerError, "could not list roles", nil)
+		writeServiceError(ctx, err, "could not list roles")
 		return
 	}
 	meta := map[string]any{"page": pagination.Page, "per_page": pagination.PerPage, "total": total}
@@ -458,7 +504,7 @@ func (h Handler) CreatePermission(ctx *gin.Context) {
 	}
 	created, err := h.permissions.Create(ctx.Request.Context(), payload)
 	if err != nil {
-		writeError(ctx, http.StatusInternalServerError, "could not create permission", nil)
+		writeServiceError(ctx, err, "could not create permission")
 		return
 	}
 	writeSuccess(ctx, http.StatusCreated, created, map[string]any{})
@@ -483,7 +529,10 @@ func (h Handler) UpdatePermission(ctx *gin.Context) {
 	}
 	updated, err := h.permissions.Update(ctx.Request.Context(), id, payload)
 	if err != nil {
-		writeError(ctx, http.StatusInternalServerError, "could not update permission", nil)
+		writeServiceError(ctx, err, "could not update permission")
+		return
+	}
+	if !h.invalidateAll(ctx) {
 		return
 	}
 	writeSuccess(ctx, http.StatusOK, updated, map[string]any{})
@@ -497,7 +546,10 @@ func (h Handler) DeletePermission(ctx *gin.Context) {
 		return
 	}
 	if err := h.permissions.Delete(ctx.Request.Context(), id); err != nil {
-		writeError(ctx, http.StatusInternalServerError, "could not delete permission", nil)
+		writeServiceError(ctx, err, "could not delete permission")
+		return
+	}
+	if !h.invalidateAll(ctx) {
 		return
 	}
 	writeSuccess(ctx, http.StatusOK, gin.H{}, map[string]any{})
@@ -508,7 +560,7 @@ func (h Handler) ListPermissions(ctx *gin.Context) {
 	pagination := paginationFromContext(ctx)
 	permissions, total, err := h.permissions.List(ctx.Request.Context(), pagination)
 	if err != nil {
-		writeError(ctx, http.StatusInternalServerError, "could not list permissions", nil)
+		writeServiceError(ctx, err, "could not list permissions")
 		return
 	}
 	meta := map[string]any{"page": pagination.Page, "per_page": pagination.PerPage, "total": total}
@@ -529,7 +581,7 @@ func (h Handler) CreateTeam(ctx *gin.Context) {
 	}
 	created, err := h.teams.Create(ctx.Request.Context(), payload)
 	if err != nil {
-		writeError(ctx, http.StatusInternalServerError, "could not create team", nil)
+		writeServiceError(ctx, err, "could not create team")
 		return
 	}
 	writeSuccess(ctx, http.StatusCreated, created, map[string]any{})
@@ -554,7 +606,7 @@ func (h Handler) UpdateTeam(ctx *gin.Context) {
 	}
 	updated, err := h.teams.Update(ctx.Request.Context(), id, payload)
 	if err != nil {
-		writeError(ctx, http.StatusInternalServerError, "could not update team", nil)
+		writeServiceError(ctx, err, "could not update team")
 		return
 	}
 	writeSuccess(ctx, http.StatusOK, updated, map[string]any{})
@@ -568,7 +620,7 @@ func (h Handler) DeleteTeam(ctx *gin.Context) {
 		return
 	}
 	if err := h.teams.Delete(ctx.Request.Context(), id); err != nil {
-		writeError(ctx, http.StatusInternalServerError, "could not delete team", nil)
+		writeServiceError(ctx, err, "could not delete team")
 		return
 	}
 	writeSuccess(ctx, http.StatusOK, gin.H{}, map[string]any{})
@@ -579,7 +631,7 @@ func (h Handler) ListTeams(ctx *gin.Context) {
 	pagination := paginationFromContext(ctx)
 	teams, total, err := h.teams.List(ctx.Request.Context(), pagination)
 	if err != nil {
-		writeError(ctx, http.StatusInternalServerError, "could not list teams", nil)
+		writeServiceError(ctx, err, "could not list teams")
 		return
 	}
 	meta := map[string]any{"page": pagination.Page, "per_page": pagination.PerPage, "total": total}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -442,3 +444,142 @@ type servicesBundle struct {
 	permissions *testPermissionService
 	teams       *testTeamService
 }
+
+// 1.- recordingInvalidator captures the cache invalidations triggered by admin mutations.
+type recordingInvalidator struct {
+	subjects []string
+	all      int
+}
+
+func (r *recordingInvalidator) InvalidateSubject(_ context.Context, subject string) error {
+	r.subjects = append(r.subjects, subject)
+	return nil
+}
+
+func (r *recordingInvalidator) InvalidateAll(_ context.Context) error {
+	r.all++
+	return nil
+}
+
+// 1.- recordingSessions captures the subjects whose sessions were revoked.
+type recordingSessions struct {
+	subjects []string
+}
+
+func (r *recordingSessions) RevokeAll(_ context.Context, subject string) error {
+	r.subjects = append(r.subjects, subject)
+	return nil
+}
+
+func TestHandler_InvalidatesCachedAccess(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	// 2.- Use the real policy so its in-process cache is exercised alongside the invalidator.
+	policy := authorization.NewPolicy()
+	invalidator := &recordingInvalidator{}
+	sessions := &recordingSessions{}
+	handler := admin.NewHandler(policy, &testUserService{}, &testRoleService{}, &testPermissionService{}, &testTeamService{}, admin.WithAccessInvalidator(invalidator), admin.WithSessionRevoker(sessions))
+
+	principal := internalauth.Principal{Subject: "admin", Permissions: []string{admin.PermissionManageUsers, admin.PermissionManageRoles, admin.PermissionManagePermissions, admin.PermissionManageTeams}}
+	router := gin.New()
+	router.Use(applyPrincipal(principal))
+	router.PUT("/admin/users/:id", handler.RBAC(admin.PermissionManageUsers), handler.UpdateUser)
+	router.DELETE("/admin/users/:id", handler.RBAC(admin.PermissionManageUsers), handler.DeleteUser)
+	router.POST("/admin/roles", handler.RBAC(admin.PermissionManageRoles), handler.CreateRole)
+	router.PUT("/admin/roles/:id", handler.RBAC(admin.PermissionManageRoles), handler.UpdateRole)
+	router.DELETE("/admin/permissions/:id", handler.RBAC(admin.PermissionManagePermissions), handler.DeletePermission)
+	router.POST("/admin/teams", handler.RBAC(admin.PermissionManageTeams), handler.CreateTeam)
+
+	userBody, _ := json.Marshal(admin.User{Email: "member@example.com", Roles: []string{"editor"}})
+	roleBody, _ := json.Marshal(admin.Role{Name: "editor", Permissions: []string{"posts.write"}})
+	teamBody, _ := json.Marshal(admin.Team{Name: "core"})
+
+	requests := []struct {
+		method string
+		path   string
+		body   []byte
+	}{
+		{http.MethodPut, "/admin/users/42", userBody},
+		{http.MethodDelete, "/admin/users/43", nil},
+		{http.MethodPost, "/admin/roles", roleBody},
+		{http.MethodPut, "/admin/roles/role-1", roleBody},
+		{http.MethodDelete, "/admin/permissions/perm-1", nil},
+		{http.MethodPost, "/admin/teams", teamBody},
+	}
+	for _, request := range requests {
+		resp := executeRequest(router, request.method, request.path, request.body)
+		if resp.Code >= http.StatusBadRequest {
+			t.Fatalf("%s %s: unexpected status %d", request.method, request.path, resp.Code)
+		}
+	}
+
+	// 3.- Only user edits drop a single subject; role and permission edits invalidate everyone.
+	if len(invalidator.subjects) != 2 || invalidator.subjects[0] != "42" || invalidator.subjects[1] != "43" {
+		t.Fatalf("unexpected subject invalidations: %v", invalidator.subjects)
+	}
+	if invalidator.all != 2 {
+		t.Fatalf("expected two global invalidations, got %d", invalidator.all)
+	}
+
+	// 4.- Deleting a user also ends every session it still holds; updates leave sessions alone.
+	if len(sessions.subjects) != 1 || sessions.subjects[0] != "43" {
+		t.Fatalf("unexpected session revocations: %v", sessions.subjects)
+	}
+}
+
+// 1.- failingRoleService returns a fixed error from every mutation.
+type failingRoleService struct {
+	testRoleService
+	err error
+}
+
+func (s *failingRoleService) Create(_ context.Context, _ admin.Role) (admin.Role, error) {
+	return admin.Role{}, s.err
+}
+
+func (s *failingRoleService) Update(_ context.Context, _ string, _ admin.Role) (admin.Role, error) {
+	return admin.Role{}, s.err
+}
+
+func TestHandler_MapsServiceErrors(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	principal := internalauth.Principal{Subject: "admin", Permissions: []string{admin.PermissionManageRoles}}
+	roleBody, _ := json.Marshal(admin.Role{Name: "editor", Permissions: []string{"posts.write"}})
+
+	cases := []struct {
+		name   string
+		err    error
+		method string
+		path   string
+		status int
+	}{
+		{"unknown id", admin.ErrNotFound, http.MethodPut, "/admin/roles/99", http.StatusNotFound},
+		{"duplicate name", admin.ErrConflict, http.MethodPost, "/admin/roles", http.StatusConflict},
+		{"unknown permission", fmt.Errorf("%w: permissions posts.write", admin.ErrUnknownReference), http.MethodPost, "/admin/roles", http.StatusUnprocessableEntity},
+		{"storage failure", errors.New("connection reset"), http.MethodPost, "/admin/roles", http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			// 2.- Mutations that fail must not invalidate cached grants.
+			invalidator := &recordingInvalidator{}
+			handler := admin.NewHandler(&testAuthorizer{allow: true}, &testUserService{}, &failingRoleService{err: tc.err}, &testPermissionService{}, &testTeamService{}, admin.WithAccessInvalidator(invalidator))
+			router := gin.New()
+			router.Use(applyPrincipal(principal))
+			router.POST("/admin/roles", handler.RBAC(admin.PermissionManageRoles), handler.CreateRole)
+			router.PUT("/admin/roles/:id", handler.RBAC(admin.PermissionManageRoles), handler.UpdateRole)
+
+			resp := executeRequest(router, tc.method, tc.path, roleBody)
+			if resp.Code != tc.status {
+				t.Fatalf("expected status %d, got %d: %s", tc.status, resp.Code, resp.Body.String())
+			}
+			if invalidator.all != 0 {
+				t.Fatalf("expected no invalidation after a failed mutation")
+			}
+		})
+	}
+}
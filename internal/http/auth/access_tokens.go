@@ -0,0 +1,441 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/respond"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/validation"
+)
+
+// 1.- AccessTokenPrefix marks personal access tokens so middleware can tell them apart from JWTs.
+const AccessTokenPrefix = "yat_"
+
+// 1.- ErrAccessTokenNotFound is returned when a personal access token is unknown or owned by someone else.
+var ErrAccessTokenNotFound = errors.New("http/auth: access token not found")
+
+// 1.- ErrInvalidAccessToken indicates a malformed, expired, or revoked personal access token.
+var ErrInvalidAccessToken = errors.New("http/auth: invalid access token")
+
+// 1.- ErrInvalidScope indicates that a requested scope is unknown or exceeds the caller's permissions.
+var ErrInvalidScope = errors.New("http/auth: invalid access token scope")
+
+// 1.- accessTokenTouchInterval throttles last_used_at writes for busy tokens.
+const accessTokenTouchInterval = time.Minute
+
+// 1.- PersonalAccessToken is a long-lived API credential stored only as a digest.
+type PersonalAccessToken struct {
+	ID         string
+	UserID     string
+	Name       string
+	Prefix     string
+	TokenHash  string
+	Scopes     []string
+	ExpiresAt  time.Time
+	LastUsedAt time.Time
+	CreatedAt  time.Time
+}
+
+// 1.- Expired reports whether the token has a deadline that already passed.
+func (t PersonalAccessToken) Expired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && !now.Before(t.ExpiresAt)
+}
+
+// 1.- AccessTokenStore persists personal access tokens keyed by their public prefix.
+type AccessTokenStore interface {
+	// 2.- Create stores the token and returns it with its assigned identifier.
+	Create(ctx context.Context, token PersonalAccessToken) (PersonalAccessToken, error)
+	// 3.- FindByPrefix loads a token for authentication or returns ErrAccessTokenNotFound.
+	FindByPrefix(ctx context.Context, prefix string) (PersonalAccessToken, error)
+	// 4.- ListByUser returns the user's tokens, newest first.
+	ListByUser(ctx context.Context, userID string) ([]PersonalAccessToken, error)
+	// 5.- Rename changes the label of a token owned by the user.
+	Rename(ctx context.Context, userID string, id string, name string) (PersonalAccessToken, error)
+	// 6.- Delete revokes a token owned by the user or returns ErrAccessTokenNotFound.
+	Delete(ctx context.Context, userID string, id string) error
+	// 7.- TouchLastUsed records when the token last authenticated a request.
+	TouchLastUsed(ctx context.Context, id string, at time.Time) error
+}
+
+// 1.- AccessTokenManager defines the CRUD workflow consumed by the handlers.
+type AccessTokenManager interface {
+	Create(ctx context.Context, principal internalauth.Principal, name string, scopes []string, expiresAt time.Time) (string, PersonalAccessToken, error)
+	List(ctx context.Context, userID string) ([]PersonalAccessToken, error)
+	Rename(ctx context.Context, userID string, id string, name string) (PersonalAccessToken, error)
+	Revoke(ctx context.Context, userID string, id string) error
+}
+
+// 1.- AccessTokenAuthenticator resolves a presented token to its record and granted permission slugs.
+type AccessTokenAuthenticator interface {
+	AuthenticateAccessToken(ctx context.Context, token string) (PersonalAccessToken, []string, error)
+}
+
+// 1.- AccessTokenConfig maps scopes to permission slugs and bounds token lifetime.
+type AccessTokenConfig struct {
+	Scopes map[string][]string
+	MaxTTL time.Duration
+}
+
+// 1.- AccessTokenService issues, lists, and authenticates personal access tokens.
+type AccessTokenService struct {
+	store AccessTokenStore
+	cfg   AccessTokenConfig
+	now   func() time.Time
+}
+
+// 1.- NewAccessTokenService wires the token workflow around the configured scope catalogue.
+func NewAccessTokenService(store AccessTokenStore, cfg AccessTokenConfig) *AccessTokenService {
+	if cfg.Scopes == nil {
+		cfg.Scopes = map[string][]string{}
+	}
+	return &AccessTokenService{store: store, cfg: cfg, now: time.Now}
+}
+
+// 1.- Create mints a token whose scopes never exceed the permissions the caller already holds.
+func (s *AccessTokenService) Create(ctx context.Context, principal internalauth.Principal, name string, scopes []string, expiresAt time.Time) (string, PersonalAccessToken, error) {
+	normalized, err := s.authorizeScopes(principal, scopes)
+	if err != nil {
+		return "", PersonalAccessToken{}, err
+	}
+
+	//1.- Clamp the lifetime so configured maximums also apply to tokens without an expiry.
+	now := s.now()
+	if s.cfg.MaxTTL > 0 && (expiresAt.IsZero() || expiresAt.After(now.Add(s.cfg.MaxTTL))) {
+		expiresAt = now.Add(s.cfg.MaxTTL)
+	}
+	if !expiresAt.IsZero() && !expiresAt.After(now) {
+		return "", PersonalAccessToken{}, ErrInvalidAccessToken
+	}
+
+	plaintext, prefix, err := newAccessToken()
+	if err != nil {
+		return "", PersonalAccessToken{}, err
+	}
+	record, err := s.store.Create(ctx, PersonalAccessToken{
+		UserID:    principal.Subject,
+		Name:      name,
+		Prefix:    prefix,
+		TokenHash: HashAccessToken(plaintext),
+		Scopes:    normalized,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return "", PersonalAccessToken{}, fmt.Errorf("http/auth: store access token: %w", err)
+	}
+	return plaintext, record, nil
+}
+
+// 1.- List returns the user's tokens.
+func (s *AccessTokenService) List(ctx context.Context, userID string) ([]PersonalAccessToken, error) {
+	return s.store.ListByUser(ctx, userID)
+}
+
+// 1.- Rename relabels one of the user's tokens.
+func (s *AccessTokenService) Rename(ctx context.Context, userID string, id string, name string) (PersonalAccessToken, error) {
+	return s.store.Rename(ctx, userID, id, name)
+}
+
+// 1.- Revoke deletes one of the user's tokens.
+func (s *AccessTokenService) Revoke(ctx context.Context, userID string, id string) error {
+	return s.store.Delete(ctx, userID, id)
+}
+
+// 1.- AuthenticateAccessToken verifies the digest and expiry, then maps scopes to permission slugs.
+func (s *AccessTokenService) AuthenticateAccessToken(ctx context.Context, token string) (PersonalAccessToken, []string, error) {
+	prefix, ok := accessTokenPrefix(token)
+	if !ok {
+		return PersonalAccessToken{}, nil, ErrInvalidAccessToken
+	}
+	record, err := s.store.FindByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, ErrAccessTokenNotFound) {
+			return PersonalAccessToken{}, nil, ErrInvalidAccessToken
+		}
+		return PersonalAccessToken{}, nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(record.TokenHash), []byte(HashAccessToken(token))) != 1 {
+		return PersonalAccessToken{}, nil, ErrInvalidAccessToken
+	}
+	now := s.now()
+	if record.Expired(now) {
+		return PersonalAccessToken{}, nil, ErrInvalidAccessToken
+	}
+
+	//1.- Skip the write when the token was already marked as used moments ago.
+	if now.Sub(record.LastUsedAt) >= accessTokenTouchInterval {
+		if err := s.store.TouchLastUsed(ctx, record.ID, now); err != nil {
+			return PersonalAccessToken{}, nil, fmt.Errorf("http/auth: touch access token: %w", err)
+		}
+		record.LastUsedAt = now
+	}
+	return record, s.permissionsFor(record.Scopes), nil
+}
+
+// 1.- authorizeScopes rejects unknown scopes and scopes granting permissions the caller lacks.
+func (s *AccessTokenService) authorizeScopes(principal internalauth.Principal, scopes []string) ([]string, error) {
+	held := make(map[string]struct{}, len(principal.Permissions))
+	for _, permission := range principal.Permissions {
+		held[permission] = struct{}{}
+	}
+
+	seen := map[string]struct{}{}
+	normalized := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if scope == "" {
+			continue
+		}
+		if _, dup := seen[scope]; dup {
+			continue
+		}
+		permissions, ok := s.cfg.Scopes[scope]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown scope %q", ErrInvalidScope, scope)
+		}
+		for _, permission := range permissions {
+			if _, ok := held[permission]; !ok {
+				return nil, fmt.Errorf("%w: scope %q requires permission %q", ErrInvalidScope, scope, permission)
+			}
+		}
+		seen[scope] = struct{}{}
+		normalized = append(normalized, scope)
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// 1.- permissionsFor expands scopes into the distinct permission slugs they grant.
+func (s *AccessTokenService) permissionsFor(scopes []string) []string {
+	seen := map[string]struct{}{}
+	permissions := []string{}
+	for _, scope := range scopes {
+		for _, permission := range s.cfg.Scopes[scope] {
+			if _, ok := seen[permission]; ok {
+				continue
+			}
+			seen[permission] = struct{}{}
+			permissions = append(permissions, permission)
+		}
+	}
+	sort.Strings(permissions)
+	return permissions
+}
+
+// 1.- HashAccessToken derives the stored digest; tokens carry 256 bits of entropy so no pepper is needed.
+func HashAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
+
+// 1.- newAccessToken returns yat_<prefix>_<secret> together with its lookup prefix.
+func newAccessToken() (string, string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("http/auth: generate token prefix: %w", err)
+	}
+	prefix := hex.EncodeToString(buf)
+	secret, err := newOneTimeToken()
+	if err != nil {
+		return "", "", err
+	}
+	return AccessTokenPrefix + prefix + "_" + secret, prefix, nil
+}
+
+// 1.- accessTokenPrefix extracts the 12-character lookup prefix from a presented token.
+func accessTokenPrefix(token string) (string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(token), AccessTokenPrefix)
+	if !ok || len(rest) < 14 || rest[12] != '_' {
+		return "", false
+	}
+	return rest[:12], true
+}
+
+// 1.- createAccessTokenRequest names the token, selects scopes, and optionally bounds its lifetime.
+type createAccessTokenRequest struct {
+	Name          string   `json:"name" validate:"required"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"omitempty,min=1"`
+}
+
+// 1.- renameAccessTokenRequest carries the new label.
+type renameAccessTokenRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// 1.- accessTokenResponse is the JSON view of a token; the secret is never included.
+type accessTokenResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// 1.- toAccessTokenResponse renders optional timestamps as null.
+func toAccessTokenResponse(token PersonalAccessToken) accessTokenResponse {
+	optional := func(value time.Time) *time.Time {
+		if value.IsZero() {
+			return nil
+		}
+		return &value
+	}
+	scopes := token.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return accessTokenResponse{
+		ID:         token.ID,
+		Name:       token.Name,
+		Prefix:     AccessTokenPrefix + token.Prefix,
+		Scopes:     scopes,
+		ExpiresAt:  optional(token.ExpiresAt),
+		LastUsedAt: optional(token.LastUsedAt),
+		CreatedAt:  token.CreatedAt,
+	}
+}
+
+// 1.- accessTokenPrincipal guards token endpoints; tokens cannot be managed with another token.
+func (h Handler) accessTokenPrincipal(ctx *gin.Context) (internalauth.Principal, bool) {
+	if h.accessTokens == nil {
+		respond.Error(ctx, http.StatusServiceUnavailable, "access tokens unavailable", map[string]interface{}{"reason": "not configured"})
+		return internalauth.Principal{}, false
+	}
+	principal, ok := internalauth.PrincipalFromContext(ctx)
+	if !ok {
+		respond.Error(ctx, http.StatusUnauthorized, "authentication required", map[string]interface{}{"reason": "principal missing"})
+		return internalauth.Principal{}, false
+	}
+	if principal.TokenID != "" {
+		respond.Error(ctx, http.StatusForbidden, "access tokens cannot manage access tokens", map[string]interface{}{"reason": "interactive session required"})
+		return internalauth.Principal{}, false
+	}
+	return principal, true
+}
+
+// 1.- ListAccessTokens returns the caller's personal access tokens.
+func (h Handler) ListAccessTokens(ctx *gin.Context) {
+	// 1.- Resolve the caller.
+	principal, ok := h.accessTokenPrincipal(ctx)
+	if !ok {
+		return
+	}
+
+	// 2.- Load and render the tokens.
+	tokens, err := h.accessTokens.List(ctx.Request.Context(), principal.Subject)
+	if err != nil {
+		respond.Error(ctx, http.StatusInternalServerError, "failed to list access tokens", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	views := make([]accessTokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		views = append(views, toAccessTokenResponse(token))
+	}
+	respond.Success(ctx, http.StatusOK, views, map[string]interface{}{"total": len(views)})
+}
+
+// 1.- CreateAccessToken mints a token and reveals its secret exactly once.
+func (h Handler) CreateAccessToken(ctx *gin.Context) {
+	// 1.- Resolve the caller.
+	principal, ok := h.accessTokenPrincipal(ctx)
+	if !ok {
+		return
+	}
+
+	// 2.- Bind and validate the payload.
+	var req createAccessTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Error(ctx, http.StatusBadRequest, "invalid request payload", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if !h.validatePayload(ctx, req) {
+		return
+	}
+	expiresAt := time.Time{}
+	if req.ExpiresInDays > 0 {
+		expiresAt = time.Now().AddDate(0, 0, req.ExpiresInDays)
+	}
+
+	// 3.- Mint the token, reporting scope problems as field errors.
+	plaintext, token, err := h.accessTokens.Create(ctx.Request.Context(), principal, req.Name, req.Scopes, expiresAt)
+	if err != nil {
+		if errors.Is(err, ErrInvalidScope) {
+			respond.Error(ctx, http.StatusUnprocessableEntity, "invalid scopes", map[string]interface{}{"fields": map[string][]validation.FieldError{
+				"scopes": []validation.FieldError{{Field: "scopes", Rule: "scope", Message: err.Error()}},
+			}})
+			return
+		}
+		respond.Error(ctx, http.StatusInternalServerError, "failed to create access token", map[string]interface{}{"details": err.Error()})
+		return
+	}
+
+	// 4.- Return the plaintext secret alongside the stored metadata.
+	respond.Success(ctx, http.StatusCreated, map[string]any{"token": plaintext, "access_token": toAccessTokenResponse(token)}, nil)
+}
+
+// 1.- RenameAccessToken updates the label of one of the caller's tokens.
+func (h Handler) RenameAccessToken(ctx *gin.Context) {
+	// 1.- Resolve the caller.
+	principal, ok := h.accessTokenPrincipal(ctx)
+	if !ok {
+		return
+	}
+
+	// 2.- Bind and validate the payload.
+	var req renameAccessTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Error(ctx, http.StatusBadRequest, "invalid request payload", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if !h.validatePayload(ctx, req) {
+		return
+	}
+
+	// 3.- Apply the rename.
+	token, err := h.accessTokens.Rename(ctx.Request.Context(), principal.Subject, strings.TrimSpace(ctx.Param("id")), req.Name)
+	if err != nil {
+		if errors.Is(err, ErrAccessTokenNotFound) {
+			respond.Error(ctx, http.StatusNotFound, "access token not found", nil)
+			return
+		}
+		respond.Error(ctx, http.StatusInternalServerError, "failed to rename access token", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	respond.Success(ctx, http.StatusOK, toAccessTokenResponse(token), nil)
+}
+
+// 1.- RevokeAccessToken deletes one of the caller's tokens.
+func (h Handler) RevokeAccessToken(ctx *gin.Context) {
+	// 1.- Resolve the caller.
+	principal, ok := h.accessTokenPrincipal(ctx)
+	if !ok {
+		return
+	}
+
+	// 2.- Delete the token, hiding tokens that belong to other users.
+	if err := h.accessTokens.Revoke(ctx.Request.Context(), principal.Subject, strings.TrimSpace(ctx.Param("id"))); err != nil {
+		if errors.Is(err, ErrAccessTokenNotFound) {
+			respond.Error(ctx, http.StatusNotFound, "access token not found", nil)
+			return
+		}
+		respond.Error(ctx, http.StatusInternalServerError, "failed to revoke access token", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	respond.Success(ctx, http.StatusOK, map[string]any{"revoked": true}, nil)
+}
@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+)
+
+// 1.- Browser clients opt into cookie sessions per request by sending SessionModeHeader: SessionModeCookie.
+const (
+	SessionModeHeader = "X-Session-Mode"
+	SessionModeCookie = "cookie"
+)
+
+// 1.- CSRFHeader echoes the CSRF cookie on mutating requests and carries fresh tokens in responses.
+const CSRFHeader = "X-CSRF-Token"
+
+// 1.- Default cookie names used when CookieSessionConfig leaves them empty.
+const (
+	DefaultAccessCookie  = "yamato_access"
+	DefaultRefreshCookie = "yamato_refresh"
+	DefaultCSRFCookie    = "yamato_csrf"
+)
+
+// 1.- CookieSessionConfig describes the cookies that carry tokens for the web dashboard.
+type CookieSessionConfig struct {
+	AccessCookie  string
+	RefreshCookie string
+	CSRFCookie    string
+	// 2.- Domain lets a dashboard on a sibling host read the CSRF cookie; empty scopes cookies to the API host.
+	Domain string
+	// 3.- RefreshPath limits where the browser sends the refresh cookie.
+	RefreshPath string
+	Secure      bool
+	SameSite    http.SameSite
+}
+
+// 1.- WithCookieDefaults fills unset names, the refresh path, and SameSite=Lax.
+func (c CookieSessionConfig) WithCookieDefaults() CookieSessionConfig {
+	if strings.TrimSpace(c.AccessCookie) == "" {
+		c.AccessCookie = DefaultAccessCookie
+	}
+	if strings.TrimSpace(c.RefreshCookie) == "" {
+		c.RefreshCookie = DefaultRefreshCookie
+	}
+	if strings.TrimSpace(c.CSRFCookie) == "" {
+		c.CSRFCookie = DefaultCSRFCookie
+	}
+	if strings.TrimSpace(c.RefreshPath) == "" {
+		c.RefreshPath = "/v1/auth"
+	}
+	if c.SameSite == 0 {
+		c.SameSite = http.SameSiteLaxMode
+	}
+	return c
+}
+
+// 1.- WithCookieSessions lets browser clients receive tokens as HttpOnly cookies instead of in the response body.
+func WithCookieSessions(cfg CookieSessionConfig) HandlerOption {
+	return func(h *Handler) {
+		cfg = cfg.WithCookieDefaults()
+		h.cookies = &cfg
+	}
+}
+
+// 1.- cookieMode reports whether this request asked for a cookie session and the handler supports it.
+func (h Handler) cookieMode(ctx *gin.Context) bool {
+	return h.cookies != nil && strings.EqualFold(strings.TrimSpace(ctx.GetHeader(SessionModeHeader)), SessionModeCookie)
+}
+
+// 1.- tokensFor returns the envelope for a new pair; in cookie mode the tokens go into cookies and are left out of the body.
+func (h Handler) tokensFor(ctx *gin.Context, pair internalauth.TokenPair) (tokenEnvelope, error) {
+	envelope := pairToEnvelope(pair)
+	if !h.cookieMode(ctx) {
+		return envelope, nil
+	}
+
+	//1.- A fresh CSRF token accompanies every rotation; it is readable by scripts by design.
+	csrf, err := newCSRFToken()
+	if err != nil {
+		return tokenEnvelope{}, err
+	}
+	cfg := h.cookies
+	h.setCookie(ctx, cfg.AccessCookie, pair.AccessToken, "/", pair.AccessExpiresAt, true)
+	h.setCookie(ctx, cfg.RefreshCookie, pair.RefreshToken, cfg.RefreshPath, pair.RefreshExpiresAt, true)
+	h.setCookie(ctx, cfg.CSRFCookie, csrf, "/", pair.RefreshExpiresAt, false)
+	ctx.Header(CSRFHeader, csrf)
+
+	envelope.AccessToken = ""
+	envelope.RefreshToken = ""
+	return envelope, nil
+}
+
+// 1.- sessionCookie reads a token cookie when the request is in cookie mode.
+func (h Handler) sessionCookie(ctx *gin.Context, name string) string {
+	if !h.cookieMode(ctx) {
+		return ""
+	}
+	value, err := ctx.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(value)
+}
+
+// 1.- clearSessionCookies expires every session cookie after logout.
+func (h Handler) clearSessionCookies(ctx *gin.Context) {
+	if !h.cookieMode(ctx) {
+		return
+	}
+	expired := time.Unix(0, 0)
+	h.setCookie(ctx, h.cookies.AccessCookie, "", "/", expired, true)
+	h.setCookie(ctx, h.cookies.RefreshCookie, "", h.cookies.RefreshPath, expired, true)
+	h.setCookie(ctx, h.cookies.CSRFCookie, "", "/", expired, false)
+}
+
+// 1.- setCookie writes a cookie with the configured domain, Secure flag, and SameSite policy.
+func (h Handler) setCookie(ctx *gin.Context, name string, value string, path string, expires time.Time, httpOnly bool) {
+	maxAge := int(time.Until(expires).Seconds())
+	if maxAge <= 0 {
+		maxAge = -1
+	}
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   h.cookies.Domain,
+		Expires:  expires.UTC(),
+		MaxAge:   maxAge,
+		Secure:   h.cookies.Secure,
+		HttpOnly: httpOnly,
+		SameSite: h.cookies.SameSite,
+	})
+}
+
+// 1.- newCSRFToken returns 256 random bits for the double-submit cookie.
+func newCSRFToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("http/auth: generate csrf token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,419 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/respond"
+	phonenumber "github.com/example/Yamato-Go-Gin-API/internal/phone"
+)
+
+// 1.- ErrDeviceTokenNotFound is returned when a device token is unknown, revoked, or expired.
+var ErrDeviceTokenNotFound = errors.New("http/auth: device token not found")
+
+// 1.- ErrPhoneTaken is returned when another live account already linked the phone.
+var ErrPhoneTaken = errors.New("http/auth: phone already linked")
+
+// 1.- PhoneSubjectPrefix marks JWT subjects that identify a verified phone rather than a user account.
+const PhoneSubjectPrefix = "phone:"
+
+// 1.- DefaultDeviceTokenIdleTTL expires device tokens left unused for 90 days.
+const DefaultDeviceTokenIdleTTL = 90 * 24 * time.Hour
+
+// 1.- Device statuses reported by the management endpoints.
+const (
+	DeviceStatusActive  = "active"
+	DeviceStatusRevoked = "revoked"
+	DeviceStatusExpired = "expired"
+)
+
+// 1.- DeviceToken is a long-lived credential issued after a phone number was verified.
+type DeviceToken struct {
+	ID         string
+	Phone      string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	RevokedAt  *time.Time
+}
+
+// 1.- DeviceTokenStore resolves and manages device tokens issued by the phone verification flow.
+type DeviceTokenStore interface {
+	// 2.- Use slides last_used_at forward on a live token; tokens idle longer than idleTTL (zero disables expiry) are rejected.
+	Use(ctx context.Context, token string, usedAt time.Time, idleTTL time.Duration) (DeviceToken, error)
+	// 3.- ListByPhone returns every token of the phone, newest first, including revoked ones.
+	ListByPhone(ctx context.Context, phone string) ([]DeviceToken, error)
+	// 4.- Revoke stamps revoked_at on a live token of the phone.
+	Revoke(ctx context.Context, phone string, id string, revokedAt time.Time) error
+	// 5.- RevokeAll stamps revoked_at on every live token of the phone and reports how many changed.
+	RevokeAll(ctx context.Context, phone string, revokedAt time.Time) (int, error)
+	// 6.- Rotate revokes a live token and issues newToken for the same phone in one step.
+	Rotate(ctx context.Context, token string, newToken string, rotatedAt time.Time, idleTTL time.Duration) (DeviceToken, error)
+}
+
+// 1.- PhoneLinkStore records the verified phone of a user account so exports and purges can reach phone-keyed rows.
+type PhoneLinkStore interface {
+	LinkPhone(ctx context.Context, userID string, phone string) error
+}
+
+// 1.- WithPhoneLinks lets signed-in users attach the phone behind one of their device tokens.
+func WithPhoneLinks(links PhoneLinkStore) HandlerOption {
+	return func(h *Handler) {
+		h.phoneLinks = links
+	}
+}
+
+// 1.- WithDeviceTokens enables exchanging and managing phone device tokens; idleTTL of zero disables expiry.
+func WithDeviceTokens(tokens DeviceTokenStore, idleTTL time.Duration) HandlerOption {
+	return func(h *Handler) {
+		h.deviceTokens = tokens
+		h.deviceIdleTTL = idleTTL
+	}
+}
+
+// 1.- PhoneSubject builds the subject for sessions bound to a verified phone.
+func PhoneSubject(phone string) string {
+	return PhoneSubjectPrefix + strings.TrimSpace(phone)
+}
+
+// 1.- deviceLoginRequest carries the token returned by the phone verification confirmation.
+type deviceLoginRequest struct {
+	DeviceToken string `json:"device_token" validate:"required"`
+}
+
+// 1.- deviceLoginResponse returns the phone identity together with the issued tokens.
+type deviceLoginResponse struct {
+	Phone   string        `json:"phone"`
+	Subject string        `json:"subject"`
+	Tokens  tokenEnvelope `json:"tokens"`
+}
+
+// 1.- deviceResponse is the JSON view of a device token; the secret itself is never listed.
+type deviceResponse struct {
+	ID         string     `json:"id"`
+	Phone      string     `json:"phone"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// 1.- deviceRotateResponse returns the replacement secret once, alongside its metadata.
+type deviceRotateResponse struct {
+	DeviceToken string         `json:"device_token"`
+	Device      deviceResponse `json:"device"`
+}
+
+// 1.- DeviceLogin exchanges a device token for a token pair bound to the verified phone.
+func (h Handler) DeviceLogin(ctx *gin.Context) {
+	// 1.- Guard against missing device token dependencies to surface clear errors.
+	if !h.requireDeviceTokens(ctx) {
+		return
+	}
+
+	// 2.- Bind and validate the payload.
+	req, ok := h.bindDeviceToken(ctx)
+	if !ok {
+		return
+	}
+
+	// 3.- Resolve the token and slide its expiry; unknown, revoked, and expired tokens are indistinguishable to the caller.
+	device, err := h.deviceTokens.Use(ctx.Request.Context(), req.DeviceToken, time.Now(), h.deviceIdleTTL)
+	if err != nil {
+		h.respondDeviceTokenError(ctx, err, "failed to resolve device token")
+		return
+	}
+
+	// 4.- Start a session whose subject is the phone identity.
+	subject := PhoneSubject(device.Phone)
+	pair, err := h.auth.Login(clientContext(ctx), subject)
+	if err != nil {
+		respond.Error(ctx, http.StatusInternalServerError, "failed to issue tokens", map[string]interface{}{"details": err.Error()})
+		return
+	}
+
+	// 5.- Return the phone identity and token envelope.
+	tokens, err := h.tokensFor(ctx, pair)
+	if err != nil {
+		respond.Error(ctx, http.StatusInternalServerError, "failed to issue tokens", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	respond.Success(ctx, http.StatusOK, deviceLoginResponse{Phone: device.Phone, Subject: subject, Tokens: tokens}, nil)
+}
+
+// 1.- RotateDeviceToken replaces a device token with a fresh secret, revoking the old one.
+func (h Handler) RotateDeviceToken(ctx *gin.Context) {
+	// 1.- Guard and bind the current token, which proves possession of the device.
+	if !h.requireDeviceTokens(ctx) {
+		return
+	}
+	req, ok := h.bindDeviceToken(ctx)
+	if !ok {
+		return
+	}
+
+	// 2.- Swap the secret atomically so a leaked copy of the old token stops working.
+	newToken, err := newDeviceToken()
+	if err != nil {
+		respond.Error(ctx, http.StatusInternalServerError, "failed to rotate device token", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	device, err := h.deviceTokens.Rotate(ctx.Request.Context(), req.DeviceToken, newToken, time.Now(), h.deviceIdleTTL)
+	if err != nil {
+		h.respondDeviceTokenError(ctx, err, "failed to rotate device token")
+		return
+	}
+	respond.Success(ctx, http.StatusOK, deviceRotateResponse{DeviceToken: newToken, Device: h.deviceView(device, time.Now())}, nil)
+}
+
+// 1.- ListDevices returns the device tokens of the phone behind the current session.
+func (h Handler) ListDevices(ctx *gin.Context) {
+	phone, ok := h.devicePhone(ctx)
+	if !ok {
+		return
+	}
+	h.listDevices(ctx, phone)
+}
+
+// 1.- RevokeDevice revokes one device token of the phone behind the current session.
+func (h Handler) RevokeDevice(ctx *gin.Context) {
+	phone, ok := h.devicePhone(ctx)
+	if !ok {
+		return
+	}
+	h.revokeDevice(ctx, phone)
+}
+
+// 1.- RevokeDevices revokes every device token of the phone and ends its other sessions.
+func (h Handler) RevokeDevices(ctx *gin.Context) {
+	phone, ok := h.devicePhone(ctx)
+	if !ok {
+		return
+	}
+	principal, _ := internalauth.PrincipalFromContext(ctx)
+	h.revokeDevices(ctx, phone, principal.SessionID)
+}
+
+// 1.- AdminListDevices lets operators inspect the device tokens of any phone.
+func (h Handler) AdminListDevices(ctx *gin.Context) {
+	phone, ok := h.adminDevicePhone(ctx)
+	if !ok {
+		return
+	}
+	h.listDevices(ctx, phone)
+}
+
+// 1.- AdminRevokeDevice lets operators revoke one device token of a phone.
+func (h Handler) AdminRevokeDevice(ctx *gin.Context) {
+	phone, ok := h.adminDevicePhone(ctx)
+	if !ok {
+		return
+	}
+	h.revokeDevice(ctx, phone)
+}
+
+// 1.- AdminRevokeDevices lets operators revoke every device token of a lost phone and end all of its sessions.
+func (h Handler) AdminRevokeDevices(ctx *gin.Context) {
+	phone, ok := h.adminDevicePhone(ctx)
+	if !ok {
+		return
+	}
+	h.revokeDevices(ctx, phone, "")
+}
+
+// 1.- LinkPhone attaches the phone proven by a device token to the signed-in user account.
+func (h Handler) LinkPhone(ctx *gin.Context) {
+	// 1.- Guard against missing dependencies and sessions that are not user accounts.
+	if !h.requireDeviceTokens(ctx) {
+		return
+	}
+	if h.phoneLinks == nil {
+		respond.Error(ctx, http.StatusServiceUnavailable, "phone linking unavailable", map[string]interface{}{"reason": "not configured"})
+		return
+	}
+	principal, ok := internalauth.PrincipalFromContext(ctx)
+	if !ok {
+		respond.Error(ctx, http.StatusUnauthorized, "authentication required", map[string]interface{}{"reason": "principal missing"})
+		return
+	}
+	if strings.HasPrefix(principal.Subject, PhoneSubjectPrefix) {
+		respond.Error(ctx, http.StatusForbidden, "user session required", map[string]interface{}{"reason": "phone sessions have no account to link"})
+		return
+	}
+
+	// 2.- The device token proves the phone was verified; only live tokens count.
+	req, ok := h.bindDeviceToken(ctx)
+	if !ok {
+		return
+	}
+	device, err := h.deviceTokens.Use(ctx.Request.Context(), req.DeviceToken, time.Now(), h.deviceIdleTTL)
+	if err != nil {
+		h.respondDeviceTokenError(ctx, err, "failed to resolve device token")
+		return
+	}
+
+	// 3.- Record the phone on the account.
+	if err := h.phoneLinks.LinkPhone(ctx.Request.Context(), principal.Subject, device.Phone); err != nil {
+		switch {
+		case errors.Is(err, ErrPhoneTaken):
+			respond.Error(ctx, http.StatusConflict, "phone already linked", map[string]interface{}{"phone": "linked to another account"})
+		case errors.Is(err, ErrUserNotFound):
+			respond.Error(ctx, http.StatusNotFound, "user not found", nil)
+		default:
+			respond.Error(ctx, http.StatusInternalServerError, "failed to link phone", map[string]interface{}{"details": err.Error()})
+		}
+		return
+	}
+	respond.Success(ctx, http.StatusOK, map[string]any{"phone": device.Phone}, nil)
+}
+
+// 1.- listDevices renders every token of the phone with its derived status.
+func (h Handler) listDevices(ctx *gin.Context, phone string) {
+	devices, err := h.deviceTokens.ListByPhone(ctx.Request.Context(), phone)
+	if err != nil {
+		respond.Error(ctx, http.StatusInternalServerError, "failed to list devices", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	now := time.Now()
+	items := make([]deviceResponse, 0, len(devices))
+	for _, device := range devices {
+		items = append(items, h.deviceView(device, now))
+	}
+	respond.Success(ctx, http.StatusOK, items, map[string]interface{}{"total": len(items)})
+}
+
+// 1.- revokeDevice revokes the token named by the :id parameter, hiding tokens of other phones.
+func (h Handler) revokeDevice(ctx *gin.Context, phone string) {
+	id := strings.TrimSpace(ctx.Param("id"))
+	if err := h.deviceTokens.Revoke(ctx.Request.Context(), phone, id, time.Now()); err != nil {
+		if errors.Is(err, ErrDeviceTokenNotFound) {
+			respond.Error(ctx, http.StatusNotFound, "device not found", map[string]interface{}{"id": id})
+			return
+		}
+		respond.Error(ctx, http.StatusInternalServerError, "failed to revoke device", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	respond.Success(ctx, http.StatusOK, map[string]any{"revoked": true}, nil)
+}
+
+// 1.- revokeDevices revokes every token of the phone and, when sessions are managed, ends the phone's sessions except keepSessionID.
+func (h Handler) revokeDevices(ctx *gin.Context, phone string, keepSessionID string) {
+	revoked, err := h.deviceTokens.RevokeAll(ctx.Request.Context(), phone, time.Now())
+	if err != nil {
+		respond.Error(ctx, http.StatusInternalServerError, "failed to revoke devices", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	sessions := 0
+	if h.sessions != nil {
+		sessions, err = h.sessions.RevokeOtherSessions(ctx.Request.Context(), PhoneSubject(phone), keepSessionID)
+		if err != nil {
+			respond.Error(ctx, http.StatusInternalServerError, "failed to revoke sessions", map[string]interface{}{"details": err.Error()})
+			return
+		}
+	}
+	respond.Success(ctx, http.StatusOK, map[string]any{"revoked": revoked, "sessions_revoked": sessions}, nil)
+}
+
+// 1.- deviceView derives the status and sliding expiry of a token at the given instant.
+func (h Handler) deviceView(device DeviceToken, now time.Time) deviceResponse {
+	view := deviceResponse{
+		ID:         device.ID,
+		Phone:      device.Phone,
+		Status:     DeviceStatusActive,
+		CreatedAt:  device.CreatedAt,
+		LastUsedAt: device.LastUsedAt,
+		RevokedAt:  device.RevokedAt,
+	}
+	if h.deviceIdleTTL > 0 {
+		expiresAt := device.LastUsedAt.Add(h.deviceIdleTTL)
+		view.ExpiresAt = &expiresAt
+		if !now.Before(expiresAt) {
+			view.Status = DeviceStatusExpired
+		}
+	}
+	if device.RevokedAt != nil {
+		view.Status = DeviceStatusRevoked
+	}
+	return view
+}
+
+// 1.- requireDeviceTokens answers 503 when device tokens are not configured.
+func (h Handler) requireDeviceTokens(ctx *gin.Context) bool {
+	if h.deviceTokens == nil {
+		respond.Error(ctx, http.StatusServiceUnavailable, "device login unavailable", map[string]interface{}{"reason": "not configured"})
+		return false
+	}
+	return true
+}
+
+// 1.- bindDeviceToken binds and validates a { device_token } payload.
+func (h Handler) bindDeviceToken(ctx *gin.Context) (deviceLoginRequest, bool) {
+	var req deviceLoginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Error(ctx, http.StatusBadRequest, "invalid request payload", map[string]interface{}{"details": err.Error()})
+		return deviceLoginRequest{}, false
+	}
+	req.DeviceToken = strings.TrimSpace(req.DeviceToken)
+	if !h.validatePayload(ctx, req) {
+		return deviceLoginRequest{}, false
+	}
+	return req, true
+}
+
+// 1.- respondDeviceTokenError maps store failures, treating unknown, revoked, and expired tokens alike.
+func (h Handler) respondDeviceTokenError(ctx *gin.Context, err error, message string) {
+	if errors.Is(err, ErrDeviceTokenNotFound) {
+		respond.Error(ctx, http.StatusUnauthorized, "invalid device token", map[string]interface{}{"reason": "device token is unknown, revoked, or expired"})
+		return
+	}
+	respond.Error(ctx, http.StatusInternalServerError, message, map[string]interface{}{"details": err.Error()})
+}
+
+// 1.- devicePhone resolves the phone of a phone-bound session; user accounts manage devices through operators.
+func (h Handler) devicePhone(ctx *gin.Context) (string, bool) {
+	if !h.requireDeviceTokens(ctx) {
+		return "", false
+	}
+	principal, ok := internalauth.PrincipalFromContext(ctx)
+	if !ok {
+		respond.Error(ctx, http.StatusUnauthorized, "authentication required", map[string]interface{}{"reason": "principal missing"})
+		return "", false
+	}
+	phone, found := strings.CutPrefix(principal.Subject, PhoneSubjectPrefix)
+	if !found || phone == "" {
+		respond.Error(ctx, http.StatusForbidden, "phone session required", map[string]interface{}{"reason": "devices are managed from a session started with POST /v1/auth/device"})
+		return "", false
+	}
+	return phone, true
+}
+
+// 1.- adminDevicePhone normalises the :phone path parameter to the E.164 key used by device tokens.
+func (h Handler) adminDevicePhone(ctx *gin.Context) (string, bool) {
+	if !h.requireDeviceTokens(ctx) {
+		return "", false
+	}
+	phone, err := phonenumber.Normalize(ctx.Param("phone"))
+	if err != nil {
+		respond.Error(ctx, http.StatusBadRequest, "invalid phone", map[string]interface{}{"phone": err.Error()})
+		return "", false
+	}
+	return phone, true
+}
+
+// 1.- newDeviceToken returns 256 random bits hex-encoded, matching the tokens minted on confirmation.
+func newDeviceToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("http/auth: generate device token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,322 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/respond"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/validation"
+	"github.com/example/Yamato-Go-Gin-API/internal/queue"
+)
+
+// 1.- One-time token purposes backing the two links of an e-mail change.
+const (
+	PurposeEmailChange       = "email_change"
+	PurposeEmailChangeCancel = "email_change_cancel"
+)
+
+// 1.- ErrSameEmail indicates that the requested address is already the account's address.
+var ErrSameEmail = errors.New("http/auth: email unchanged")
+
+// 1.- EmailChanger defines the double-confirmation address change consumed by the handlers.
+type EmailChanger interface {
+	Request(ctx context.Context, user User, email string) error
+	Confirm(ctx context.Context, token string) (User, error)
+	Cancel(ctx context.Context, token string) error
+}
+
+// 1.- EmailChangeConfig tunes link lifetime, hashing secret, and the pages receiving each link.
+type EmailChangeConfig struct {
+	Secret     string
+	TTL        time.Duration
+	ConfirmURL string
+	CancelURL  string
+}
+
+// 1.- EmailChangeService confirms new addresses by mail, warns the old one, and swaps them once confirmed.
+type EmailChangeService struct {
+	users    UserStore
+	tokens   OneTimeTokenStore
+	mail     MailQueue
+	sessions SessionRevoker
+	cfg      EmailChangeConfig
+	now      func() time.Time
+}
+
+// 1.- NewEmailChangeService wires the change workflow with defaults for unset configuration.
+func NewEmailChangeService(users UserStore, tokens OneTimeTokenStore, mail MailQueue, sessions SessionRevoker, cfg EmailChangeConfig) *EmailChangeService {
+	if strings.TrimSpace(cfg.Secret) == "" {
+		cfg.Secret = "development-email-change-secret"
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = time.Hour
+	}
+	if strings.TrimSpace(cfg.ConfirmURL) == "" {
+		cfg.ConfirmURL = "http://localhost:3000/email/confirm"
+	}
+	if strings.TrimSpace(cfg.CancelURL) == "" {
+		cfg.CancelURL = "http://localhost:3000/email/cancel"
+	}
+	return &EmailChangeService{users: users, tokens: tokens, mail: mail, sessions: sessions, cfg: cfg, now: time.Now}
+}
+
+// 1.- Request mails a confirmation link to the new address and a cancel link to the current one.
+func (s *EmailChangeService) Request(ctx context.Context, user User, email string) error {
+	email = strings.TrimSpace(strings.ToLower(email))
+	if email == strings.ToLower(user.Email) {
+		return ErrSameEmail
+	}
+	if _, err := s.users.FindByEmail(ctx, email); err == nil {
+		return ErrEmailTaken
+	} else if !errors.Is(err, ErrUserNotFound) {
+		return err
+	}
+
+	//1.- Both tokens carry the pending address; saving supersedes the links of any earlier request.
+	expiresAt := s.now().Add(s.cfg.TTL)
+	confirmToken, err := s.issue(ctx, PurposeEmailChange, user.ID, email, expiresAt)
+	if err != nil {
+		return err
+	}
+	cancelToken, err := s.issue(ctx, PurposeEmailChangeCancel, user.ID, email, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	//2.- The new mailbox proves ownership; the old one gets a chance to stop a hijack.
+	confirmLink, err := appendQuery(s.cfg.ConfirmURL, url.Values{"token": {confirmToken}})
+	if err != nil {
+		return err
+	}
+	cancelLink, err := appendQuery(s.cfg.CancelURL, url.Values{"token": {cancelToken}})
+	if err != nil {
+		return err
+	}
+	if _, err := s.mail.Enqueue(ctx, queue.EmailSendJobName, map[string]any{
+		"to":      email,
+		"subject": "Confirm your new e-mail address",
+		"body":    fmt.Sprintf("Use the link below within %s to make this your sign-in address:\n%s\n\nIf you did not ask for this, you can ignore this message.", s.cfg.TTL, confirmLink),
+	}); err != nil {
+		return fmt.Errorf("http/auth: enqueue email change confirmation: %w", err)
+	}
+	if _, err := s.mail.Enqueue(ctx, queue.EmailSendJobName, map[string]any{
+		"to":      user.Email,
+		"subject": "Your e-mail address is about to change",
+		"body":    fmt.Sprintf("Someone asked to change the sign-in address of your account to %s.\n\nIf this was not you, cancel the change here:\n%s", email, cancelLink),
+	}); err != nil {
+		return fmt.Errorf("http/auth: enqueue email change notice: %w", err)
+	}
+	return nil
+}
+
+// 1.- Confirm swaps the address, retires the cancel link, and signs the user out everywhere.
+func (s *EmailChangeService) Confirm(ctx context.Context, token string) (User, error) {
+	record, err := s.tokens.Consume(ctx, PurposeEmailChange, HashOneTimeToken(token, s.cfg.Secret), s.now())
+	if err != nil {
+		return User{}, err
+	}
+
+	if err := s.users.UpdateEmail(ctx, record.UserID, record.Payload); err != nil {
+		return User{}, err
+	}
+	if err := s.tokens.Revoke(ctx, record.UserID, PurposeEmailChangeCancel); err != nil {
+		return User{}, fmt.Errorf("http/auth: revoke email change cancel link: %w", err)
+	}
+	if err := s.sessions.RevokeAll(ctx, record.UserID); err != nil {
+		return User{}, fmt.Errorf("http/auth: revoke sessions: %w", err)
+	}
+	return s.users.FindByID(ctx, record.UserID)
+}
+
+// 1.- Cancel redeems the cancel link and invalidates the pending confirmation.
+func (s *EmailChangeService) Cancel(ctx context.Context, token string) error {
+	record, err := s.tokens.Consume(ctx, PurposeEmailChangeCancel, HashOneTimeToken(token, s.cfg.Secret), s.now())
+	if err != nil {
+		return err
+	}
+	if err := s.tokens.Revoke(ctx, record.UserID, PurposeEmailChange); err != nil {
+		return fmt.Errorf("http/auth: revoke email change confirmation: %w", err)
+	}
+	return nil
+}
+
+// 1.- issue stores the digest of a fresh token and returns the plaintext for the link.
+func (s *EmailChangeService) issue(ctx context.Context, purpose string, userID string, email string, expiresAt time.Time) (string, error) {
+	token, err := newOneTimeToken()
+	if err != nil {
+		return "", err
+	}
+	if err := s.tokens.Save(ctx, OneTimeToken{
+		Purpose:   purpose,
+		UserID:    userID,
+		TokenHash: HashOneTimeToken(token, s.cfg.Secret),
+		Payload:   email,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return "", fmt.Errorf("http/auth: store email change token: %w", err)
+	}
+	return token, nil
+}
+
+// 1.- changeEmailRequest carries the new address and, unless the session signed in recently, the current password.
+type changeEmailRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password"`
+}
+
+// 1.- emailChangeTokenRequest carries the token from a confirmation or cancel link.
+type emailChangeTokenRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// 1.- RequestEmailChange starts an address change for the authenticated user.
+func (h Handler) RequestEmailChange(ctx *gin.Context) {
+	// 1.- Guard against missing dependencies to surface clear errors.
+	if h.emailChanges == nil {
+		respond.Error(ctx, http.StatusServiceUnavailable, "email change unavailable", map[string]interface{}{"reason": "not configured"})
+		return
+	}
+
+	// 2.- Only interactive sessions may change the sign-in address.
+	principal, ok := internalauth.PrincipalFromContext(ctx)
+	if !ok {
+		respond.Error(ctx, http.StatusUnauthorized, "authentication required", map[string]interface{}{"reason": "principal missing"})
+		return
+	}
+	if principal.TokenID != "" {
+		respond.Error(ctx, http.StatusForbidden, "access tokens cannot change the email address", map[string]interface{}{"reason": "interactive session required"})
+		return
+	}
+
+	// 3.- Bind and validate the payload.
+	var req changeEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Error(ctx, http.StatusBadRequest, "invalid request payload", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
+	if !h.validatePayload(ctx, req) {
+		return
+	}
+
+	// 4.- Re-authenticate so a borrowed session cannot redirect the account.
+	user, err := h.users.FindByID(ctx.Request.Context(), principal.Subject)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			respond.Error(ctx, http.StatusNotFound, "user not found", nil)
+			return
+		}
+		respond.Error(ctx, http.StatusInternalServerError, "failed to load user", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	if !ConfirmIdentity(ctx, principal, user, req.Password, h.auth.CheckPassword) {
+		return
+	}
+
+	// 5.- Send both links and translate domain errors.
+	if err := h.emailChanges.Request(ctx.Request.Context(), user, req.Email); err != nil {
+		switch {
+		case errors.Is(err, ErrEmailTaken):
+			respond.Error(ctx, http.StatusConflict, "email already in use", map[string]interface{}{"fields": map[string][]validation.FieldError{
+				"email": []validation.FieldError{{Field: "email", Rule: "unique", Message: "email is already registered"}},
+			}})
+		case errors.Is(err, ErrSameEmail):
+			respond.Error(ctx, http.StatusUnprocessableEntity, "email unchanged", map[string]interface{}{"fields": map[string][]validation.FieldError{
+				"email": []validation.FieldError{{Field: "email", Rule: "different", Message: "email must differ from the current address"}},
+			}})
+		default:
+			respond.Error(ctx, http.StatusInternalServerError, "failed to request email change", map[string]interface{}{"details": err.Error()})
+		}
+		return
+	}
+
+	// 6.- Nothing changes until the new address confirms.
+	respond.Success(ctx, http.StatusAccepted, map[string]any{"status": "Confirm the change from the link sent to the new address."}, nil)
+}
+
+// 1.- ConfirmEmailChange applies the pending address and revokes every session.
+func (h Handler) ConfirmEmailChange(ctx *gin.Context) {
+	// 1.- Guard against missing dependencies to surface clear errors.
+	if h.emailChanges == nil {
+		respond.Error(ctx, http.StatusServiceUnavailable, "email change unavailable", map[string]interface{}{"reason": "not configured"})
+		return
+	}
+
+	// 2.- Bind and validate the payload.
+	req, ok := h.bindEmailChangeToken(ctx)
+	if !ok {
+		return
+	}
+
+	// 3.- Redeem the token and translate domain errors.
+	user, err := h.emailChanges.Confirm(ctx.Request.Context(), req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrTokenNotFound), errors.Is(err, ErrUserNotFound):
+			respondInvalidEmailChangeToken(ctx, "confirmation link is invalid, expired, or cancelled")
+		case errors.Is(err, ErrEmailTaken):
+			respond.Error(ctx, http.StatusConflict, "email already in use", nil)
+		default:
+			respond.Error(ctx, http.StatusInternalServerError, "failed to confirm email change", map[string]interface{}{"details": err.Error()})
+		}
+		return
+	}
+
+	// 4.- Clients must log in again because every session was revoked.
+	respond.Success(ctx, http.StatusOK, map[string]any{"email": user.Email, "sessions_revoked": true}, nil)
+}
+
+// 1.- CancelEmailChange lets the current address stop a pending change.
+func (h Handler) CancelEmailChange(ctx *gin.Context) {
+	// 1.- Guard against missing dependencies to surface clear errors.
+	if h.emailChanges == nil {
+		respond.Error(ctx, http.StatusServiceUnavailable, "email change unavailable", map[string]interface{}{"reason": "not configured"})
+		return
+	}
+
+	// 2.- Bind and validate the payload.
+	req, ok := h.bindEmailChangeToken(ctx)
+	if !ok {
+		return
+	}
+
+	// 3.- Redeem the cancel token.
+	if err := h.emailChanges.Cancel(ctx.Request.Context(), req.Token); err != nil {
+		if errors.Is(err, ErrTokenNotFound) || errors.Is(err, ErrUserNotFound) {
+			respondInvalidEmailChangeToken(ctx, "cancel link is invalid, expired, or already used")
+			return
+		}
+		respond.Error(ctx, http.StatusInternalServerError, "failed to cancel email change", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	respond.Success(ctx, http.StatusOK, map[string]any{"cancelled": true}, nil)
+}
+
+// 1.- bindEmailChangeToken decodes and validates a link token payload.
+func (h Handler) bindEmailChangeToken(ctx *gin.Context) (emailChangeTokenRequest, bool) {
+	var req emailChangeTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Error(ctx, http.StatusBadRequest, "invalid request payload", map[string]interface{}{"details": err.Error()})
+		return emailChangeTokenRequest{}, false
+	}
+	req.Token = strings.TrimSpace(req.Token)
+	if !h.validatePayload(ctx, req) {
+		return emailChangeTokenRequest{}, false
+	}
+	return req, true
+}
+
+// 1.- respondInvalidEmailChangeToken reports a dead link as a token field error.
+func respondInvalidEmailChangeToken(ctx *gin.Context, message string) {
+	respond.Error(ctx, http.StatusBadRequest, "invalid email change token", map[string]interface{}{"fields": map[string][]validation.FieldError{
+		"token": []validation.FieldError{{Field: "token", Rule: "valid", Message: message}},
+	}})
+}
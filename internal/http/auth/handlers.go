@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"errors"
+	"io"
 	"net/http"
 	"strings"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"github.com/google/uuid"
 
 	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/auth/passwordpolicy"
 	"github.com/example/Yamato-Go-Gin-API/internal/http/respond"
 	"github.com/example/Yamato-Go-Gin-API/internal/http/validation"
 )
@@ -24,6 +26,9 @@ var ErrInvalidVerification = errors.New("http/auth: invalid verification link")
 // 1.- ErrVerificationThrottled signals that verification resends are temporarily rate limited.
 var ErrVerificationThrottled = errors.New("http/auth: verification request throttled")
 
+// 1.- ErrEmailTaken is returned when another account already uses the requested address.
+var ErrEmailTaken = errors.New("http/auth: email already in use")
+
 // 1.- User represents the minimal information stored for authentication flows.
 type User struct {
 	ID           string `json:"id"`
@@ -40,6 +45,10 @@ type UserStore interface {
 	FindByEmail(ctx context.Context, email string) (User, error)
 	// 4.- FindByID retrieves a user by identifier when loading principals.
 	FindByID(ctx context.Context, id string) (User, error)
+	// 5.- UpdatePassword replaces the stored password hash or returns ErrUserNotFound.
+	UpdatePassword(ctx context.Context, id string, passwordHash string) error
+	// 6.- UpdateEmail replaces the address, returning ErrUserNotFound or ErrEmailTaken.
+	UpdateEmail(ctx context.Context, id string, email string) error
 }
 
 // 1.- AuthService defines the subset of the core auth service used by handlers.
@@ -51,21 +60,116 @@ type AuthService interface {
 	Logout(ctx context.Context, refreshToken string, accessToken string) error
 }
 
+// 1.- PasswordUpgrader is optionally implemented by the auth service to migrate outdated password hashes.
+type PasswordUpgrader interface {
+	PasswordNeedsRehash(hash string) bool
+	EqualizePasswordTiming(password string)
+}
+
 // 1.- Handler wires HTTP requests to the auth service, user store, and validator dependencies.
 type Handler struct {
-	auth         AuthService
-	users        UserStore
-	verification EmailVerificationService
-	validator    *validation.Validator
+	auth               AuthService
+	users              UserStore
+	verification       EmailVerificationService
+	resets             PasswordResetter
+	mfa                MFAManager
+	mfaTokens          MFAPendingTokens
+	sessions           SessionManager
+	lockout            LoginGuard
+	sso                SSOManager
+	accessTokens       AccessTokenManager
+	impersonator       Impersonator
+	impersonationAudit ImpersonationAuditor
+	impersonationTTL   time.Duration
+	passkeys           PasskeyManager
+	magicLinks         MagicLinker
+	emailChanges       EmailChanger
+	cookies            *CookieSessionConfig
+	passwordPolicy     PasswordPolicy
+	deviceTokens       DeviceTokenStore
+	deviceIdleTTL      time.Duration
+	phoneLinks         PhoneLinkStore
+	validator          *validation.Validator
+}
+
+// 1.- HandlerOption customizes optional Handler dependencies.
+type HandlerOption func(*Handler)
+
+// 1.- WithPasswordResets enables the forgot/reset password endpoints.
+func WithPasswordResets(resets PasswordResetter) HandlerOption {
+	return func(h *Handler) {
+		h.resets = resets
+	}
+}
+
+// 1.- WithMFA enables TOTP enrollment and the two-step login exchange.
+func WithMFA(mfa MFAManager, tokens MFAPendingTokens) HandlerOption {
+	return func(h *Handler) {
+		h.mfa = mfa
+		h.mfaTokens = tokens
+	}
+}
+
+// 1.- WithSessions enables the session listing and revocation endpoints.
+func WithSessions(sessions SessionManager) HandlerOption {
+	return func(h *Handler) {
+		h.sessions = sessions
+	}
+}
+
+// 1.- WithLoginGuard enables failed-login counting, progressive lockout, and the admin unlock endpoint.
+func WithLoginGuard(lockout LoginGuard) HandlerOption {
+	return func(h *Handler) {
+		h.lockout = lockout
+	}
+}
+
+// 1.- WithSSO enables OIDC single sign-on through the registered identity providers.
+func WithSSO(sso SSOManager) HandlerOption {
+	return func(h *Handler) {
+		h.sso = sso
+	}
+}
+
+// 1.- WithAccessTokens enables the personal access token endpoints.
+func WithAccessTokens(tokens AccessTokenManager) HandlerOption {
+	return func(h *Handler) {
+		h.accessTokens = tokens
+	}
+}
+
+// 1.- WithPasskeys enables WebAuthn passkey registration and login.
+func WithPasskeys(passkeys PasskeyManager) HandlerOption {
+	return func(h *Handler) {
+		h.passkeys = passkeys
+	}
+}
+
+// 1.- WithMagicLinks enables passwordless login through e-mailed links.
+func WithMagicLinks(magicLinks MagicLinker) HandlerOption {
+	return func(h *Handler) {
+		h.magicLinks = magicLinks
+	}
+}
+
+// 1.- WithEmailChanges enables the double-confirmation e-mail address change.
+func WithEmailChanges(emailChanges EmailChanger) HandlerOption {
+	return func(h *Handler) {
+		h.emailChanges = emailChanges
+	}
 }
 
 // 1.- NewHandler constructs a Handler with the supplied dependencies and shared validator.
-func NewHandler(auth AuthService, users UserStore, verification EmailVerificationService) Handler {
+func NewHandler(auth AuthService, users UserStore, verification EmailVerificationService, opts ...HandlerOption) Handler {
 	validator, err := validation.New()
 	if err != nil {
 		panic(err)
 	}
-	return Handler{auth: auth, users: users, verification: verification, validator: validator}
+	handler := Handler{auth: auth, users: users, verification: verification, validator: validator}
+	for _, opt := range opts {
+		opt(&handler)
+	}
+	return handler
 }
 
 // 1.- EmailVerificationService defines verification and resend workflows used by handlers.
@@ -101,8 +205,8 @@ type logoutRequest struct {
 
 // 1.- tokenEnvelope exposes tokens alongside expiration metadata.
 type tokenEnvelope struct {
-	AccessToken      string    `json:"access_token"`
-	RefreshToken     string    `json:"refresh_token"`
+	AccessToken      string    `json:"access_token,omitempty"`
+	RefreshToken     string    `json:"refresh_token,omitempty"`
 	AccessExpiresAt  time.Time `json:"access_expires_at"`
 	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
 }
@@ -128,6 +232,8 @@ type principalResponse struct {
 	Name        string   `json:"name"`
 	Roles       []string `json:"roles"`
 	Permissions []string `json:"permissions"`
+	// 2.- ImpersonatedBy names the administrator when the request used an impersonation token.
+	ImpersonatedBy string `json:"impersonated_by,omitempty"`
 }
 
 // 1.- pairToEnvelope maps TokenPair instances to the JSON structure returned to clients.
@@ -178,7 +284,12 @@ func (h Handler) Register(ctx *gin.Context) {
 		return
 	}
 
-	// 4.- Derive a default display name when none is supplied.
+	// 4.- Reject passwords the policy forbids before touching the user store.
+	if !h.enforcePasswordPolicy(ctx, req.Password, passwordpolicy.Identity{Email: req.Email, Name: req.Name}) {
+		return
+	}
+
+	// 5.- Derive a default display name when none is supplied.
 	if req.Name == "" {
 		if at := strings.Index(req.Email, "@"); at > 0 {
 			local := strings.ReplaceAll(req.Email[:at], ".", " ")
@@ -197,40 +308,48 @@ func (h Handler) Register(ctx *gin.Context) {
 		}
 	}
 
-	// 5.- Guard against duplicate registrations for the same email address.
+	// 6.- Guard against duplicate registrations for the same email address.
 	if _, err := h.users.FindByEmail(ctx.Request.Context(), req.Email); err == nil {
-		respond.Error(ctx, http.StatusConflict, "account already exists", map[string]interface{}{"fields": map[string][]validation.FieldError{
-			"email": []validation.FieldError{{Field: "email", Rule: "unique", Message: "email already registered"}},
-		}})
+		respondEmailRegistered(ctx)
 		return
 	} else if !errors.Is(err, ErrUserNotFound) {
 		respond.Error(ctx, http.StatusInternalServerError, "failed to query users", map[string]interface{}{"details": err.Error()})
 		return
 	}
 
-	// 6.- Hash the provided password prior to persistence.
+	// 7.- Hash the provided password prior to persistence.
 	hashed, err := h.auth.HashPassword(req.Password)
 	if err != nil {
 		respond.Error(ctx, http.StatusInternalServerError, "failed to hash password", map[string]interface{}{"details": err.Error()})
 		return
 	}
 
-	// 7.- Persist the new user record via the store abstraction.
+	// 8.- Persist the new user record via the store abstraction.
 	user := User{ID: uuid.NewString(), Email: req.Email, Name: req.Name, PasswordHash: hashed}
 	created, err := h.users.Create(ctx.Request.Context(), user)
+	if errors.Is(err, ErrEmailTaken) {
+		// 8.1.- Accounts in their deletion grace period still hold the address.
+		respondEmailRegistered(ctx)
+		return
+	}
 	if err != nil {
 		respond.Error(ctx, http.StatusInternalServerError, "failed to create user", map[string]interface{}{"details": err.Error()})
 		return
 	}
 
-	// 8.- Issue a fresh token pair for the registered user.
-	pair, err := h.auth.Login(ctx.Request.Context(), created.ID)
+	// 9.- Issue a fresh token pair for the registered user, as cookies when the browser asked for them.
+	pair, err := h.auth.Login(clientContext(ctx), created.ID)
+	if err != nil {
+		respond.Error(ctx, http.StatusInternalServerError, "failed to issue tokens", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	tokens, err := h.tokensFor(ctx, pair)
 	if err != nil {
 		respond.Error(ctx, http.StatusInternalServerError, "failed to issue tokens", map[string]interface{}{"details": err.Error()})
 		return
 	}
 
-	// 9.- Compose a verification notice mirroring Laravel's onboarding flow.
+	// 10.- Compose a verification notice mirroring Laravel's onboarding flow.
 	notice := "Please verify your email address for {email}."
 	verificationHash := ""
 	if h.verification != nil {
@@ -238,15 +357,22 @@ func (h Handler) Register(ctx *gin.Context) {
 		notice = notice + " Use the link in your inbox to complete setup."
 	}
 
-	// 10.- Return the success envelope with the new user, tokens, and verification metadata.
+	// 11.- Return the success envelope with the new user, tokens, and verification metadata.
 	respond.Success(ctx, http.StatusCreated, registerResponse{
 		User:             User{ID: created.ID, Email: created.Email, Name: created.Name},
-		Tokens:           pairToEnvelope(pair),
+		Tokens:           tokens,
 		Notice:           notice,
 		VerificationHash: verificationHash,
 	}, nil)
 }
 
+// 1.- respondEmailRegistered answers 409 with the field error used for duplicate registrations.
+func respondEmailRegistered(ctx *gin.Context) {
+	respond.Error(ctx, http.StatusConflict, "account already exists", map[string]interface{}{"fields": map[string][]validation.FieldError{
+		"email": []validation.FieldError{{Field: "email", Rule: "unique", Message: "email already registered"}},
+	}})
+}
+
 // 1.- Login authenticates an existing user and returns a rotated token pair.
 func (h Handler) Login(ctx *gin.Context) {
 	// 1.- Bind the request payload and validate structure.
@@ -265,55 +391,121 @@ func (h Handler) Login(ctx *gin.Context) {
 		return
 	}
 
-	// 4.- Retrieve the user from storage.
+	// 4.- Refuse attempts while the account or client IP is locked out.
+	if h.loginLocked(ctx, req.Email) {
+		return
+	}
+
+	// 5.- Retrieve the user and compare passwords, failing identically for unknown e-mails.
 	user, err := h.users.FindByEmail(ctx.Request.Context(), req.Email)
 	if err != nil {
 		if errors.Is(err, ErrUserNotFound) {
-			respond.Error(ctx, http.StatusUnauthorized, "invalid credentials", map[string]interface{}{"fields": map[string][]validation.FieldError{
-				"email": []validation.FieldError{{Field: "email", Rule: "credentials", Message: "email not found"}},
-			}})
+			h.equalizePasswordTiming(req.Password)
+			h.loginFailed(ctx, req.Email)
 			return
 		}
 		respond.Error(ctx, http.StatusInternalServerError, "failed to query users", map[string]interface{}{"details": err.Error()})
 		return
 	}
-
-	// 5.- Compare the provided password with the stored hash.
 	if err := h.auth.CheckPassword(user.PasswordHash, req.Password); err != nil {
-		respond.Error(ctx, http.StatusUnauthorized, "invalid credentials", map[string]interface{}{"fields": map[string][]validation.FieldError{
-			"password": []validation.FieldError{{Field: "password", Rule: "credentials", Message: "password mismatch"}},
-		}})
+		h.loginFailed(ctx, req.Email)
 		return
 	}
 
-	// 6.- Issue a new token pair for the authenticated subject.
-	pair, err := h.auth.Login(ctx.Request.Context(), user.ID)
+	// 6.- Move the stored hash to the current algorithm and parameters while the plaintext is at hand.
+	h.upgradePassword(ctx, user, req.Password)
+
+	// 7.- Finish with the MFA challenge or the token pair; lockout counters reset only once every factor passed.
+	h.completeLogin(ctx, user)
+}
+
+// 1.- equalizePasswordTiming checks the password against a dummy hash made with the active hasher.
+func (h Handler) equalizePasswordTiming(password string) {
+	if upgrader, ok := h.auth.(PasswordUpgrader); ok {
+		upgrader.EqualizePasswordTiming(password)
+		return
+	}
+	_ = h.auth.CheckPassword(timingEqualizerHash, password)
+}
+
+// 1.- upgradePassword rehashes an outdated hash after a successful login; failures leave the old hash usable.
+func (h Handler) upgradePassword(ctx *gin.Context, user User, password string) {
+	upgrader, ok := h.auth.(PasswordUpgrader)
+	if !ok || !upgrader.PasswordNeedsRehash(user.PasswordHash) {
+		return
+	}
+	hashed, err := h.auth.HashPassword(password)
+	if err != nil {
+		return
+	}
+	_ = h.users.UpdatePassword(ctx.Request.Context(), user.ID, hashed)
+}
+
+// 1.- completeLogin issues the MFA challenge or token pair once a primary factor has been verified.
+func (h Handler) completeLogin(ctx *gin.Context, user User) {
+	// 1.- Defer token issuance to the second step when the account enabled TOTP.
+	if h.mfa != nil && h.mfaTokens != nil {
+		enabled, err := h.mfa.Enabled(ctx.Request.Context(), user.ID)
+		if err != nil {
+			respond.Error(ctx, http.StatusInternalServerError, "failed to load mfa state", map[string]interface{}{"details": err.Error()})
+			return
+		}
+		if enabled {
+			token, expiresAt, err := h.mfaTokens.IssueMFAPending(ctx.Request.Context(), user.ID)
+			if err != nil {
+				respond.Error(ctx, http.StatusInternalServerError, "failed to issue mfa token", map[string]interface{}{"details": err.Error()})
+				return
+			}
+			respond.Success(ctx, http.StatusOK, mfaChallengeResponse{MFARequired: true, MFAToken: token, MFAExpiresAt: expiresAt}, nil)
+			return
+		}
+	}
+
+	// 2.- Otherwise the primary factor suffices and clears earlier failures.
+	if !h.loginSucceeded(ctx, user) {
+		return
+	}
+	h.issueLogin(ctx, user)
+}
+
+// 1.- issueLogin starts a session for a fully authenticated user and returns the login payload.
+func (h Handler) issueLogin(ctx *gin.Context, user User) {
+	// 1.- Issue a new token pair for the authenticated subject.
+	pair, err := h.auth.Login(clientContext(ctx), user.ID)
 	if err != nil {
 		respond.Error(ctx, http.StatusInternalServerError, "failed to issue tokens", map[string]interface{}{"details": err.Error()})
 		return
 	}
 
-	// 7.- Return the authenticated user and token envelope.
-	respond.Success(ctx, http.StatusOK, loginResponse{User: User{ID: user.ID, Email: user.Email, Name: user.Name}, Tokens: pairToEnvelope(pair)}, nil)
+	// 2.- Return the authenticated user and token envelope, or set session cookies for browser clients.
+	tokens, err := h.tokensFor(ctx, pair)
+	if err != nil {
+		respond.Error(ctx, http.StatusInternalServerError, "failed to issue tokens", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	respond.Success(ctx, http.StatusOK, loginResponse{User: User{ID: user.ID, Email: user.Email, Name: user.Name}, Tokens: tokens}, nil)
 }
 
 // 1.- Refresh rotates refresh tokens and returns a new token pair.
 func (h Handler) Refresh(ctx *gin.Context) {
 	// 1.- Bind the refresh token payload.
 	var req refreshRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
+	if err := ctx.ShouldBindJSON(&req); err != nil && !(h.cookieMode(ctx) && errors.Is(err, io.EOF)) {
 		respond.Error(ctx, http.StatusBadRequest, "invalid request payload", map[string]interface{}{"details": err.Error()})
 		return
 	}
 
-	// 2.- Ensure the refresh token is present before invoking the service.
+	// 2.- Ensure the refresh token is present, falling back to the cookie for browser sessions.
 	req.RefreshToken = strings.TrimSpace(req.RefreshToken)
+	if req.RefreshToken == "" && h.cookies != nil {
+		req.RefreshToken = h.sessionCookie(ctx, h.cookies.RefreshCookie)
+	}
 	if !h.validatePayload(ctx, req) {
 		return
 	}
 
 	// 3.- Delegate rotation to the auth service while translating domain errors.
-	pair, err := h.auth.Refresh(ctx.Request.Context(), req.RefreshToken)
+	pair, err := h.auth.Refresh(clientContext(ctx), req.RefreshToken)
 	if err != nil {
 		status := http.StatusInternalServerError
 		switch {
@@ -327,21 +519,34 @@ func (h Handler) Refresh(ctx *gin.Context) {
 	}
 
 	// 4.- Return the rotated token pair to the client.
-	respond.Success(ctx, http.StatusOK, pairToEnvelope(pair), nil)
+	tokens, err := h.tokensFor(ctx, pair)
+	if err != nil {
+		respond.Error(ctx, http.StatusInternalServerError, "failed to issue tokens", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	respond.Success(ctx, http.StatusOK, tokens, nil)
 }
 
 // 1.- Logout revokes the supplied tokens to terminate the session.
 func (h Handler) Logout(ctx *gin.Context) {
 	// 1.- Bind the refresh and access tokens from the payload.
 	var req logoutRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
+	if err := ctx.ShouldBindJSON(&req); err != nil && !(h.cookieMode(ctx) && errors.Is(err, io.EOF)) {
 		respond.Error(ctx, http.StatusBadRequest, "invalid request payload", map[string]interface{}{"details": err.Error()})
 		return
 	}
 
-	// 2.- Validate that both tokens are present.
+	// 2.- Validate that both tokens are present, reading the cookies for browser sessions.
 	req.RefreshToken = strings.TrimSpace(req.RefreshToken)
 	req.AccessToken = strings.TrimSpace(req.AccessToken)
+	if h.cookies != nil {
+		if req.RefreshToken == "" {
+			req.RefreshToken = h.sessionCookie(ctx, h.cookies.RefreshCookie)
+		}
+		if req.AccessToken == "" {
+			req.AccessToken = h.sessionCookie(ctx, h.cookies.AccessCookie)
+		}
+	}
 	if !h.validatePayload(ctx, req) {
 		return
 	}
@@ -356,7 +561,8 @@ func (h Handler) Logout(ctx *gin.Context) {
 		return
 	}
 
-	// 4.- Indicate success with an empty data payload.
+	// 4.- Indicate success with an empty data payload and drop any session cookies.
+	h.clearSessionCookies(ctx)
 	respond.Success(ctx, http.StatusOK, map[string]any{"revoked": true}, nil)
 }
 
@@ -384,11 +590,12 @@ func (h Handler) CurrentUser(ctx *gin.Context) {
 
 	// 3.- Return the principal details as a success envelope.
 	respond.Success(ctx, http.StatusOK, principalResponse{
-		Subject:     principal.Subject,
-		Email:       user.Email,
-		Name:        user.Name,
-		Roles:       principal.Roles,
-		Permissions: principal.Permissions,
+		Subject:        principal.Subject,
+		Email:          user.Email,
+		Name:           user.Name,
+		Roles:          principal.Roles,
+		Permissions:    principal.Permissions,
+		ImpersonatedBy: principal.Actor,
 	}, nil)
 }
 
@@ -3,9 +3,9 @@ package auth_test
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
@@ -16,15 +16,25 @@ import (
 	"github.com/stretchr/testify/require"
 
 	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/authorization"
 	"github.com/example/Yamato-Go-Gin-API/internal/config"
 	authpkg "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/auth/oidc"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/auth/oidc/oidctest"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/auth/passwordpolicy"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/auth/webauthn"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/auth/webauthn/webauthntest"
 	"github.com/example/Yamato-Go-Gin-API/internal/middleware"
+	memoryplatform "github.com/example/Yamato-Go-Gin-API/internal/platform/memory"
+	"github.com/example/Yamato-Go-Gin-API/internal/queue"
 )
 
 // 1.- memoryUserStore stores users in memory for deterministic handler tests.
 type memoryUserStore struct {
 	users   map[string]authpkg.User
 	byEmail map[string]string
+	// 2.- deleted hides addresses from lookups while Create still treats them as taken, like soft-deleted rows.
+	deleted map[string]bool
 }
 
 // 1.- stubVerificationService records verification and resend calls for assertions.
@@ -54,13 +64,13 @@ func (s *stubVerificationService) HashForUser(userID string) string {
 
 // 1.- newMemoryUserStore constructs a thread-safe in-memory repository.
 func newMemoryUserStore() *memoryUserStore {
-	return &memoryUserStore{users: map[string]authpkg.User{}, byEmail: map[string]string{}}
+	return &memoryUserStore{users: map[string]authpkg.User{}, byEmail: map[string]string{}, deleted: map[string]bool{}}
 }
 
 // 1.- Create inserts a new user into the in-memory maps.
 func (m *memoryUserStore) Create(_ context.Context, user authpkg.User) (authpkg.User, error) {
 	if _, exists := m.byEmail[user.Email]; exists {
-		return authpkg.User{}, errors.New("duplicate email")
+		return authpkg.User{}, authpkg.ErrEmailTaken
 	}
 	copied := user
 	m.users[user.ID] = copied
@@ -71,7 +81,7 @@ func (m *memoryUserStore) Create(_ context.Context, user authpkg.User) (authpkg.
 // 1.- FindByEmail returns the stored user or authpkg.ErrUserNotFound.
 func (m *memoryUserStore) FindByEmail(_ context.Context, email string) (authpkg.User, error) {
 	id, ok := m.byEmail[email]
-	if !ok {
+	if !ok || m.deleted[email] {
 		return authpkg.User{}, authpkg.ErrUserNotFound
 	}
 	return m.users[id], nil
@@ -86,6 +96,33 @@ func (m *memoryUserStore) FindByID(_ context.Context, id string) (authpkg.User,
 	return user, nil
 }
 
+// 1.- UpdatePassword replaces the stored hash for the identified user.
+func (m *memoryUserStore) UpdatePassword(_ context.Context, id string, passwordHash string) error {
+	user, ok := m.users[id]
+	if !ok {
+		return authpkg.ErrUserNotFound
+	}
+	user.PasswordHash = passwordHash
+	m.users[id] = user
+	return nil
+}
+
+// 1.- UpdateEmail moves the user to a new address unless another account owns it.
+func (m *memoryUserStore) UpdateEmail(_ context.Context, id string, email string) error {
+	user, ok := m.users[id]
+	if !ok {
+		return authpkg.ErrUserNotFound
+	}
+	if owner, exists := m.byEmail[email]; exists && owner != id {
+		return authpkg.ErrEmailTaken
+	}
+	delete(m.byEmail, user.Email)
+	user.Email = email
+	m.users[id] = user
+	m.byEmail[email] = id
+	return nil
+}
+
 // 1.- setupHandler constructs a handler with real token service dependencies.
 func setupHandler(t *testing.T) (authpkg.Handler, *memoryUserStore, *stubVerificationService, func()) {
 	gin.SetMode(gin.TestMode)
@@ -160,6 +197,14 @@ type principalPayload struct {
 	Permissions []string `json:"permissions"`
 }
 
+// 1.- grantsBySubject resolves access per subject so tests can grant or revoke permissions between requests.
+type grantsBySubject map[string]internalauth.Access
+
+// 1.- LoadAccess returns the grants stored for the subject, or none.
+func (g grantsBySubject) LoadAccess(_ context.Context, subject string) (internalauth.Access, error) {
+	return g[subject], nil
+}
+
 // 1.- newTestEngine returns a Gin engine configured with the shared error middleware.
 func newTestEngine() *gin.Engine {
 	engine := gin.New()
@@ -277,6 +322,25 @@ func TestRegisterValidationErrors(t *testing.T) {
 	require.Contains(t, fields, "password")
 }
 
+// 1.- TestRegisterRejectsEmailInGracePeriod answers 409 when the address still belongs to a soft-deleted account.
+func TestRegisterRejectsEmailInGracePeriod(t *testing.T) {
+	handler, store, _, cleanup := setupHandler(t)
+	defer cleanup()
+
+	engine := newTestEngine()
+	engine.POST("/v1/auth/register", handler.Register)
+
+	body := `{"email":"leaving@example.com","password":"secret"}`
+	require.Equal(t, http.StatusCreated, performRequest(engine, http.MethodPost, "/v1/auth/register", body, "application/json").Code)
+	store.deleted["leaving@example.com"] = true
+
+	recorder := performRequest(engine, http.MethodPost, "/v1/auth/register", body, "application/json")
+	require.Equal(t, http.StatusConflict, recorder.Code)
+	var payload errorPayload
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &payload))
+	require.Contains(t, payload.Errors["fields"], "email")
+}
+
 // 1.- TestLoginValidationErrors ensures invalid credentials trigger field errors before lookup.
 func TestLoginValidationErrors(t *testing.T) {
 	handler, _, _, cleanup := setupHandler(t)
@@ -491,3 +555,1441 @@ func TestResendVerificationSuccess(t *testing.T) {
 	require.Equal(t, "success", body.Status)
 	require.True(t, body.Data["resent"])
 }
+
+// 1.- recordingQueue captures enqueued jobs instead of pushing them to Redis.
+type recordingQueue struct {
+	jobs []queue.Message
+}
+
+// 1.- Enqueue stores the job for later assertions.
+func (q *recordingQueue) Enqueue(_ context.Context, jobName string, payload map[string]any) (queue.Message, error) {
+	message := queue.Message{Job: jobName, Payload: payload}
+	q.jobs = append(q.jobs, message)
+	return message, nil
+}
+
+// 1.- TestPasswordResetFlow covers forgot, reset, session revocation, and replay protection.
+func TestPasswordResetFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mini := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mini.Addr()})
+	defer client.Close()
+
+	svc, err := internalauth.NewService(config.JWTConfig{Secret: "test-secret", Issuer: "yamato-test"}, client)
+	require.NoError(t, err)
+
+	store := newMemoryUserStore()
+	mail := &recordingQueue{}
+	resets := authpkg.NewPasswordResetService(store, memoryplatform.NewOneTimeTokenStore(), mail, svc, authpkg.PasswordResetConfig{
+		Secret:   "reset-secret",
+		ResetURL: "https://app.example.com/reset",
+	})
+	handler := authpkg.NewHandler(svc, store, nil, authpkg.WithPasswordResets(resets))
+
+	engine := newTestEngine()
+	engine.POST("/v1/auth/register", handler.Register)
+	engine.POST("/v1/auth/login", handler.Login)
+	engine.POST("/v1/auth/refresh", handler.Refresh)
+	engine.POST("/v1/auth/password/forgot", handler.ForgotPassword)
+	engine.POST("/v1/auth/password/reset", handler.ResetPassword)
+
+	// 2.- Register an account and keep its session tokens.
+	registerRecorder := performRequest(engine, http.MethodPost, "/v1/auth/register", `{"email":"reset@example.com","password":"old-secret"}`, "application/json")
+	require.Equal(t, http.StatusCreated, registerRecorder.Code)
+	var registerBody successPayload[registerPayload]
+	require.NoError(t, json.Unmarshal(registerRecorder.Body.Bytes(), &registerBody))
+
+	// 3.- Unknown addresses receive the same answer without any e-mail being queued.
+	unknown := performRequest(engine, http.MethodPost, "/v1/auth/password/forgot", `{"email":"ghost@example.com"}`, "application/json")
+	require.Equal(t, http.StatusAccepted, unknown.Code)
+	require.Empty(t, mail.jobs)
+
+	// 4.- Request a reset for the real account and extract the token from the queued e-mail.
+	forgot := performRequest(engine, http.MethodPost, "/v1/auth/password/forgot", `{"email":"reset@example.com"}`, "application/json")
+	require.Equal(t, http.StatusAccepted, forgot.Code)
+	require.Len(t, mail.jobs, 1)
+	require.Equal(t, queue.EmailSendJobName, mail.jobs[0].Job)
+	require.Equal(t, "reset@example.com", mail.jobs[0].Payload["to"])
+	body, _ := mail.jobs[0].Payload["body"].(string)
+	start := strings.Index(body, "https://app.example.com/reset?token=")
+	require.GreaterOrEqual(t, start, 0)
+	token := strings.Fields(body[start+len("https://app.example.com/reset?token="):])[0]
+	require.NotEmpty(t, token)
+
+	// 5.- Redeem the token and ensure the previous session was revoked.
+	reset := performRequest(engine, http.MethodPost, "/v1/auth/password/reset", `{"token":"`+token+`","password":"new-secret"}`, "application/json")
+	require.Equal(t, http.StatusOK, reset.Code)
+
+	stale := performRequest(engine, http.MethodPost, "/v1/auth/refresh", `{"refresh_token":"`+registerBody.Data.Tokens.RefreshToken+`"}`, "application/json")
+	require.Equal(t, http.StatusUnauthorized, stale.Code)
+
+	// 6.- Only the new password authenticates.
+	oldLogin := performRequest(engine, http.MethodPost, "/v1/auth/login", `{"email":"reset@example.com","password":"old-secret"}`, "application/json")
+	require.Equal(t, http.StatusUnauthorized, oldLogin.Code)
+	newLogin := performRequest(engine, http.MethodPost, "/v1/auth/login", `{"email":"reset@example.com","password":"new-secret"}`, "application/json")
+	require.Equal(t, http.StatusOK, newLogin.Code)
+
+	// 7.- Replaying the token must fail because it is single-use.
+	replay := performRequest(engine, http.MethodPost, "/v1/auth/password/reset", `{"token":"`+token+`","password":"another-secret"}`, "application/json")
+	require.Equal(t, http.StatusBadRequest, replay.Code)
+	var replayBody errorPayload
+	require.NoError(t, json.Unmarshal(replay.Body.Bytes(), &replayBody))
+	require.Contains(t, replayBody.Errors, "fields")
+}
+
+// 1.- TestPasswordResetRejectsSupersededAndExpiredTokens ensures only the newest live token is accepted.
+func TestPasswordResetRejectsSupersededAndExpiredTokens(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryUserStore()
+	_, err := store.Create(ctx, authpkg.User{ID: "user-1", Email: "user@example.com", PasswordHash: "x"})
+	require.NoError(t, err)
+
+	tokens := memoryplatform.NewOneTimeTokenStore()
+	for _, hash := range []string{"first", "second"} {
+		require.NoError(t, tokens.Save(ctx, authpkg.OneTimeToken{Purpose: authpkg.PurposePasswordReset, UserID: "user-1", TokenHash: authpkg.HashOneTimeToken(hash, "s"), ExpiresAt: time.Now().Add(time.Minute)}))
+	}
+
+	_, err = tokens.Consume(ctx, authpkg.PurposePasswordReset, authpkg.HashOneTimeToken("first", "s"), time.Now())
+	require.ErrorIs(t, err, authpkg.ErrTokenNotFound)
+	_, err = tokens.Consume(ctx, authpkg.PurposePasswordReset, authpkg.HashOneTimeToken("second", "s"), time.Now().Add(2*time.Minute))
+	require.ErrorIs(t, err, authpkg.ErrTokenNotFound)
+}
+
+// 1.- TestMFAEnrollmentAndTwoStepLogin covers enrollment, confirmation, and the pending token exchange.
+func TestMFAEnrollmentAndTwoStepLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mini := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mini.Addr()})
+	defer client.Close()
+
+	svc, err := internalauth.NewService(config.JWTConfig{Secret: "test-secret", Issuer: "yamato-test"}, client)
+	require.NoError(t, err)
+
+	store := newMemoryUserStore()
+	mfaSvc := authpkg.NewMFAService(store, memoryplatform.NewMFAStore(), authpkg.MFAConfig{Issuer: "Yamato", Secret: "mfa-secret"})
+	handler := authpkg.NewHandler(svc, store, nil, authpkg.WithMFA(mfaSvc, svc))
+
+	engine := newTestEngine()
+	engine.POST("/v1/auth/register", handler.Register)
+	engine.POST("/v1/auth/login", handler.Login)
+	engine.POST("/v1/auth/mfa/verify", handler.VerifyMFA)
+	userGroup := engine.Group("/v1/user", middleware.Authentication(svc, store))
+	userGroup.POST("/mfa/enroll", handler.EnrollMFA)
+	userGroup.POST("/mfa/confirm", handler.ConfirmMFA)
+
+	authorized := func(method string, path string, body string, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// 2.- Register and enroll, receiving an otpauth URI.
+	registerRecorder := performRequest(engine, http.MethodPost, "/v1/auth/register", `{"email":"admin@example.com","password":"secret"}`, "application/json")
+	require.Equal(t, http.StatusCreated, registerRecorder.Code)
+	var registerBody successPayload[registerPayload]
+	require.NoError(t, json.Unmarshal(registerRecorder.Body.Bytes(), &registerBody))
+	access := registerBody.Data.Tokens.AccessToken
+
+	enrollRecorder := authorized(http.MethodPost, "/v1/user/mfa/enroll", "", access)
+	require.Equal(t, http.StatusCreated, enrollRecorder.Code)
+	var enrollBody successPayload[struct {
+		Secret     string `json:"secret"`
+		OTPAuthURI string `json:"otpauth_uri"`
+	}]
+	require.NoError(t, json.Unmarshal(enrollRecorder.Body.Bytes(), &enrollBody))
+	require.True(t, strings.HasPrefix(enrollBody.Data.OTPAuthURI, "otpauth://totp/Yamato:admin@example.com?"))
+
+	// 3.- Logins still issue tokens until the enrollment is confirmed.
+	beforeConfirm := performRequest(engine, http.MethodPost, "/v1/auth/login", `{"email":"admin@example.com","password":"secret"}`, "application/json")
+	require.Equal(t, http.StatusOK, beforeConfirm.Code)
+	require.NotContains(t, beforeConfirm.Body.String(), "mfa_token")
+
+	// 4.- Confirm with a valid code and collect recovery codes.
+	code, err := internalauth.TOTPCode(enrollBody.Data.Secret, time.Now())
+	require.NoError(t, err)
+	wrongConfirm := authorized(http.MethodPost, "/v1/user/mfa/confirm", `{"code":"000000x"}`, access)
+	require.Equal(t, http.StatusBadRequest, wrongConfirm.Code)
+	confirmRecorder := authorized(http.MethodPost, "/v1/user/mfa/confirm", `{"code":"`+code+`"}`, access)
+	require.Equal(t, http.StatusOK, confirmRecorder.Code)
+	var confirmBody successPayload[struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}]
+	require.NoError(t, json.Unmarshal(confirmRecorder.Body.Bytes(), &confirmBody))
+	require.Len(t, confirmBody.Data.RecoveryCodes, 10)
+
+	// 5.- The password step now yields only an mfa_pending token.
+	loginRecorder := performRequest(engine, http.MethodPost, "/v1/auth/login", `{"email":"admin@example.com","password":"secret"}`, "application/json")
+	require.Equal(t, http.StatusOK, loginRecorder.Code)
+	var challenge successPayload[struct {
+		MFARequired bool   `json:"mfa_required"`
+		MFAToken    string `json:"mfa_token"`
+	}]
+	require.NoError(t, json.Unmarshal(loginRecorder.Body.Bytes(), &challenge))
+	require.True(t, challenge.Data.MFARequired)
+	require.NotEmpty(t, challenge.Data.MFAToken)
+	require.NotContains(t, loginRecorder.Body.String(), "refresh_token")
+
+	// 6.- The pending token is not an access token.
+	require.Equal(t, http.StatusUnauthorized, authorized(http.MethodPost, "/v1/user/mfa/enroll", "", challenge.Data.MFAToken).Code)
+
+	// 7.- Replaying the confirmation code is rejected, while a recovery code completes the login.
+	replay := performRequest(engine, http.MethodPost, "/v1/auth/mfa/verify", `{"mfa_token":"`+challenge.Data.MFAToken+`","code":"`+code+`"}`, "application/json")
+	require.Equal(t, http.StatusUnauthorized, replay.Code)
+
+	recovery := confirmBody.Data.RecoveryCodes[0]
+	verify := performRequest(engine, http.MethodPost, "/v1/auth/mfa/verify", `{"mfa_token":"`+challenge.Data.MFAToken+`","code":"`+strings.ToUpper(recovery)+`"}`, "application/json")
+	require.Equal(t, http.StatusOK, verify.Code)
+	var verifyBody successPayload[loginPayload]
+	require.NoError(t, json.Unmarshal(verify.Body.Bytes(), &verifyBody))
+	require.NotEmpty(t, verifyBody.Data.Tokens.AccessToken)
+	require.Equal(t, "admin@example.com", verifyBody.Data.User.Email)
+
+	// 8.- Both the pending token and the recovery code are single-use.
+	again := performRequest(engine, http.MethodPost, "/v1/auth/mfa/verify", `{"mfa_token":"`+challenge.Data.MFAToken+`","code":"`+recovery+`"}`, "application/json")
+	require.Equal(t, http.StatusUnauthorized, again.Code)
+	require.ErrorIs(t, mfaSvc.Verify(context.Background(), registerBody.Data.User.ID, recovery), authpkg.ErrInvalidMFACode)
+}
+
+// 1.- TestMFAFailuresLockTheAccount counts wrong second factors against the account so new logins do not reset the guesses.
+func TestMFAFailuresLockTheAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mini := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mini.Addr()})
+	defer client.Close()
+
+	svc, err := internalauth.NewService(config.JWTConfig{Secret: "test-secret", Issuer: "yamato-test"}, client)
+	require.NoError(t, err)
+	throttle, err := internalauth.NewLoginThrottle(client, internalauth.LockoutConfig{AccountThreshold: 3, BaseDelay: time.Minute})
+	require.NoError(t, err)
+
+	store := newMemoryUserStore()
+	mfaSvc := authpkg.NewMFAService(store, memoryplatform.NewMFAStore(), authpkg.MFAConfig{Issuer: "Yamato", Secret: "mfa-secret"})
+	handler := authpkg.NewHandler(svc, store, nil, authpkg.WithMFA(mfaSvc, svc), authpkg.WithLoginGuard(throttle))
+
+	engine := newTestEngine()
+	engine.POST("/v1/auth/login", handler.Login)
+	engine.POST("/v1/auth/mfa/verify", handler.VerifyMFA)
+	engine.POST("/v1/admin/users/:id/unlock", handler.UnlockAccount)
+
+	// 2.- Seed an account with confirmed TOTP.
+	ctx := context.Background()
+	hash, err := svc.HashPassword("secret")
+	require.NoError(t, err)
+	user, err := store.Create(ctx, authpkg.User{Email: "mfa-lock@example.com", PasswordHash: hash})
+	require.NoError(t, err)
+	secret, _, err := mfaSvc.Enroll(ctx, user.ID)
+	require.NoError(t, err)
+	code, err := internalauth.TOTPCode(secret, time.Now())
+	require.NoError(t, err)
+	recoveryCodes, err := mfaSvc.Confirm(ctx, user.ID, code)
+	require.NoError(t, err)
+
+	login := func() *httptest.ResponseRecorder {
+		return performRequest(engine, http.MethodPost, "/v1/auth/login", `{"email":"mfa-lock@example.com","password":"secret"}`, "application/json")
+	}
+	pendingToken := func() string {
+		recorder := login()
+		require.Equal(t, http.StatusOK, recorder.Code)
+		var challenge successPayload[struct {
+			MFAToken string `json:"mfa_token"`
+		}]
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &challenge))
+		require.NotEmpty(t, challenge.Data.MFAToken)
+		return challenge.Data.MFAToken
+	}
+	verify := func(token string, code string) *httptest.ResponseRecorder {
+		return performRequest(engine, http.MethodPost, "/v1/auth/mfa/verify", `{"mfa_token":"`+token+`","code":"`+code+`"}`, "application/json")
+	}
+
+	// 3.- Each wrong code uses a fresh login, yet the failures add up and lock both steps.
+	spare := pendingToken()
+	for attempt := 0; attempt < 3; attempt++ {
+		require.Equal(t, http.StatusUnauthorized, verify(pendingToken(), "00000a").Code)
+	}
+	require.Equal(t, http.StatusTooManyRequests, login().Code)
+	locked := verify(spare, recoveryCodes[0])
+	require.Equal(t, http.StatusTooManyRequests, locked.Code)
+	require.NotEmpty(t, locked.Header().Get("Retry-After"))
+
+	// 4.- After an unlock, a valid second factor signs in and clears the history.
+	require.Equal(t, http.StatusOK, performRequest(engine, http.MethodPost, "/v1/admin/users/"+user.ID+"/unlock", "", "").Code)
+	wrongTwice := func() {
+		for attempt := 0; attempt < 2; attempt++ {
+			require.Equal(t, http.StatusUnauthorized, verify(pendingToken(), "00000a").Code)
+		}
+	}
+	wrongTwice()
+	require.Equal(t, http.StatusOK, verify(pendingToken(), recoveryCodes[1]).Code)
+	wrongTwice()
+	require.Equal(t, http.StatusOK, login().Code)
+}
+
+// 1.- TestSessionEndpoints lists, revokes one, and revokes all other sessions.
+func TestSessionEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mini := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mini.Addr()})
+	defer client.Close()
+
+	svc, err := internalauth.NewService(config.JWTConfig{Secret: "test-secret", Issuer: "yamato-test"}, client)
+	require.NoError(t, err)
+
+	store := newMemoryUserStore()
+	handler := authpkg.NewHandler(svc, store, nil, authpkg.WithSessions(svc))
+
+	engine := newTestEngine()
+	engine.POST("/v1/auth/register", handler.Register)
+	engine.POST("/v1/auth/login", handler.Login)
+	userGroup := engine.Group("/v1/user", middleware.Authentication(svc, store))
+	userGroup.GET("/sessions", handler.ListSessions)
+	userGroup.DELETE("/sessions", handler.RevokeOtherSessions)
+	userGroup.DELETE("/sessions/:id", handler.RevokeSession)
+
+	send := func(method string, path string, body string, token string, userAgent string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", userAgent)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// 2.- Open three sessions from distinct user agents.
+	require.Equal(t, http.StatusCreated, send(http.MethodPost, "/v1/auth/register", `{"email":"s@example.com","password":"secret"}`, "", "register-agent").Code)
+	tokens := map[string]string{}
+	for _, agent := range []string{"agent-a", "agent-b"} {
+		recorder := send(http.MethodPost, "/v1/auth/login", `{"email":"s@example.com","password":"secret"}`, "", agent)
+		require.Equal(t, http.StatusOK, recorder.Code)
+		var body successPayload[loginPayload]
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		tokens[agent] = body.Data.Tokens.AccessToken
+	}
+
+	// 3.- List sessions from agent-a and find the current one.
+	type sessionView struct {
+		ID        string `json:"id"`
+		UserAgent string `json:"user_agent"`
+		Current   bool   `json:"current"`
+	}
+	listRecorder := send(http.MethodGet, "/v1/user/sessions", "", tokens["agent-a"], "agent-a")
+	require.Equal(t, http.StatusOK, listRecorder.Code)
+	var listBody successPayload[[]sessionView]
+	require.NoError(t, json.Unmarshal(listRecorder.Body.Bytes(), &listBody))
+	require.Len(t, listBody.Data, 3)
+	byAgent := map[string]sessionView{}
+	for _, session := range listBody.Data {
+		byAgent[session.UserAgent] = session
+	}
+	require.True(t, byAgent["agent-a"].Current)
+	require.False(t, byAgent["agent-b"].Current)
+
+	// 4.- Revoke agent-b explicitly and confirm its token stops working.
+	require.Equal(t, http.StatusOK, send(http.MethodDelete, "/v1/user/sessions/"+byAgent["agent-b"].ID, "", tokens["agent-a"], "agent-a").Code)
+	require.Equal(t, http.StatusUnauthorized, send(http.MethodGet, "/v1/user/sessions", "", tokens["agent-b"], "agent-b").Code)
+	require.Equal(t, http.StatusNotFound, send(http.MethodDelete, "/v1/user/sessions/unknown", "", tokens["agent-a"], "agent-a").Code)
+
+	// 5.- Revoke all others, leaving only the caller.
+	othersRecorder := send(http.MethodDelete, "/v1/user/sessions", "", tokens["agent-a"], "agent-a")
+	require.Equal(t, http.StatusOK, othersRecorder.Code)
+	var othersBody successPayload[map[string]int]
+	require.NoError(t, json.Unmarshal(othersRecorder.Body.Bytes(), &othersBody))
+	require.Equal(t, 1, othersBody.Data["revoked"])
+
+	finalRecorder := send(http.MethodGet, "/v1/user/sessions", "", tokens["agent-a"], "agent-a")
+	require.Equal(t, http.StatusOK, finalRecorder.Code)
+	var finalBody successPayload[[]sessionView]
+	require.NoError(t, json.Unmarshal(finalRecorder.Body.Bytes(), &finalBody))
+	require.Len(t, finalBody.Data, 1)
+	require.Equal(t, "agent-a", finalBody.Data[0].UserAgent)
+}
+
+// 1.- TestLoginLockoutAndUniformErrors checks identical failures, progressive lockout, and admin unlock.
+func TestLoginLockoutAndUniformErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mini := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mini.Addr()})
+	defer client.Close()
+
+	svc, err := internalauth.NewService(config.JWTConfig{Secret: "test-secret", Issuer: "yamato-test"}, client)
+	require.NoError(t, err)
+	throttle, err := internalauth.NewLoginThrottle(client, internalauth.LockoutConfig{AccountThreshold: 2, BaseDelay: time.Minute})
+	require.NoError(t, err)
+
+	store := newMemoryUserStore()
+	handler := authpkg.NewHandler(svc, store, nil, authpkg.WithLoginGuard(throttle))
+
+	engine := newTestEngine()
+	engine.POST("/v1/auth/register", handler.Register)
+	engine.POST("/v1/auth/login", handler.Login)
+	engine.POST("/v1/admin/users/:id/unlock", handler.UnlockAccount)
+
+	registerRecorder := performRequest(engine, http.MethodPost, "/v1/auth/register", `{"email":"locked@example.com","password":"secret"}`, "application/json")
+	require.Equal(t, http.StatusCreated, registerRecorder.Code)
+	var registered successPayload[loginPayload]
+	require.NoError(t, json.Unmarshal(registerRecorder.Body.Bytes(), &registered))
+
+	// 2.- Unknown accounts and wrong passwords are indistinguishable.
+	unknown := performRequest(engine, http.MethodPost, "/v1/auth/login", `{"email":"missing@example.com","password":"secret"}`, "application/json")
+	wrong := performRequest(engine, http.MethodPost, "/v1/auth/login", `{"email":"locked@example.com","password":"nope"}`, "application/json")
+	require.Equal(t, http.StatusUnauthorized, unknown.Code)
+	require.Equal(t, http.StatusUnauthorized, wrong.Code)
+	require.JSONEq(t, unknown.Body.String(), wrong.Body.String())
+
+	// 3.- The second failure locks the account, even for the correct password.
+	require.Equal(t, http.StatusUnauthorized, performRequest(engine, http.MethodPost, "/v1/auth/login", `{"email":"locked@example.com","password":"nope"}`, "application/json").Code)
+	locked := performRequest(engine, http.MethodPost, "/v1/auth/login", `{"email":"locked@example.com","password":"secret"}`, "application/json")
+	require.Equal(t, http.StatusTooManyRequests, locked.Code)
+	require.NotEmpty(t, locked.Header().Get("Retry-After"))
+
+	// 4.- An administrator unlock restores access.
+	require.Equal(t, http.StatusNotFound, performRequest(engine, http.MethodPost, "/v1/admin/users/unknown/unlock", "", "").Code)
+	require.Equal(t, http.StatusOK, performRequest(engine, http.MethodPost, "/v1/admin/users/"+registered.Data.User.ID+"/unlock", "", "").Code)
+	require.Equal(t, http.StatusOK, performRequest(engine, http.MethodPost, "/v1/auth/login", `{"email":"locked@example.com","password":"secret"}`, "application/json").Code)
+}
+
+// 1.- TestLoginRehashesLegacyPasswords upgrades bcrypt hashes to Argon2id on the next successful login.
+func TestLoginRehashesLegacyPasswords(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mini := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mini.Addr()})
+	defer client.Close()
+
+	svc, err := internalauth.NewService(config.JWTConfig{Secret: "test-secret", Issuer: "yamato-test"}, client)
+	require.NoError(t, err)
+	legacy, err := internalauth.NewBcryptHasher(4).Hash("secret")
+	require.NoError(t, err)
+	store := newMemoryUserStore()
+	_, err = store.Create(context.Background(), authpkg.User{Email: "legacy@example.com", PasswordHash: legacy})
+	require.NoError(t, err)
+
+	engine := newTestEngine()
+	engine.POST("/v1/auth/login", authpkg.NewHandler(svc, store, nil).Login)
+
+	// 2.- A failed login leaves the legacy hash alone.
+	require.Equal(t, http.StatusUnauthorized, performRequest(engine, http.MethodPost, "/v1/auth/login", `{"email":"legacy@example.com","password":"nope"}`, "application/json").Code)
+	user, err := store.FindByEmail(context.Background(), "legacy@example.com")
+	require.NoError(t, err)
+	require.Equal(t, legacy, user.PasswordHash)
+
+	// 3.- A successful login stores an Argon2id hash that keeps working and is not rehashed again.
+	require.Equal(t, http.StatusOK, performRequest(engine, http.MethodPost, "/v1/auth/login", `{"email":"legacy@example.com","password":"secret"}`, "application/json").Code)
+	user, err = store.FindByEmail(context.Background(), "legacy@example.com")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(user.PasswordHash, "$argon2id$"))
+	upgraded := user.PasswordHash
+	require.Equal(t, http.StatusOK, performRequest(engine, http.MethodPost, "/v1/auth/login", `{"email":"legacy@example.com","password":"secret"}`, "application/json").Code)
+	user, err = store.FindByEmail(context.Background(), "legacy@example.com")
+	require.NoError(t, err)
+	require.Equal(t, upgraded, user.PasswordHash)
+}
+
+// 1.- TestSSOLoginLinksVerifiedEmail runs the redirect and callback legs against a stub OIDC issuer.
+func TestSSOLoginLinksVerifiedEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mini := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mini.Addr()})
+	defer client.Close()
+
+	svc, err := internalauth.NewService(config.JWTConfig{Secret: "test-secret", Issuer: "yamato-test"}, client)
+	require.NoError(t, err)
+
+	idp, err := oidctest.NewServer("yamato", "client-secret")
+	require.NoError(t, err)
+	defer idp.Close()
+	provider, err := oidc.NewProvider(oidc.Config{Name: "corp", Issuer: idp.Issuer(), ClientID: "yamato", ClientSecret: "client-secret", RedirectURL: "http://localhost/v1/auth/sso/corp/callback"}, idp.Client())
+	require.NoError(t, err)
+
+	store := newMemoryUserStore()
+	sso := authpkg.NewSSOService(store, svc, client, authpkg.SSOConfig{}, provider)
+	handler := authpkg.NewHandler(svc, store, nil, authpkg.WithSSO(sso))
+
+	engine := newTestEngine()
+	engine.POST("/v1/auth/register", handler.Register)
+	engine.GET("/v1/auth/sso/:provider", handler.SSORedirect)
+	engine.GET("/v1/auth/sso/:provider/callback", handler.SSOCallback)
+
+	registerRecorder := performRequest(engine, http.MethodPost, "/v1/auth/register", `{"email":"jane@example.com","password":"secret"}`, "application/json")
+	require.Equal(t, http.StatusCreated, registerRecorder.Code)
+	var registered successPayload[loginPayload]
+	require.NoError(t, json.Unmarshal(registerRecorder.Body.Bytes(), &registered))
+
+	// 2.- login redirects through the issuer and returns the callback path with the state cookie it was issued.
+	login := func() (string, *http.Cookie) {
+		redirect := performRequest(engine, http.MethodGet, "/v1/auth/sso/corp", "", "")
+		require.Equal(t, http.StatusFound, redirect.Code)
+		var stateCookie *http.Cookie
+		for _, cookie := range redirect.Result().Cookies() {
+			if cookie.Name == authpkg.SSOStateCookie {
+				stateCookie = cookie
+			}
+		}
+		require.NotNil(t, stateCookie)
+		require.True(t, stateCookie.HttpOnly)
+		require.Equal(t, http.SameSiteLaxMode, stateCookie.SameSite)
+		callback, err := idp.Authorize(redirect.Header().Get("Location"))
+		require.NoError(t, err)
+		parsed, err := url.Parse(callback)
+		require.NoError(t, err)
+		return parsed.RequestURI(), stateCookie
+	}
+	finish := func(callback string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, callback, nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// 3.- A callback replayed in a browser that did not start the login is refused, as is a cookie from another login.
+	idp.SetUser(oidctest.User{Subject: "idp-jane", Email: "Jane@example.com", EmailVerified: true})
+	victim, _ := login()
+	require.Equal(t, http.StatusUnauthorized, finish(victim, nil).Code)
+	_, otherCookie := login()
+	foreign, _ := login()
+	require.Equal(t, http.StatusUnauthorized, finish(foreign, otherCookie).Code)
+
+	// 4.- A verified e-mail links to the existing account and yields the normal token pair.
+	callback, stateCookie := login()
+	recorder := finish(callback, stateCookie)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var body successPayload[loginPayload]
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	require.Equal(t, registered.Data.User.ID, body.Data.User.ID)
+	require.NotEmpty(t, body.Data.Tokens.AccessToken)
+	claims, err := svc.ValidateAccessToken(context.Background(), body.Data.Tokens.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, registered.Data.User.ID, claims.Subject)
+
+	// 5.- Replaying the callback fails because state is single use.
+	require.Equal(t, http.StatusUnauthorized, finish(callback, stateCookie).Code)
+
+	// 6.- Unverified e-mails and unknown accounts are refused without auto-provisioning.
+	idp.SetUser(oidctest.User{Subject: "idp-jane", Email: "jane@example.com", EmailVerified: false})
+	require.Equal(t, http.StatusForbidden, finish(login()).Code)
+	idp.SetUser(oidctest.User{Subject: "idp-new", Email: "new@example.com", EmailVerified: true})
+	require.Equal(t, http.StatusForbidden, finish(login()).Code)
+
+	// 7.- Unknown providers are reported as such.
+	require.Equal(t, http.StatusNotFound, performRequest(engine, http.MethodGet, "/v1/auth/sso/other", "", "").Code)
+}
+
+// 1.- TestPersonalAccessTokens covers CRUD, bearer authentication, scope mapping, and revocation.
+func TestPersonalAccessTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mini := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mini.Addr()})
+	defer client.Close()
+
+	svc, err := internalauth.NewService(config.JWTConfig{Secret: "test-secret", Issuer: "yamato-test"}, client)
+	require.NoError(t, err)
+
+	store := newMemoryUserStore()
+	tokenStore := memoryplatform.NewAccessTokenStore()
+	tokens := authpkg.NewAccessTokenService(tokenStore, authpkg.AccessTokenConfig{Scopes: map[string][]string{"admin:users": {"admin.users.manage"}}})
+	handler := authpkg.NewHandler(svc, store, nil, authpkg.WithAccessTokens(tokens))
+
+	engine := newTestEngine()
+	engine.POST("/v1/auth/register", handler.Register)
+	userGroup := engine.Group("/v1/user", middleware.Authentication(svc, store, middleware.WithAccessTokens(tokens)))
+	userGroup.GET("", handler.CurrentUser)
+	userGroup.GET("/tokens", handler.ListAccessTokens)
+	userGroup.POST("/tokens", handler.CreateAccessToken)
+	userGroup.PATCH("/tokens/:id", handler.RenameAccessToken)
+	userGroup.DELETE("/tokens/:id", handler.RevokeAccessToken)
+	engine.GET("/v1/admin/ping", middleware.Authentication(svc, store, middleware.WithAccessTokens(tokens)), middleware.RequirePermission(authorization.NewPolicy(), "admin.users.manage"), func(ctx *gin.Context) {
+		ctx.Status(http.StatusNoContent)
+	})
+
+	send := func(method string, path string, body string, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	registerRecorder := performRequest(engine, http.MethodPost, "/v1/auth/register", `{"email":"pat@example.com","password":"secret"}`, "application/json")
+	require.Equal(t, http.StatusCreated, registerRecorder.Code)
+	var registered successPayload[loginPayload]
+	require.NoError(t, json.Unmarshal(registerRecorder.Body.Bytes(), &registered))
+	session := registered.Data.Tokens.AccessToken
+
+	// 2.- Scopes beyond the caller's permissions and unknown scopes are refused.
+	require.Equal(t, http.StatusUnprocessableEntity, send(http.MethodPost, "/v1/user/tokens", `{"name":"ci","scopes":["admin:users"]}`, session).Code)
+	require.Equal(t, http.StatusUnprocessableEntity, send(http.MethodPost, "/v1/user/tokens", `{"name":"ci","scopes":["root"]}`, session).Code)
+
+	// 3.- An unscoped token is shown once and authenticates as the user.
+	type createdToken struct {
+		Token       string `json:"token"`
+		AccessToken struct {
+			ID         string     `json:"id"`
+			Name       string     `json:"name"`
+			Prefix     string     `json:"prefix"`
+			LastUsedAt *time.Time `json:"last_used_at"`
+		} `json:"access_token"`
+	}
+	createRecorder := send(http.MethodPost, "/v1/user/tokens", `{"name":"ci","expires_in_days":30}`, session)
+	require.Equal(t, http.StatusCreated, createRecorder.Code)
+	var created successPayload[createdToken]
+	require.NoError(t, json.Unmarshal(createRecorder.Body.Bytes(), &created))
+	require.True(t, strings.HasPrefix(created.Data.Token, created.Data.AccessToken.Prefix+"_"))
+	require.NotContains(t, send(http.MethodGet, "/v1/user/tokens", "", session).Body.String(), created.Data.Token)
+
+	require.Equal(t, http.StatusOK, send(http.MethodGet, "/v1/user", "", created.Data.Token).Code)
+	require.Equal(t, http.StatusForbidden, send(http.MethodGet, "/v1/admin/ping", "", created.Data.Token).Code)
+	require.Equal(t, http.StatusForbidden, send(http.MethodGet, "/v1/user/tokens", "", created.Data.Token).Code)
+	require.Equal(t, http.StatusUnauthorized, send(http.MethodGet, "/v1/user", "", created.Data.Token+"x").Code)
+
+	listed, err := tokenStore.ListByUser(context.Background(), registered.Data.User.ID)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	require.False(t, listed[0].LastUsedAt.IsZero())
+	require.False(t, listed[0].ExpiresAt.IsZero())
+
+	// 4.- Scoped tokens populate the principal's permissions.
+	scoped, _, err := tokens.Create(context.Background(), internalauth.Principal{Subject: registered.Data.User.ID, Permissions: []string{"admin.users.manage"}}, "admin", []string{"admin:users"}, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, send(http.MethodGet, "/v1/admin/ping", "", scoped).Code)
+
+	// 5.- Renaming and revoking are limited to the owner.
+	require.Equal(t, http.StatusOK, send(http.MethodPatch, "/v1/user/tokens/"+created.Data.AccessToken.ID, `{"name":"deploy"}`, session).Code)
+	require.Equal(t, http.StatusNotFound, send(http.MethodDelete, "/v1/user/tokens/999", "", session).Code)
+	require.Equal(t, http.StatusOK, send(http.MethodDelete, "/v1/user/tokens/"+created.Data.AccessToken.ID, "", session).Code)
+	require.Equal(t, http.StatusUnauthorized, send(http.MethodGet, "/v1/user", "", created.Data.Token).Code)
+}
+
+func TestImpersonationIsAuditedAndBarredFromSensitiveRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mini := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mini.Addr()})
+	defer client.Close()
+
+	svc, err := internalauth.NewService(config.JWTConfig{Secret: "test-secret", Issuer: "yamato-test"}, client)
+	require.NoError(t, err)
+
+	store := newMemoryUserStore()
+	audit := memoryplatform.NewImpersonationAudit()
+	tokens := authpkg.NewAccessTokenService(memoryplatform.NewAccessTokenStore(), authpkg.AccessTokenConfig{})
+	handler := authpkg.NewHandler(svc, store, nil, authpkg.WithAccessTokens(tokens), authpkg.WithImpersonation(svc, audit, 10*time.Minute))
+
+	engine := newTestEngine()
+	engine.POST("/v1/auth/register", handler.Register)
+	grants := grantsBySubject{}
+	authenticated := middleware.Authentication(svc, store, middleware.WithImpersonationAudit(audit), middleware.WithAccessLoader(grants))
+	userGroup := engine.Group("/v1/user", authenticated)
+	userGroup.GET("", handler.CurrentUser)
+	userGroup.POST("/tokens", authpkg.DenyImpersonation, handler.CreateAccessToken)
+	engine.POST("/v1/admin/users/:id/impersonate", authenticated, authpkg.DenyImpersonation, handler.Impersonate)
+	engine.DELETE("/v1/admin/impersonations/:token_id", authenticated, authpkg.DenyImpersonation, handler.EndImpersonation)
+	engine.GET("/v1/unaudited", middleware.Authentication(svc, store), func(ctx *gin.Context) { ctx.Status(http.StatusNoContent) })
+
+	send := func(method string, path string, body string, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+		return recorder
+	}
+	register := func(email string) loginPayload {
+		recorder := performRequest(engine, http.MethodPost, "/v1/auth/register", `{"email":"`+email+`","password":"secret"}`, "application/json")
+		require.Equal(t, http.StatusCreated, recorder.Code)
+		var registered successPayload[loginPayload]
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &registered))
+		return registered.Data
+	}
+	admin := register("support@example.com")
+	customer := register("customer@example.com")
+	grants[admin.User.ID] = internalauth.Access{Roles: []string{"admin"}, Permissions: []string{internalauth.PermissionImpersonate}}
+
+	// 2.- A justification is required and administrators cannot impersonate themselves.
+	require.Equal(t, http.StatusBadRequest, send(http.MethodPost, "/v1/admin/users/"+customer.User.ID+"/impersonate", `{}`, admin.Tokens.AccessToken).Code)
+	require.Equal(t, http.StatusUnprocessableEntity, send(http.MethodPost, "/v1/admin/users/"+admin.User.ID+"/impersonate", `{"reason":"test"}`, admin.Tokens.AccessToken).Code)
+	require.Equal(t, http.StatusNotFound, send(http.MethodPost, "/v1/admin/users/999/impersonate", `{"reason":"test"}`, admin.Tokens.AccessToken).Code)
+	require.Empty(t, audit.Events())
+
+	// 3.- The issued token acts as the customer and records the administrator.
+	type impersonation struct {
+		AccessToken string       `json:"access_token"`
+		ExpiresAt   time.Time    `json:"expires_at"`
+		User        authpkg.User `json:"user"`
+		Actor       string       `json:"actor"`
+	}
+	recorder := send(http.MethodPost, "/v1/admin/users/"+customer.User.ID+"/impersonate", `{"reason":"ticket 42"}`, admin.Tokens.AccessToken)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+	require.NotContains(t, recorder.Body.String(), "refresh_token")
+	var issued successPayload[impersonation]
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &issued))
+	require.Equal(t, customer.User.ID, issued.Data.User.ID)
+	require.Equal(t, admin.User.ID, issued.Data.Actor)
+	require.WithinDuration(t, time.Now().Add(10*time.Minute), issued.Data.ExpiresAt, 5*time.Second)
+
+	events := audit.Events()
+	require.Len(t, events, 1)
+	require.Equal(t, authpkg.ImpersonationActionStart, events[0].Action)
+	require.Equal(t, "ticket 42", events[0].Reason)
+	require.Equal(t, admin.User.ID, events[0].Actor)
+	require.Equal(t, customer.User.ID, events[0].Subject)
+	tokenID := events[0].TokenID
+
+	current := send(http.MethodGet, "/v1/user", "", issued.Data.AccessToken)
+	require.Equal(t, http.StatusOK, current.Code)
+	var principal successPayload[struct {
+		principalPayload
+		ImpersonatedBy string `json:"impersonated_by"`
+	}]
+	require.NoError(t, json.Unmarshal(current.Body.Bytes(), &principal))
+	require.Equal(t, customer.User.ID, principal.Data.Subject)
+	require.Equal(t, admin.User.ID, principal.Data.ImpersonatedBy)
+
+	// 4.- Sensitive routes and chained impersonation are refused, yet every attempt is audited.
+	require.Equal(t, http.StatusForbidden, send(http.MethodPost, "/v1/user/tokens", `{"name":"ci"}`, issued.Data.AccessToken).Code)
+	require.Equal(t, http.StatusForbidden, send(http.MethodPost, "/v1/admin/users/"+admin.User.ID+"/impersonate", `{"reason":"escalate"}`, issued.Data.AccessToken).Code)
+
+	events = audit.Events()
+	require.Len(t, events, 4)
+	paths := []string{}
+	for _, event := range events[1:] {
+		require.Equal(t, authpkg.ImpersonationActionRequest, event.Action)
+		require.Equal(t, tokenID, event.TokenID)
+		paths = append(paths, event.Method+" "+event.Path)
+	}
+	require.Equal(t, []string{"GET /v1/user", "POST /v1/user/tokens", "POST /v1/admin/users/" + admin.User.ID + "/impersonate"}, paths)
+
+	// 5.- Routes that cannot audit refuse impersonation tokens altogether.
+	require.Equal(t, http.StatusUnauthorized, send(http.MethodGet, "/v1/unaudited", "", issued.Data.AccessToken).Code)
+	require.Equal(t, http.StatusNoContent, send(http.MethodGet, "/v1/unaudited", "", customer.Tokens.AccessToken).Code)
+
+	// 6.- Tokens stop working while the administrator no longer holds the impersonation grant.
+	grants[admin.User.ID] = internalauth.Access{Roles: []string{"support"}, Permissions: []string{}}
+	require.Equal(t, http.StatusForbidden, send(http.MethodGet, "/v1/user", "", issued.Data.AccessToken).Code)
+	grants[admin.User.ID] = internalauth.Access{Roles: []string{"admin"}, Permissions: []string{internalauth.PermissionImpersonate}}
+	require.Equal(t, http.StatusOK, send(http.MethodGet, "/v1/user", "", issued.Data.AccessToken).Code)
+
+	// 7.- Ending the session blacklists the token and is audited; a second attempt finds nothing.
+	require.Equal(t, http.StatusForbidden, send(http.MethodDelete, "/v1/admin/impersonations/"+tokenID, "", issued.Data.AccessToken).Code)
+	require.Equal(t, http.StatusOK, send(http.MethodDelete, "/v1/admin/impersonations/"+tokenID, "", admin.Tokens.AccessToken).Code)
+	require.Equal(t, http.StatusUnauthorized, send(http.MethodGet, "/v1/user", "", issued.Data.AccessToken).Code)
+	require.Equal(t, http.StatusNotFound, send(http.MethodDelete, "/v1/admin/impersonations/"+tokenID, "", admin.Tokens.AccessToken).Code)
+
+	events = audit.Events()
+	ended := events[len(events)-1]
+	require.Equal(t, authpkg.ImpersonationActionEnd, ended.Action)
+	require.Equal(t, tokenID, ended.TokenID)
+	require.Equal(t, admin.User.ID, ended.Actor)
+	require.Equal(t, customer.User.ID, ended.Subject)
+
+	// 8.- Revoking the customer's sessions also revokes tokens impersonating them.
+	recorder = send(http.MethodPost, "/v1/admin/users/"+customer.User.ID+"/impersonate", `{"reason":"ticket 43"}`, admin.Tokens.AccessToken)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &issued))
+	require.Equal(t, http.StatusOK, send(http.MethodGet, "/v1/user", "", issued.Data.AccessToken).Code)
+	require.NoError(t, svc.RevokeAll(context.Background(), customer.User.ID))
+	require.Equal(t, http.StatusUnauthorized, send(http.MethodGet, "/v1/user", "", issued.Data.AccessToken).Code)
+}
+
+// 1.- TestPasskeyRegistrationAndLogin registers a software authenticator and signs in with it.
+func TestPasskeyRegistrationAndLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mini := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mini.Addr()})
+	defer client.Close()
+
+	svc, err := internalauth.NewService(config.JWTConfig{Secret: "test-secret", Issuer: "yamato-test"}, client)
+	require.NoError(t, err)
+
+	store := newMemoryUserStore()
+	rp, err := webauthn.New(webauthn.Config{RPID: "example.com", RPName: "Yamato"})
+	require.NoError(t, err)
+	passkeyStore := memoryplatform.NewPasskeyStore()
+	handler := authpkg.NewHandler(svc, store, nil, authpkg.WithPasskeys(authpkg.NewPasskeyService(store, passkeyStore, client, rp)))
+
+	engine := newTestEngine()
+	engine.POST("/v1/auth/register", handler.Register)
+	engine.POST("/v1/auth/passkeys/options", handler.PasskeyLoginOptions)
+	engine.POST("/v1/auth/passkeys/login", handler.PasskeyLogin)
+	userGroup := engine.Group("/v1/user", middleware.Authentication(svc, store))
+	userGroup.GET("", handler.CurrentUser)
+	userGroup.GET("/passkeys", handler.ListPasskeys)
+	userGroup.POST("/passkeys/options", handler.PasskeyRegistrationOptions)
+	userGroup.POST("/passkeys", handler.RegisterPasskey)
+
+	send := func(method string, path string, body string, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+		return recorder
+	}
+	encode := func(value any) string {
+		raw, err := json.Marshal(value)
+		require.NoError(t, err)
+		return string(raw)
+	}
+
+	recorder := performRequest(engine, http.MethodPost, "/v1/auth/register", `{"email":"passkey@example.com","password":"secret"}`, "application/json")
+	require.Equal(t, http.StatusCreated, recorder.Code)
+	var registered successPayload[loginPayload]
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &registered))
+	accessToken := registered.Data.Tokens.AccessToken
+
+	// 2.- Register a passkey through the creation ceremony.
+	authenticator := webauthntest.NewAuthenticator("https://example.com")
+	recorder = send(http.MethodPost, "/v1/user/passkeys/options", "", accessToken)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var creation successPayload[struct {
+		PublicKey webauthn.CreationOptions `json:"public_key"`
+	}]
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &creation))
+	require.Equal(t, "passkey@example.com", creation.Data.PublicKey.User.Name)
+	attestation, err := authenticator.Create(creation.Data.PublicKey)
+	require.NoError(t, err)
+
+	recorder = send(http.MethodPost, "/v1/user/passkeys", encode(map[string]any{"name": "Laptop", "credential": attestation}), accessToken)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+	require.NotContains(t, recorder.Body.String(), "public_key")
+
+	// 3.- Replaying the attestation fails because its challenge was consumed.
+	recorder = send(http.MethodPost, "/v1/user/passkeys", encode(map[string]any{"credential": attestation}), accessToken)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = send(http.MethodGet, "/v1/user/passkeys", "", accessToken)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var listed successPayload[[]struct {
+		Name string `json:"name"`
+	}]
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &listed))
+	require.Len(t, listed.Data, 1)
+	require.Equal(t, "Laptop", listed.Data[0].Name)
+
+	// 4.- Log in with the passkey; the assertion ends in a regular token pair.
+	login := func() *httptest.ResponseRecorder {
+		recorder := send(http.MethodPost, "/v1/auth/passkeys/options", `{"email":"passkey@example.com"}`, "")
+		require.Equal(t, http.StatusOK, recorder.Code)
+		var request successPayload[struct {
+			PublicKey webauthn.RequestOptions `json:"public_key"`
+		}]
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &request))
+		require.Len(t, request.Data.PublicKey.AllowCredentials, 1)
+		assertion, err := authenticator.Get(request.Data.PublicKey)
+		require.NoError(t, err)
+		return send(http.MethodPost, "/v1/auth/passkeys/login", encode(map[string]any{"credential": assertion}), "")
+	}
+	recorder = login()
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var loggedIn successPayload[loginPayload]
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &loggedIn))
+	require.Equal(t, registered.Data.User.ID, loggedIn.Data.User.ID)
+	require.NotEmpty(t, loggedIn.Data.Tokens.RefreshToken)
+	require.Equal(t, http.StatusOK, send(http.MethodGet, "/v1/user", "", loggedIn.Data.Tokens.AccessToken).Code)
+
+	passkeys, err := passkeyStore.ListByUser(context.Background(), registered.Data.User.ID)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), passkeys[0].SignCount)
+	require.False(t, passkeys[0].LastUsedAt.IsZero())
+
+	// 5.- A counter that does not advance signals a cloned authenticator and is refused.
+	authenticator.SetSignCount(passkeys[0].CredentialID, 0)
+	require.Equal(t, http.StatusUnauthorized, login().Code)
+
+	// 6.- Unknown e-mails still receive options so accounts cannot be enumerated.
+	recorder = send(http.MethodPost, "/v1/auth/passkeys/options", `{"email":"nobody@example.com"}`, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+// 1.- TestMagicLinkLogin covers link delivery, signature checks, token issuance, and replay protection.
+func TestMagicLinkLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mini := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mini.Addr()})
+	defer client.Close()
+
+	svc, err := internalauth.NewService(config.JWTConfig{Secret: "test-secret", Issuer: "yamato-test"}, client)
+	require.NoError(t, err)
+
+	store := newMemoryUserStore()
+	mail := &recordingQueue{}
+	magicLinks := authpkg.NewMagicLinkService(store, memoryplatform.NewOneTimeTokenStore(), mail, authpkg.MagicLinkConfig{
+		Secret:  "magic-secret",
+		LinkURL: "https://app.example.com/magic",
+	})
+	handler := authpkg.NewHandler(svc, store, nil, authpkg.WithMagicLinks(magicLinks))
+
+	engine := newTestEngine()
+	engine.POST("/v1/auth/register", handler.Register)
+	engine.POST("/v1/auth/magic-link", handler.RequestMagicLink)
+	engine.POST("/v1/auth/magic-link/consume", handler.ConsumeMagicLink)
+
+	registerRecorder := performRequest(engine, http.MethodPost, "/v1/auth/register", `{"email":"magic@example.com","password":"secret"}`, "application/json")
+	require.Equal(t, http.StatusCreated, registerRecorder.Code)
+	var registered successPayload[loginPayload]
+	require.NoError(t, json.Unmarshal(registerRecorder.Body.Bytes(), &registered))
+
+	// 2.- Unknown addresses receive the same answer without any e-mail being queued.
+	require.Equal(t, http.StatusAccepted, performRequest(engine, http.MethodPost, "/v1/auth/magic-link", `{"email":"ghost@example.com"}`, "application/json").Code)
+	require.Empty(t, mail.jobs)
+
+	// 3.- Request two links; only the newest one stays valid.
+	link := func() url.Values {
+		recorder := performRequest(engine, http.MethodPost, "/v1/auth/magic-link", `{"email":"magic@example.com"}`, "application/json")
+		require.Equal(t, http.StatusAccepted, recorder.Code)
+		last := mail.jobs[len(mail.jobs)-1]
+		require.Equal(t, queue.EmailSendJobName, last.Job)
+		require.Equal(t, "magic@example.com", last.Payload["to"])
+		body, _ := last.Payload["body"].(string)
+		start := strings.Index(body, "https://app.example.com/magic?")
+		require.GreaterOrEqual(t, start, 0)
+		parsed, err := url.Parse(strings.Fields(body[start:])[0])
+		require.NoError(t, err)
+		return parsed.Query()
+	}
+	consume := func(values url.Values) *httptest.ResponseRecorder {
+		body := `{"token":"` + values.Get("token") + `","expires":` + values.Get("expires") + `,"signature":"` + values.Get("signature") + `"}`
+		return performRequest(engine, http.MethodPost, "/v1/auth/magic-link/consume", body, "application/json")
+	}
+	superseded := link()
+	current := link()
+	require.Equal(t, http.StatusBadRequest, consume(superseded).Code)
+
+	// 4.- Tampering with the expiry breaks the signature.
+	tampered := url.Values{"token": {current.Get("token")}, "expires": {"9999999999"}, "signature": {current.Get("signature")}}
+	require.Equal(t, http.StatusBadRequest, consume(tampered).Code)
+
+	// 5.- The genuine link returns a regular token pair, exactly once.
+	recorder := consume(current)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var loggedIn successPayload[loginPayload]
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &loggedIn))
+	require.Equal(t, registered.Data.User.ID, loggedIn.Data.User.ID)
+	require.NotEmpty(t, loggedIn.Data.Tokens.AccessToken)
+	require.NotEmpty(t, loggedIn.Data.Tokens.RefreshToken)
+
+	replay := consume(current)
+	require.Equal(t, http.StatusBadRequest, replay.Code)
+	var replayBody errorPayload
+	require.NoError(t, json.Unmarshal(replay.Body.Bytes(), &replayBody))
+	require.Contains(t, replayBody.Errors, "fields")
+}
+
+// 1.- TestEmailChangeDoubleConfirmation covers both links, cancellation, the swap, and session revocation.
+func TestEmailChangeDoubleConfirmation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mini := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mini.Addr()})
+	defer client.Close()
+
+	svc, err := internalauth.NewService(config.JWTConfig{Secret: "test-secret", Issuer: "yamato-test"}, client)
+	require.NoError(t, err)
+
+	store := newMemoryUserStore()
+	mail := &recordingQueue{}
+	changes := authpkg.NewEmailChangeService(store, memoryplatform.NewOneTimeTokenStore(), mail, svc, authpkg.EmailChangeConfig{
+		Secret:     "change-secret",
+		ConfirmURL: "https://app.example.com/email/confirm",
+		CancelURL:  "https://app.example.com/email/cancel",
+	})
+	handler := authpkg.NewHandler(svc, store, nil, authpkg.WithEmailChanges(changes))
+
+	engine := newTestEngine()
+	engine.POST("/v1/auth/register", handler.Register)
+	engine.POST("/v1/auth/login", handler.Login)
+	engine.POST("/v1/auth/refresh", handler.Refresh)
+	engine.POST("/v1/auth/email/confirm", handler.ConfirmEmailChange)
+	engine.POST("/v1/auth/email/cancel", handler.CancelEmailChange)
+	engine.POST("/v1/user/email", middleware.Authentication(svc, store), handler.RequestEmailChange)
+
+	register := func(email string) loginPayload {
+		recorder := performRequest(engine, http.MethodPost, "/v1/auth/register", `{"email":"`+email+`","password":"secret"}`, "application/json")
+		require.Equal(t, http.StatusCreated, recorder.Code)
+		var registered successPayload[loginPayload]
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &registered))
+		return registered.Data
+	}
+	owner := register("old@example.com")
+	register("taken@example.com")
+
+	change := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/user/email", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+owner.Tokens.AccessToken)
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+		return recorder
+	}
+	tokenFor := func(to string, base string) string {
+		for i := len(mail.jobs) - 1; i >= 0; i-- {
+			if mail.jobs[i].Payload["to"] != to {
+				continue
+			}
+			body, _ := mail.jobs[i].Payload["body"].(string)
+			start := strings.Index(body, base+"?token=")
+			require.GreaterOrEqual(t, start, 0)
+			return strings.Fields(body[start+len(base+"?token="):])[0]
+		}
+		t.Fatalf("no e-mail queued for %s", to)
+		return ""
+	}
+	redeem := func(path string, token string) int {
+		return performRequest(engine, http.MethodPost, path, `{"token":"`+token+`"}`, "application/json").Code
+	}
+
+	// 2.- The password is re-checked and addresses in use or unchanged are refused without sending mail.
+	require.Equal(t, http.StatusUnauthorized, change(`{"email":"new@example.com","password":"wrong"}`).Code)
+	require.Equal(t, http.StatusConflict, change(`{"email":"taken@example.com","password":"secret"}`).Code)
+	require.Equal(t, http.StatusUnprocessableEntity, change(`{"email":"old@example.com","password":"secret"}`).Code)
+	require.Empty(t, mail.jobs)
+
+	// 3.- A request mails the new address a confirmation and the old one a cancel link; cancelling kills the confirmation.
+	require.Equal(t, http.StatusAccepted, change(`{"email":"new@example.com","password":"secret"}`).Code)
+	require.Len(t, mail.jobs, 2)
+	firstConfirm := tokenFor("new@example.com", "https://app.example.com/email/confirm")
+	firstCancel := tokenFor("old@example.com", "https://app.example.com/email/cancel")
+	require.Equal(t, http.StatusOK, redeem("/v1/auth/email/cancel", firstCancel))
+	require.Equal(t, http.StatusBadRequest, redeem("/v1/auth/email/confirm", firstConfirm))
+	_, err = store.FindByEmail(context.Background(), "old@example.com")
+	require.NoError(t, err)
+
+	// 4.- A fresh request is confirmed; the swap happens and every session is revoked.
+	require.Equal(t, http.StatusAccepted, change(`{"email":"new@example.com","password":"secret"}`).Code)
+	confirm := tokenFor("new@example.com", "https://app.example.com/email/confirm")
+	cancel := tokenFor("old@example.com", "https://app.example.com/email/cancel")
+	require.Equal(t, http.StatusOK, redeem("/v1/auth/email/confirm", confirm))
+	require.Equal(t, http.StatusBadRequest, redeem("/v1/auth/email/confirm", confirm))
+	require.Equal(t, http.StatusBadRequest, redeem("/v1/auth/email/cancel", cancel))
+
+	stale := performRequest(engine, http.MethodPost, "/v1/auth/refresh", `{"refresh_token":"`+owner.Tokens.RefreshToken+`"}`, "application/json")
+	require.Equal(t, http.StatusUnauthorized, stale.Code)
+	require.Equal(t, http.StatusUnauthorized, performRequest(engine, http.MethodPost, "/v1/auth/login", `{"email":"old@example.com","password":"secret"}`, "application/json").Code)
+	relogin := performRequest(engine, http.MethodPost, "/v1/auth/login", `{"email":"new@example.com","password":"secret"}`, "application/json")
+	require.Equal(t, http.StatusOK, relogin.Code)
+
+	// 5.- A session that just signed in may omit the password, which is how passwordless accounts change address.
+	var fresh successPayload[loginPayload]
+	require.NoError(t, json.Unmarshal(relogin.Body.Bytes(), &fresh))
+	owner = fresh.Data
+	require.Equal(t, http.StatusAccepted, change(`{"email":"newer@example.com"}`).Code)
+}
+
+// 1.- TestPasswordPolicyRejectsWeakPasswords reports localized policy violations on register and reset, keeping reset tokens usable.
+func TestPasswordPolicyRejectsWeakPasswords(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mini := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mini.Addr()})
+	defer client.Close()
+
+	svc, err := internalauth.NewService(config.JWTConfig{Secret: "test-secret", Issuer: "yamato-test"}, client)
+	require.NoError(t, err)
+	breached, err := passwordpolicy.DefaultBreachedFilter()
+	require.NoError(t, err)
+	policy := passwordpolicy.New(passwordpolicy.Config{MinLength: 10, RejectSimilar: true, Breached: breached})
+
+	store := newMemoryUserStore()
+	tokens := memoryplatform.NewOneTimeTokenStore()
+	mail := &recordingQueue{}
+	resets := authpkg.NewPasswordResetService(store, tokens, mail, svc, authpkg.PasswordResetConfig{Secret: "reset-secret", ResetURL: "https://app.example.com/reset"})
+	handler := authpkg.NewHandler(svc, store, nil, authpkg.WithPasswordResets(resets), authpkg.WithPasswordPolicy(policy))
+
+	engine := newTestEngine()
+	engine.POST("/v1/auth/register", handler.Register)
+	engine.POST("/v1/auth/password/forgot", handler.ForgotPassword)
+	engine.POST("/v1/auth/password/reset", handler.ResetPassword)
+
+	passwordRules := func(recorder *httptest.ResponseRecorder) map[string]string {
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+		var payload errorPayload
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &payload))
+		fields, ok := payload.Errors["fields"].(map[string]interface{})
+		require.True(t, ok)
+		entries, ok := fields["password"].([]interface{})
+		require.True(t, ok)
+		rules := map[string]string{}
+		for _, entry := range entries {
+			fieldErr := entry.(map[string]interface{})
+			rules[fieldErr["rule"].(string)] = fieldErr["message"].(string)
+		}
+		return rules
+	}
+
+	// 2.- Short, breached, and identity-derived passwords are refused before any account exists.
+	short := passwordRules(performRequest(engine, http.MethodPost, "/v1/auth/register", `{"email":"jane.doe@example.com","password":"a"}`, "application/json"))
+	require.Equal(t, "Password must be at least 10 characters long.", short[passwordpolicy.RuleMinLength])
+	breachedRules := passwordRules(performRequest(engine, http.MethodPost, "/v1/auth/register", `{"email":"jane.doe@example.com","password":"1234567890"}`, "application/json"))
+	require.Contains(t, breachedRules, passwordpolicy.RuleBreached)
+	similar := passwordRules(performRequest(engine, http.MethodPost, "/v1/auth/register", `{"email":"jane.doe@example.com","password":"JaneDoe2024!"}`, "application/json"))
+	require.Contains(t, similar, passwordpolicy.RuleSimilar)
+	_, err = store.FindByEmail(context.Background(), "jane.doe@example.com")
+	require.ErrorIs(t, err, authpkg.ErrUserNotFound)
+
+	// 3.- Messages follow Accept-Language.
+	req := httptest.NewRequest(http.MethodPost, "/v1/auth/register", strings.NewReader(`{"email":"jane.doe@example.com","password":"corta"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "es-MX,es;q=0.9")
+	localized := httptest.NewRecorder()
+	engine.ServeHTTP(localized, req)
+	require.Equal(t, "La contraseña debe tener al menos 10 caracteres.", passwordRules(localized)[passwordpolicy.RuleMinLength])
+
+	// 4.- A strong password registers; a weak reset is refused without consuming the token.
+	require.Equal(t, http.StatusCreated, performRequest(engine, http.MethodPost, "/v1/auth/register", `{"email":"jane.doe@example.com","password":"violet-harbor-lantern"}`, "application/json").Code)
+	require.Equal(t, http.StatusAccepted, performRequest(engine, http.MethodPost, "/v1/auth/password/forgot", `{"email":"jane.doe@example.com"}`, "application/json").Code)
+	require.Len(t, mail.jobs, 1)
+	body, _ := mail.jobs[0].Payload["body"].(string)
+	start := strings.Index(body, "token=")
+	require.GreaterOrEqual(t, start, 0)
+	token := strings.Fields(body[start+len("token="):])[0]
+	require.Contains(t, passwordRules(performRequest(engine, http.MethodPost, "/v1/auth/password/reset", `{"token":"`+token+`","password":"password123"}`, "application/json")), passwordpolicy.RuleBreached)
+	require.Contains(t, passwordRules(performRequest(engine, http.MethodPost, "/v1/auth/password/reset", `{"token":"`+token+`","password":"JaneDoe2024!"}`, "application/json")), passwordpolicy.RuleSimilar)
+	unknown := performRequest(engine, http.MethodPost, "/v1/auth/password/reset", `{"token":"unknown","password":"JaneDoe2024!"}`, "application/json")
+	require.Equal(t, http.StatusBadRequest, unknown.Code)
+	require.Contains(t, unknown.Body.String(), "invalid reset token")
+	require.Equal(t, http.StatusOK, performRequest(engine, http.MethodPost, "/v1/auth/password/reset", `{"token":"`+token+`","password":"copper-meadow-signal"}`, "application/json").Code)
+}
+
+// 1.- recordingDevices remembers when tokens were successfully used.
+type recordingDevices struct {
+	*memoryplatform.DeviceTokenStore
+	used []time.Time
+}
+
+func (r *recordingDevices) Use(ctx context.Context, token string, usedAt time.Time, idleTTL time.Duration) (authpkg.DeviceToken, error) {
+	device, err := r.DeviceTokenStore.Use(ctx, token, usedAt, idleTTL)
+	if err == nil {
+		r.used = append(r.used, device.LastUsedAt)
+	}
+	return device, err
+}
+
+// 1.- TestDeviceLoginExchangesDeviceTokens issues phone-bound sessions and refuses revoked tokens.
+func TestDeviceLoginExchangesDeviceTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mini := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mini.Addr()})
+	defer client.Close()
+
+	svc, err := internalauth.NewService(config.JWTConfig{Secret: "test-secret", Issuer: "yamato-test"}, client)
+	require.NoError(t, err)
+	devices := &recordingDevices{DeviceTokenStore: memoryplatform.NewDeviceTokenStore()}
+	device, err := devices.Create(context.Background(), "+5215550000000", "device-secret")
+	require.NoError(t, err)
+
+	engine := newTestEngine()
+	engine.POST("/plain/device", authpkg.NewHandler(svc, newMemoryUserStore(), nil).DeviceLogin)
+	engine.POST("/v1/auth/device", authpkg.NewHandler(svc, newMemoryUserStore(), nil, authpkg.WithDeviceTokens(devices, time.Hour)).DeviceLogin)
+
+	// 2.- The endpoint is unavailable until device tokens are configured and requires a token.
+	require.Equal(t, http.StatusServiceUnavailable, performRequest(engine, http.MethodPost, "/plain/device", `{"device_token":"device-secret"}`, "application/json").Code)
+	require.Equal(t, http.StatusBadRequest, performRequest(engine, http.MethodPost, "/v1/auth/device", `{}`, "application/json").Code)
+	require.Equal(t, http.StatusUnauthorized, performRequest(engine, http.MethodPost, "/v1/auth/device", `{"device_token":"unknown"}`, "application/json").Code)
+
+	// 3.- A live token yields a session for the phone identity and refreshes last_used_at.
+	recorder := performRequest(engine, http.MethodPost, "/v1/auth/device", `{"device_token":"device-secret"}`, "application/json")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var body successPayload[struct {
+		Phone   string `json:"phone"`
+		Subject string `json:"subject"`
+		Tokens  struct {
+			AccessToken  string `json:"access_token"`
+			RefreshToken string `json:"refresh_token"`
+		} `json:"tokens"`
+	}]
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	require.Equal(t, "+5215550000000", body.Data.Phone)
+	require.Equal(t, authpkg.PhoneSubject("+5215550000000"), body.Data.Subject)
+	claims, err := svc.ValidateAccessToken(context.Background(), body.Data.Tokens.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, "phone:+5215550000000", claims.Subject)
+	require.Len(t, devices.used, 1)
+	require.False(t, devices.used[0].Before(device.LastUsedAt))
+
+	// 4.- Revoked tokens can no longer be exchanged.
+	require.NoError(t, devices.Revoke(context.Background(), device.Phone, device.ID, time.Now()))
+	require.Equal(t, http.StatusUnauthorized, performRequest(engine, http.MethodPost, "/v1/auth/device", `{"device_token":"device-secret"}`, "application/json").Code)
+}
+
+// 1.- TestCookieSessionMode issues tokens as HttpOnly cookies and reads them back on refresh and logout.
+func TestCookieSessionMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mini := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mini.Addr()})
+	defer client.Close()
+
+	svc, err := internalauth.NewService(config.JWTConfig{Secret: "test-secret", Issuer: "yamato-test", AccessExpiration: time.Minute, RefreshExpiration: time.Hour}, client)
+	require.NoError(t, err)
+	handler := authpkg.NewHandler(svc, newMemoryUserStore(), &stubVerificationService{}, authpkg.WithCookieSessions(authpkg.CookieSessionConfig{Secure: true}))
+
+	engine := newTestEngine()
+	engine.POST("/v1/auth/register", handler.Register)
+	engine.POST("/v1/auth/login", handler.Login)
+	engine.POST("/v1/auth/refresh", handler.Refresh)
+	engine.POST("/v1/auth/logout", handler.Logout)
+
+	cookieRequest := func(path string, body string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(authpkg.SessionModeHeader, authpkg.SessionModeCookie)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+		return recorder
+	}
+	byName := func(recorder *httptest.ResponseRecorder) map[string]*http.Cookie {
+		cookies := map[string]*http.Cookie{}
+		for _, cookie := range recorder.Result().Cookies() {
+			cookies[cookie.Name] = cookie
+		}
+		return cookies
+	}
+
+	// 2.- Clients that do not opt in keep receiving tokens in the body.
+	register := performRequest(engine, http.MethodPost, "/v1/auth/register", `{"email":"cookie@example.com","password":"secret"}`, "application/json")
+	require.Equal(t, http.StatusCreated, register.Code)
+	require.Empty(t, register.Result().Cookies())
+
+	// 3.- Cookie mode sets HttpOnly token cookies, a readable CSRF cookie, and omits tokens from the body.
+	login := cookieRequest("/v1/auth/login", `{"email":"cookie@example.com","password":"secret"}`, nil)
+	require.Equal(t, http.StatusOK, login.Code)
+	var loginBody successPayload[loginPayload]
+	require.NoError(t, json.Unmarshal(login.Body.Bytes(), &loginBody))
+	require.Empty(t, loginBody.Data.Tokens.AccessToken)
+	require.Empty(t, loginBody.Data.Tokens.RefreshToken)
+
+	cookies := byName(login)
+	access, refresh, csrf := cookies[authpkg.DefaultAccessCookie], cookies[authpkg.DefaultRefreshCookie], cookies[authpkg.DefaultCSRFCookie]
+	require.NotNil(t, access)
+	require.NotNil(t, refresh)
+	require.NotNil(t, csrf)
+	require.True(t, access.HttpOnly && access.Secure)
+	require.Equal(t, http.SameSiteLaxMode, access.SameSite)
+	require.True(t, refresh.HttpOnly)
+	require.Equal(t, "/v1/auth", refresh.Path)
+	require.False(t, csrf.HttpOnly)
+	require.Equal(t, csrf.Value, login.Header().Get(authpkg.CSRFHeader))
+	_, err = svc.ValidateAccessToken(context.Background(), access.Value)
+	require.NoError(t, err)
+
+	// 4.- Refresh reads the refresh cookie when the body is empty and rotates every cookie.
+	refreshed := cookieRequest("/v1/auth/refresh", "", []*http.Cookie{refresh})
+	require.Equal(t, http.StatusOK, refreshed.Code)
+	rotated := byName(refreshed)
+	require.NotEqual(t, refresh.Value, rotated[authpkg.DefaultRefreshCookie].Value)
+	require.NotEqual(t, csrf.Value, rotated[authpkg.DefaultCSRFCookie].Value)
+	require.Equal(t, http.StatusUnauthorized, cookieRequest("/v1/auth/refresh", "", []*http.Cookie{refresh}).Code)
+
+	// 5.- Logout revokes the cookie-held tokens and expires the cookies.
+	logout := cookieRequest("/v1/auth/logout", "", []*http.Cookie{rotated[authpkg.DefaultAccessCookie], rotated[authpkg.DefaultRefreshCookie]})
+	require.Equal(t, http.StatusOK, logout.Code)
+	for _, cookie := range logout.Result().Cookies() {
+		require.Empty(t, cookie.Value)
+		require.Negative(t, cookie.MaxAge)
+	}
+	require.Len(t, logout.Result().Cookies(), 3)
+	require.Equal(t, http.StatusUnauthorized, cookieRequest("/v1/auth/refresh", "", []*http.Cookie{rotated[authpkg.DefaultRefreshCookie]}).Code)
+}
+
+// 1.- TestDeviceManagementEndpoints lists, rotates, and revokes device tokens for phone sessions and operators.
+func TestDeviceManagementEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mini := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mini.Addr()})
+	defer client.Close()
+
+	svc, err := internalauth.NewService(config.JWTConfig{Secret: "test-secret", Issuer: "yamato-test"}, client)
+	require.NoError(t, err)
+	devices := memoryplatform.NewDeviceTokenStore()
+	const phone = "+525550000000"
+	first, err := devices.Create(context.Background(), phone, "device-one")
+	require.NoError(t, err)
+	_, err = devices.Create(context.Background(), phone, "device-two")
+	require.NoError(t, err)
+	other, err := devices.Create(context.Background(), "+5215559999999", "device-other")
+	require.NoError(t, err)
+
+	store := newMemoryUserStore()
+	handler := authpkg.NewHandler(svc, store, nil, authpkg.WithDeviceTokens(devices, time.Hour), authpkg.WithSessions(svc))
+	engine := newTestEngine()
+	engine.POST("/v1/auth/register", handler.Register)
+	engine.POST("/v1/auth/device", handler.DeviceLogin)
+	engine.POST("/v1/auth/device/rotate", handler.RotateDeviceToken)
+	deviceGroup := engine.Group("/v1/devices", middleware.Authentication(svc, store))
+	deviceGroup.GET("", handler.ListDevices)
+	deviceGroup.DELETE("", handler.RevokeDevices)
+	deviceGroup.DELETE("/:id", handler.RevokeDevice)
+	adminGroup := engine.Group("/v1/admin", middleware.Authentication(svc, store))
+	adminGroup.GET("/phones/:phone/devices", handler.AdminListDevices)
+	adminGroup.DELETE("/phones/:phone/devices", handler.AdminRevokeDevices)
+
+	send := func(method string, path string, body string, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+		return recorder
+	}
+	deviceLogin := func(token string) string {
+		recorder := send(http.MethodPost, "/v1/auth/device", `{"device_token":"`+token+`"}`, "")
+		require.Equal(t, http.StatusOK, recorder.Code)
+		var body successPayload[struct {
+			Tokens tokenPayload `json:"tokens"`
+		}]
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		return body.Data.Tokens.AccessToken
+	}
+	type deviceView struct {
+		ID        string     `json:"id"`
+		Phone     string     `json:"phone"`
+		Status    string     `json:"status"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+
+	// 2.- A phone session sees only the devices of its own phone, with the sliding expiry.
+	access := deviceLogin("device-one")
+	listRecorder := send(http.MethodGet, "/v1/devices", "", access)
+	require.Equal(t, http.StatusOK, listRecorder.Code)
+	var listBody successPayload[[]deviceView]
+	require.NoError(t, json.Unmarshal(listRecorder.Body.Bytes(), &listBody))
+	require.Len(t, listBody.Data, 2)
+	for _, device := range listBody.Data {
+		require.Equal(t, phone, device.Phone)
+		require.Equal(t, authpkg.DeviceStatusActive, device.Status)
+		require.NotNil(t, device.ExpiresAt)
+	}
+	require.NotContains(t, listRecorder.Body.String(), "device-one")
+
+	// 3.- User accounts cannot use the phone endpoints, and devices of other phones stay hidden.
+	register := send(http.MethodPost, "/v1/auth/register", `{"email":"ops@example.com","password":"secret"}`, "")
+	require.Equal(t, http.StatusCreated, register.Code)
+	var registerBody successPayload[registerPayload]
+	require.NoError(t, json.Unmarshal(register.Body.Bytes(), &registerBody))
+	require.Equal(t, http.StatusForbidden, send(http.MethodGet, "/v1/devices", "", registerBody.Data.Tokens.AccessToken).Code)
+	require.Equal(t, http.StatusNotFound, send(http.MethodDelete, "/v1/devices/"+other.ID, "", access).Code)
+
+	// 4.- Rotation returns a new secret once and retires the old one.
+	rotateRecorder := send(http.MethodPost, "/v1/auth/device/rotate", `{"device_token":"device-two"}`, "")
+	require.Equal(t, http.StatusOK, rotateRecorder.Code)
+	var rotateBody successPayload[struct {
+		DeviceToken string     `json:"device_token"`
+		Device      deviceView `json:"device"`
+	}]
+	require.NoError(t, json.Unmarshal(rotateRecorder.Body.Bytes(), &rotateBody))
+	require.Len(t, rotateBody.Data.DeviceToken, 64)
+	require.Equal(t, phone, rotateBody.Data.Device.Phone)
+	require.Equal(t, http.StatusUnauthorized, send(http.MethodPost, "/v1/auth/device", `{"device_token":"device-two"}`, "").Code)
+	rotatedAccess := deviceLogin(rotateBody.Data.DeviceToken)
+
+	// 5.- Revoking a single device stops it from starting new sessions.
+	require.Equal(t, http.StatusOK, send(http.MethodDelete, "/v1/devices/"+first.ID, "", access).Code)
+	require.Equal(t, http.StatusUnauthorized, send(http.MethodPost, "/v1/auth/device", `{"device_token":"device-one"}`, "").Code)
+
+	// 6.- Operators can revoke every device of a lost phone, which also ends its sessions.
+	adminRecorder := send(http.MethodGet, "/v1/admin/phones/"+phone+"/devices", "", registerBody.Data.Tokens.AccessToken)
+	require.Equal(t, http.StatusOK, adminRecorder.Code)
+	var adminBody successPayload[[]deviceView]
+	require.NoError(t, json.Unmarshal(adminRecorder.Body.Bytes(), &adminBody))
+	require.Len(t, adminBody.Data, 3)
+	revokeRecorder := send(http.MethodDelete, "/v1/admin/phones/"+phone+"/devices", "", registerBody.Data.Tokens.AccessToken)
+	require.Equal(t, http.StatusOK, revokeRecorder.Code)
+	var revokeBody successPayload[map[string]int]
+	require.NoError(t, json.Unmarshal(revokeRecorder.Body.Bytes(), &revokeBody))
+	require.Equal(t, 1, revokeBody.Data["revoked"])
+	require.Equal(t, 2, revokeBody.Data["sessions_revoked"])
+	require.Equal(t, http.StatusUnauthorized, send(http.MethodGet, "/v1/devices", "", rotatedAccess).Code)
+	require.Equal(t, http.StatusUnauthorized, send(http.MethodPost, "/v1/auth/device", `{"device_token":"`+rotateBody.Data.DeviceToken+`"}`, "").Code)
+
+	// 7.- Tokens idle past the TTL expire even when never revoked.
+	_, err = devices.Create(context.Background(), phone, "device-idle")
+	require.NoError(t, err)
+	_, err = devices.Use(context.Background(), "device-idle", time.Now().Add(2*time.Hour), time.Hour)
+	require.ErrorIs(t, err, authpkg.ErrDeviceTokenNotFound)
+}
+
+// 1.- recordingPhoneLinks keeps one phone per account and refuses phones held by another account.
+type recordingPhoneLinks struct {
+	phones map[string]string
+}
+
+func (r *recordingPhoneLinks) LinkPhone(_ context.Context, userID string, phone string) error {
+	for owner, linked := range r.phones {
+		if linked == phone && owner != userID {
+			return authpkg.ErrPhoneTaken
+		}
+	}
+	r.phones[userID] = phone
+	return nil
+}
+
+// 1.- TestLinkPhoneRecordsVerifiedPhone attaches the phone behind a live device token to the signed-in account.
+func TestLinkPhoneRecordsVerifiedPhone(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mini := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mini.Addr()})
+	defer client.Close()
+
+	svc, err := internalauth.NewService(config.JWTConfig{Secret: "test-secret", Issuer: "yamato-test"}, client)
+	require.NoError(t, err)
+	devices := memoryplatform.NewDeviceTokenStore()
+	const phone = "+525550000000"
+	_, err = devices.Create(context.Background(), phone, "device-one")
+	require.NoError(t, err)
+
+	store := newMemoryUserStore()
+	links := &recordingPhoneLinks{phones: map[string]string{}}
+	handler := authpkg.NewHandler(svc, store, nil, authpkg.WithDeviceTokens(devices, time.Hour), authpkg.WithPhoneLinks(links))
+	engine := newTestEngine()
+	engine.POST("/v1/auth/register", handler.Register)
+	engine.POST("/v1/auth/device", handler.DeviceLogin)
+	engine.POST("/v1/user/phone", middleware.Authentication(svc, store), handler.LinkPhone)
+
+	send := func(path string, body string, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+		return recorder
+	}
+	register := func(email string) (string, string) {
+		recorder := send("/v1/auth/register", `{"email":"`+email+`","password":"secret"}`, "")
+		require.Equal(t, http.StatusCreated, recorder.Code)
+		var body successPayload[registerPayload]
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		return body.Data.User.ID, body.Data.Tokens.AccessToken
+	}
+
+	// 2.- A user proving the phone gets it linked.
+	ownerID, ownerAccess := register("owner@example.com")
+	recorder := send("/v1/user/phone", `{"device_token":"device-one"}`, ownerAccess)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, phone, links.phones[ownerID])
+
+	// 3.- Unknown tokens and phones already linked elsewhere are refused.
+	_, otherAccess := register("other@example.com")
+	require.Equal(t, http.StatusUnauthorized, send("/v1/user/phone", `{"device_token":"missing"}`, otherAccess).Code)
+	require.Equal(t, http.StatusConflict, send("/v1/user/phone", `{"device_token":"device-one"}`, otherAccess).Code)
+
+	// 4.- Phone sessions have no account to link.
+	deviceRecorder := send("/v1/auth/device", `{"device_token":"device-one"}`, "")
+	require.Equal(t, http.StatusOK, deviceRecorder.Code)
+	var deviceBody successPayload[struct {
+		Tokens tokenPayload `json:"tokens"`
+	}]
+	require.NoError(t, json.Unmarshal(deviceRecorder.Body.Bytes(), &deviceBody))
+	require.Equal(t, http.StatusForbidden, send("/v1/user/phone", `{"device_token":"device-one"}`, deviceBody.Data.Tokens.AccessToken).Code)
+}
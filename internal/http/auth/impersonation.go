@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/respond"
+)
+
+// 1.- Audit actions recorded for impersonation sessions.
+const (
+	ImpersonationActionStart   = "impersonation.start"
+	ImpersonationActionRequest = "impersonation.request"
+	ImpersonationActionEnd     = "impersonation.end"
+)
+
+// 1.- ImpersonationEvent is a single audit trail entry written while an administrator acts as a user.
+type ImpersonationEvent struct {
+	Actor     string
+	Subject   string
+	TokenID   string
+	Action    string
+	Reason    string
+	Method    string
+	Path      string
+	IP        string
+	UserAgent string
+	CreatedAt time.Time
+}
+
+// 1.- ImpersonationAuditor persists impersonation audit entries.
+type ImpersonationAuditor interface {
+	RecordImpersonation(ctx context.Context, event ImpersonationEvent) error
+}
+
+// 1.- Impersonator issues access tokens that let an actor act as another subject, and revokes them early.
+type Impersonator interface {
+	Impersonate(ctx context.Context, actor string, subject string, ttl time.Duration) (internalauth.ImpersonationToken, error)
+	EndImpersonation(ctx context.Context, tokenID string) (internalauth.ImpersonationSession, error)
+}
+
+// 1.- WithImpersonation enables the admin impersonation endpoint; every token issued is audited.
+func WithImpersonation(tokens Impersonator, audit ImpersonationAuditor, ttl time.Duration) HandlerOption {
+	return func(h *Handler) {
+		h.impersonator = tokens
+		h.impersonationAudit = audit
+		h.impersonationTTL = ttl
+	}
+}
+
+// 1.- DenyImpersonation blocks sensitive routes for requests made with an impersonation token.
+func DenyImpersonation(ctx *gin.Context) {
+	if principal, ok := internalauth.PrincipalFromContext(ctx); ok && principal.Actor != "" {
+		respond.Error(ctx, http.StatusForbidden, "not allowed while impersonating", map[string]interface{}{"reason": "impersonation tokens cannot access this route"})
+		ctx.Abort()
+		return
+	}
+	ctx.Next()
+}
+
+// 1.- impersonateRequest requires a justification that is stored with the audit trail.
+type impersonateRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+// 1.- impersonationResponse returns the short-lived access token; there is never a refresh token.
+type impersonationResponse struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	User        User      `json:"user"`
+	Actor       string    `json:"actor"`
+}
+
+// 1.- Impersonate lets an administrator obtain a short-lived access token for another user.
+func (h Handler) Impersonate(ctx *gin.Context) {
+	// 1.- Guard against missing impersonation dependencies to surface clear errors.
+	if h.impersonator == nil || h.impersonationAudit == nil {
+		respond.Error(ctx, http.StatusServiceUnavailable, "impersonation unavailable", map[string]interface{}{"reason": "not configured"})
+		return
+	}
+
+	// 2.- Only an interactive administrator session may start impersonating.
+	principal, ok := internalauth.PrincipalFromContext(ctx)
+	if !ok {
+		respond.Error(ctx, http.StatusUnauthorized, "authentication required", map[string]interface{}{"reason": "principal missing"})
+		return
+	}
+	if principal.TokenID != "" || principal.Actor != "" || principal.IsServiceAccount() {
+		respond.Error(ctx, http.StatusForbidden, "impersonation requires an interactive session", map[string]interface{}{"reason": "interactive session required"})
+		return
+	}
+
+	// 3.- Bind and validate the justification.
+	var req impersonateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Error(ctx, http.StatusBadRequest, "invalid request payload", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if !h.validatePayload(ctx, req) {
+		return
+	}
+
+	// 4.- Resolve the target account.
+	user, err := h.users.FindByID(ctx.Request.Context(), strings.TrimSpace(ctx.Param("id")))
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			respond.Error(ctx, http.StatusNotFound, "user not found", nil)
+			return
+		}
+		respond.Error(ctx, http.StatusInternalServerError, "failed to load user", map[string]interface{}{"details": err.Error()})
+		return
+	}
+
+	// 5.- Mint the token carrying the administrator in its act claim.
+	token, err := h.impersonator.Impersonate(ctx.Request.Context(), principal.Subject, user.ID, h.impersonationTTL)
+	if err != nil {
+		if errors.Is(err, internalauth.ErrSelfImpersonation) {
+			respond.Error(ctx, http.StatusUnprocessableEntity, "cannot impersonate yourself", nil)
+			return
+		}
+		respond.Error(ctx, http.StatusInternalServerError, "failed to issue impersonation token", map[string]interface{}{"details": err.Error()})
+		return
+	}
+
+	// 6.- The token is only handed out once its issuance is on the audit trail.
+	event := ImpersonationEvent{
+		Actor:     principal.Subject,
+		Subject:   user.ID,
+		TokenID:   token.TokenID,
+		Action:    ImpersonationActionStart,
+		Reason:    req.Reason,
+		Method:    ctx.Request.Method,
+		Path:      ctx.Request.URL.Path,
+		IP:        ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := h.impersonationAudit.RecordImpersonation(ctx.Request.Context(), event); err != nil {
+		respond.Error(ctx, http.StatusInternalServerError, "failed to record impersonation", map[string]interface{}{"details": err.Error()})
+		return
+	}
+
+	respond.Success(ctx, http.StatusCreated, impersonationResponse{
+		AccessToken: token.AccessToken,
+		TokenType:   "Bearer",
+		ExpiresAt:   token.ExpiresAt,
+		User:        User{ID: user.ID, Email: user.Email, Name: user.Name},
+		Actor:       principal.Subject,
+	}, nil)
+}
+
+// 1.- EndImpersonation revokes an impersonation token before it expires and records who ended it.
+func (h Handler) EndImpersonation(ctx *gin.Context) {
+	// 1.- Guard against missing impersonation dependencies to surface clear errors.
+	if h.impersonator == nil || h.impersonationAudit == nil {
+		respond.Error(ctx, http.StatusServiceUnavailable, "impersonation unavailable", map[string]interface{}{"reason": "not configured"})
+		return
+	}
+
+	// 2.- Resolve the administrator ending the session.
+	principal, ok := internalauth.PrincipalFromContext(ctx)
+	if !ok {
+		respond.Error(ctx, http.StatusUnauthorized, "authentication required", map[string]interface{}{"reason": "principal missing"})
+		return
+	}
+
+	// 3.- Blacklist the token; unknown, ended, and expired sessions look the same.
+	session, err := h.impersonator.EndImpersonation(ctx.Request.Context(), strings.TrimSpace(ctx.Param("token_id")))
+	if err != nil {
+		if errors.Is(err, internalauth.ErrImpersonationNotFound) {
+			respond.Error(ctx, http.StatusNotFound, "impersonation session not found", nil)
+			return
+		}
+		respond.Error(ctx, http.StatusInternalServerError, "failed to end impersonation", map[string]interface{}{"details": err.Error()})
+		return
+	}
+
+	// 4.- Keep the end of the session on the same audit trail as its start.
+	event := ImpersonationEvent{
+		Actor:     session.Actor,
+		Subject:   session.Subject,
+		TokenID:   session.TokenID,
+		Action:    ImpersonationActionEnd,
+		Reason:    "ended by " + principal.Subject,
+		Method:    ctx.Request.Method,
+		Path:      ctx.Request.URL.Path,
+		IP:        ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := h.impersonationAudit.RecordImpersonation(ctx.Request.Context(), event); err != nil {
+		respond.Error(ctx, http.StatusInternalServerError, "failed to record impersonation", map[string]interface{}{"details": err.Error()})
+		return
+	}
+
+	respond.Success(ctx, http.StatusOK, map[string]any{"status": "Impersonation session ended."}, nil)
+}
@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+)
+
+// 1.- JWKSProvider exposes the public verification keys published for token consumers.
+type JWKSProvider interface {
+	JWKS() internalauth.JWKS
+}
+
+// 1.- JWKS serves the RFC 7517 key set verbatim so standard JOSE clients can consume it.
+func JWKS(provider JWKSProvider) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		//1.- Allow caches to hold the set briefly while still picking up rotations quickly.
+		ctx.Header("Cache-Control", "public, max-age=300")
+		ctx.JSON(http.StatusOK, provider.JWKS())
+	}
+}
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/respond"
+)
+
+// 1.- LoginGuard tracks failed logins and enforces temporary lockouts per account and client IP.
+type LoginGuard interface {
+	CheckLogin(ctx context.Context, account string, ip string) (time.Duration, error)
+	RecordLoginFailure(ctx context.Context, account string, ip string) (time.Duration, error)
+	RecordLoginSuccess(ctx context.Context, account string) error
+	UnlockAccount(ctx context.Context, account string) error
+}
+
+// 1.- timingEqualizerHash is a bcrypt digest of a random phrase checked when an e-mail is unknown
+// so both failure paths spend the same time hashing; auth services implementing PasswordUpgrader use their own.
+const timingEqualizerHash = "$2a$12$/zEH0FeDEx6nZhyryp9sCuxqiCSdjIzBGD5WbynPfmm6AnpZGbuqK"
+
+// 1.- respondInvalidCredentials emits the single error shape used for every credential failure.
+func respondInvalidCredentials(ctx *gin.Context) {
+	respond.Error(ctx, http.StatusUnauthorized, "invalid credentials", map[string]interface{}{"reason": "email or password is incorrect"})
+}
+
+// 1.- respondLoginLocked tells the client how long to wait before retrying.
+func respondLoginLocked(ctx *gin.Context, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	ctx.Header("Retry-After", strconv.Itoa(seconds))
+	respond.Error(ctx, http.StatusTooManyRequests, "too many login attempts", map[string]interface{}{"retry_after": seconds})
+}
+
+// 1.- loginLocked reports whether the guard currently rejects the attempt, answering the request when it does.
+func (h Handler) loginLocked(ctx *gin.Context, account string) bool {
+	if h.lockout == nil {
+		return false
+	}
+	wait, err := h.lockout.CheckLogin(ctx.Request.Context(), account, ctx.ClientIP())
+	if err != nil {
+		if errors.Is(err, internalauth.ErrLoginLocked) {
+			respondLoginLocked(ctx, wait)
+			return true
+		}
+		respond.Error(ctx, http.StatusInternalServerError, "failed to check login throttle", map[string]interface{}{"details": err.Error()})
+		return true
+	}
+	return false
+}
+
+// 1.- loginFailed records the failure and answers with the uniform credential error.
+func (h Handler) loginFailed(ctx *gin.Context, account string) {
+	if h.lockout != nil {
+		if _, err := h.lockout.RecordLoginFailure(ctx.Request.Context(), account, ctx.ClientIP()); err != nil {
+			respond.Error(ctx, http.StatusInternalServerError, "failed to record login attempt", map[string]interface{}{"details": err.Error()})
+			return
+		}
+	}
+	respondInvalidCredentials(ctx)
+}
+
+// 1.- loginSucceeded clears the account's failure history once every factor passed, answering the request on error.
+func (h Handler) loginSucceeded(ctx *gin.Context, user User) bool {
+	if h.lockout == nil {
+		return true
+	}
+	if err := h.lockout.RecordLoginSuccess(ctx.Request.Context(), strings.ToLower(user.Email)); err != nil {
+		respond.Error(ctx, http.StatusInternalServerError, "failed to reset login attempts", map[string]interface{}{"details": err.Error()})
+		return false
+	}
+	return true
+}
+
+// 1.- UnlockAccount clears the lockout and failure history of a user on behalf of an administrator.
+func (h Handler) UnlockAccount(ctx *gin.Context) {
+	// 1.- Guard against missing lockout dependencies to surface clear errors.
+	if h.lockout == nil {
+		respond.Error(ctx, http.StatusServiceUnavailable, "login lockout unavailable", map[string]interface{}{"reason": "not configured"})
+		return
+	}
+
+	// 2.- Resolve the account key from the user identifier.
+	user, err := h.users.FindByID(ctx.Request.Context(), strings.TrimSpace(ctx.Param("id")))
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			respond.Error(ctx, http.StatusNotFound, "user not found", nil)
+			return
+		}
+		respond.Error(ctx, http.StatusInternalServerError, "failed to load user", map[string]interface{}{"details": err.Error()})
+		return
+	}
+
+	// 3.- Drop the lock so the user can sign in immediately.
+	if err := h.lockout.UnlockAccount(ctx.Request.Context(), strings.ToLower(user.Email)); err != nil {
+		respond.Error(ctx, http.StatusInternalServerError, "failed to unlock account", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	respond.Success(ctx, http.StatusOK, map[string]any{"unlocked": true}, nil)
+}
@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/Yamato-Go-Gin-API/internal/http/respond"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/validation"
+	"github.com/example/Yamato-Go-Gin-API/internal/queue"
+)
+
+// 1.- ErrInvalidMagicLink is returned when a link's signature, expiry, or token does not check out.
+var ErrInvalidMagicLink = errors.New("http/auth: invalid magic link")
+
+// 1.- PurposeMagicLink tags one-time tokens that sign a user in without a password.
+const PurposeMagicLink = "magic_link"
+
+// 1.- MagicLinker defines the passwordless e-mail login workflow consumed by the handlers.
+type MagicLinker interface {
+	Request(ctx context.Context, email string) error
+	Consume(ctx context.Context, token string, expires int64, signature string) (User, error)
+}
+
+// 1.- MagicLinkConfig tunes link lifetime, signing secret, and the page receiving the link.
+type MagicLinkConfig struct {
+	Secret  string
+	TTL     time.Duration
+	LinkURL string
+}
+
+// 1.- MagicLinkService e-mails signed single-use login links and redeems them.
+type MagicLinkService struct {
+	users  UserStore
+	tokens OneTimeTokenStore
+	mail   MailQueue
+	cfg    MagicLinkConfig
+	now    func() time.Time
+}
+
+// 1.- NewMagicLinkService wires the magic-link workflow with defaults for unset configuration.
+func NewMagicLinkService(users UserStore, tokens OneTimeTokenStore, mail MailQueue, cfg MagicLinkConfig) *MagicLinkService {
+	if strings.TrimSpace(cfg.Secret) == "" {
+		cfg.Secret = "development-magic-link-secret"
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 15 * time.Minute
+	}
+	if strings.TrimSpace(cfg.LinkURL) == "" {
+		cfg.LinkURL = "http://localhost:3000/magic-link"
+	}
+	return &MagicLinkService{users: users, tokens: tokens, mail: mail, cfg: cfg, now: time.Now}
+}
+
+// 1.- Request e-mails a login link when the account exists and stays silent otherwise.
+func (s *MagicLinkService) Request(ctx context.Context, email string) error {
+	user, err := s.users.FindByEmail(ctx, strings.TrimSpace(strings.ToLower(email)))
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	//1.- Persist only the digest; saving also supersedes any link sent earlier.
+	token, err := newOneTimeToken()
+	if err != nil {
+		return err
+	}
+	expiresAt := s.now().Add(s.cfg.TTL).Truncate(time.Second)
+	if err := s.tokens.Save(ctx, OneTimeToken{
+		Purpose:   PurposeMagicLink,
+		UserID:    user.ID,
+		TokenHash: HashOneTimeToken(token, s.cfg.Secret),
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return fmt.Errorf("http/auth: store magic link token: %w", err)
+	}
+
+	//2.- Sign the token together with its expiry so tampered links are rejected before storage is consulted.
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	link, err := appendQuery(s.cfg.LinkURL, url.Values{
+		"token":     {token},
+		"expires":   {expires},
+		"signature": {s.sign(token, expires)},
+	})
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf("Use the link below within %s to sign in:\n%s\n\nThe link works once. If you did not ask for it, you can ignore this message.", s.cfg.TTL, link)
+	if _, err := s.mail.Enqueue(ctx, queue.EmailSendJobName, map[string]any{
+		"to":      user.Email,
+		"subject": "Your sign-in link",
+		"body":    body,
+	}); err != nil {
+		return fmt.Errorf("http/auth: enqueue magic link email: %w", err)
+	}
+	return nil
+}
+
+// 1.- Consume checks the signature and expiry, then redeems the token exactly once.
+func (s *MagicLinkService) Consume(ctx context.Context, token string, expires int64, signature string) (User, error) {
+	token = strings.TrimSpace(token)
+	expected := s.sign(token, strconv.FormatInt(expires, 10))
+	if !hmac.Equal([]byte(expected), []byte(strings.TrimSpace(signature))) {
+		return User{}, ErrInvalidMagicLink
+	}
+	now := s.now()
+	if !now.Before(time.Unix(expires, 0)) {
+		return User{}, ErrInvalidMagicLink
+	}
+
+	record, err := s.tokens.Consume(ctx, PurposeMagicLink, HashOneTimeToken(token, s.cfg.Secret), now)
+	if err != nil {
+		if errors.Is(err, ErrTokenNotFound) {
+			return User{}, ErrInvalidMagicLink
+		}
+		return User{}, err
+	}
+	return s.users.FindByID(ctx, record.UserID)
+}
+
+// 1.- sign computes the hex HMAC-SHA256 binding a token to its expiry.
+func (s *MagicLinkService) sign(token string, expires string) string {
+	mac := hmac.New(sha256.New, []byte(s.cfg.Secret))
+	mac.Write([]byte(token + "|" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// 1.- magicLinkRequest captures the address that should receive the login link.
+type magicLinkRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// 1.- consumeMagicLinkRequest carries the query parameters of the e-mailed link.
+type consumeMagicLinkRequest struct {
+	Token     string `json:"token" validate:"required"`
+	Expires   int64  `json:"expires" validate:"required"`
+	Signature string `json:"signature" validate:"required"`
+}
+
+// 1.- RequestMagicLink queues a login link without revealing whether the account exists.
+func (h Handler) RequestMagicLink(ctx *gin.Context) {
+	// 1.- Guard against missing magic-link dependencies to surface clear errors.
+	if h.magicLinks == nil {
+		respond.Error(ctx, http.StatusServiceUnavailable, "magic link login unavailable", map[string]interface{}{"reason": "not configured"})
+		return
+	}
+
+	// 2.- Bind and validate the payload.
+	var req magicLinkRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Error(ctx, http.StatusBadRequest, "invalid request payload", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
+	if !h.validatePayload(ctx, req) {
+		return
+	}
+
+	// 3.- Delegate to the magic-link workflow.
+	if err := h.magicLinks.Request(ctx.Request.Context(), req.Email); err != nil {
+		respond.Error(ctx, http.StatusInternalServerError, "failed to request magic link", map[string]interface{}{"details": err.Error()})
+		return
+	}
+
+	// 4.- Answer identically for known and unknown addresses.
+	respond.Success(ctx, http.StatusAccepted, map[string]any{"status": "If the address is registered, a sign-in link has been sent."}, nil)
+}
+
+// 1.- ConsumeMagicLink redeems a login link and continues like a password login.
+func (h Handler) ConsumeMagicLink(ctx *gin.Context) {
+	// 1.- Guard against missing magic-link dependencies to surface clear errors.
+	if h.magicLinks == nil {
+		respond.Error(ctx, http.StatusServiceUnavailable, "magic link login unavailable", map[string]interface{}{"reason": "not configured"})
+		return
+	}
+
+	// 2.- Bind and validate the payload.
+	var req consumeMagicLinkRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Error(ctx, http.StatusBadRequest, "invalid request payload", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	req.Token = strings.TrimSpace(req.Token)
+	req.Signature = strings.TrimSpace(req.Signature)
+	if !h.validatePayload(ctx, req) {
+		return
+	}
+
+	// 3.- Redeem the link and translate domain errors.
+	user, err := h.magicLinks.Consume(ctx.Request.Context(), req.Token, req.Expires, req.Signature)
+	if err != nil {
+		if errors.Is(err, ErrInvalidMagicLink) || errors.Is(err, ErrUserNotFound) {
+			respond.Error(ctx, http.StatusBadRequest, "invalid magic link", map[string]interface{}{"fields": map[string][]validation.FieldError{
+				"token": []validation.FieldError{{Field: "token", Rule: "valid", Message: "sign-in link is invalid, expired, or already used"}},
+			}})
+			return
+		}
+		respond.Error(ctx, http.StatusInternalServerError, "failed to consume magic link", map[string]interface{}{"details": err.Error()})
+		return
+	}
+
+	// 4.- The mailbox stands in for the password; MFA still applies when enabled.
+	h.completeLogin(ctx, user)
+}
@@ -0,0 +1,450 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/respond"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/validation"
+)
+
+// 1.- ErrMFANotEnrolled is returned when a user has no TOTP enrollment on record.
+var ErrMFANotEnrolled = errors.New("http/auth: mfa not enrolled")
+
+// 1.- ErrMFAAlreadyEnabled prevents a confirmed enrollment from being silently replaced.
+var ErrMFAAlreadyEnabled = errors.New("http/auth: mfa already enabled")
+
+// 1.- ErrInvalidMFACode signals a wrong, replayed, or already used second factor code.
+var ErrInvalidMFACode = errors.New("http/auth: invalid mfa code")
+
+// 1.- MFAEnrollment captures a user's TOTP secret and confirmation state.
+type MFAEnrollment struct {
+	UserID string
+	Secret string
+	// 2.- ConfirmedAt stays zero until the user proves possession of the secret.
+	ConfirmedAt time.Time
+	// 3.- LastUsedStep blocks replays of a code inside its validity window.
+	LastUsedStep int64
+}
+
+// 1.- Enabled reports whether the enrollment has been confirmed.
+func (e MFAEnrollment) Enabled() bool {
+	return !e.ConfirmedAt.IsZero()
+}
+
+// 1.- MFAStore persists TOTP enrollments and hashed recovery codes.
+type MFAStore interface {
+	// 2.- SavePending stores an unconfirmed secret, replacing any previous unconfirmed one.
+	SavePending(ctx context.Context, userID string, secret string) error
+	// 3.- Find returns the enrollment or ErrMFANotEnrolled.
+	Find(ctx context.Context, userID string) (MFAEnrollment, error)
+	// 4.- Confirm activates the enrollment and replaces every recovery code hash.
+	Confirm(ctx context.Context, userID string, confirmedAt time.Time, step int64, recoveryHashes []string) error
+	// 5.- AdvanceStep records a used time step, reporting false when it is not newer than the last one.
+	AdvanceStep(ctx context.Context, userID string, step int64) (bool, error)
+	// 6.- ConsumeRecoveryCode burns an unused recovery code or returns ErrInvalidMFACode.
+	ConsumeRecoveryCode(ctx context.Context, userID string, codeHash string, now time.Time) error
+	// 7.- Delete removes the enrollment together with its recovery codes.
+	Delete(ctx context.Context, userID string) error
+}
+
+// 1.- MFAManager exposes the TOTP lifecycle consumed by the handlers.
+type MFAManager interface {
+	Enabled(ctx context.Context, userID string) (bool, error)
+	Enroll(ctx context.Context, userID string) (string, string, error)
+	Confirm(ctx context.Context, userID string, code string) ([]string, error)
+	Verify(ctx context.Context, userID string, code string) error
+	Disable(ctx context.Context, userID string, code string) error
+}
+
+// 1.- MFAPendingTokens issues and redeems the short-lived tokens bridging the two login steps.
+type MFAPendingTokens interface {
+	IssueMFAPending(ctx context.Context, subject string) (string, time.Time, error)
+	ExchangeMFAPending(ctx context.Context, token string, verify func(subject string) error) (internalauth.TokenPair, string, error)
+}
+
+// 1.- MFAConfig controls the otpauth issuer label and recovery code hashing.
+type MFAConfig struct {
+	Issuer        string
+	Secret        string
+	RecoveryCodes int
+}
+
+// 1.- MFAService implements MFAManager on top of an MFAStore.
+type MFAService struct {
+	users UserStore
+	store MFAStore
+	cfg   MFAConfig
+	now   func() time.Time
+}
+
+// 1.- NewMFAService wires the TOTP workflow with defaults for unset configuration.
+func NewMFAService(users UserStore, store MFAStore, cfg MFAConfig) *MFAService {
+	if strings.TrimSpace(cfg.Issuer) == "" {
+		cfg.Issuer = "Yamato"
+	}
+	if strings.TrimSpace(cfg.Secret) == "" {
+		cfg.Secret = "development-mfa-secret"
+	}
+	if cfg.RecoveryCodes <= 0 {
+		cfg.RecoveryCodes = 10
+	}
+	return &MFAService{users: users, store: store, cfg: cfg, now: time.Now}
+}
+
+// 1.- Enabled reports whether the user must present a second factor at login.
+func (s *MFAService) Enabled(ctx context.Context, userID string) (bool, error) {
+	enrollment, err := s.store.Find(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrMFANotEnrolled) {
+			return false, nil
+		}
+		return false, err
+	}
+	return enrollment.Enabled(), nil
+}
+
+// 1.- Enroll generates a fresh secret and returns it with the otpauth URI for authenticator apps.
+func (s *MFAService) Enroll(ctx context.Context, userID string) (string, string, error) {
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return "", "", err
+	}
+	if enabled, err := s.Enabled(ctx, userID); err != nil {
+		return "", "", err
+	} else if enabled {
+		return "", "", ErrMFAAlreadyEnabled
+	}
+
+	secret, err := internalauth.GenerateTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+	if err := s.store.SavePending(ctx, userID, secret); err != nil {
+		return "", "", fmt.Errorf("http/auth: store mfa enrollment: %w", err)
+	}
+	return secret, internalauth.TOTPURI(s.cfg.Issuer, user.Email, secret), nil
+}
+
+// 1.- Confirm activates a pending enrollment and returns the plaintext recovery codes exactly once.
+func (s *MFAService) Confirm(ctx context.Context, userID string, code string) ([]string, error) {
+	enrollment, err := s.store.Find(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enrollment.Enabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	now := s.now()
+	step, ok := internalauth.ValidateTOTP(enrollment.Secret, code, now)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	//1.- Only digests are stored; the caller must show the codes to the user now.
+	codes := make([]string, 0, s.cfg.RecoveryCodes)
+	hashes := make([]string, 0, s.cfg.RecoveryCodes)
+	for i := 0; i < s.cfg.RecoveryCodes; i++ {
+		recovery, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, recovery)
+		hashes = append(hashes, HashOneTimeToken(normalizeRecoveryCode(recovery), s.cfg.Secret))
+	}
+	if err := s.store.Confirm(ctx, userID, now, step, hashes); err != nil {
+		return nil, fmt.Errorf("http/auth: confirm mfa enrollment: %w", err)
+	}
+	return codes, nil
+}
+
+// 1.- Verify accepts either a current TOTP code or an unused recovery code.
+func (s *MFAService) Verify(ctx context.Context, userID string, code string) error {
+	enrollment, err := s.store.Find(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrMFANotEnrolled) {
+			return ErrInvalidMFACode
+		}
+		return err
+	}
+	if !enrollment.Enabled() {
+		return ErrInvalidMFACode
+	}
+
+	now := s.now()
+	code = strings.TrimSpace(code)
+	if step, ok := internalauth.ValidateTOTP(enrollment.Secret, code, now); ok {
+		advanced, err := s.store.AdvanceStep(ctx, userID, step)
+		if err != nil {
+			return err
+		}
+		if !advanced {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return ErrInvalidMFACode
+	}
+	return s.store.ConsumeRecoveryCode(ctx, userID, HashOneTimeToken(normalized, s.cfg.Secret), now)
+}
+
+// 1.- Disable removes the enrollment after the user proves possession of a second factor.
+func (s *MFAService) Disable(ctx context.Context, userID string, code string) error {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return err
+	}
+	return s.store.Delete(ctx, userID)
+}
+
+// 1.- newRecoveryCode returns a random code formatted as two groups of five characters.
+func newRecoveryCode() (string, error) {
+	buf := make([]byte, 7)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("http/auth: generate recovery code: %w", err)
+	}
+	encoded := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf))[:10]
+	return encoded[:5] + "-" + encoded[5:], nil
+}
+
+// 1.- normalizeRecoveryCode strips separators and case so users can type codes loosely.
+func normalizeRecoveryCode(code string) string {
+	replacer := strings.NewReplacer("-", "", " ", "")
+	return strings.ToLower(replacer.Replace(strings.TrimSpace(code)))
+}
+
+// 1.- mfaChallengeResponse tells clients to continue with the second login step.
+type mfaChallengeResponse struct {
+	MFARequired  bool      `json:"mfa_required"`
+	MFAToken     string    `json:"mfa_token"`
+	MFAExpiresAt time.Time `json:"mfa_expires_at"`
+}
+
+// 1.- mfaVerifyRequest exchanges an mfa_pending token and a code for real tokens.
+type mfaVerifyRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// 1.- mfaCodeRequest carries a single TOTP or recovery code.
+type mfaCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// 1.- mfaEnrollResponse returns the secret and otpauth URI for QR rendering.
+type mfaEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// 1.- mfaConfirmResponse returns the recovery codes generated on confirmation.
+type mfaConfirmResponse struct {
+	Enabled       bool     `json:"enabled"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// 1.- mfaAvailable guards MFA endpoints when the feature was not configured.
+func (h Handler) mfaAvailable(ctx *gin.Context) bool {
+	if h.mfa == nil || h.mfaTokens == nil {
+		respond.Error(ctx, http.StatusServiceUnavailable, "mfa unavailable", map[string]interface{}{"reason": "not configured"})
+		return false
+	}
+	return true
+}
+
+// 1.- respondInvalidMFACode renders the shared field error for rejected codes.
+func respondInvalidMFACode(ctx *gin.Context, status int) {
+	respond.Error(ctx, status, "invalid mfa code", map[string]interface{}{"fields": map[string][]validation.FieldError{
+		"code": []validation.FieldError{{Field: "code", Rule: "mfa", Message: "code is invalid or already used"}},
+	}})
+}
+
+// 1.- VerifyMFA completes the two-step login by exchanging the pending token for a token pair.
+func (h Handler) VerifyMFA(ctx *gin.Context) {
+	// 1.- Guard against missing MFA dependencies.
+	if !h.mfaAvailable(ctx) {
+		return
+	}
+
+	// 2.- Bind and validate the payload.
+	var req mfaVerifyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Error(ctx, http.StatusBadRequest, "invalid request payload", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	req.MFAToken = strings.TrimSpace(req.MFAToken)
+	req.Code = strings.TrimSpace(req.Code)
+	if !h.validatePayload(ctx, req) {
+		return
+	}
+
+	// 3.- Check the code for the pending subject and swap the token on success.
+	// Wrong codes count against the account lockout, so fresh pending tokens do not buy fresh guesses.
+	requestCtx := clientContext(ctx)
+	var (
+		user User
+		wait time.Duration
+	)
+	pair, _, err := h.mfaTokens.ExchangeMFAPending(requestCtx, req.MFAToken, func(subject string) error {
+		var err error
+		if user, err = h.users.FindByID(requestCtx, subject); err != nil {
+			return err
+		}
+		account := strings.ToLower(user.Email)
+		if h.lockout != nil {
+			if wait, err = h.lockout.CheckLogin(requestCtx, account, ctx.ClientIP()); err != nil {
+				return err
+			}
+		}
+		if err := h.mfa.Verify(requestCtx, subject, req.Code); err != nil {
+			if errors.Is(err, ErrInvalidMFACode) && h.lockout != nil {
+				if _, recordErr := h.lockout.RecordLoginFailure(requestCtx, account, ctx.ClientIP()); recordErr != nil {
+					return recordErr
+				}
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, internalauth.ErrLoginLocked):
+			respondLoginLocked(ctx, wait)
+		case errors.Is(err, ErrInvalidMFACode):
+			respondInvalidMFACode(ctx, http.StatusUnauthorized)
+		case errors.Is(err, internalauth.ErrInvalidToken):
+			respond.Error(ctx, http.StatusUnauthorized, "invalid mfa token", map[string]interface{}{"fields": map[string][]validation.FieldError{
+				"mfa_token": []validation.FieldError{{Field: "mfa_token", Rule: "valid", Message: "mfa token is invalid, expired, or exhausted"}},
+			}})
+		default:
+			respond.Error(ctx, http.StatusInternalServerError, "failed to verify mfa", map[string]interface{}{"details": err.Error()})
+		}
+		return
+	}
+
+	// 4.- Both factors passed, so the account's failure history is cleared.
+	if !h.loginSucceeded(ctx, user) {
+		return
+	}
+
+	// 5.- Mirror the login response so clients share one success path.
+	tokens, err := h.tokensFor(ctx, pair)
+	if err != nil {
+		respond.Error(ctx, http.StatusInternalServerError, "failed to issue tokens", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	respond.Success(ctx, http.StatusOK, loginResponse{User: User{ID: user.ID, Email: user.Email, Name: user.Name}, Tokens: tokens}, nil)
+}
+
+// 1.- EnrollMFA starts TOTP enrollment for the authenticated user.
+func (h Handler) EnrollMFA(ctx *gin.Context) {
+	// 1.- Guard against missing MFA dependencies and anonymous callers.
+	if !h.mfaAvailable(ctx) {
+		return
+	}
+	principal, ok := internalauth.PrincipalFromContext(ctx)
+	if !ok {
+		respond.Error(ctx, http.StatusUnauthorized, "authentication required", map[string]interface{}{"reason": "principal missing"})
+		return
+	}
+
+	// 2.- Generate the secret and hand back the otpauth URI.
+	secret, uri, err := h.mfa.Enroll(ctx.Request.Context(), principal.Subject)
+	if err != nil {
+		if errors.Is(err, ErrMFAAlreadyEnabled) {
+			respond.Error(ctx, http.StatusConflict, "mfa already enabled", map[string]interface{}{"reason": "disable mfa before enrolling again"})
+			return
+		}
+		respond.Error(ctx, http.StatusInternalServerError, "failed to enroll mfa", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	respond.Success(ctx, http.StatusCreated, mfaEnrollResponse{Secret: secret, OTPAuthURI: uri}, nil)
+}
+
+// 1.- ConfirmMFA activates the pending enrollment once the user submits a valid code.
+func (h Handler) ConfirmMFA(ctx *gin.Context) {
+	// 1.- Guard against missing MFA dependencies and anonymous callers.
+	if !h.mfaAvailable(ctx) {
+		return
+	}
+	principal, ok := internalauth.PrincipalFromContext(ctx)
+	if !ok {
+		respond.Error(ctx, http.StatusUnauthorized, "authentication required", map[string]interface{}{"reason": "principal missing"})
+		return
+	}
+
+	// 2.- Bind and validate the payload.
+	var req mfaCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Error(ctx, http.StatusBadRequest, "invalid request payload", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	req.Code = strings.TrimSpace(req.Code)
+	if !h.validatePayload(ctx, req) {
+		return
+	}
+
+	// 3.- Confirm and return recovery codes that will never be shown again.
+	codes, err := h.mfa.Confirm(ctx.Request.Context(), principal.Subject, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidMFACode):
+			respondInvalidMFACode(ctx, http.StatusBadRequest)
+		case errors.Is(err, ErrMFANotEnrolled):
+			respond.Error(ctx, http.StatusNotFound, "mfa not enrolled", map[string]interface{}{"reason": "start enrollment first"})
+		case errors.Is(err, ErrMFAAlreadyEnabled):
+			respond.Error(ctx, http.StatusConflict, "mfa already enabled", map[string]interface{}{"reason": "enrollment already confirmed"})
+		default:
+			respond.Error(ctx, http.StatusInternalServerError, "failed to confirm mfa", map[string]interface{}{"details": err.Error()})
+		}
+		return
+	}
+	respond.Success(ctx, http.StatusOK, mfaConfirmResponse{Enabled: true, RecoveryCodes: codes}, nil)
+}
+
+// 1.- DisableMFA removes the enrollment after re-verifying a second factor.
+func (h Handler) DisableMFA(ctx *gin.Context) {
+	// 1.- Guard against missing MFA dependencies and anonymous callers.
+	if !h.mfaAvailable(ctx) {
+		return
+	}
+	principal, ok := internalauth.PrincipalFromContext(ctx)
+	if !ok {
+		respond.Error(ctx, http.StatusUnauthorized, "authentication required", map[string]interface{}{"reason": "principal missing"})
+		return
+	}
+
+	// 2.- Bind and validate the payload.
+	var req mfaCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Error(ctx, http.StatusBadRequest, "invalid request payload", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	req.Code = strings.TrimSpace(req.Code)
+	if !h.validatePayload(ctx, req) {
+		return
+	}
+
+	// 3.- Delegate to the MFA service.
+	if err := h.mfa.Disable(ctx.Request.Context(), principal.Subject, req.Code); err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			respondInvalidMFACode(ctx, http.StatusBadRequest)
+			return
+		}
+		respond.Error(ctx, http.StatusInternalServerError, "failed to disable mfa", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	respond.Success(ctx, http.StatusOK, map[string]any{"enabled": false}, nil)
+}
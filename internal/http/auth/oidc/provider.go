@@ -0,0 +1,364 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+)
+
+// 1.- ErrInvalidIDToken indicates that the identity provider returned an ID token that failed validation.
+var ErrInvalidIDToken = errors.New("oidc: invalid id token")
+
+// 1.- ErrExchangeFailed indicates that the token endpoint rejected the authorization code.
+var ErrExchangeFailed = errors.New("oidc: code exchange failed")
+
+// 1.- Identity is the verified subset of ID token claims used to link local accounts.
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// 1.- AuthRequest carries the per-login values bound into the authorization redirect.
+type AuthRequest struct {
+	State         string
+	Nonce         string
+	CodeChallenge string
+}
+
+// 1.- IdentityProvider abstracts an OpenID Connect issuer running the authorization-code + PKCE flow.
+type IdentityProvider interface {
+	// 2.- Name is the stable slug used in routes such as /v1/auth/sso/:provider.
+	Name() string
+	// 3.- AuthCodeURL builds the authorization endpoint URL the browser is redirected to.
+	AuthCodeURL(ctx context.Context, req AuthRequest) (string, error)
+	// 4.- Exchange redeems the code with its PKCE verifier and returns the validated identity.
+	Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (Identity, error)
+}
+
+// 1.- Config describes a confidential OIDC client registered with an issuer.
+type Config struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// 1.- Provider is a discovery-driven IdentityProvider backed by any standards-compliant issuer.
+type Provider struct {
+	cfg    Config
+	client *http.Client
+	now    func() time.Time
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+	keys      map[string]verificationKey
+	keysAt    time.Time
+}
+
+// 1.- discoveryDocument captures the fields read from /.well-known/openid-configuration.
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// 1.- verificationKey binds a JWKS entry to the algorithm it may verify.
+type verificationKey struct {
+	key    interface{}
+	method jwt.SigningMethod
+}
+
+// 1.- idTokenClaims lists the ID token claims validated during the exchange.
+type idTokenClaims struct {
+	Nonce         string `json:"nonce"`
+	Email         string `json:"email"`
+	EmailVerified any    `json:"email_verified"`
+	Name          string `json:"name"`
+	jwt.RegisteredClaims
+}
+
+// 1.- keyRefreshInterval bounds how often the JWKS is re-fetched when an unknown kid appears.
+const keyRefreshInterval = time.Minute
+
+// 1.- NewProvider validates the client configuration; discovery runs lazily on first use.
+func NewProvider(cfg Config, client *http.Client) (*Provider, error) {
+	cfg.Name = strings.TrimSpace(strings.ToLower(cfg.Name))
+	cfg.Issuer = strings.TrimRight(strings.TrimSpace(cfg.Issuer), "/")
+	if cfg.Name == "" || cfg.Issuer == "" || cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return nil, errors.New("oidc: name, issuer, client id, and redirect url are required")
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Provider{cfg: cfg, client: client, now: time.Now}, nil
+}
+
+// 1.- Name returns the provider slug.
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+// 1.- AuthCodeURL renders the authorization request with S256 PKCE, state, and nonce.
+func (p *Provider) AuthCodeURL(ctx context.Context, req AuthRequest) (string, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	endpoint, err := url.Parse(doc.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("oidc: invalid authorization endpoint: %w", err)
+	}
+	query := endpoint.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.cfg.ClientID)
+	query.Set("redirect_uri", p.cfg.RedirectURL)
+	query.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	query.Set("state", req.State)
+	query.Set("nonce", req.Nonce)
+	query.Set("code_challenge", req.CodeChallenge)
+	query.Set("code_challenge_method", "S256")
+	endpoint.RawQuery = query.Encode()
+	return endpoint.String(), nil
+}
+
+// 1.- Exchange posts the code to the token endpoint and validates the returned ID token.
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (Identity, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	//1.- Authenticate as a confidential client with HTTP Basic, the OIDC default.
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", p.cfg.ClientID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Identity{}, fmt.Errorf("oidc: build token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	var tokenResponse struct {
+		IDToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	status, err := p.doJSON(req, &tokenResponse)
+	if err != nil {
+		return Identity{}, err
+	}
+	if status != http.StatusOK || tokenResponse.IDToken == "" {
+		return Identity{}, fmt.Errorf("%w: status %d %s", ErrExchangeFailed, status, tokenResponse.Error)
+	}
+
+	return p.verifyIDToken(ctx, doc, tokenResponse.IDToken, nonce)
+}
+
+// 1.- verifyIDToken checks signature, issuer, audience, expiry, and nonce before trusting any claim.
+func (p *Provider) verifyIDToken(ctx context.Context, doc *discoveryDocument, raw string, nonce string) (Identity, error) {
+	claims := &idTokenClaims{}
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(doc.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithTimeFunc(p.now),
+		jwt.WithLeeway(time.Minute),
+	)
+	_, err := parser.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := p.key(ctx, doc, kid)
+		if err != nil {
+			return nil, err
+		}
+		if key.method.Alg() != token.Method.Alg() {
+			return nil, ErrInvalidIDToken
+		}
+		return key.key, nil
+	})
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if claims.Nonce == "" || claims.Nonce != nonce {
+		return Identity{}, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+	if claims.Subject == "" {
+		return Identity{}, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+
+	return Identity{
+		Provider:      p.cfg.Name,
+		Subject:       claims.Subject,
+		Email:         strings.TrimSpace(strings.ToLower(claims.Email)),
+		EmailVerified: claims.EmailVerified == true || claims.EmailVerified == "true",
+		Name:          claims.Name,
+	}, nil
+}
+
+// 1.- key looks up a signing key by kid, refreshing the JWKS at most once per interval for rotations.
+func (p *Provider) key(ctx context.Context, doc *discoveryDocument, kid string) (verificationKey, error) {
+	lookup := func(keys map[string]verificationKey) (verificationKey, bool) {
+		if kid == "" && len(keys) == 1 {
+			for _, key := range keys {
+				return key, true
+			}
+		}
+		key, ok := keys[kid]
+		return key, ok
+	}
+
+	//1.- Serve cached keys and the refresh throttle under the lock, but never hold it across the fetch.
+	p.mu.Lock()
+	key, ok := lookup(p.keys)
+	throttled := !p.keysAt.IsZero() && p.now().Sub(p.keysAt) < keyRefreshInterval
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+	if throttled {
+		return verificationKey{}, fmt.Errorf("%w: unknown key %q", ErrInvalidIDToken, kid)
+	}
+
+	//2.- Fetch and parse the JWKS without the lock so a slow issuer cannot stall other logins.
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, doc.JWKSURI, nil)
+	if err != nil {
+		return verificationKey{}, fmt.Errorf("oidc: build jwks request: %w", err)
+	}
+	var set internalauth.JWKS
+	status, err := p.doJSON(req, &set)
+	if err != nil {
+		return verificationKey{}, err
+	}
+	if status != http.StatusOK {
+		return verificationKey{}, fmt.Errorf("oidc: jwks returned status %d", status)
+	}
+	keys := map[string]verificationKey{}
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		public, method, err := internalauth.ParseJWK(jwk)
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = verificationKey{key: public, method: method}
+	}
+
+	//3.- Lock only to swap in the fresh key set.
+	p.mu.Lock()
+	p.keys = keys
+	p.keysAt = p.now()
+	p.mu.Unlock()
+
+	if key, ok := lookup(keys); ok {
+		return key, nil
+	}
+	return verificationKey{}, fmt.Errorf("%w: unknown key %q", ErrInvalidIDToken, kid)
+}
+
+// 1.- discover fetches and caches the issuer metadata, insisting the issuer matches the configuration.
+func (p *Provider) discover(ctx context.Context) (*discoveryDocument, error) {
+	//1.- Read the cache under the lock; the fetch below runs without it.
+	p.mu.Lock()
+	cached := p.discovery
+	p.mu.Unlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	//2.- Fetch and validate the metadata.
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: build discovery request: %w", err)
+	}
+	var doc discoveryDocument
+	status, err := p.doJSON(req, &doc)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("oidc: discovery returned status %d", status)
+	}
+	if strings.TrimRight(doc.Issuer, "/") != p.cfg.Issuer {
+		return nil, fmt.Errorf("oidc: discovery issuer %q does not match %q", doc.Issuer, p.cfg.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, errors.New("oidc: discovery document is missing endpoints")
+	}
+
+	//3.- Keep whichever document landed first when concurrent callers raced the fetch.
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery == nil {
+		p.discovery = &doc
+	}
+	return p.discovery, nil
+}
+
+// 1.- doJSON performs the request and decodes a bounded JSON body.
+func (p *Provider) doJSON(req *http.Request, target interface{}) (int, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("oidc: request %s: %w", req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(target); err != nil {
+		return resp.StatusCode, fmt.Errorf("oidc: decode %s: %w", req.URL.Path, err)
+	}
+	return resp.StatusCode, nil
+}
+
+// 1.- NewCodeVerifier returns a high-entropy PKCE verifier (RFC 7636 section 4.1).
+func NewCodeVerifier() (string, error) {
+	return randomToken(32)
+}
+
+// 1.- CodeChallenge derives the S256 challenge for a verifier.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// 1.- NewState returns an opaque random value suitable for state and nonce parameters.
+func NewState() (string, error) {
+	return randomToken(24)
+}
+
+// 1.- randomToken encodes n random bytes as unpadded base64url.
+func randomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("oidc: generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
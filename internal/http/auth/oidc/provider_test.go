@@ -0,0 +1,147 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/example/Yamato-Go-Gin-API/internal/http/auth/oidc"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/auth/oidc/oidctest"
+)
+
+// 1.- startLogin runs the authorization leg against the stub and returns the code plus the PKCE verifier.
+func startLogin(t *testing.T, server *oidctest.Server, provider *oidc.Provider, nonce string) (string, string) {
+	t.Helper()
+	verifier, err := oidc.NewCodeVerifier()
+	require.NoError(t, err)
+	authURL, err := provider.AuthCodeURL(context.Background(), oidc.AuthRequest{State: "state-1", Nonce: nonce, CodeChallenge: oidc.CodeChallenge(verifier)})
+	require.NoError(t, err)
+
+	callback, err := server.Authorize(authURL)
+	require.NoError(t, err)
+	parsed, err := url.Parse(callback)
+	require.NoError(t, err)
+	require.Equal(t, "state-1", parsed.Query().Get("state"))
+	return parsed.Query().Get("code"), verifier
+}
+
+// 1.- TestProviderExchangeValidatesIDToken covers the happy path and the checks guarding each claim.
+func TestProviderExchangeValidatesIDToken(t *testing.T) {
+	server, err := oidctest.NewServer("yamato", "client-secret")
+	require.NoError(t, err)
+	defer server.Close()
+	server.SetUser(oidctest.User{Subject: "idp-123", Email: "Jane@Example.com", EmailVerified: true, Name: "Jane"})
+
+	provider, err := oidc.NewProvider(oidc.Config{Name: "Corp", Issuer: server.Issuer(), ClientID: "yamato", ClientSecret: "client-secret", RedirectURL: "http://localhost/callback"}, server.Client())
+	require.NoError(t, err)
+	require.Equal(t, "corp", provider.Name())
+	ctx := context.Background()
+
+	// 2.- A correct verifier and nonce yield the normalized identity.
+	code, verifier := startLogin(t, server, provider, "nonce-1")
+	identity, err := provider.Exchange(ctx, code, verifier, "nonce-1")
+	require.NoError(t, err)
+	require.Equal(t, oidc.Identity{Provider: "corp", Subject: "idp-123", Email: "jane@example.com", EmailVerified: true, Name: "Jane"}, identity)
+
+	// 3.- Codes are single use.
+	_, err = provider.Exchange(ctx, code, verifier, "nonce-1")
+	require.ErrorIs(t, err, oidc.ErrExchangeFailed)
+
+	// 4.- A wrong PKCE verifier is rejected by the issuer.
+	code, _ = startLogin(t, server, provider, "nonce-2")
+	other, err := oidc.NewCodeVerifier()
+	require.NoError(t, err)
+	_, err = provider.Exchange(ctx, code, other, "nonce-2")
+	require.ErrorIs(t, err, oidc.ErrExchangeFailed)
+
+	// 5.- A nonce that does not match the stored value is rejected locally.
+	code, verifier = startLogin(t, server, provider, "nonce-3")
+	_, err = provider.Exchange(ctx, code, verifier, "another-nonce")
+	require.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+
+	// 6.- Tokens minted for another audience or already expired are rejected.
+	for _, mutate := range []func(jwt.MapClaims){
+		func(claims jwt.MapClaims) { claims["aud"] = "someone-else" },
+		func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() },
+		func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" },
+	} {
+		server.Mutate = mutate
+		code, verifier = startLogin(t, server, provider, "nonce-4")
+		_, err = provider.Exchange(ctx, code, verifier, "nonce-4")
+		require.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+	}
+}
+
+// 1.- TestProviderRejectsWrongClientSecret ensures confidential client authentication is sent.
+func TestProviderRejectsWrongClientSecret(t *testing.T) {
+	server, err := oidctest.NewServer("yamato", "client-secret")
+	require.NoError(t, err)
+	defer server.Close()
+	server.SetUser(oidctest.User{Subject: "idp-1", Email: "a@example.com", EmailVerified: true})
+
+	provider, err := oidc.NewProvider(oidc.Config{Name: "corp", Issuer: server.Issuer(), ClientID: "yamato", ClientSecret: "wrong", RedirectURL: "http://localhost/callback"}, server.Client())
+	require.NoError(t, err)
+
+	code, verifier := startLogin(t, server, provider, "nonce")
+	_, err = provider.Exchange(context.Background(), code, verifier, "nonce")
+	require.ErrorIs(t, err, oidc.ErrExchangeFailed)
+}
+
+// 1.- stallingTransport parks JWKS requests until released so tests can observe the provider mid-fetch.
+type stallingTransport struct {
+	next    http.RoundTripper
+	started chan struct{}
+	release chan struct{}
+}
+
+// 1.- RoundTrip blocks only the JWKS path and forwards everything else.
+func (s stallingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Path == "/jwks" {
+		s.started <- struct{}{}
+		<-s.release
+	}
+	return s.next.RoundTrip(req)
+}
+
+// 1.- TestProviderDoesNotLockAcrossFetches keeps other logins moving while a JWKS fetch is slow.
+func TestProviderDoesNotLockAcrossFetches(t *testing.T) {
+	server, err := oidctest.NewServer("yamato", "client-secret")
+	require.NoError(t, err)
+	defer server.Close()
+	server.SetUser(oidctest.User{Subject: "idp-1", Email: "a@example.com", EmailVerified: true})
+
+	transport := stallingTransport{next: server.Client().Transport, started: make(chan struct{}, 1), release: make(chan struct{})}
+	provider, err := oidc.NewProvider(oidc.Config{Name: "corp", Issuer: server.Issuer(), ClientID: "yamato", ClientSecret: "client-secret", RedirectURL: "http://localhost/callback"}, &http.Client{Transport: transport})
+	require.NoError(t, err)
+
+	// 2.- Park an exchange inside the JWKS fetch.
+	code, verifier := startLogin(t, server, provider, "nonce")
+	done := make(chan error, 1)
+	go func() {
+		_, err := provider.Exchange(context.Background(), code, verifier, "nonce")
+		done <- err
+	}()
+	<-transport.started
+
+	// 3.- Starting another login still completes while the fetch is outstanding.
+	started := make(chan error, 1)
+	go func() {
+		_, err := provider.AuthCodeURL(context.Background(), oidc.AuthRequest{State: "state-2", Nonce: "nonce-2", CodeChallenge: oidc.CodeChallenge(verifier)})
+		started <- err
+	}()
+	select {
+	case err := <-started:
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("AuthCodeURL blocked behind the JWKS fetch")
+	}
+
+	// 4.- Releasing the fetch lets the exchange finish normally.
+	close(transport.release)
+	require.NoError(t, <-done)
+}
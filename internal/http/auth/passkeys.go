@@ -0,0 +1,478 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/auth/webauthn"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/respond"
+)
+
+// 1.- ErrPasskeyNotFound is returned when a passkey is unknown or owned by someone else.
+var ErrPasskeyNotFound = errors.New("http/auth: passkey not found")
+
+// 1.- ErrPasskeyExists indicates that the credential id is already registered.
+var ErrPasskeyExists = errors.New("http/auth: passkey already registered")
+
+// 1.- ErrInvalidPasskeyChallenge indicates that the ceremony is unknown, expired, replayed, or of the wrong kind.
+var ErrInvalidPasskeyChallenge = errors.New("http/auth: invalid passkey challenge")
+
+// 1.- Passkey is a WebAuthn credential registered to a user.
+type Passkey struct {
+	ID             string
+	UserID         string
+	Name           string
+	CredentialID   []byte
+	PublicKey      []byte
+	SignCount      uint32
+	AAGUID         []byte
+	Transports     []string
+	BackupEligible bool
+	LastUsedAt     time.Time
+	CreatedAt      time.Time
+}
+
+// 1.- PasskeyStore persists passkeys keyed by their credential id.
+type PasskeyStore interface {
+	// 2.- Create stores the passkey or returns ErrPasskeyExists for a duplicate credential id.
+	Create(ctx context.Context, passkey Passkey) (Passkey, error)
+	// 3.- FindByCredentialID loads a passkey for login or returns ErrPasskeyNotFound.
+	FindByCredentialID(ctx context.Context, credentialID []byte) (Passkey, error)
+	// 4.- ListByUser returns the user's passkeys, newest first.
+	ListByUser(ctx context.Context, userID string) ([]Passkey, error)
+	// 5.- UpdateSignCount records the counter and time of the latest successful assertion.
+	UpdateSignCount(ctx context.Context, id string, signCount uint32, usedAt time.Time) error
+	// 6.- Delete removes a passkey owned by the user or returns ErrPasskeyNotFound.
+	Delete(ctx context.Context, userID string, id string) error
+}
+
+// 1.- PasskeyManager defines the registration and login ceremonies consumed by the handlers.
+type PasskeyManager interface {
+	BeginRegistration(ctx context.Context, user User) (webauthn.CreationOptions, error)
+	FinishRegistration(ctx context.Context, user User, name string, response webauthn.RegistrationResponse) (Passkey, error)
+	List(ctx context.Context, userID string) ([]Passkey, error)
+	Delete(ctx context.Context, userID string, id string) error
+	BeginLogin(ctx context.Context, email string) (webauthn.RequestOptions, error)
+	FinishLogin(ctx context.Context, response webauthn.AssertionResponse) (User, bool, error)
+}
+
+// 1.- PasskeyService runs WebAuthn ceremonies with single-use challenges kept in Redis.
+type PasskeyService struct {
+	users UserStore
+	store PasskeyStore
+	redis internalauth.RedisCommander
+	rp    *webauthn.RelyingParty
+	now   func() time.Time
+}
+
+// 1.- passkeyCeremony is the server-side half of an outstanding ceremony, keyed by its challenge.
+type passkeyCeremony struct {
+	Kind   string `json:"kind"`
+	UserID string `json:"user_id,omitempty"`
+}
+
+// 1.- Ceremony kinds stored alongside challenges.
+const (
+	passkeyCeremonyRegister = "register"
+	passkeyCeremonyLogin    = "login"
+)
+
+// 1.- NewPasskeyService wires the stores and relying party together.
+func NewPasskeyService(users UserStore, store PasskeyStore, redis internalauth.RedisCommander, rp *webauthn.RelyingParty) *PasskeyService {
+	return &PasskeyService{users: users, store: store, redis: redis, rp: rp, now: time.Now}
+}
+
+// 1.- BeginRegistration issues creation options that exclude the user's existing passkeys.
+func (s *PasskeyService) BeginRegistration(ctx context.Context, user User) (webauthn.CreationOptions, error) {
+	existing, err := s.store.ListByUser(ctx, user.ID)
+	if err != nil {
+		return webauthn.CreationOptions{}, err
+	}
+	exclude := make([]webauthn.CredentialDescriptor, 0, len(existing))
+	for _, passkey := range existing {
+		exclude = append(exclude, webauthn.CredentialDescriptor{Type: "public-key", ID: passkey.CredentialID, Transports: passkey.Transports})
+	}
+
+	challenge, err := s.storeCeremony(ctx, passkeyCeremony{Kind: passkeyCeremonyRegister, UserID: user.ID})
+	if err != nil {
+		return webauthn.CreationOptions{}, err
+	}
+	displayName := user.Name
+	if displayName == "" {
+		displayName = user.Email
+	}
+	return s.rp.CreationOptions(webauthn.UserEntity{ID: []byte(user.ID), Name: user.Email, DisplayName: displayName}, challenge, exclude), nil
+}
+
+// 1.- FinishRegistration verifies the attestation for the user's outstanding challenge and stores the credential.
+func (s *PasskeyService) FinishRegistration(ctx context.Context, user User, name string, response webauthn.RegistrationResponse) (Passkey, error) {
+	challenge, err := s.consumeCeremony(ctx, response.Response.ClientDataJSON, passkeyCeremony{Kind: passkeyCeremonyRegister, UserID: user.ID})
+	if err != nil {
+		return Passkey{}, err
+	}
+	credential, err := s.rp.VerifyRegistration(response, challenge)
+	if err != nil {
+		return Passkey{}, err
+	}
+	if name == "" {
+		name = "Passkey"
+	}
+	return s.store.Create(ctx, Passkey{
+		UserID:         user.ID,
+		Name:           name,
+		CredentialID:   credential.ID,
+		PublicKey:      credential.PublicKey,
+		SignCount:      credential.SignCount,
+		AAGUID:         credential.AAGUID,
+		Transports:     credential.Transports,
+		BackupEligible: credential.BackupEligible,
+	})
+}
+
+// 1.- List returns the user's passkeys.
+func (s *PasskeyService) List(ctx context.Context, userID string) ([]Passkey, error) {
+	return s.store.ListByUser(ctx, userID)
+}
+
+// 1.- Delete removes one of the user's passkeys.
+func (s *PasskeyService) Delete(ctx context.Context, userID string, id string) error {
+	return s.store.Delete(ctx, userID, id)
+}
+
+// 1.- BeginLogin issues request options; unknown e-mails get the same discoverable options to avoid enumeration.
+func (s *PasskeyService) BeginLogin(ctx context.Context, email string) (webauthn.RequestOptions, error) {
+	allow := []webauthn.CredentialDescriptor{}
+	if email = strings.TrimSpace(email); email != "" {
+		user, err := s.users.FindByEmail(ctx, email)
+		switch {
+		case err == nil:
+			passkeys, err := s.store.ListByUser(ctx, user.ID)
+			if err != nil {
+				return webauthn.RequestOptions{}, err
+			}
+			for _, passkey := range passkeys {
+				allow = append(allow, webauthn.CredentialDescriptor{Type: "public-key", ID: passkey.CredentialID, Transports: passkey.Transports})
+			}
+		case !errors.Is(err, ErrUserNotFound):
+			return webauthn.RequestOptions{}, err
+		}
+	}
+
+	challenge, err := s.storeCeremony(ctx, passkeyCeremony{Kind: passkeyCeremonyLogin})
+	if err != nil {
+		return webauthn.RequestOptions{}, err
+	}
+	return s.rp.RequestOptions(challenge, allow), nil
+}
+
+// 1.- FinishLogin verifies the assertion, advances the sign count, and reports whether the user was verified.
+func (s *PasskeyService) FinishLogin(ctx context.Context, response webauthn.AssertionResponse) (User, bool, error) {
+	challenge, err := s.consumeCeremony(ctx, response.Response.ClientDataJSON, passkeyCeremony{Kind: passkeyCeremonyLogin})
+	if err != nil {
+		return User{}, false, err
+	}
+
+	passkey, err := s.store.FindByCredentialID(ctx, response.RawID)
+	if err != nil {
+		return User{}, false, err
+	}
+	//1.- A discoverable credential names its owner; it must agree with the stored record.
+	if len(response.Response.UserHandle) > 0 && string(response.Response.UserHandle) != passkey.UserID {
+		return User{}, false, fmt.Errorf("%w: user handle mismatch", webauthn.ErrInvalidResponse)
+	}
+
+	assertion, err := s.rp.VerifyAssertion(response, challenge, passkey.PublicKey, passkey.SignCount)
+	if err != nil {
+		return User{}, false, err
+	}
+	if err := s.store.UpdateSignCount(ctx, passkey.ID, assertion.SignCount, s.now().UTC()); err != nil {
+		return User{}, false, err
+	}
+
+	user, err := s.users.FindByID(ctx, passkey.UserID)
+	if err != nil {
+		return User{}, false, err
+	}
+	return user, assertion.UserVerified, nil
+}
+
+// 1.- storeCeremony generates a challenge and remembers what it was issued for.
+func (s *PasskeyService) storeCeremony(ctx context.Context, ceremony passkeyCeremony) ([]byte, error) {
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(ceremony)
+	if err != nil {
+		return nil, fmt.Errorf("http/auth: encode passkey ceremony: %w", err)
+	}
+	if err := s.redis.Set(ctx, passkeyChallengeKey(challenge), string(payload), s.rp.Timeout()).Err(); err != nil {
+		return nil, fmt.Errorf("http/auth: store passkey challenge: %w", err)
+	}
+	return challenge, nil
+}
+
+// 1.- consumeCeremony redeems the challenge named in the client data once and checks it matches the expected ceremony.
+func (s *PasskeyService) consumeCeremony(ctx context.Context, clientDataJSON []byte, expected passkeyCeremony) ([]byte, error) {
+	challenge, err := webauthn.ChallengeFromClientData(clientDataJSON)
+	if err != nil {
+		return nil, ErrInvalidPasskeyChallenge
+	}
+	key := passkeyChallengeKey(challenge)
+	raw, err := s.redis.Get(ctx, key).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrInvalidPasskeyChallenge
+		}
+		return nil, fmt.Errorf("http/auth: load passkey challenge: %w", err)
+	}
+	removed, err := s.redis.Del(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("http/auth: delete passkey challenge: %w", err)
+	}
+	if removed == 0 {
+		return nil, ErrInvalidPasskeyChallenge
+	}
+
+	var stored passkeyCeremony
+	if err := json.Unmarshal([]byte(raw), &stored); err != nil || stored != expected {
+		return nil, ErrInvalidPasskeyChallenge
+	}
+	return challenge, nil
+}
+
+// 1.- passkeyChallengeKey namespaces outstanding ceremonies by challenge.
+func passkeyChallengeKey(challenge []byte) string {
+	return "auth:passkey-challenge:" + base64.RawURLEncoding.EncodeToString(challenge)
+}
+
+// 1.- registerPasskeyRequest names the passkey and carries the attestation response.
+type registerPasskeyRequest struct {
+	Name       string                        `json:"name"`
+	Credential webauthn.RegistrationResponse `json:"credential"`
+}
+
+// 1.- passkeyLoginOptionsRequest optionally narrows login to one account's passkeys.
+type passkeyLoginOptionsRequest struct {
+	Email string `json:"email"`
+}
+
+// 1.- passkeyLoginRequest carries the assertion response.
+type passkeyLoginRequest struct {
+	Credential webauthn.AssertionResponse `json:"credential"`
+}
+
+// 1.- passkeyResponse is the JSON view of a passkey; key material is never included.
+type passkeyResponse struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	CredentialID string     `json:"credential_id"`
+	Transports   []string   `json:"transports"`
+	Synced       bool       `json:"synced"`
+	LastUsedAt   *time.Time `json:"last_used_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// 1.- toPasskeyResponse renders optional fields as null or empty arrays.
+func toPasskeyResponse(passkey Passkey) passkeyResponse {
+	var lastUsed *time.Time
+	if !passkey.LastUsedAt.IsZero() {
+		value := passkey.LastUsedAt
+		lastUsed = &value
+	}
+	transports := passkey.Transports
+	if transports == nil {
+		transports = []string{}
+	}
+	return passkeyResponse{
+		ID:           passkey.ID,
+		Name:         passkey.Name,
+		CredentialID: base64.RawURLEncoding.EncodeToString(passkey.CredentialID),
+		Transports:   transports,
+		Synced:       passkey.BackupEligible,
+		LastUsedAt:   lastUsed,
+		CreatedAt:    passkey.CreatedAt,
+	}
+}
+
+// 1.- passkeyUser guards passkey management; only interactive sessions may change sign-in methods.
+func (h Handler) passkeyUser(ctx *gin.Context) (User, bool) {
+	if h.passkeys == nil {
+		respond.Error(ctx, http.StatusServiceUnavailable, "passkeys unavailable", map[string]interface{}{"reason": "not configured"})
+		return User{}, false
+	}
+	principal, ok := internalauth.PrincipalFromContext(ctx)
+	if !ok {
+		respond.Error(ctx, http.StatusUnauthorized, "authentication required", map[string]interface{}{"reason": "principal missing"})
+		return User{}, false
+	}
+	if principal.TokenID != "" {
+		respond.Error(ctx, http.StatusForbidden, "access tokens cannot manage passkeys", map[string]interface{}{"reason": "interactive session required"})
+		return User{}, false
+	}
+	user, err := h.users.FindByID(ctx.Request.Context(), principal.Subject)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			respond.Error(ctx, http.StatusNotFound, "user not found", nil)
+			return User{}, false
+		}
+		respond.Error(ctx, http.StatusInternalServerError, "failed to load user", map[string]interface{}{"details": err.Error()})
+		return User{}, false
+	}
+	return user, true
+}
+
+// 1.- PasskeyRegistrationOptions starts registering a passkey for the caller.
+func (h Handler) PasskeyRegistrationOptions(ctx *gin.Context) {
+	// 1.- Resolve the caller.
+	user, ok := h.passkeyUser(ctx)
+	if !ok {
+		return
+	}
+
+	// 2.- Hand the browser the options for navigator.credentials.create.
+	options, err := h.passkeys.BeginRegistration(ctx.Request.Context(), user)
+	if err != nil {
+		respond.Error(ctx, http.StatusInternalServerError, "failed to start passkey registration", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	respond.Success(ctx, http.StatusOK, map[string]any{"public_key": options}, nil)
+}
+
+// 1.- RegisterPasskey verifies the authenticator response and stores the new passkey.
+func (h Handler) RegisterPasskey(ctx *gin.Context) {
+	// 1.- Resolve the caller.
+	user, ok := h.passkeyUser(ctx)
+	if !ok {
+		return
+	}
+
+	// 2.- Bind the attestation response.
+	var req registerPasskeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Error(ctx, http.StatusBadRequest, "invalid request payload", map[string]interface{}{"details": err.Error()})
+		return
+	}
+
+	// 3.- Verify and persist the credential.
+	passkey, err := h.passkeys.FinishRegistration(ctx.Request.Context(), user, strings.TrimSpace(req.Name), req.Credential)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidPasskeyChallenge), errors.Is(err, webauthn.ErrInvalidResponse), errors.Is(err, webauthn.ErrUnsupportedKey):
+			respond.Error(ctx, http.StatusBadRequest, "passkey registration failed", map[string]interface{}{"details": err.Error()})
+		case errors.Is(err, ErrPasskeyExists):
+			respond.Error(ctx, http.StatusConflict, "passkey already registered", nil)
+		default:
+			respond.Error(ctx, http.StatusInternalServerError, "failed to register passkey", map[string]interface{}{"details": err.Error()})
+		}
+		return
+	}
+	respond.Success(ctx, http.StatusCreated, toPasskeyResponse(passkey), nil)
+}
+
+// 1.- ListPasskeys returns the caller's passkeys.
+func (h Handler) ListPasskeys(ctx *gin.Context) {
+	user, ok := h.passkeyUser(ctx)
+	if !ok {
+		return
+	}
+	passkeys, err := h.passkeys.List(ctx.Request.Context(), user.ID)
+	if err != nil {
+		respond.Error(ctx, http.StatusInternalServerError, "failed to list passkeys", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	items := make([]passkeyResponse, 0, len(passkeys))
+	for _, passkey := range passkeys {
+		items = append(items, toPasskeyResponse(passkey))
+	}
+	respond.Success(ctx, http.StatusOK, items, map[string]interface{}{"total": len(items)})
+}
+
+// 1.- DeletePasskey removes one of the caller's passkeys.
+func (h Handler) DeletePasskey(ctx *gin.Context) {
+	user, ok := h.passkeyUser(ctx)
+	if !ok {
+		return
+	}
+	if err := h.passkeys.Delete(ctx.Request.Context(), user.ID, strings.TrimSpace(ctx.Param("id"))); err != nil {
+		if errors.Is(err, ErrPasskeyNotFound) {
+			respond.Error(ctx, http.StatusNotFound, "passkey not found", nil)
+			return
+		}
+		respond.Error(ctx, http.StatusInternalServerError, "failed to delete passkey", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	respond.Success(ctx, http.StatusOK, map[string]any{"deleted": true}, nil)
+}
+
+// 1.- PasskeyLoginOptions starts a passkey login, optionally for a given e-mail.
+func (h Handler) PasskeyLoginOptions(ctx *gin.Context) {
+	// 1.- Guard against missing passkey dependencies to surface clear errors.
+	if h.passkeys == nil {
+		respond.Error(ctx, http.StatusServiceUnavailable, "passkeys unavailable", map[string]interface{}{"reason": "not configured"})
+		return
+	}
+
+	// 2.- The body is optional; an empty one requests discoverable credentials.
+	var req passkeyLoginOptionsRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			respond.Error(ctx, http.StatusBadRequest, "invalid request payload", map[string]interface{}{"details": err.Error()})
+			return
+		}
+	}
+
+	// 3.- Hand the browser the options for navigator.credentials.get.
+	options, err := h.passkeys.BeginLogin(ctx.Request.Context(), strings.ToLower(req.Email))
+	if err != nil {
+		respond.Error(ctx, http.StatusInternalServerError, "failed to start passkey login", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	respond.Success(ctx, http.StatusOK, map[string]any{"public_key": options}, nil)
+}
+
+// 1.- PasskeyLogin verifies an assertion and signs the user in.
+func (h Handler) PasskeyLogin(ctx *gin.Context) {
+	// 1.- Guard against missing passkey dependencies to surface clear errors.
+	if h.passkeys == nil {
+		respond.Error(ctx, http.StatusServiceUnavailable, "passkeys unavailable", map[string]interface{}{"reason": "not configured"})
+		return
+	}
+
+	// 2.- Bind the assertion response.
+	var req passkeyLoginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Error(ctx, http.StatusBadRequest, "invalid request payload", map[string]interface{}{"details": err.Error()})
+		return
+	}
+
+	// 3.- Verify the assertion against the stored credential.
+	user, verified, err := h.passkeys.FinishLogin(ctx.Request.Context(), req.Credential)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidPasskeyChallenge), errors.Is(err, webauthn.ErrInvalidResponse), errors.Is(err, webauthn.ErrUnsupportedKey),
+			errors.Is(err, webauthn.ErrSignCountRegression), errors.Is(err, ErrPasskeyNotFound), errors.Is(err, ErrUserNotFound):
+			respond.Error(ctx, http.StatusUnauthorized, "passkey login failed", map[string]interface{}{"details": err.Error()})
+		default:
+			respond.Error(ctx, http.StatusInternalServerError, "passkey login failed", map[string]interface{}{"details": err.Error()})
+		}
+		return
+	}
+
+	// 4.- A user-verified passkey already proves two factors; otherwise continue like password login.
+	if verified {
+		h.issueLogin(ctx, user)
+		return
+	}
+	h.completeLogin(ctx, user)
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/Yamato-Go-Gin-API/internal/http/auth/passwordpolicy"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/respond"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/validation"
+)
+
+// 1.- PasswordPolicy rejects weak, identity-derived, or breached passwords with localized field errors.
+type PasswordPolicy interface {
+	Validate(field string, password string, identity passwordpolicy.Identity, locale string) []validation.FieldError
+}
+
+// 1.- WithPasswordPolicy enforces the policy wherever a new password is chosen.
+func WithPasswordPolicy(policy PasswordPolicy) HandlerOption {
+	return func(h *Handler) {
+		h.passwordPolicy = policy
+	}
+}
+
+// 1.- enforcePasswordPolicy answers 400 with the policy violations and reports whether the password is acceptable.
+func (h Handler) enforcePasswordPolicy(ctx *gin.Context, password string, identity passwordpolicy.Identity) bool {
+	if h.passwordPolicy == nil {
+		return true
+	}
+	violations := h.passwordPolicy.Validate("password", password, identity, requestLocale(ctx))
+	if len(violations) == 0 {
+		return true
+	}
+	errs := validation.Errors{Fields: map[string][]validation.FieldError{"password": violations}}
+	respond.Error(ctx, http.StatusBadRequest, "validation failed", errs.ToMap())
+	return false
+}
+
+// 1.- requestLocale picks the first language tag from Accept-Language; the translator falls back to English.
+func requestLocale(ctx *gin.Context) string {
+	header := ctx.GetHeader("Accept-Language")
+	if comma := strings.IndexByte(header, ','); comma >= 0 {
+		header = header[:comma]
+	}
+	if semicolon := strings.IndexByte(header, ';'); semicolon >= 0 {
+		header = header[:semicolon]
+	}
+	return strings.TrimSpace(header)
+}
@@ -0,0 +1,238 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/Yamato-Go-Gin-API/internal/http/auth/passwordpolicy"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/respond"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/validation"
+	"github.com/example/Yamato-Go-Gin-API/internal/queue"
+)
+
+// 1.- PasswordResetter defines the forgot/reset workflow consumed by the handlers.
+type PasswordResetter interface {
+	Request(ctx context.Context, email string) error
+	Account(ctx context.Context, token string) (User, error)
+	Reset(ctx context.Context, token string, password string) error
+}
+
+// 1.- PasswordResetAuth captures the auth service features needed to rotate credentials.
+type PasswordResetAuth interface {
+	HashPassword(password string) (string, error)
+	SessionRevoker
+}
+
+// 1.- PasswordResetConfig tunes token lifetime, hashing secret, and the link sent to users.
+type PasswordResetConfig struct {
+	Secret   string
+	TTL      time.Duration
+	ResetURL string
+}
+
+// 1.- PasswordResetService issues reset links and swaps passwords once a token is redeemed.
+type PasswordResetService struct {
+	users  UserStore
+	tokens OneTimeTokenStore
+	mail   MailQueue
+	auth   PasswordResetAuth
+	cfg    PasswordResetConfig
+	now    func() time.Time
+}
+
+// 1.- NewPasswordResetService wires the reset workflow with defaults for unset configuration.
+func NewPasswordResetService(users UserStore, tokens OneTimeTokenStore, mail MailQueue, auth PasswordResetAuth, cfg PasswordResetConfig) *PasswordResetService {
+	if strings.TrimSpace(cfg.Secret) == "" {
+		cfg.Secret = "development-password-reset-secret"
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 30 * time.Minute
+	}
+	if strings.TrimSpace(cfg.ResetURL) == "" {
+		cfg.ResetURL = "http://localhost:3000/reset-password"
+	}
+	return &PasswordResetService{users: users, tokens: tokens, mail: mail, auth: auth, cfg: cfg, now: time.Now}
+}
+
+// 1.- Request e-mails a reset link when the account exists and stays silent otherwise.
+func (s *PasswordResetService) Request(ctx context.Context, email string) error {
+	user, err := s.users.FindByEmail(ctx, strings.TrimSpace(strings.ToLower(email)))
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	//1.- Persist only the digest so a leaked table cannot be replayed.
+	token, err := newOneTimeToken()
+	if err != nil {
+		return err
+	}
+	if err := s.tokens.Save(ctx, OneTimeToken{
+		Purpose:   PurposePasswordReset,
+		UserID:    user.ID,
+		TokenHash: HashOneTimeToken(token, s.cfg.Secret),
+		ExpiresAt: s.now().Add(s.cfg.TTL),
+	}); err != nil {
+		return fmt.Errorf("http/auth: store reset token: %w", err)
+	}
+
+	//1.- Hand delivery to the email worker so the request path never blocks on SMTP.
+	link, err := appendQuery(s.cfg.ResetURL, url.Values{"token": {token}})
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf("We received a request to reset your password.\n\nUse the link below within %s to choose a new one:\n%s\n\nIf you did not ask for this, you can ignore this message.", s.cfg.TTL, link)
+	if _, err := s.mail.Enqueue(ctx, queue.EmailSendJobName, map[string]any{
+		"to":      user.Email,
+		"subject": "Reset your password",
+		"body":    body,
+	}); err != nil {
+		return fmt.Errorf("http/auth: enqueue reset email: %w", err)
+	}
+	return nil
+}
+
+// 1.- Account resolves the user a live reset token belongs to without redeeming it.
+func (s *PasswordResetService) Account(ctx context.Context, token string) (User, error) {
+	record, err := s.tokens.Find(ctx, PurposePasswordReset, HashOneTimeToken(token, s.cfg.Secret), s.now())
+	if err != nil {
+		return User{}, err
+	}
+	return s.users.FindByID(ctx, record.UserID)
+}
+
+// 1.- Reset redeems the token, stores the new password hash, and revokes every existing session.
+func (s *PasswordResetService) Reset(ctx context.Context, token string, password string) error {
+	record, err := s.tokens.Consume(ctx, PurposePasswordReset, HashOneTimeToken(token, s.cfg.Secret), s.now())
+	if err != nil {
+		return err
+	}
+
+	hashed, err := s.auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := s.users.UpdatePassword(ctx, record.UserID, hashed); err != nil {
+		return err
+	}
+	if err := s.auth.RevokeAll(ctx, record.UserID); err != nil {
+		return fmt.Errorf("http/auth: revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// 1.- appendQuery merges the supplied values into the base URL's query string.
+func appendQuery(base string, values url.Values) (string, error) {
+	parsed, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("http/auth: invalid link base %q: %w", base, err)
+	}
+	query := parsed.Query()
+	for key, entries := range values {
+		for _, entry := range entries {
+			query.Add(key, entry)
+		}
+	}
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
+
+// 1.- forgotPasswordRequest captures the address that should receive the reset link.
+type forgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// 1.- resetPasswordRequest carries the emailed token and the replacement password.
+type resetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// 1.- ForgotPassword queues a reset link without revealing whether the account exists.
+func (h Handler) ForgotPassword(ctx *gin.Context) {
+	// 1.- Guard against missing reset dependencies to surface clear errors.
+	if h.resets == nil {
+		respond.Error(ctx, http.StatusServiceUnavailable, "password reset unavailable", map[string]interface{}{"reason": "not configured"})
+		return
+	}
+
+	// 2.- Bind and validate the payload.
+	var req forgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Error(ctx, http.StatusBadRequest, "invalid request payload", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
+	if !h.validatePayload(ctx, req) {
+		return
+	}
+
+	// 3.- Delegate to the reset workflow.
+	if err := h.resets.Request(ctx.Request.Context(), req.Email); err != nil {
+		respond.Error(ctx, http.StatusInternalServerError, "failed to request password reset", map[string]interface{}{"details": err.Error()})
+		return
+	}
+
+	// 4.- Answer identically for known and unknown addresses.
+	respond.Success(ctx, http.StatusAccepted, map[string]any{"status": "If the address is registered, a reset link has been sent."}, nil)
+}
+
+// 1.- ResetPassword redeems a reset token and replaces the stored password.
+func (h Handler) ResetPassword(ctx *gin.Context) {
+	// 1.- Guard against missing reset dependencies to surface clear errors.
+	if h.resets == nil {
+		respond.Error(ctx, http.StatusServiceUnavailable, "password reset unavailable", map[string]interface{}{"reason": "not configured"})
+		return
+	}
+
+	// 2.- Bind and validate the payload.
+	var req resetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Error(ctx, http.StatusBadRequest, "invalid request payload", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	req.Token = strings.TrimSpace(req.Token)
+	req.Password = strings.TrimSpace(req.Password)
+	if !h.validatePayload(ctx, req) {
+		return
+	}
+
+	// 3.- Check the policy against the token's account first so a rejected password does not burn the single-use token.
+	user, err := h.resets.Account(ctx.Request.Context(), req.Token)
+	if err != nil {
+		h.respondResetError(ctx, err)
+		return
+	}
+	if !h.enforcePasswordPolicy(ctx, req.Password, passwordpolicy.Identity{Email: user.Email, Name: user.Name}) {
+		return
+	}
+
+	// 4.- Redeem the token and translate domain errors.
+	if err := h.resets.Reset(ctx.Request.Context(), req.Token, req.Password); err != nil {
+		h.respondResetError(ctx, err)
+		return
+	}
+
+	// 5.- Confirm the reset; clients must log in again because every session was revoked.
+	respond.Success(ctx, http.StatusOK, map[string]any{"reset": true}, nil)
+}
+
+// 1.- respondResetError reports unknown, expired, or orphaned tokens as a field error and anything else as a 500.
+func (h Handler) respondResetError(ctx *gin.Context, err error) {
+	if errors.Is(err, ErrTokenNotFound) || errors.Is(err, ErrUserNotFound) {
+		respond.Error(ctx, http.StatusBadRequest, "invalid reset token", map[string]interface{}{"fields": map[string][]validation.FieldError{
+			"token": []validation.FieldError{{Field: "token", Rule: "valid", Message: "reset token is invalid or expired"}},
+		}})
+		return
+	}
+	respond.Error(ctx, http.StatusInternalServerError, "failed to reset password", map[string]interface{}{"details": err.Error()})
+}
@@ -0,0 +1,136 @@
+package passwordpolicy
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// 1.- bloomMagic identifies the serialized filter format and its version.
+const bloomMagic = "YBF1"
+
+// 1.- ErrInvalidFilter reports a serialized filter that is truncated or was written by another format.
+var ErrInvalidFilter = errors.New("passwordpolicy: invalid breached-password filter")
+
+// 1.- BloomFilter is a fixed-size set of SHA-1 password digests that answers "definitely not" or "probably" breached.
+// Only digests are stored, so the bundled file never contains the plaintext list.
+type BloomFilter struct {
+	bits   []uint64
+	size   uint64
+	hashes uint32
+}
+
+// 1.- NewBloomFilter sizes a filter for the expected number of entries at the requested false-positive rate.
+func NewBloomFilter(entries int, falsePositiveRate float64) *BloomFilter {
+	if entries < 1 {
+		entries = 1
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.001
+	}
+	//1.- m = -n·ln(p)/ln(2)² bits and k = m/n·ln(2) hash functions minimise the false-positive rate.
+	size := uint64(math.Ceil(-float64(entries) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	hashes := uint32(math.Max(1, math.Round(float64(size)/float64(entries)*math.Ln2)))
+	return &BloomFilter{bits: make([]uint64, (size+63)/64), size: size, hashes: hashes}
+}
+
+// 1.- Add records a plaintext password.
+func (f *BloomFilter) Add(password string) {
+	f.AddDigest(sha1.Sum([]byte(password)))
+}
+
+// 1.- AddDigest records a SHA-1 digest, as published by breach corpora such as Pwned Passwords.
+func (f *BloomFilter) AddDigest(digest [sha1.Size]byte) {
+	h1, h2 := splitDigest(digest)
+	for i := uint32(0); i < f.hashes; i++ {
+		bit := (h1 + uint64(i)*h2) % f.size
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// 1.- AddHexDigest records a hex-encoded SHA-1 digest, ignoring an optional ":count" suffix.
+func (f *BloomFilter) AddHexDigest(line string) error {
+	value := strings.TrimSpace(line)
+	if colon := strings.IndexByte(value, ':'); colon >= 0 {
+		value = value[:colon]
+	}
+	raw, err := hex.DecodeString(value)
+	if err != nil || len(raw) != sha1.Size {
+		return fmt.Errorf("passwordpolicy: invalid sha1 digest %q", line)
+	}
+	var digest [sha1.Size]byte
+	copy(digest[:], raw)
+	f.AddDigest(digest)
+	return nil
+}
+
+// 1.- MightContain reports whether the password was probably added; false is always accurate.
+func (f *BloomFilter) MightContain(password string) bool {
+	if f == nil || f.size == 0 {
+		return false
+	}
+	h1, h2 := splitDigest(sha1.Sum([]byte(password)))
+	for i := uint32(0); i < f.hashes; i++ {
+		bit := (h1 + uint64(i)*h2) % f.size
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// 1.- WriteTo serializes the filter as magic, hash count, bit size, and the little-endian bit words.
+func (f *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	header := make([]byte, len(bloomMagic)+4+8)
+	copy(header, bloomMagic)
+	binary.LittleEndian.PutUint32(header[4:], f.hashes)
+	binary.LittleEndian.PutUint64(header[8:], f.size)
+	n, err := w.Write(header)
+	written := int64(n)
+	if err != nil {
+		return written, err
+	}
+	body := make([]byte, 8*len(f.bits))
+	for i, word := range f.bits {
+		binary.LittleEndian.PutUint64(body[8*i:], word)
+	}
+	n, err = w.Write(body)
+	return written + int64(n), err
+}
+
+// 1.- ReadBloomFilter parses a filter produced by WriteTo.
+func ReadBloomFilter(r io.Reader) (*BloomFilter, error) {
+	header := make([]byte, len(bloomMagic)+4+8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, ErrInvalidFilter
+	}
+	if string(header[:4]) != bloomMagic {
+		return nil, ErrInvalidFilter
+	}
+	hashes := binary.LittleEndian.Uint32(header[4:])
+	size := binary.LittleEndian.Uint64(header[8:])
+	if hashes == 0 || size == 0 || size > 1<<36 {
+		return nil, ErrInvalidFilter
+	}
+	body := make([]byte, 8*((size+63)/64))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, ErrInvalidFilter
+	}
+	bits := make([]uint64, len(body)/8)
+	for i := range bits {
+		bits[i] = binary.LittleEndian.Uint64(body[8*i:])
+	}
+	return &BloomFilter{bits: bits, size: size, hashes: hashes}, nil
+}
+
+// 1.- splitDigest derives the two base hashes for Kirsch–Mitzenmacher double hashing.
+func splitDigest(digest [sha1.Size]byte) (uint64, uint64) {
+	h1 := binary.BigEndian.Uint64(digest[0:8])
+	h2 := binary.BigEndian.Uint64(digest[8:16]) | 1
+	return h1, h2
+}
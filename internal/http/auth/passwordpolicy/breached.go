@@ -0,0 +1,50 @@
+package passwordpolicy
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+//go:generate go run ../../../../cmd/tools/breachfilter -in data/common-passwords.txt -out data/breached.bloom
+
+// 1.- bundledFilter is the bloom filter generated from data/common-passwords.txt.
+//
+//go:embed data/breached.bloom
+var bundledFilter []byte
+
+// 1.- Cached parse of the bundled filter so every policy shares one copy.
+var (
+	defaultFilterOnce sync.Once
+	defaultFilter     *BloomFilter
+	defaultFilterErr  error
+)
+
+// 1.- DefaultBreachedFilter returns the filter compiled into the binary; it needs no network or files.
+func DefaultBreachedFilter() (*BloomFilter, error) {
+	defaultFilterOnce.Do(func() {
+		defaultFilter, defaultFilterErr = ReadBloomFilter(bytes.NewReader(bundledFilter))
+	})
+	return defaultFilter, defaultFilterErr
+}
+
+// 1.- LoadBreachedFilter reads a filter from disk, falling back to the bundled one when path is empty.
+// Larger corpora, such as the full Pwned Passwords SHA-1 dump, can be compiled with cmd/tools/breachfilter.
+func LoadBreachedFilter(path string) (*BloomFilter, error) {
+	if strings.TrimSpace(path) == "" {
+		return DefaultBreachedFilter()
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("passwordpolicy: open breached filter: %w", err)
+	}
+	defer file.Close()
+	filter, err := ReadBloomFilter(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, path)
+	}
+	return filter, nil
+}
@@ -0,0 +1,298 @@
+package passwordpolicy
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/example/Yamato-Go-Gin-API/internal/config"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/validation"
+	"github.com/example/Yamato-Go-Gin-API/internal/i18n"
+)
+
+// 1.- Rule names reported in validation.FieldError.Rule for each kind of violation.
+const (
+	RuleMinLength = "min"
+	RuleMaxLength = "max"
+	RuleUpper     = "uppercase"
+	RuleLower     = "lowercase"
+	RuleDigit     = "digit"
+	RuleSymbol    = "symbol"
+	RuleSimilar   = "similar_identity"
+	RuleBreached  = "breached"
+)
+
+// 1.- Defaults follow NIST SP 800-63B: a length floor, a generous ceiling, and no forced composition.
+const (
+	DefaultMinLength = 8
+	DefaultMaxLength = 128
+)
+
+// 1.- similarityThreshold is the normalised edit-distance similarity above which a password mirrors the identity.
+const similarityThreshold = 0.7
+
+// 1.- minIdentityFragment ignores identity fragments too short to be meaningful, such as initials.
+const minIdentityFragment = 4
+
+// 1.- fallbackMessages are used when the translation catalogue cannot be loaded.
+var fallbackMessages = map[string]string{
+	RuleMinLength: "Password must be at least {param} characters long.",
+	RuleMaxLength: "Password must be at most {param} characters long.",
+	RuleUpper:     "Password must contain an uppercase letter.",
+	RuleLower:     "Password must contain a lowercase letter.",
+	RuleDigit:     "Password must contain a digit.",
+	RuleSymbol:    "Password must contain a symbol.",
+	RuleSimilar:   "Password is too similar to your email address or name.",
+	RuleBreached:  "This password has appeared in a data breach; choose a different one.",
+}
+
+// 1.- Config selects which rules the policy enforces.
+type Config struct {
+	// 2.- MinLength and MaxLength count characters, not bytes; zero selects the defaults.
+	MinLength int
+	MaxLength int
+	// 3.- Require* demand at least one character of the class.
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// 4.- RejectSimilar refuses passwords that contain or closely resemble the e-mail or name.
+	RejectSimilar bool
+	// 5.- Breached is consulted offline; nil disables the breached-password check.
+	Breached *BloomFilter
+}
+
+// 1.- Identity carries the account attributes a password must not resemble.
+type Identity struct {
+	Email string
+	Name  string
+}
+
+// 1.- Violation is a single failed rule with its optional parameter.
+type Violation struct {
+	Rule  string
+	Param string
+}
+
+// 1.- Policy evaluates candidate passwords against the configured rules.
+type Policy struct {
+	cfg Config
+}
+
+// 1.- New builds a Policy, filling unset length bounds with the defaults.
+func New(cfg Config) *Policy {
+	if cfg.MinLength <= 0 {
+		cfg.MinLength = DefaultMinLength
+	}
+	if cfg.MaxLength <= 0 {
+		cfg.MaxLength = DefaultMaxLength
+	}
+	if cfg.MaxLength < cfg.MinLength {
+		cfg.MaxLength = cfg.MinLength
+	}
+	return &Policy{cfg: cfg}
+}
+
+// 1.- NewFromConfig builds the policy described by the password configuration section.
+func NewFromConfig(cfg config.PasswordConfig) (*Policy, error) {
+	policy := Config{
+		MinLength:     cfg.MinLength,
+		MaxLength:     cfg.MaxLength,
+		RequireUpper:  cfg.RequireUpper,
+		RequireLower:  cfg.RequireLower,
+		RequireDigit:  cfg.RequireDigit,
+		RequireSymbol: cfg.RequireSymbol,
+		RejectSimilar: cfg.RejectSimilar,
+	}
+	if cfg.CheckBreached {
+		filter, err := LoadBreachedFilter(cfg.BreachedFilter)
+		if err != nil {
+			return nil, err
+		}
+		policy.Breached = filter
+	}
+	return New(policy), nil
+}
+
+// 1.- Check returns every rule the password breaks; an empty result means it is acceptable.
+func (p *Policy) Check(password string, identity Identity) []Violation {
+	var violations []Violation
+
+	//1.- Length is measured in runes so multi-byte characters are not penalised.
+	//    An overlong password is refused outright so the costlier checks never see unbounded input.
+	length := utf8.RuneCountInString(password)
+	if length > p.cfg.MaxLength {
+		return []Violation{{Rule: RuleMaxLength, Param: strconv.Itoa(p.cfg.MaxLength)}}
+	}
+	if length < p.cfg.MinLength {
+		violations = append(violations, Violation{Rule: RuleMinLength, Param: strconv.Itoa(p.cfg.MinLength)})
+	}
+
+	//2.- Character classes are only checked when the deployment opts into composition rules.
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.cfg.RequireUpper && !upper {
+		violations = append(violations, Violation{Rule: RuleUpper})
+	}
+	if p.cfg.RequireLower && !lower {
+		violations = append(violations, Violation{Rule: RuleLower})
+	}
+	if p.cfg.RequireDigit && !digit {
+		violations = append(violations, Violation{Rule: RuleDigit})
+	}
+	if p.cfg.RequireSymbol && !symbol {
+		violations = append(violations, Violation{Rule: RuleSymbol})
+	}
+
+	//3.- Similarity and breach checks run last because they are the most expensive.
+	if p.cfg.RejectSimilar && resemblesIdentity(password, identity) {
+		violations = append(violations, Violation{Rule: RuleSimilar})
+	}
+	if p.cfg.Breached.MightContain(password) {
+		violations = append(violations, Violation{Rule: RuleBreached})
+	}
+	return violations
+}
+
+// 1.- Validate checks the password and renders violations as field errors in the requested locale.
+func (p *Policy) Validate(field string, password string, identity Identity, locale string) []validation.FieldError {
+	violations := p.Check(password, identity)
+	if len(violations) == 0 {
+		return nil
+	}
+	//1.- A missing catalogue degrades to the English fallbacks rather than failing the request.
+	translator, _ := i18n.New(locale)
+	errs := make([]validation.FieldError, 0, len(violations))
+	for _, violation := range violations {
+		errs = append(errs, validation.FieldError{
+			Field:   field,
+			Rule:    violation.Rule,
+			Param:   violation.Param,
+			Message: message(translator, violation),
+		})
+	}
+	return errs
+}
+
+// 1.- message resolves validation.password.<rule> and substitutes the rule parameter.
+func message(translator *i18n.Translator, violation Violation) string {
+	key := "validation.password." + violation.Rule
+	text := fallbackMessages[violation.Rule]
+	if translator != nil {
+		if translated := translator.Translate(key); translated != key {
+			text = translated
+		}
+	}
+	return strings.ReplaceAll(text, "{param}", violation.Param)
+}
+
+// 1.- resemblesIdentity compares the password against the e-mail local part, the full address, and the name.
+func resemblesIdentity(password string, identity Identity) bool {
+	candidate := normalize(password)
+	if candidate == "" {
+		return false
+	}
+	for _, fragment := range identityFragments(identity) {
+		if strings.Contains(candidate, fragment) || strings.Contains(fragment, candidate) {
+			return true
+		}
+		if similarity(candidate, fragment) >= similarityThreshold {
+			return true
+		}
+	}
+	return false
+}
+
+// 1.- identityFragments lists the normalised pieces of the identity worth comparing.
+func identityFragments(identity Identity) []string {
+	var raw []string
+	email := strings.TrimSpace(identity.Email)
+	if at := strings.LastIndex(email, "@"); at > 0 {
+		raw = append(raw, email[:at], email)
+		raw = append(raw, strings.FieldsFunc(email[:at], isSeparator)...)
+	} else if email != "" {
+		raw = append(raw, email)
+	}
+	if name := strings.TrimSpace(identity.Name); name != "" {
+		raw = append(raw, name)
+		raw = append(raw, strings.Fields(name)...)
+	}
+
+	fragments := make([]string, 0, len(raw))
+	seen := make(map[string]struct{}, len(raw))
+	for _, value := range raw {
+		normalized := normalize(value)
+		if utf8.RuneCountInString(normalized) < minIdentityFragment {
+			continue
+		}
+		if _, ok := seen[normalized]; ok {
+			continue
+		}
+		seen[normalized] = struct{}{}
+		fragments = append(fragments, normalized)
+	}
+	return fragments
+}
+
+// 1.- normalize lowercases and keeps only letters and digits so "J.Doe-1" and "jdoe1" compare equal.
+func normalize(value string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(value) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+// 1.- isSeparator splits e-mail local parts such as "jane.doe+work".
+func isSeparator(r rune) bool {
+	return r == '.' || r == '_' || r == '-' || r == '+'
+}
+
+// 1.- similarity is 1 minus the Levenshtein distance divided by the longer length.
+func similarity(a string, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// 1.- levenshtein computes the edit distance with a single reusable row.
+func levenshtein(a []rune, b []rune) int {
+	row := make([]int, len(b)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		previous := row[0]
+		row[0] = i
+		for j := 1; j <= len(b); j++ {
+			current := row[j]
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			row[j] = min(row[j]+1, row[j-1]+1, previous+cost)
+			previous = current
+		}
+	}
+	return row[len(b)]
+}
@@ -0,0 +1,103 @@
+package passwordpolicy_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/example/Yamato-Go-Gin-API/internal/http/auth/passwordpolicy"
+)
+
+// 1.- rules extracts the rule names from a violation list.
+func rules(violations []passwordpolicy.Violation) []string {
+	names := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		names = append(names, violation.Rule)
+	}
+	return names
+}
+
+// 1.- TestPolicyRules covers length, character classes, and identity similarity.
+func TestPolicyRules(t *testing.T) {
+	policy := passwordpolicy.New(passwordpolicy.Config{
+		MinLength:     10,
+		MaxLength:     16,
+		RequireUpper:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+		RejectSimilar: true,
+	})
+	identity := passwordpolicy.Identity{Email: "maria.lopez@example.com", Name: "María López"}
+
+	// 2.- Length is counted in characters and both bounds are reported with their parameter.
+	short := policy.Check("Ab1!", identity)
+	require.Equal(t, []string{passwordpolicy.RuleMinLength}, rules(short))
+	require.Equal(t, "10", short[0].Param)
+	require.Equal(t, []string{passwordpolicy.RuleMaxLength}, rules(policy.Check("Ab1!Ab1!Ab1!Ab1!Ab1!", identity)))
+	require.Empty(t, policy.Check("Ñandú-Río-42", identity))
+
+	// 3.- Missing classes are listed individually.
+	require.Equal(t, []string{passwordpolicy.RuleUpper, passwordpolicy.RuleDigit, passwordpolicy.RuleSymbol}, rules(policy.Check("lowercaseonly", identity)))
+
+	// 4.- Passwords containing or resembling the e-mail or name are refused.
+	require.Contains(t, rules(policy.Check("MariaLopez#1", identity)), passwordpolicy.RuleSimilar)
+	require.Contains(t, rules(policy.Check("Lopez-2024!!", identity)), passwordpolicy.RuleSimilar)
+	require.Contains(t, rules(policy.Check("Maria.L0pez!", identity)), passwordpolicy.RuleSimilar)
+	require.NotContains(t, rules(policy.Check("Maria.L0pez!", passwordpolicy.Identity{})), passwordpolicy.RuleSimilar)
+}
+
+// 1.- TestPolicyStopsAtMaxLength ensures overlong input skips the similarity and breach checks.
+func TestPolicyStopsAtMaxLength(t *testing.T) {
+	overlong := "marialopezmarialopez"
+	breached := passwordpolicy.NewBloomFilter(1, 0.0001)
+	breached.Add(overlong)
+	policy := passwordpolicy.New(passwordpolicy.Config{
+		MinLength:     10,
+		MaxLength:     16,
+		RequireUpper:  true,
+		RejectSimilar: true,
+		Breached:      breached,
+	})
+
+	// 2.- Only the length violation is reported even though every other rule would fire.
+	violations := policy.Check(overlong, passwordpolicy.Identity{Email: "maria.lopez@example.com", Name: "María López"})
+	require.Equal(t, []string{passwordpolicy.RuleMaxLength}, rules(violations))
+	require.Equal(t, "16", violations[0].Param)
+}
+
+// 1.- TestBreachedFilter checks the bundled list and the serialization round trip.
+func TestBreachedFilter(t *testing.T) {
+	bundled, err := passwordpolicy.DefaultBreachedFilter()
+	require.NoError(t, err)
+	require.True(t, bundled.MightContain("password123"))
+	require.True(t, bundled.MightContain("P@ssw0rd"))
+	require.False(t, bundled.MightContain("violet-harbor-lantern-7"))
+
+	// 2.- SHA-1 digests from breach corpora are accepted with or without a count suffix.
+	filter := passwordpolicy.NewBloomFilter(2, 0.0001)
+	filter.Add("hunter2")
+	require.NoError(t, filter.AddHexDigest("5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493"))
+	require.Error(t, filter.AddHexDigest("not-a-digest"))
+	require.True(t, filter.MightContain("password"))
+
+	var buf bytes.Buffer
+	_, err = filter.WriteTo(&buf)
+	require.NoError(t, err)
+	restored, err := passwordpolicy.ReadBloomFilter(&buf)
+	require.NoError(t, err)
+	require.True(t, restored.MightContain("hunter2"))
+	require.True(t, restored.MightContain("password"))
+	require.False(t, restored.MightContain("hunter3"))
+	_, err = passwordpolicy.ReadBloomFilter(bytes.NewReader([]byte("garbage")))
+	require.ErrorIs(t, err, passwordpolicy.ErrInvalidFilter)
+
+	// 3.- The policy reports breached candidates with a localized message.
+	policy := passwordpolicy.New(passwordpolicy.Config{Breached: bundled})
+	errs := policy.Validate("password", "iloveyou1", passwordpolicy.Identity{}, "es-MX")
+	require.Len(t, errs, 1)
+	require.Equal(t, "password", errs[0].Field)
+	require.Equal(t, passwordpolicy.RuleBreached, errs[0].Rule)
+	require.Equal(t, "Esta contraseña apareció en una filtración de datos; elige otra.", errs[0].Message)
+	require.Nil(t, policy.Validate("password", "violet-harbor-lantern-7", passwordpolicy.Identity{}, "en"))
+}
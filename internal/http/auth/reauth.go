@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/respond"
+)
+
+// 1.- RecentAuthWindow is how long after signing in a session may make sensitive account changes without a password.
+const RecentAuthWindow = 10 * time.Minute
+
+// 1.- ConfirmIdentity re-authenticates the account owner before a sensitive change and answers 401 otherwise.
+// 2.- The current password always works; without one the session must have signed in within RecentAuthWindow,
+// so SSO, magic-link and passkey users sign in again and retry.
+func ConfirmIdentity(ctx *gin.Context, principal internalauth.Principal, user User, password string, check func(hash string, password string) error) bool {
+	if password != "" {
+		if err := check(user.PasswordHash, password); err != nil {
+			respond.Error(ctx, http.StatusUnauthorized, "invalid credentials", nil)
+			return false
+		}
+		return true
+	}
+
+	//1.- Impersonation tokens never carry a sign-in time, so an administrator cannot skip the owner's password.
+	if principal.Actor == "" && principal.AuthenticatedWithin(RecentAuthWindow, time.Now()) {
+		return true
+	}
+	respond.Error(ctx, http.StatusUnauthorized, "recent authentication required", map[string]interface{}{
+		"reason":          "provide the current password or sign in again",
+		"max_age_seconds": int(RecentAuthWindow / time.Second),
+	})
+	return false
+}
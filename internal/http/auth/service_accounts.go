@@ -0,0 +1,20 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/respond"
+)
+
+// 1.- DenyServiceAccounts blocks routes that act on the caller's own user account for client_credentials tokens.
+func DenyServiceAccounts(ctx *gin.Context) {
+	if principal, ok := internalauth.PrincipalFromContext(ctx); ok && principal.IsServiceAccount() {
+		respond.Error(ctx, http.StatusForbidden, "user account required", map[string]interface{}{"reason": "service accounts cannot access this route"})
+		ctx.Abort()
+		return
+	}
+	ctx.Next()
+}
@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/respond"
+)
+
+// 1.- SessionManager lists and revokes the refresh families owned by a subject.
+type SessionManager interface {
+	ListSessions(ctx context.Context, subject string) ([]internalauth.Session, error)
+	RevokeSession(ctx context.Context, subject string, sessionID string) error
+	RevokeOtherSessions(ctx context.Context, subject string, keepSessionID string) (int, error)
+}
+
+// 1.- sessionResponse is the JSON view of a session, flagging the caller's own.
+type sessionResponse struct {
+	ID            string    `json:"id"`
+	UserAgent     string    `json:"user_agent"`
+	IP            string    `json:"ip"`
+	CreatedAt     time.Time `json:"created_at"`
+	LastRefreshAt time.Time `json:"last_refresh_at"`
+	Current       bool      `json:"current"`
+}
+
+// 1.- clientContext attaches the caller's user agent and IP so issued sessions record them.
+func clientContext(ctx *gin.Context) context.Context {
+	return internalauth.WithClientInfo(ctx.Request.Context(), internalauth.ClientInfo{
+		UserAgent: ctx.Request.UserAgent(),
+		IP:        ctx.ClientIP(),
+	})
+}
+
+// 1.- sessionPrincipal guards session endpoints and resolves the authenticated principal.
+func (h Handler) sessionPrincipal(ctx *gin.Context) (internalauth.Principal, bool) {
+	if h.sessions == nil {
+		respond.Error(ctx, http.StatusServiceUnavailable, "session management unavailable", map[string]interface{}{"reason": "not configured"})
+		return internalauth.Principal{}, false
+	}
+	principal, ok := internalauth.PrincipalFromContext(ctx)
+	if !ok {
+		respond.Error(ctx, http.StatusUnauthorized, "authentication required", map[string]interface{}{"reason": "principal missing"})
+		return internalauth.Principal{}, false
+	}
+	return principal, true
+}
+
+// 1.- ListSessions returns every active session of the authenticated user.
+func (h Handler) ListSessions(ctx *gin.Context) {
+	// 1.- Resolve the caller.
+	principal, ok := h.sessionPrincipal(ctx)
+	if !ok {
+		return
+	}
+
+	// 2.- Load the sessions from the auth service.
+	sessions, err := h.sessions.ListSessions(ctx.Request.Context(), principal.Subject)
+	if err != nil {
+		respond.Error(ctx, http.StatusInternalServerError, "failed to list sessions", map[string]interface{}{"details": err.Error()})
+		return
+	}
+
+	// 3.- Render the sessions, marking the one behind the current access token.
+	items := make([]sessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		items = append(items, sessionResponse{
+			ID:            session.ID,
+			UserAgent:     session.UserAgent,
+			IP:            session.IP,
+			CreatedAt:     session.CreatedAt,
+			LastRefreshAt: session.LastRefreshAt,
+			Current:       session.ID == principal.SessionID,
+		})
+	}
+	respond.Success(ctx, http.StatusOK, items, map[string]interface{}{"total": len(items)})
+}
+
+// 1.- RevokeSession ends one session owned by the authenticated user.
+func (h Handler) RevokeSession(ctx *gin.Context) {
+	// 1.- Resolve the caller and the targeted session.
+	principal, ok := h.sessionPrincipal(ctx)
+	if !ok {
+		return
+	}
+	sessionID := strings.TrimSpace(ctx.Param("id"))
+
+	// 2.- Delegate to the auth service, hiding sessions owned by other users.
+	if err := h.sessions.RevokeSession(ctx.Request.Context(), principal.Subject, sessionID); err != nil {
+		if errors.Is(err, internalauth.ErrSessionNotFound) {
+			respond.Error(ctx, http.StatusNotFound, "session not found", map[string]interface{}{"id": sessionID})
+			return
+		}
+		respond.Error(ctx, http.StatusInternalServerError, "failed to revoke session", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	respond.Success(ctx, http.StatusOK, map[string]any{"revoked": true, "current": sessionID == principal.SessionID}, nil)
+}
+
+// 1.- RevokeOtherSessions ends every session except the one making the request.
+func (h Handler) RevokeOtherSessions(ctx *gin.Context) {
+	// 1.- Resolve the caller.
+	principal, ok := h.sessionPrincipal(ctx)
+	if !ok {
+		return
+	}
+
+	// 2.- Revoke everything but the current refresh family.
+	revoked, err := h.sessions.RevokeOtherSessions(ctx.Request.Context(), principal.Subject, principal.SessionID)
+	if err != nil {
+		respond.Error(ctx, http.StatusInternalServerError, "failed to revoke sessions", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	respond.Success(ctx, http.StatusOK, map[string]any{"revoked": revoked}, nil)
+}
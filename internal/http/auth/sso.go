@@ -0,0 +1,300 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/auth/oidc"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/respond"
+)
+
+// 1.- ErrUnknownSSOProvider indicates that no identity provider is registered under the requested name.
+var ErrUnknownSSOProvider = errors.New("http/auth: unknown sso provider")
+
+// 1.- ErrInvalidSSOState indicates that the callback state is unknown, expired, replayed, or for another provider.
+var ErrInvalidSSOState = errors.New("http/auth: invalid sso state")
+
+// 1.- ErrSSOEmailUnverified indicates that the identity provider did not vouch for the e-mail address.
+var ErrSSOEmailUnverified = errors.New("http/auth: sso email not verified")
+
+// 1.- SSOStateCookie binds a pending SSO login to the browser that started it.
+const SSOStateCookie = "yamato_sso_state"
+
+// 1.- SSOLogin is a started authorization request: where to send the browser and the state it must return with.
+type SSOLogin struct {
+	URL       string
+	State     string
+	ExpiresAt time.Time
+}
+
+// 1.- SSOManager defines the authorization-code workflow consumed by the handlers.
+type SSOManager interface {
+	Begin(ctx context.Context, provider string) (SSOLogin, error)
+	Complete(ctx context.Context, provider string, state string, code string) (User, error)
+}
+
+// 1.- SSOAuth captures the auth service features needed to provision SSO-only accounts.
+type SSOAuth interface {
+	HashPassword(password string) (string, error)
+}
+
+// 1.- SSOConfig tunes the login window and whether unknown e-mails get a new account.
+type SSOConfig struct {
+	StateTTL      time.Duration
+	AutoProvision bool
+}
+
+// 1.- SSOService runs OIDC logins against registered identity providers and links users by verified e-mail.
+type SSOService struct {
+	users     UserStore
+	auth      SSOAuth
+	redis     internalauth.RedisCommander
+	providers map[string]oidc.IdentityProvider
+	cfg       SSOConfig
+}
+
+// 1.- ssoState is the server-side half of an authorization request, keyed by its state value.
+type ssoState struct {
+	Provider     string `json:"provider"`
+	CodeVerifier string `json:"code_verifier"`
+	Nonce        string `json:"nonce"`
+}
+
+// 1.- NewSSOService registers the providers by name and applies configuration defaults.
+func NewSSOService(users UserStore, auth SSOAuth, redis internalauth.RedisCommander, cfg SSOConfig, providers ...oidc.IdentityProvider) *SSOService {
+	if cfg.StateTTL <= 0 {
+		cfg.StateTTL = 10 * time.Minute
+	}
+	registry := make(map[string]oidc.IdentityProvider, len(providers))
+	for _, provider := range providers {
+		registry[provider.Name()] = provider
+	}
+	return &SSOService{users: users, auth: auth, redis: redis, providers: registry, cfg: cfg}
+}
+
+// 1.- Begin stores fresh PKCE and nonce values and returns the provider's authorization URL with its state.
+func (s *SSOService) Begin(ctx context.Context, provider string) (SSOLogin, error) {
+	idp, ok := s.providers[provider]
+	if !ok {
+		return SSOLogin{}, ErrUnknownSSOProvider
+	}
+
+	//1.- Generate the per-login secrets; only the challenge leaves the server.
+	state, err := oidc.NewState()
+	if err != nil {
+		return SSOLogin{}, err
+	}
+	nonce, err := oidc.NewState()
+	if err != nil {
+		return SSOLogin{}, err
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return SSOLogin{}, err
+	}
+	payload, err := json.Marshal(ssoState{Provider: provider, CodeVerifier: verifier, Nonce: nonce})
+	if err != nil {
+		return SSOLogin{}, fmt.Errorf("http/auth: encode sso state: %w", err)
+	}
+	if err := s.redis.Set(ctx, ssoStateKey(state), string(payload), s.cfg.StateTTL).Err(); err != nil {
+		return SSOLogin{}, fmt.Errorf("http/auth: store sso state: %w", err)
+	}
+
+	//2.- The handler pins the state to the browser, so hand it back next to the URL.
+	target, err := idp.AuthCodeURL(ctx, oidc.AuthRequest{State: state, Nonce: nonce, CodeChallenge: oidc.CodeChallenge(verifier)})
+	if err != nil {
+		return SSOLogin{}, err
+	}
+	return SSOLogin{URL: target, State: state, ExpiresAt: time.Now().Add(s.cfg.StateTTL)}, nil
+}
+
+// 1.- Complete redeems the state once, exchanges the code, and resolves the local account.
+func (s *SSOService) Complete(ctx context.Context, provider string, state string, code string) (User, error) {
+	idp, ok := s.providers[provider]
+	if !ok {
+		return User{}, ErrUnknownSSOProvider
+	}
+	pending, err := s.consumeState(ctx, state)
+	if err != nil {
+		return User{}, err
+	}
+	if pending.Provider != provider {
+		return User{}, ErrInvalidSSOState
+	}
+
+	identity, err := idp.Exchange(ctx, code, pending.CodeVerifier, pending.Nonce)
+	if err != nil {
+		return User{}, err
+	}
+	//1.- Only an address the issuer verified may be trusted to identify a local account.
+	if identity.Email == "" || !identity.EmailVerified {
+		return User{}, ErrSSOEmailUnverified
+	}
+
+	user, err := s.users.FindByEmail(ctx, identity.Email)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, ErrUserNotFound) || !s.cfg.AutoProvision {
+		return User{}, err
+	}
+
+	//2.- Provision SSO-only users with an unguessable password; they can set one through the reset flow.
+	secret, err := newOneTimeToken()
+	if err != nil {
+		return User{}, err
+	}
+	hashed, err := s.auth.HashPassword(secret)
+	if err != nil {
+		return User{}, err
+	}
+	return s.users.Create(ctx, User{Email: identity.Email, Name: identity.Name, PasswordHash: hashed})
+}
+
+// 1.- consumeState loads and deletes the stored state so a callback can only be replayed once.
+func (s *SSOService) consumeState(ctx context.Context, state string) (ssoState, error) {
+	key := ssoStateKey(strings.TrimSpace(state))
+	raw, err := s.redis.Get(ctx, key).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return ssoState{}, ErrInvalidSSOState
+		}
+		return ssoState{}, fmt.Errorf("http/auth: load sso state: %w", err)
+	}
+	removed, err := s.redis.Del(ctx, key).Result()
+	if err != nil {
+		return ssoState{}, fmt.Errorf("http/auth: delete sso state: %w", err)
+	}
+	if removed == 0 {
+		return ssoState{}, ErrInvalidSSOState
+	}
+
+	var pending ssoState
+	if err := json.Unmarshal([]byte(raw), &pending); err != nil {
+		return ssoState{}, ErrInvalidSSOState
+	}
+	return pending, nil
+}
+
+// 1.- ssoStateKey namespaces pending authorization requests.
+func ssoStateKey(state string) string {
+	return "auth:sso-state:" + state
+}
+
+// 1.- ssoStateDigest is the cookie form of a state value, so the raw state never sits in the browser jar.
+func ssoStateDigest(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(sum[:])
+}
+
+// 1.- setSSOStateCookie scopes the state cookie to the provider's routes; Lax lets the IdP's top-level redirect carry it back.
+func (h Handler) setSSOStateCookie(ctx *gin.Context, value string, expires time.Time) {
+	maxAge := int(time.Until(expires).Seconds())
+	if maxAge <= 0 {
+		maxAge = -1
+	}
+	secure := ctx.Request.TLS != nil || (h.cookies != nil && h.cookies.Secure)
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     SSOStateCookie,
+		Value:    value,
+		Path:     strings.TrimSuffix(ctx.Request.URL.Path, "/callback"),
+		Expires:  expires.UTC(),
+		MaxAge:   maxAge,
+		Secure:   secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// 1.- ssoStateMatches reports whether the browser holds the cookie issued alongside the returned state.
+func ssoStateMatches(ctx *gin.Context, state string) bool {
+	cookie, err := ctx.Cookie(SSOStateCookie)
+	if err != nil || cookie == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookie), []byte(ssoStateDigest(state))) == 1
+}
+
+// 1.- SSORedirect starts an SSO login by redirecting the browser to the identity provider.
+func (h Handler) SSORedirect(ctx *gin.Context) {
+	// 1.- Guard against missing SSO dependencies to surface clear errors.
+	if h.sso == nil {
+		respond.Error(ctx, http.StatusServiceUnavailable, "sso unavailable", map[string]interface{}{"reason": "not configured"})
+		return
+	}
+
+	// 2.- Build the authorization URL for the requested provider.
+	login, err := h.sso.Begin(ctx.Request.Context(), strings.ToLower(ctx.Param("provider")))
+	if err != nil {
+		if errors.Is(err, ErrUnknownSSOProvider) {
+			respond.Error(ctx, http.StatusNotFound, "unknown sso provider", nil)
+			return
+		}
+		respond.Error(ctx, http.StatusBadGateway, "failed to start sso login", map[string]interface{}{"details": err.Error()})
+		return
+	}
+
+	// 3.- Bind the state to this browser, then hand it over to the identity provider.
+	h.setSSOStateCookie(ctx, ssoStateDigest(login.State), login.ExpiresAt)
+	ctx.Redirect(http.StatusFound, login.URL)
+}
+
+// 1.- SSOCallback finishes an SSO login and returns the same payload as password login.
+func (h Handler) SSOCallback(ctx *gin.Context) {
+	// 1.- Guard against missing SSO dependencies to surface clear errors.
+	if h.sso == nil {
+		respond.Error(ctx, http.StatusServiceUnavailable, "sso unavailable", map[string]interface{}{"reason": "not configured"})
+		return
+	}
+
+	// 2.- Surface provider-side denials before touching the stored state.
+	if reason := ctx.Query("error"); reason != "" {
+		respond.Error(ctx, http.StatusBadRequest, "sso login was not approved", map[string]interface{}{"reason": reason})
+		return
+	}
+	code := strings.TrimSpace(ctx.Query("code"))
+	state := strings.TrimSpace(ctx.Query("state"))
+	if code == "" || state == "" {
+		respond.Error(ctx, http.StatusBadRequest, "invalid sso callback", map[string]interface{}{"reason": "code and state are required"})
+		return
+	}
+
+	// 3.- Only the browser that started the login may finish it; the cookie is single use either way.
+	matched := ssoStateMatches(ctx, state)
+	h.setSSOStateCookie(ctx, "", time.Unix(0, 0))
+	if !matched {
+		respond.Error(ctx, http.StatusUnauthorized, "sso login failed", map[string]interface{}{"details": ErrInvalidSSOState.Error()})
+		return
+	}
+
+	// 4.- Exchange the code and resolve the linked account.
+	user, err := h.sso.Complete(ctx.Request.Context(), strings.ToLower(ctx.Param("provider")), state, code)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUnknownSSOProvider):
+			respond.Error(ctx, http.StatusNotFound, "unknown sso provider", nil)
+		case errors.Is(err, ErrInvalidSSOState), errors.Is(err, oidc.ErrExchangeFailed), errors.Is(err, oidc.ErrInvalidIDToken):
+			respond.Error(ctx, http.StatusUnauthorized, "sso login failed", map[string]interface{}{"details": err.Error()})
+		case errors.Is(err, ErrSSOEmailUnverified), errors.Is(err, ErrUserNotFound):
+			respond.Error(ctx, http.StatusForbidden, "sso account cannot be linked", map[string]interface{}{"reason": "a verified e-mail matching an existing account is required"})
+		default:
+			respond.Error(ctx, http.StatusBadGateway, "sso login failed", map[string]interface{}{"details": err.Error()})
+		}
+		return
+	}
+
+	// 5.- Continue exactly like password login, including the MFA challenge.
+	h.completeLogin(ctx, user)
+}
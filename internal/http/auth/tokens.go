@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/example/Yamato-Go-Gin-API/internal/queue"
+)
+
+// 1.- ErrTokenNotFound is returned when a one-time token is unknown, expired, or already consumed.
+var ErrTokenNotFound = errors.New("http/auth: one-time token not found")
+
+// 1.- PurposePasswordReset tags one-time tokens that authorize a password reset.
+const PurposePasswordReset = "password_reset"
+
+// 1.- OneTimeToken describes a hashed, expiring, single-use token issued for an account workflow.
+type OneTimeToken struct {
+	Purpose   string
+	UserID    string
+	TokenHash string
+	// 2.- Payload carries purpose-specific data that must survive until the token is consumed.
+	Payload   string
+	ExpiresAt time.Time
+}
+
+// 1.- OneTimeTokenStore persists token hashes so plaintext tokens never reach storage.
+type OneTimeTokenStore interface {
+	// 2.- Save stores the token and invalidates pending tokens sharing its user and purpose.
+	Save(ctx context.Context, token OneTimeToken) error
+	// 3.- Consume atomically marks a live token as used or returns ErrTokenNotFound.
+	Consume(ctx context.Context, purpose string, tokenHash string, now time.Time) (OneTimeToken, error)
+	// 4.- Find returns a live token without consuming it or returns ErrTokenNotFound.
+	Find(ctx context.Context, purpose string, tokenHash string, now time.Time) (OneTimeToken, error)
+	// 5.- Revoke invalidates every pending token sharing the user and purpose.
+	Revoke(ctx context.Context, userID string, purpose string) error
+}
+
+// 1.- MailQueue enqueues background jobs such as queue.EmailSendJobName deliveries.
+type MailQueue interface {
+	Enqueue(ctx context.Context, jobName string, payload map[string]any) (queue.Message, error)
+}
+
+// 1.- SessionRevoker terminates every refresh family issued to a subject.
+type SessionRevoker interface {
+	RevokeAll(ctx context.Context, subject string) error
+}
+
+// 1.- HashOneTimeToken derives the stored digest for a token the same way verification hashes are built.
+func HashOneTimeToken(token string, secret string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token) + "|" + secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// 1.- newOneTimeToken returns a URL-safe random token carrying 256 bits of entropy.
+func newOneTimeToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("http/auth: generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,47 @@
+package webauthn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeCBORHandlesWebAuthnSubset(t *testing.T) {
+	// 1.- {"fmt": "none", 1: -7, "b": h'0102', "l": [true, null]} followed by a trailing byte.
+	data := []byte{0xa4, 0x63, 'f', 'm', 't', 0x64, 'n', 'o', 'n', 'e', 0x01, 0x26, 0x61, 'b', 0x42, 0x01, 0x02, 0x61, 'l', 0x82, 0xf5, 0xf6, 0xff}
+	value, rest, err := decodeCBOR(data)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xff}, rest)
+
+	entries := value.(map[interface{}]interface{})
+	require.Equal(t, "none", entries["fmt"])
+	require.Equal(t, int64(-7), entries[int64(1)])
+	require.Equal(t, []byte{0x01, 0x02}, entries["b"])
+	require.Equal(t, []interface{}{true, nil}, entries["l"])
+}
+
+func TestDecodeCBORRejectsMalformedInput(t *testing.T) {
+	cases := map[string][]byte{
+		"empty":             {},
+		"truncated string":  {0x45, 0x01},
+		"oversized array":   {0x9a, 0xff, 0xff, 0xff, 0xff},
+		"indefinite length": {0x5f},
+		"tag":               {0xc0, 0x01},
+		"float":             {0xf9, 0x00, 0x00},
+		"duplicate key":     {0xa2, 0x01, 0x01, 0x01, 0x02},
+		"array key":         {0xa1, 0x80, 0x01},
+	}
+	for name, data := range cases {
+		_, _, err := decodeCBOR(data)
+		require.ErrorIs(t, err, errMalformedCBOR, name)
+	}
+
+	// 2.- Deep nesting is bounded.
+	deep := make([]byte, 0, 64)
+	for i := 0; i < 40; i++ {
+		deep = append(deep, 0x81)
+	}
+	deep = append(deep, 0x00)
+	_, _, err := decodeCBOR(deep)
+	require.ErrorIs(t, err, errMalformedCBOR)
+}
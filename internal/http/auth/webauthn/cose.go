@@ -0,0 +1,122 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// 1.- ErrUnsupportedKey indicates a credential public key whose type or algorithm is not accepted.
+var ErrUnsupportedKey = errors.New("webauthn: unsupported credential key")
+
+// 1.- COSE algorithm identifiers offered during registration, in order of preference.
+const (
+	AlgES256 int64 = -7
+	AlgEdDSA int64 = -8
+	AlgRS256 int64 = -257
+)
+
+// 1.- COSE key map labels and key types used by the supported algorithms.
+const (
+	coseKeyType  int64 = 1
+	coseKeyAlg   int64 = 3
+	coseEC2Curve int64 = -1
+	coseEC2X     int64 = -2
+	coseEC2Y     int64 = -3
+	coseRSAN     int64 = -1
+	coseRSAE     int64 = -2
+
+	coseKtyOKP int64 = 1
+	coseKtyEC2 int64 = 2
+	coseKtyRSA int64 = 3
+
+	coseCurveP256    int64 = 1
+	coseCurveEd25519 int64 = 6
+)
+
+// 1.- credentialKey is a parsed COSE public key able to verify assertion signatures.
+type credentialKey struct {
+	alg int64
+	key crypto.PublicKey
+}
+
+// 1.- parseCredentialKey decodes a COSE_Key and rejects trailing bytes.
+func parseCredentialKey(raw []byte) (credentialKey, error) {
+	value, rest, err := decodeCBOR(raw)
+	if err != nil {
+		return credentialKey{}, err
+	}
+	if len(rest) != 0 {
+		return credentialKey{}, errMalformedCBOR
+	}
+	return credentialKeyFromMap(value)
+}
+
+// 1.- credentialKeyFromMap converts a decoded COSE_Key map into a Go public key.
+func credentialKeyFromMap(value interface{}) (credentialKey, error) {
+	entries, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return credentialKey{}, errMalformedCBOR
+	}
+	kty, _ := entries[coseKeyType].(int64)
+	alg, _ := entries[coseKeyAlg].(int64)
+
+	switch {
+	case kty == coseKtyEC2 && alg == AlgES256:
+		curve, _ := entries[coseEC2Curve].(int64)
+		x, _ := entries[coseEC2X].([]byte)
+		y, _ := entries[coseEC2Y].([]byte)
+		if curve != coseCurveP256 || len(x) != 32 || len(y) != 32 {
+			return credentialKey{}, ErrUnsupportedKey
+		}
+		//1.- Reject points off the curve before they reach signature checks.
+		point := append([]byte{0x04}, append(append([]byte(nil), x...), y...)...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return credentialKey{}, ErrUnsupportedKey
+		}
+		return credentialKey{alg: alg, key: &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}}, nil
+	case kty == coseKtyOKP && alg == AlgEdDSA:
+		curve, _ := entries[coseEC2Curve].(int64)
+		x, _ := entries[coseEC2X].([]byte)
+		if curve != coseCurveEd25519 || len(x) != ed25519.PublicKeySize {
+			return credentialKey{}, ErrUnsupportedKey
+		}
+		return credentialKey{alg: alg, key: ed25519.PublicKey(append([]byte(nil), x...))}, nil
+	case kty == coseKtyRSA && alg == AlgRS256:
+		n, _ := entries[coseRSAN].([]byte)
+		e, _ := entries[coseRSAE].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return credentialKey{}, ErrUnsupportedKey
+		}
+		exponent := int(new(big.Int).SetBytes(e).Int64())
+		if exponent < 3 {
+			return credentialKey{}, ErrUnsupportedKey
+		}
+		return credentialKey{alg: alg, key: &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exponent}}, nil
+	default:
+		return credentialKey{}, fmt.Errorf("%w: kty %d alg %d", ErrUnsupportedKey, kty, alg)
+	}
+}
+
+// 1.- verify checks signature over message using the key's algorithm.
+func (k credentialKey) verify(message []byte, signature []byte) bool {
+	switch k.alg {
+	case AlgES256:
+		digest := sha256.Sum256(message)
+		return ecdsa.VerifyASN1(k.key.(*ecdsa.PublicKey), digest[:], signature)
+	case AlgEdDSA:
+		return ed25519.Verify(k.key.(ed25519.PublicKey), message, signature)
+	case AlgRS256:
+		digest := sha256.Sum256(message)
+		return rsa.VerifyPKCS1v15(k.key.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) == nil
+	default:
+		return false
+	}
+}
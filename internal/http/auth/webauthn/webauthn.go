@@ -0,0 +1,432 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 1.- ErrInvalidResponse indicates that a client response failed ceremony verification.
+var ErrInvalidResponse = errors.New("webauthn: invalid response")
+
+// 1.- ErrSignCountRegression indicates a signature counter that did not advance, a hint that the authenticator was cloned.
+var ErrSignCountRegression = errors.New("webauthn: sign count did not increase")
+
+// 1.- Authenticator data flags defined by the WebAuthn specification.
+const (
+	flagUserPresent    = 0x01
+	flagUserVerified   = 0x04
+	flagBackupEligible = 0x08
+	flagAttestedData   = 0x40
+	flagExtensions     = 0x80
+)
+
+// 1.- challengeSize is the number of random bytes in every ceremony challenge.
+const challengeSize = 32
+
+// 1.- Bytes marshals binary fields as unpadded base64url, the encoding used by the WebAuthn JSON API.
+type Bytes []byte
+
+// 1.- MarshalJSON renders the bytes as unpadded base64url.
+func (b Bytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+// 1.- UnmarshalJSON accepts padded or unpadded base64url.
+func (b *Bytes) UnmarshalJSON(data []byte) error {
+	var encoded string
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return fmt.Errorf("webauthn: invalid base64url value: %w", err)
+	}
+	*b = decoded
+	return nil
+}
+
+// 1.- Config identifies the relying party and the web origins allowed to run ceremonies.
+type Config struct {
+	RPID    string
+	RPName  string
+	Origins []string
+	Timeout time.Duration
+}
+
+// 1.- RelyingParty builds ceremony options and verifies authenticator responses.
+type RelyingParty struct {
+	cfg Config
+}
+
+// 1.- New validates the configuration and applies defaults.
+func New(cfg Config) (*RelyingParty, error) {
+	cfg.RPID = strings.TrimSpace(cfg.RPID)
+	if cfg.RPID == "" {
+		return nil, errors.New("webauthn: relying party id is required")
+	}
+	if cfg.RPName == "" {
+		cfg.RPName = cfg.RPID
+	}
+	if len(cfg.Origins) == 0 {
+		cfg.Origins = []string{"https://" + cfg.RPID}
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Minute
+	}
+	return &RelyingParty{cfg: cfg}, nil
+}
+
+// 1.- RPID returns the relying party identifier.
+func (rp *RelyingParty) RPID() string {
+	return rp.cfg.RPID
+}
+
+// 1.- Timeout returns how long a ceremony may take.
+func (rp *RelyingParty) Timeout() time.Duration {
+	return rp.cfg.Timeout
+}
+
+// 1.- RelyingPartyEntity names the relying party in creation options.
+type RelyingPartyEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// 1.- UserEntity describes the account a credential is created for; ID becomes the user handle.
+type UserEntity struct {
+	ID          Bytes  `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// 1.- CredentialParameter offers one acceptable public key algorithm.
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+// 1.- CredentialDescriptor references an existing credential.
+type CredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         Bytes    `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+// 1.- AuthenticatorSelection states the relying party's authenticator preferences.
+type AuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// 1.- CreationOptions mirrors PublicKeyCredentialCreationOptions in its JSON form.
+type CreationOptions struct {
+	Challenge              Bytes                  `json:"challenge"`
+	RP                     RelyingPartyEntity     `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// 1.- RequestOptions mirrors PublicKeyCredentialRequestOptions in its JSON form.
+type RequestOptions struct {
+	Challenge        Bytes                  `json:"challenge"`
+	Timeout          int64                  `json:"timeout"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// 1.- RegistrationResponse is the JSON form of the credential returned by navigator.credentials.create.
+type RegistrationResponse struct {
+	ID       string `json:"id"`
+	RawID    Bytes  `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    Bytes    `json:"clientDataJSON"`
+		AttestationObject Bytes    `json:"attestationObject"`
+		Transports        []string `json:"transports,omitempty"`
+	} `json:"response"`
+}
+
+// 1.- AssertionResponse is the JSON form of the credential returned by navigator.credentials.get.
+type AssertionResponse struct {
+	ID       string `json:"id"`
+	RawID    Bytes  `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    Bytes `json:"clientDataJSON"`
+		AuthenticatorData Bytes `json:"authenticatorData"`
+		Signature         Bytes `json:"signature"`
+		UserHandle        Bytes `json:"userHandle,omitempty"`
+	} `json:"response"`
+}
+
+// 1.- Credential is the verified outcome of a registration ceremony.
+type Credential struct {
+	ID             []byte
+	PublicKey      []byte
+	SignCount      uint32
+	AAGUID         []byte
+	Transports     []string
+	UserVerified   bool
+	BackupEligible bool
+}
+
+// 1.- Assertion is the verified outcome of an authentication ceremony.
+type Assertion struct {
+	SignCount    uint32
+	UserVerified bool
+}
+
+// 1.- NewChallenge returns a fresh random ceremony challenge.
+func NewChallenge() ([]byte, error) {
+	challenge := make([]byte, challengeSize)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, fmt.Errorf("webauthn: generate challenge: %w", err)
+	}
+	return challenge, nil
+}
+
+// 1.- CreationOptions builds the options passed to navigator.credentials.create.
+func (rp *RelyingParty) CreationOptions(user UserEntity, challenge []byte, exclude []CredentialDescriptor) CreationOptions {
+	if exclude == nil {
+		exclude = []CredentialDescriptor{}
+	}
+	return CreationOptions{
+		Challenge: challenge,
+		RP:        RelyingPartyEntity{ID: rp.cfg.RPID, Name: rp.cfg.RPName},
+		User:      user,
+		PubKeyCredParams: []CredentialParameter{
+			{Type: "public-key", Alg: AlgES256},
+			{Type: "public-key", Alg: AlgEdDSA},
+			{Type: "public-key", Alg: AlgRS256},
+		},
+		Timeout:                rp.cfg.Timeout.Milliseconds(),
+		ExcludeCredentials:     exclude,
+		AuthenticatorSelection: AuthenticatorSelection{ResidentKey: "preferred", UserVerification: "preferred"},
+		Attestation:            "none",
+	}
+}
+
+// 1.- RequestOptions builds the options passed to navigator.credentials.get; an empty allow list permits discoverable credentials.
+func (rp *RelyingParty) RequestOptions(challenge []byte, allow []CredentialDescriptor) RequestOptions {
+	if allow == nil {
+		allow = []CredentialDescriptor{}
+	}
+	return RequestOptions{
+		Challenge:        challenge,
+		Timeout:          rp.cfg.Timeout.Milliseconds(),
+		RPID:             rp.cfg.RPID,
+		AllowCredentials: allow,
+		UserVerification: "preferred",
+	}
+}
+
+// 1.- clientData holds the fields of CollectedClientData checked by the relying party.
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// 1.- ChallengeFromClientData extracts the challenge so the server can look up the pending ceremony.
+func ChallengeFromClientData(raw []byte) ([]byte, error) {
+	var data clientData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, ErrInvalidResponse
+	}
+	challenge, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(data.Challenge, "="))
+	if err != nil || len(challenge) == 0 {
+		return nil, ErrInvalidResponse
+	}
+	return challenge, nil
+}
+
+// 1.- verifyClientData checks the ceremony type, challenge, and origin.
+func (rp *RelyingParty) verifyClientData(raw []byte, ceremony string, challenge []byte) error {
+	var data clientData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return fmt.Errorf("%w: client data is not json", ErrInvalidResponse)
+	}
+	if data.Type != ceremony {
+		return fmt.Errorf("%w: unexpected client data type %q", ErrInvalidResponse, data.Type)
+	}
+	presented, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(data.Challenge, "="))
+	if err != nil || subtle.ConstantTimeCompare(presented, challenge) != 1 {
+		return fmt.Errorf("%w: challenge mismatch", ErrInvalidResponse)
+	}
+	if data.CrossOrigin {
+		return fmt.Errorf("%w: cross-origin ceremonies are not allowed", ErrInvalidResponse)
+	}
+	for _, origin := range rp.cfg.Origins {
+		if data.Origin == origin {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: origin %q is not allowed", ErrInvalidResponse, data.Origin)
+}
+
+// 1.- authenticatorData is the parsed binary structure signed by the authenticator.
+type authenticatorData struct {
+	rpIDHash     []byte
+	flags        byte
+	signCount    uint32
+	aaguid       []byte
+	credentialID []byte
+	publicKey    []byte
+}
+
+// 1.- parseAuthenticatorData decodes the fixed header and, when present, the attested credential data.
+func parseAuthenticatorData(raw []byte) (authenticatorData, error) {
+	if len(raw) < 37 {
+		return authenticatorData{}, fmt.Errorf("%w: authenticator data too short", ErrInvalidResponse)
+	}
+	data := authenticatorData{rpIDHash: raw[:32], flags: raw[32], signCount: binary.BigEndian.Uint32(raw[33:37])}
+	rest := raw[37:]
+
+	if data.flags&flagAttestedData != 0 {
+		if len(rest) < 18 {
+			return authenticatorData{}, fmt.Errorf("%w: attested credential data too short", ErrInvalidResponse)
+		}
+		data.aaguid = rest[:16]
+		idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if idLength == 0 || idLength > 1023 || len(rest) < idLength {
+			return authenticatorData{}, fmt.Errorf("%w: invalid credential id length", ErrInvalidResponse)
+		}
+		data.credentialID = rest[:idLength]
+		rest = rest[idLength:]
+
+		//1.- The COSE key is self-delimiting CBOR; whatever follows belongs to extensions.
+		_, tail, err := decodeCBOR(rest)
+		if err != nil {
+			return authenticatorData{}, fmt.Errorf("%w: invalid credential public key", ErrInvalidResponse)
+		}
+		data.publicKey = rest[:len(rest)-len(tail)]
+		rest = tail
+	}
+	if data.flags&flagExtensions != 0 {
+		_, tail, err := decodeCBOR(rest)
+		if err != nil {
+			return authenticatorData{}, fmt.Errorf("%w: invalid extensions", ErrInvalidResponse)
+		}
+		rest = tail
+	}
+	if len(rest) != 0 {
+		return authenticatorData{}, fmt.Errorf("%w: trailing authenticator data", ErrInvalidResponse)
+	}
+	return data, nil
+}
+
+// 1.- verifyAuthenticatorData checks the relying party hash and user presence.
+func (rp *RelyingParty) verifyAuthenticatorData(data authenticatorData) error {
+	expected := sha256.Sum256([]byte(rp.cfg.RPID))
+	if subtle.ConstantTimeCompare(data.rpIDHash, expected[:]) != 1 {
+		return fmt.Errorf("%w: relying party id mismatch", ErrInvalidResponse)
+	}
+	if data.flags&flagUserPresent == 0 {
+		return fmt.Errorf("%w: user presence required", ErrInvalidResponse)
+	}
+	return nil
+}
+
+// 1.- VerifyRegistration validates an attestation response against the issued challenge.
+func (rp *RelyingParty) VerifyRegistration(response RegistrationResponse, challenge []byte) (Credential, error) {
+	if response.Type != "public-key" {
+		return Credential{}, fmt.Errorf("%w: unexpected credential type", ErrInvalidResponse)
+	}
+	if err := rp.verifyClientData(response.Response.ClientDataJSON, "webauthn.create", challenge); err != nil {
+		return Credential{}, err
+	}
+
+	//1.- Attestation is requested as "none", so only that statement format is accepted.
+	decoded, rest, err := decodeCBOR(response.Response.AttestationObject)
+	if err != nil || len(rest) != 0 {
+		return Credential{}, fmt.Errorf("%w: invalid attestation object", ErrInvalidResponse)
+	}
+	object, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return Credential{}, fmt.Errorf("%w: invalid attestation object", ErrInvalidResponse)
+	}
+	if format, _ := object["fmt"].(string); format != "none" {
+		return Credential{}, fmt.Errorf("%w: unsupported attestation format %q", ErrInvalidResponse, format)
+	}
+	rawAuthData, ok := object["authData"].([]byte)
+	if !ok {
+		return Credential{}, fmt.Errorf("%w: missing authenticator data", ErrInvalidResponse)
+	}
+
+	data, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return Credential{}, err
+	}
+	if err := rp.verifyAuthenticatorData(data); err != nil {
+		return Credential{}, err
+	}
+	if data.credentialID == nil {
+		return Credential{}, fmt.Errorf("%w: attested credential data missing", ErrInvalidResponse)
+	}
+	if len(response.RawID) > 0 && !bytes.Equal(response.RawID, data.credentialID) {
+		return Credential{}, fmt.Errorf("%w: credential id mismatch", ErrInvalidResponse)
+	}
+	if _, err := parseCredentialKey(data.publicKey); err != nil {
+		return Credential{}, err
+	}
+
+	return Credential{
+		ID:             append([]byte(nil), data.credentialID...),
+		PublicKey:      append([]byte(nil), data.publicKey...),
+		SignCount:      data.signCount,
+		AAGUID:         append([]byte(nil), data.aaguid...),
+		Transports:     response.Response.Transports,
+		UserVerified:   data.flags&flagUserVerified != 0,
+		BackupEligible: data.flags&flagBackupEligible != 0,
+	}, nil
+}
+
+// 1.- VerifyAssertion validates an assertion signed by the stored credential and enforces sign-count progress.
+func (rp *RelyingParty) VerifyAssertion(response AssertionResponse, challenge []byte, publicKey []byte, storedSignCount uint32) (Assertion, error) {
+	if response.Type != "public-key" {
+		return Assertion{}, fmt.Errorf("%w: unexpected credential type", ErrInvalidResponse)
+	}
+	if err := rp.verifyClientData(response.Response.ClientDataJSON, "webauthn.get", challenge); err != nil {
+		return Assertion{}, err
+	}
+	data, err := parseAuthenticatorData(response.Response.AuthenticatorData)
+	if err != nil {
+		return Assertion{}, err
+	}
+	if err := rp.verifyAuthenticatorData(data); err != nil {
+		return Assertion{}, err
+	}
+
+	//1.- The signature covers the authenticator data followed by the client data hash.
+	key, err := parseCredentialKey(publicKey)
+	if err != nil {
+		return Assertion{}, err
+	}
+	clientHash := sha256.Sum256(response.Response.ClientDataJSON)
+	signed := append(append([]byte(nil), response.Response.AuthenticatorData...), clientHash[:]...)
+	if !key.verify(signed, response.Response.Signature) {
+		return Assertion{}, fmt.Errorf("%w: signature verification failed", ErrInvalidResponse)
+	}
+
+	//1.- Counters of zero mean the authenticator does not track usage; otherwise they must advance.
+	if (data.signCount != 0 || storedSignCount != 0) && data.signCount <= storedSignCount {
+		return Assertion{}, ErrSignCountRegression
+	}
+	return Assertion{SignCount: data.signCount, UserVerified: data.flags&flagUserVerified != 0}, nil
+}
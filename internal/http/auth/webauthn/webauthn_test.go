@@ -0,0 +1,121 @@
+package webauthn_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/example/Yamato-Go-Gin-API/internal/http/auth/webauthn"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/auth/webauthn/webauthntest"
+)
+
+// 1.- newRelyingParty configures the relying party used by every ceremony test.
+func newRelyingParty(t *testing.T) *webauthn.RelyingParty {
+	t.Helper()
+	rp, err := webauthn.New(webauthn.Config{RPID: "example.com", RPName: "Yamato", Origins: []string{"https://example.com"}})
+	require.NoError(t, err)
+	return rp
+}
+
+// 1.- register runs a registration ceremony with the software authenticator.
+func register(t *testing.T, rp *webauthn.RelyingParty, authenticator *webauthntest.Authenticator) webauthn.Credential {
+	t.Helper()
+	challenge, err := webauthn.NewChallenge()
+	require.NoError(t, err)
+	response, err := authenticator.Create(rp.CreationOptions(webauthn.UserEntity{ID: []byte("42"), Name: "user@example.com", DisplayName: "User"}, challenge, nil))
+	require.NoError(t, err)
+	credential, err := rp.VerifyRegistration(response, challenge)
+	require.NoError(t, err)
+	return credential
+}
+
+func TestRegistrationAndAssertionRoundTrip(t *testing.T) {
+	rp := newRelyingParty(t)
+	authenticator := webauthntest.NewAuthenticator("https://example.com")
+
+	// 2.- Registration yields the credential id, COSE key, and flags.
+	credential := register(t, rp, authenticator)
+	require.Len(t, credential.ID, 16)
+	require.NotEmpty(t, credential.PublicKey)
+	require.True(t, credential.UserVerified)
+	require.Equal(t, uint32(0), credential.SignCount)
+
+	// 3.- The JSON form survives a round trip through the HTTP layer.
+	challenge, err := webauthn.NewChallenge()
+	require.NoError(t, err)
+	assertion, err := authenticator.Get(rp.RequestOptions(challenge, []webauthn.CredentialDescriptor{{Type: "public-key", ID: credential.ID}}))
+	require.NoError(t, err)
+	encoded, err := json.Marshal(assertion)
+	require.NoError(t, err)
+	var decoded webauthn.AssertionResponse
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	require.True(t, bytes.Equal(decoded.RawID, credential.ID))
+
+	extracted, err := webauthn.ChallengeFromClientData(decoded.Response.ClientDataJSON)
+	require.NoError(t, err)
+	require.Equal(t, challenge, extracted)
+
+	result, err := rp.VerifyAssertion(decoded, challenge, credential.PublicKey, credential.SignCount)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), result.SignCount)
+	require.True(t, result.UserVerified)
+
+	// 4.- A counter that does not advance is treated as a cloned authenticator.
+	authenticator.SetSignCount(credential.ID, 0)
+	challenge, err = webauthn.NewChallenge()
+	require.NoError(t, err)
+	replayed, err := authenticator.Get(rp.RequestOptions(challenge, nil))
+	require.NoError(t, err)
+	_, err = rp.VerifyAssertion(replayed, challenge, credential.PublicKey, result.SignCount)
+	require.True(t, errors.Is(err, webauthn.ErrSignCountRegression), "got %v", err)
+}
+
+func TestCeremoniesRejectTamperedResponses(t *testing.T) {
+	rp := newRelyingParty(t)
+	authenticator := webauthntest.NewAuthenticator("https://example.com")
+	credential := register(t, rp, authenticator)
+
+	// 2.- A response for another challenge is refused.
+	challenge, err := webauthn.NewChallenge()
+	require.NoError(t, err)
+	other, err := webauthn.NewChallenge()
+	require.NoError(t, err)
+	assertion, err := authenticator.Get(rp.RequestOptions(challenge, nil))
+	require.NoError(t, err)
+	_, err = rp.VerifyAssertion(assertion, other, credential.PublicKey, 0)
+	require.ErrorIs(t, err, webauthn.ErrInvalidResponse)
+
+	// 3.- A forged signature is refused.
+	assertion, err = authenticator.Get(rp.RequestOptions(challenge, nil))
+	require.NoError(t, err)
+	assertion.Response.Signature[len(assertion.Response.Signature)-1] ^= 0xff
+	_, err = rp.VerifyAssertion(assertion, challenge, credential.PublicKey, 0)
+	require.ErrorIs(t, err, webauthn.ErrInvalidResponse)
+
+	// 4.- Origins outside the configuration are refused for both ceremonies.
+	phishing := webauthntest.NewAuthenticator("https://example.com.evil.test")
+	response, err := phishing.Create(rp.CreationOptions(webauthn.UserEntity{ID: []byte("42"), Name: "user"}, challenge, nil))
+	require.NoError(t, err)
+	_, err = rp.VerifyRegistration(response, challenge)
+	require.ErrorIs(t, err, webauthn.ErrInvalidResponse)
+
+	// 5.- A credential registered for another relying party id is refused.
+	otherRP, err := webauthn.New(webauthn.Config{RPID: "other.example", Origins: []string{"https://example.com"}})
+	require.NoError(t, err)
+	response, err = authenticator.Create(otherRP.CreationOptions(webauthn.UserEntity{ID: []byte("42"), Name: "user"}, challenge, nil))
+	require.NoError(t, err)
+	_, err = rp.VerifyRegistration(response, challenge)
+	require.ErrorIs(t, err, webauthn.ErrInvalidResponse)
+
+	// 6.- Client data claiming the wrong ceremony type is refused.
+	authenticator.Mutate = func(raw []byte) []byte {
+		return bytes.Replace(raw, []byte("webauthn.get"), []byte("webauthn.create"), 1)
+	}
+	assertion, err = authenticator.Get(rp.RequestOptions(challenge, nil))
+	require.NoError(t, err)
+	_, err = rp.VerifyAssertion(assertion, challenge, credential.PublicKey, 0)
+	require.ErrorIs(t, err, webauthn.ErrInvalidResponse)
+}
@@ -0,0 +1,231 @@
+package webauthntest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/example/Yamato-Go-Gin-API/internal/http/auth/webauthn"
+)
+
+// 1.- ErrNoCredential indicates that the authenticator holds no credential matching the request.
+var ErrNoCredential = errors.New("webauthntest: no matching credential")
+
+// 1.- Authenticator is a software ES256 authenticator that speaks the WebAuthn JSON API for tests.
+type Authenticator struct {
+	// 2.- Origin is reported in client data; it must match the relying party configuration.
+	Origin string
+	// 3.- UserVerified controls the UV flag, mimicking a PIN or biometric check.
+	UserVerified bool
+	// 4.- Mutate, when set, may tamper with the client data JSON before it is signed.
+	Mutate func(clientDataJSON []byte) []byte
+
+	mu          sync.Mutex
+	credentials []*credential
+}
+
+// 1.- credential is one resident key held by the authenticator.
+type credential struct {
+	id         []byte
+	rpID       string
+	userHandle []byte
+	key        *ecdsa.PrivateKey
+	signCount  uint32
+}
+
+// 1.- NewAuthenticator returns an authenticator that performs user verification by default.
+func NewAuthenticator(origin string) *Authenticator {
+	return &Authenticator{Origin: origin, UserVerified: true}
+}
+
+// 1.- Create runs navigator.credentials.create and returns the registration response.
+func (a *Authenticator) Create(options webauthn.CreationOptions) (webauthn.RegistrationResponse, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return webauthn.RegistrationResponse{}, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return webauthn.RegistrationResponse{}, err
+	}
+	cred := &credential{id: id, rpID: options.RP.ID, userHandle: append([]byte(nil), options.User.ID...), key: key}
+
+	//1.- Attested credential data: zero AAGUID, credential id, and the COSE public key.
+	attested := make([]byte, 16, 16+2+len(id))
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(id)))
+	attested = append(attested, id...)
+	attested = append(attested, encodeCOSEKey(&key.PublicKey)...)
+	authData := a.authenticatorData(cred, 0x40, attested)
+
+	clientDataJSON := a.clientData("webauthn.create", options.Challenge)
+	attestation := encodeMap(
+		entry{text("fmt"), text("none")},
+		entry{text("attStmt"), encodeMap()},
+		entry{text("authData"), byteString(authData)},
+	)
+
+	a.mu.Lock()
+	a.credentials = append(a.credentials, cred)
+	a.mu.Unlock()
+
+	response := webauthn.RegistrationResponse{ID: base64.RawURLEncoding.EncodeToString(id), RawID: id, Type: "public-key"}
+	response.Response.ClientDataJSON = clientDataJSON
+	response.Response.AttestationObject = attestation
+	response.Response.Transports = []string{"internal"}
+	return response, nil
+}
+
+// 1.- Get runs navigator.credentials.get, honouring the allow list or choosing a discoverable credential.
+func (a *Authenticator) Get(options webauthn.RequestOptions) (webauthn.AssertionResponse, error) {
+	cred := a.find(options)
+	if cred == nil {
+		return webauthn.AssertionResponse{}, ErrNoCredential
+	}
+
+	a.mu.Lock()
+	cred.signCount++
+	authData := a.authenticatorData(cred, 0, nil)
+	a.mu.Unlock()
+
+	clientDataJSON := a.clientData("webauthn.get", options.Challenge)
+	clientHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), clientHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, cred.key, digest[:])
+	if err != nil {
+		return webauthn.AssertionResponse{}, err
+	}
+
+	response := webauthn.AssertionResponse{ID: base64.RawURLEncoding.EncodeToString(cred.id), RawID: cred.id, Type: "public-key"}
+	response.Response.ClientDataJSON = clientDataJSON
+	response.Response.AuthenticatorData = authData
+	response.Response.Signature = signature
+	response.Response.UserHandle = cred.userHandle
+	return response, nil
+}
+
+// 1.- SetSignCount overrides a credential's counter, for example to simulate a cloned authenticator.
+func (a *Authenticator) SetSignCount(credentialID []byte, count uint32) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, cred := range a.credentials {
+		if string(cred.id) == string(credentialID) {
+			cred.signCount = count
+		}
+	}
+}
+
+// 1.- find selects the credential for the relying party, preferring the allow list order.
+func (a *Authenticator) find(options webauthn.RequestOptions) *credential {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, cred := range a.credentials {
+		if cred.rpID != options.RPID {
+			continue
+		}
+		if len(options.AllowCredentials) == 0 {
+			return cred
+		}
+		for _, allowed := range options.AllowCredentials {
+			if string(allowed.ID) == string(cred.id) {
+				return cred
+			}
+		}
+	}
+	return nil
+}
+
+// 1.- authenticatorData assembles rpIdHash, flags, and the counter followed by any attested data.
+func (a *Authenticator) authenticatorData(cred *credential, extraFlags byte, attested []byte) []byte {
+	rpHash := sha256.Sum256([]byte(cred.rpID))
+	flags := byte(0x01) | extraFlags
+	if a.UserVerified {
+		flags |= 0x04
+	}
+	data := append([]byte(nil), rpHash[:]...)
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, cred.signCount)
+	return append(data, attested...)
+}
+
+// 1.- clientData serializes CollectedClientData the way browsers do.
+func (a *Authenticator) clientData(ceremony string, challenge []byte) []byte {
+	raw, _ := json.Marshal(map[string]interface{}{
+		"type":        ceremony,
+		"challenge":   base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":      a.Origin,
+		"crossOrigin": false,
+	})
+	if a.Mutate != nil {
+		raw = a.Mutate(raw)
+	}
+	return raw
+}
+
+// 1.- encodeCOSEKey renders an EC2 P-256 ES256 COSE_Key.
+func encodeCOSEKey(key *ecdsa.PublicKey) []byte {
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	key.X.FillBytes(x)
+	key.Y.FillBytes(y)
+	return encodeMap(
+		entry{integer(1), integer(2)},
+		entry{integer(3), integer(-7)},
+		entry{integer(-1), integer(1)},
+		entry{integer(-2), byteString(x)},
+		entry{integer(-3), byteString(y)},
+	)
+}
+
+// 1.- entry is an already encoded CBOR key/value pair.
+type entry struct {
+	key   []byte
+	value []byte
+}
+
+// 1.- header encodes a CBOR initial byte and argument.
+func header(major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return []byte{major<<5 | byte(n)}
+	case n <= 0xff:
+		return []byte{major<<5 | 24, byte(n)}
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(n))
+	}
+}
+
+// 1.- integer encodes a small signed integer.
+func integer(value int64) []byte {
+	if value >= 0 {
+		return header(0, uint64(value))
+	}
+	return header(1, uint64(-1-value))
+}
+
+// 1.- byteString encodes a CBOR byte string.
+func byteString(value []byte) []byte {
+	return append(header(2, uint64(len(value))), value...)
+}
+
+// 1.- text encodes a CBOR text string.
+func text(value string) []byte {
+	return append(header(3, uint64(len(value))), value...)
+}
+
+// 1.- encodeMap encodes the entries in the given order.
+func encodeMap(entries ...entry) []byte {
+	out := header(5, uint64(len(entries)))
+	for _, e := range entries {
+		out = append(out, e.key...)
+		out = append(out, e.value...)
+	}
+	return out
+}
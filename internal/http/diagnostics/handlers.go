@@ -40,6 +40,7 @@ type Option func(*Handler)
 type Handler struct {
 	db      DBPinger
 	redis   RedisPinger
+	queue   RedisPinger
 	limiter RateLimiter
 	service string
 }
@@ -79,6 +80,13 @@ func WithRedis(redis RedisPinger) Option {
 	}
 }
 
+// 1.- WithQueue injects the Redis connection behind the job queue, which may differ from the token store.
+func WithQueue(queue RedisPinger) Option {
+	return func(h *Handler) {
+		h.queue = queue
+	}
+}
+
 // 1.- WithRateLimiter injects a limiter dependency into the diagnostics handler.
 func WithRateLimiter(limiter RateLimiter) Option {
 	return func(h *Handler) {
@@ -120,20 +128,22 @@ func (h Handler) Health(ctx *gin.Context) {
 		return
 	}
 
-	// 2.- Run the database and Redis connectivity checks sequentially.
+	// 2.- Run the database, Redis, and job queue connectivity checks sequentially.
 	dbStatus := h.checkDatabase(ctx.Request.Context())
 	redisStatus := h.checkRedis(ctx.Request.Context())
+	queueStatus := h.checkQueue(ctx.Request.Context())
 
 	// 3.- Aggregate check results to determine overall availability.
 	checks := map[string]componentStatus{
 		"database": dbStatus,
 		"redis":    redisStatus,
+		"queue":    queueStatus,
 	}
 
 	// 4.- Derive the correct HTTP status code based on dependency health.
 	statusCode := http.StatusOK
 	overall := "ok"
-	if dbStatus.Status == "error" || redisStatus.Status == "error" {
+	if dbStatus.Status == "error" || redisStatus.Status == "error" || queueStatus.Status == "error" {
 		statusCode = http.StatusServiceUnavailable
 		overall = "error"
 	}
@@ -158,12 +168,14 @@ func (h Handler) Ready(ctx *gin.Context) {
 	// 2.- Reuse the dependency checks to evaluate readiness status.
 	dbStatus := h.checkDatabase(ctx.Request.Context())
 	redisStatus := h.checkRedis(ctx.Request.Context())
+	queueStatus := h.checkQueue(ctx.Request.Context())
 
 	// 3.- Calculate readiness from individual dependency health states.
-	ready := dbStatus.Status != "error" && redisStatus.Status != "error"
+	ready := dbStatus.Status != "error" && redisStatus.Status != "error" && queueStatus.Status != "error"
 	checks := map[string]componentStatus{
 		"database": dbStatus,
 		"redis":    redisStatus,
+		"queue":    queueStatus,
 	}
 
 	// 4.- Serve the readiness payload and propagate failure when necessary.
@@ -250,6 +262,21 @@ func (h Handler) checkRedis(ctx context.Context) componentStatus {
 	return componentStatus{Status: "ok"}
 }
 
+// 1.- checkQueue executes the optional job queue connectivity probe.
+func (h Handler) checkQueue(ctx context.Context) componentStatus {
+	// 1.- Skip the check when no queue connection has been supplied.
+	if h.queue == nil {
+		return componentStatus{Status: "skipped"}
+	}
+
+	// 2.- Ping the queue's Redis and record either success or the failure message.
+	if err := h.queue.Ping(ctx); err != nil {
+		return componentStatus{Status: "error", Error: err.Error()}
+	}
+
+	return componentStatus{Status: "ok"}
+}
+
 // 1.- collectErrors extracts dependency errors for inclusion in failure payloads.
 func (h Handler) collectErrors(checks map[string]componentStatus) map[string]string {
 	// 1.- Initialise the error map to avoid nil handling downstream.
@@ -153,6 +153,43 @@ func TestReadyReflectsDependencyStatus(t *testing.T) {
 	}
 }
 
+// 1.- TestHealthChecksQueueSeparately reports a queue outage even when the token store answers.
+func TestHealthChecksQueueSeparately(t *testing.T) {
+	// 1.- Mirror REDIS_MODE=memory: the emulator answers while the queue's server is down.
+	jobs := &stubRedis{err: errors.New("dial tcp: connection refused")}
+	handler := NewHandler("Test Service", WithRedis(&stubRedis{}), WithQueue(jobs))
+
+	// 2.- Invoke both probes.
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	router.GET("/api/health", handler.Health)
+	router.GET("/ready", handler.Ready)
+
+	for _, path := range []string{"/api/health", "/ready"} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+		// 3.- Expect 503 naming the queue rather than Redis.
+		if recorder.Code != http.StatusServiceUnavailable {
+			t.Fatalf("%s: expected status 503, got %d", path, recorder.Code)
+		}
+		var envelope struct {
+			Errors struct {
+				Checks map[string]string `json:"checks"`
+			} `json:"errors"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &envelope); err != nil {
+			t.Fatalf("%s: failed to decode error response: %v", path, err)
+		}
+		if envelope.Errors.Checks["queue"] != jobs.err.Error() {
+			t.Fatalf("%s: expected queue error, got %v", path, envelope.Errors.Checks)
+		}
+		if _, ok := envelope.Errors.Checks["redis"]; ok {
+			t.Fatalf("%s: expected redis to stay healthy, got %v", path, envelope.Errors.Checks)
+		}
+	}
+}
+
 // 1.- TestRateLimitFailure short-circuits when the limiter rejects the request.
 func TestRateLimitFailure(t *testing.T) {
 	// 1.- Configure a limiter stub that denies the incoming request.
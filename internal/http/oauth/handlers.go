@@ -0,0 +1,327 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+)
+
+// 1.- TokenTypePersonalAccess reports personal access tokens in introspection responses.
+const TokenTypePersonalAccess = "personal_access_token"
+
+// 1.- GrantTypeClientCredentials is the only grant_type accepted by the token endpoint.
+const GrantTypeClientCredentials = "client_credentials"
+
+// 1.- ScopeRevokeAny lets a client revoke user tokens and tokens issued to other clients.
+const ScopeRevokeAny = "oauth:revoke"
+
+// 1.- TokenIntrospector inspects and revokes the JWTs issued by the auth service.
+type TokenIntrospector interface {
+	Introspect(ctx context.Context, token string, hint string) (internalauth.TokenInfo, error)
+	Revoke(ctx context.Context, token string, hint string) error
+}
+
+// 1.- ClientTokenIssuer mints access tokens for clients using the client_credentials grant.
+type ClientTokenIssuer interface {
+	IssueClientToken(ctx context.Context, clientID string, scopes []string) (internalauth.ClientToken, error)
+}
+
+// 1.- PersonalAccessTokens resolves and revokes yat_ tokens so resource servers can validate them too.
+type PersonalAccessTokens interface {
+	authhttp.AccessTokenAuthenticator
+	Revoke(ctx context.Context, userID string, id string) error
+}
+
+// 1.- Handler serves the OAuth 2.0 endpoints used by registered clients.
+type Handler struct {
+	clients      ClientAuthenticator
+	tokens       TokenIntrospector
+	accessTokens PersonalAccessTokens
+	issuer       ClientTokenIssuer
+}
+
+// 1.- Option customizes optional handler dependencies.
+type Option func(*Handler)
+
+// 1.- WithPersonalAccessTokens lets introspection and revocation understand personal access tokens.
+func WithPersonalAccessTokens(tokens PersonalAccessTokens) Option {
+	return func(h *Handler) {
+		h.accessTokens = tokens
+	}
+}
+
+// 1.- WithClientCredentials enables the client_credentials grant on the token endpoint.
+func WithClientCredentials(issuer ClientTokenIssuer) Option {
+	return func(h *Handler) {
+		h.issuer = issuer
+	}
+}
+
+// 1.- NewHandler creates a Handler for the given client registry and token service.
+func NewHandler(clients ClientAuthenticator, tokens TokenIntrospector, opts ...Option) Handler {
+	handler := Handler{clients: clients, tokens: tokens}
+	for _, opt := range opts {
+		opt(&handler)
+	}
+	return handler
+}
+
+// 1.- Introspect implements RFC 7662: it reports whether a token is active and, if so, its claims.
+func (h Handler) Introspect(ctx *gin.Context) {
+	if _, ok := h.authenticateClient(ctx); !ok {
+		return
+	}
+	token, hint, ok := tokenParameters(ctx)
+	if !ok {
+		return
+	}
+
+	//1.- Personal access tokens carry their own prefix; everything else is treated as a JWT.
+	if h.accessTokens != nil && strings.HasPrefix(token, authhttp.AccessTokenPrefix) {
+		record, _, err := h.accessTokens.AuthenticateAccessToken(ctx.Request.Context(), token)
+		if err != nil {
+			if errors.Is(err, authhttp.ErrInvalidAccessToken) {
+				writeInactive(ctx)
+				return
+			}
+			writeOAuthError(ctx, http.StatusInternalServerError, "server_error", err.Error())
+			return
+		}
+		response := gin.H{
+			"active":     true,
+			"token_type": TokenTypePersonalAccess,
+			"sub":        record.UserID,
+			"jti":        record.ID,
+			"scope":      strings.Join(record.Scopes, " "),
+			"iat":        record.CreatedAt.Unix(),
+		}
+		if !record.ExpiresAt.IsZero() {
+			response["exp"] = record.ExpiresAt.Unix()
+		}
+		writeNoStore(ctx, http.StatusOK, response)
+		return
+	}
+
+	info, err := h.tokens.Introspect(ctx.Request.Context(), token, hint)
+	if err != nil {
+		writeOAuthError(ctx, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	if !info.Active {
+		writeInactive(ctx)
+		return
+	}
+	response := gin.H{
+		"active":     true,
+		"token_type": info.TokenType,
+		"sub":        info.Subject,
+		"jti":        info.JTI,
+		"scope":      info.Scope,
+		"iss":        info.Issuer,
+		"exp":        info.ExpiresAt.Unix(),
+		"iat":        info.IssuedAt.Unix(),
+	}
+	if len(info.Audience) > 0 {
+		response["aud"] = info.Audience
+	}
+	if info.SessionID != "" {
+		response["sid"] = info.SessionID
+	}
+	if info.Actor != "" {
+		response["act"] = gin.H{"sub": info.Actor}
+	}
+	if info.ClientID != "" {
+		response["client_id"] = info.ClientID
+	}
+	writeNoStore(ctx, http.StatusOK, response)
+}
+
+// 1.- Token implements the RFC 6749 §4.4 client_credentials grant for service-to-service calls.
+func (h Handler) Token(ctx *gin.Context) {
+	client, ok := h.authenticateClient(ctx)
+	if !ok {
+		return
+	}
+	if grantType := strings.TrimSpace(ctx.PostForm("grant_type")); grantType != GrantTypeClientCredentials {
+		writeOAuthError(ctx, http.StatusBadRequest, "unsupported_grant_type", "only client_credentials is supported")
+		return
+	}
+	if h.issuer == nil {
+		writeOAuthError(ctx, http.StatusServiceUnavailable, "temporarily_unavailable", "client credentials not configured")
+		return
+	}
+
+	//1.- Requested scopes must be a subset of the client's allowed scopes; none requested means all of them.
+	scopes, ok := grantedScopes(client.Scopes, strings.Fields(ctx.PostForm("scope")))
+	if !ok {
+		writeOAuthError(ctx, http.StatusBadRequest, "invalid_scope", "requested scope exceeds the client's allowed scopes")
+		return
+	}
+
+	token, err := h.issuer.IssueClientToken(ctx.Request.Context(), client.ClientID, scopes)
+	if err != nil {
+		writeOAuthError(ctx, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	writeNoStore(ctx, http.StatusOK, gin.H{
+		"access_token": token.AccessToken,
+		"token_type":   "Bearer",
+		"expires_in":   int64(time.Until(token.ExpiresAt).Round(time.Second) / time.Second),
+		"scope":        token.Scope,
+	})
+}
+
+// 1.- Revoke implements RFC 7009: it invalidates the token and answers 200 even for unknown tokens.
+// Clients may revoke tokens issued to themselves; user tokens and other clients' tokens need ScopeRevokeAny.
+func (h Handler) Revoke(ctx *gin.Context) {
+	client, ok := h.authenticateClient(ctx)
+	if !ok {
+		return
+	}
+	token, hint, ok := tokenParameters(ctx)
+	if !ok {
+		return
+	}
+
+	if h.accessTokens != nil && strings.HasPrefix(token, authhttp.AccessTokenPrefix) {
+		record, _, err := h.accessTokens.AuthenticateAccessToken(ctx.Request.Context(), token)
+		if err == nil {
+			//1.- Personal access tokens belong to users, never to a client.
+			if !mayRevoke(client, "") {
+				writeUnauthorizedRevocation(ctx)
+				return
+			}
+			err = h.accessTokens.Revoke(ctx.Request.Context(), record.UserID, record.ID)
+		}
+		if err != nil && !errors.Is(err, authhttp.ErrInvalidAccessToken) && !errors.Is(err, authhttp.ErrAccessTokenNotFound) {
+			writeOAuthError(ctx, http.StatusServiceUnavailable, "temporarily_unavailable", err.Error())
+			return
+		}
+		ctx.Status(http.StatusOK)
+		return
+	}
+
+	//2.- Resolve the owner first; tokens that are already unusable need no revocation.
+	info, err := h.tokens.Introspect(ctx.Request.Context(), token, hint)
+	if err != nil {
+		writeOAuthError(ctx, http.StatusServiceUnavailable, "temporarily_unavailable", err.Error())
+		return
+	}
+	if !info.Active {
+		ctx.Status(http.StatusOK)
+		return
+	}
+	if !mayRevoke(client, info.ClientID) {
+		writeUnauthorizedRevocation(ctx)
+		return
+	}
+	if err := h.tokens.Revoke(ctx.Request.Context(), token, info.TokenType); err != nil {
+		writeOAuthError(ctx, http.StatusServiceUnavailable, "temporarily_unavailable", err.Error())
+		return
+	}
+	ctx.Status(http.StatusOK)
+}
+
+// 1.- mayRevoke reports whether client may revoke a token issued to owner; an empty owner means a user token.
+func mayRevoke(client Client, owner string) bool {
+	if owner != "" && owner == client.ClientID {
+		return true
+	}
+	for _, scope := range client.Scopes {
+		if scope == ScopeRevokeAny {
+			return true
+		}
+	}
+	return false
+}
+
+// 1.- writeUnauthorizedRevocation refuses revocation of a token the client does not own (RFC 7009 §2.1).
+func writeUnauthorizedRevocation(ctx *gin.Context) {
+	writeOAuthError(ctx, http.StatusBadRequest, "unauthorized_client", "token was not issued to this client")
+}
+
+// 1.- authenticateClient accepts client_secret_basic or client_secret_post credentials (RFC 6749 §2.3.1).
+func (h Handler) authenticateClient(ctx *gin.Context) (Client, bool) {
+	clientID, secret, basic := ctx.Request.BasicAuth()
+	if basic {
+		//1.- Basic credentials are form-encoded before being joined, so decode them first.
+		var err error
+		if clientID, err = url.QueryUnescape(clientID); err != nil {
+			basic = false
+		} else if secret, err = url.QueryUnescape(secret); err != nil {
+			basic = false
+		}
+	}
+	if !basic {
+		clientID, secret = ctx.PostForm("client_id"), ctx.PostForm("client_secret")
+	}
+
+	if h.clients == nil {
+		writeOAuthError(ctx, http.StatusServiceUnavailable, "temporarily_unavailable", "client registry not configured")
+		return Client{}, false
+	}
+	client, err := h.clients.AuthenticateClient(ctx.Request.Context(), clientID, secret)
+	if err != nil {
+		if errors.Is(err, ErrInvalidClient) {
+			ctx.Header("WWW-Authenticate", `Basic realm="oauth"`)
+			writeOAuthError(ctx, http.StatusUnauthorized, "invalid_client", "client authentication failed")
+			return Client{}, false
+		}
+		writeOAuthError(ctx, http.StatusInternalServerError, "server_error", err.Error())
+		return Client{}, false
+	}
+	return client, true
+}
+
+// 1.- grantedScopes narrows the allowed scopes to the requested ones, failing on anything not allowed.
+func grantedScopes(allowed []string, requested []string) ([]string, bool) {
+	if len(requested) == 0 {
+		return allowed, true
+	}
+	permitted := make(map[string]struct{}, len(allowed))
+	for _, scope := range allowed {
+		permitted[scope] = struct{}{}
+	}
+	for _, scope := range requested {
+		if _, ok := permitted[scope]; !ok {
+			return nil, false
+		}
+	}
+	return normalizeScopes(requested), true
+}
+
+// 1.- tokenParameters reads the form-encoded token and optional token_type_hint.
+func tokenParameters(ctx *gin.Context) (string, string, bool) {
+	token := strings.TrimSpace(ctx.PostForm("token"))
+	if token == "" {
+		writeOAuthError(ctx, http.StatusBadRequest, "invalid_request", "token is required")
+		return "", "", false
+	}
+	return token, strings.TrimSpace(ctx.PostForm("token_type_hint")), true
+}
+
+// 1.- writeInactive answers with the bare RFC 7662 inactive response, revealing nothing else.
+func writeInactive(ctx *gin.Context) {
+	writeNoStore(ctx, http.StatusOK, gin.H{"active": false})
+}
+
+// 1.- writeOAuthError renders the RFC 6749 §5.2 error format instead of the API envelope.
+func writeOAuthError(ctx *gin.Context, status int, code string, description string) {
+	writeNoStore(ctx, status, gin.H{"error": code, "error_description": description})
+	ctx.Abort()
+}
+
+// 1.- writeNoStore writes JSON that intermediaries must not cache.
+func writeNoStore(ctx *gin.Context, status int, body gin.H) {
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Pragma", "no-cache")
+	ctx.JSON(status, body)
+}
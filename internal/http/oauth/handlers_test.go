@@ -0,0 +1,254 @@
+package oauth_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/config"
+	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+	oauthhttp "github.com/example/Yamato-Go-Gin-API/internal/http/oauth"
+	"github.com/example/Yamato-Go-Gin-API/internal/platform/memory"
+)
+
+// 1.- TestIntrospectAndRevoke covers client authentication, JWT and personal access token introspection, and revocation.
+func TestIntrospectAndRevoke(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	// 2.- Wire the auth service on miniredis and register one resource server client.
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	authSvc, err := internalauth.NewService(config.JWTConfig{
+		Secret:            "oauth-test-secret",
+		Issuer:            "oauth-test",
+		Audience:          "oauth-test-audience",
+		AccessExpiration:  time.Minute,
+		RefreshExpiration: time.Hour,
+	}, client)
+	require.NoError(t, err)
+
+	registry := oauthhttp.NewClientRegistry(memory.NewOAuthClientStore())
+	_, secret, err := registry.Register(ctx, "resource-server", "Resource server", []string{oauthhttp.ScopeRevokeAny})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(secret, oauthhttp.ClientSecretPrefix))
+
+	accessTokens := authhttp.NewAccessTokenService(memory.NewAccessTokenStore(), authhttp.AccessTokenConfig{
+		Scopes: map[string][]string{"tasks:read": {"tasks.view"}},
+	})
+	pat, _, err := accessTokens.Create(ctx, internalauth.Principal{Subject: "42", Permissions: []string{"tasks.view"}}, "ci", []string{"tasks:read"}, time.Time{})
+	require.NoError(t, err)
+
+	handler := oauthhttp.NewHandler(registry, authSvc, oauthhttp.WithPersonalAccessTokens(accessTokens))
+	engine := gin.New()
+	engine.POST("/v1/oauth/introspect", handler.Introspect)
+	engine.POST("/v1/oauth/revoke", handler.Revoke)
+
+	perform := func(path string, form url.Values, basic bool) *httptest.ResponseRecorder {
+		if !basic {
+			form.Set("client_id", "resource-server")
+			form.Set("client_secret", secret)
+		}
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if basic {
+			req.SetBasicAuth("resource-server", secret)
+		}
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		return rec
+	}
+	decode := func(rec *httptest.ResponseRecorder) map[string]interface{} {
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return body
+	}
+
+	// 3.- Unknown clients and missing tokens are rejected with RFC 6749 errors.
+	req := httptest.NewRequest(http.MethodPost, "/v1/oauth/introspect", strings.NewReader("token=abc"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("resource-server", "wrong")
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	require.Equal(t, "invalid_client", decode(rec)["error"])
+	require.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+	missing := perform("/v1/oauth/introspect", url.Values{}, true)
+	require.Equal(t, http.StatusBadRequest, missing.Code)
+	require.Equal(t, "invalid_request", decode(missing)["error"])
+
+	// 4.- An issued access token is active with its claims; garbage is inactive.
+	pair, err := authSvc.Login(ctx, "7")
+	require.NoError(t, err)
+	active := perform("/v1/oauth/introspect", url.Values{"token": {pair.AccessToken}}, true)
+	require.Equal(t, http.StatusOK, active.Code)
+	require.Equal(t, "no-store", active.Header().Get("Cache-Control"))
+	body := decode(active)
+	require.Equal(t, true, body["active"])
+	require.Equal(t, "7", body["sub"])
+	require.Equal(t, internalauth.TokenTypeAccess, body["token_type"])
+	require.NotEmpty(t, body["jti"])
+	require.NotZero(t, body["exp"])
+	require.Contains(t, body, "scope")
+	garbage := perform("/v1/oauth/introspect", url.Values{"token": {"garbage"}}, false)
+	require.Equal(t, map[string]interface{}{"active": false}, decode(garbage))
+
+	// 5.- Personal access tokens report their scopes until revoked.
+	patInfo := decode(perform("/v1/oauth/introspect", url.Values{"token": {pat}}, false))
+	require.Equal(t, true, patInfo["active"])
+	require.Equal(t, oauthhttp.TokenTypePersonalAccess, patInfo["token_type"])
+	require.Equal(t, "tasks:read", patInfo["scope"])
+	require.Equal(t, http.StatusOK, perform("/v1/oauth/revoke", url.Values{"token": {pat}}, false).Code)
+	require.Equal(t, false, decode(perform("/v1/oauth/introspect", url.Values{"token": {pat}}, false))["active"])
+
+	// 6.- Revoking the refresh token ends the session; unknown tokens still answer 200.
+	require.Equal(t, http.StatusOK, perform("/v1/oauth/revoke", url.Values{"token": {pair.RefreshToken}, "token_type_hint": {internalauth.TokenTypeRefresh}}, true).Code)
+	require.Equal(t, false, decode(perform("/v1/oauth/introspect", url.Values{"token": {pair.AccessToken}}, true))["active"])
+	require.Equal(t, http.StatusOK, perform("/v1/oauth/revoke", url.Values{"token": {"garbage"}}, true).Code)
+}
+
+// 1.- TestRevokeRequiresTokenOwnership keeps clients from revoking user tokens or tokens issued to other clients.
+func TestRevokeRequiresTokenOwnership(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	authSvc, err := internalauth.NewService(config.JWTConfig{Secret: "oauth-test-secret", Issuer: "oauth-test", AccessExpiration: time.Minute, RefreshExpiration: time.Hour}, client)
+	require.NoError(t, err)
+
+	// 2.- Two ordinary clients, neither holding the revoke-any scope.
+	registry := oauthhttp.NewClientRegistry(memory.NewOAuthClientStore())
+	_, secretA, err := registry.Register(ctx, "client-a", "Client A", nil)
+	require.NoError(t, err)
+	_, secretB, err := registry.Register(ctx, "client-b", "Client B", nil)
+	require.NoError(t, err)
+
+	accessTokens := authhttp.NewAccessTokenService(memory.NewAccessTokenStore(), authhttp.AccessTokenConfig{
+		Scopes: map[string][]string{"tasks:read": {"tasks.view"}},
+	})
+	pat, _, err := accessTokens.Create(ctx, internalauth.Principal{Subject: "42", Permissions: []string{"tasks.view"}}, "ci", []string{"tasks:read"}, time.Time{})
+	require.NoError(t, err)
+
+	handler := oauthhttp.NewHandler(registry, authSvc, oauthhttp.WithPersonalAccessTokens(accessTokens), oauthhttp.WithClientCredentials(authSvc))
+	engine := gin.New()
+	engine.POST("/v1/oauth/introspect", handler.Introspect)
+	engine.POST("/v1/oauth/revoke", handler.Revoke)
+
+	perform := func(clientID string, secret string, path string, form url.Values) (int, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth(clientID, secret)
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		var body map[string]interface{}
+		if rec.Body.Len() > 0 {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		}
+		return rec.Code, body
+	}
+	active := func(token string) bool {
+		_, body := perform("client-a", secretA, "/v1/oauth/introspect", url.Values{"token": {token}})
+		return body["active"] == true
+	}
+
+	pair, err := authSvc.Login(ctx, "7")
+	require.NoError(t, err)
+	clientToken, err := authSvc.IssueClientToken(ctx, "client-a", nil)
+	require.NoError(t, err)
+
+	// 3.- Client B may touch neither the user's session, the user's PAT, nor client A's token.
+	for _, token := range []string{pair.RefreshToken, pair.AccessToken, pat, clientToken.AccessToken} {
+		code, body := perform("client-b", secretB, "/v1/oauth/revoke", url.Values{"token": {token}})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Equal(t, "unauthorized_client", body["error"])
+		require.True(t, active(token))
+	}
+
+	// 4.- Client A still may not revoke user tokens, but may revoke its own.
+	code, body := perform("client-a", secretA, "/v1/oauth/revoke", url.Values{"token": {pair.RefreshToken}})
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Equal(t, "unauthorized_client", body["error"])
+	code, _ = perform("client-a", secretA, "/v1/oauth/revoke", url.Values{"token": {clientToken.AccessToken}})
+	require.Equal(t, http.StatusOK, code)
+	require.False(t, active(clientToken.AccessToken))
+
+	// 5.- Inactive and unknown tokens still answer 200 to any client.
+	code, _ = perform("client-b", secretB, "/v1/oauth/revoke", url.Values{"token": {clientToken.AccessToken}})
+	require.Equal(t, http.StatusOK, code)
+	code, _ = perform("client-b", secretB, "/v1/oauth/revoke", url.Values{"token": {"garbage"}})
+	require.Equal(t, http.StatusOK, code)
+}
+
+// 1.- TestClientCredentialsGrant issues service tokens limited to the client's allowed scopes.
+func TestClientCredentialsGrant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	authSvc, err := internalauth.NewService(config.JWTConfig{Secret: "oauth-test-secret", Issuer: "oauth-test", AccessExpiration: time.Minute}, client)
+	require.NoError(t, err)
+
+	registry := oauthhttp.NewClientRegistry(memory.NewOAuthClientStore())
+	_, secret, err := registry.Register(ctx, "worker", "Background worker", []string{"admin:users", "admin:teams"})
+	require.NoError(t, err)
+
+	engine := gin.New()
+	plain := oauthhttp.NewHandler(registry, authSvc)
+	handler := oauthhttp.NewHandler(registry, authSvc, oauthhttp.WithClientCredentials(authSvc))
+	engine.POST("/plain/token", plain.Token)
+	engine.POST("/v1/oauth/token", handler.Token)
+	engine.POST("/v1/oauth/introspect", handler.Introspect)
+
+	perform := func(path string, form url.Values) (int, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth("worker", secret)
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return rec.Code, body
+	}
+
+	// 2.- Only client_credentials is supported, and only when an issuer is configured.
+	code, body := perform("/v1/oauth/token", url.Values{"grant_type": {"password"}})
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Equal(t, "unsupported_grant_type", body["error"])
+	code, _ = perform("/plain/token", url.Values{"grant_type": {oauthhttp.GrantTypeClientCredentials}})
+	require.Equal(t, http.StatusServiceUnavailable, code)
+
+	// 3.- Scopes outside the allowed set are refused; a subset is granted as requested.
+	code, body = perform("/v1/oauth/token", url.Values{"grant_type": {oauthhttp.GrantTypeClientCredentials}, "scope": {"admin:roles"}})
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Equal(t, "invalid_scope", body["error"])
+	code, body = perform("/v1/oauth/token", url.Values{"grant_type": {oauthhttp.GrantTypeClientCredentials}, "scope": {"admin:teams"}})
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "Bearer", body["token_type"])
+	require.Equal(t, "admin:teams", body["scope"])
+	require.InDelta(t, 60, body["expires_in"], 2)
+
+	// 4.- Without a scope parameter every allowed scope is granted, and introspection names the client.
+	code, body = perform("/v1/oauth/token", url.Values{"grant_type": {oauthhttp.GrantTypeClientCredentials}})
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "admin:teams admin:users", body["scope"])
+	_, info := perform("/v1/oauth/introspect", url.Values{"token": {body["access_token"].(string)}})
+	require.Equal(t, true, info["active"])
+	require.Equal(t, "worker", info["client_id"])
+	require.Equal(t, internalauth.ClientSubjectPrefix+"worker", info["sub"])
+}
@@ -0,0 +1,166 @@
+package privacy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/respond"
+)
+
+// 1.- PasswordChecker re-verifies the password before an account is deleted.
+type PasswordChecker interface {
+	CheckPassword(hash string, password string) error
+}
+
+// 1.- Handler wires Gin routes to the privacy workflow.
+type Handler struct {
+	manager   Manager
+	users     authhttp.UserStore
+	passwords PasswordChecker
+	sessions  authhttp.SessionRevoker
+}
+
+// 1.- NewHandler creates a Handler; sessions may be nil when refresh tokens need no revocation.
+func NewHandler(manager Manager, users authhttp.UserStore, passwords PasswordChecker, sessions authhttp.SessionRevoker) Handler {
+	return Handler{manager: manager, users: users, passwords: passwords, sessions: sessions}
+}
+
+// 1.- deleteAccountRequest carries the password confirming the deletion; a recent sign-in may omit it.
+type deleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+// 1.- RequestExport queues a new archive of the caller's personal data.
+func (h Handler) RequestExport(ctx *gin.Context) {
+	principal, ok := interactivePrincipal(ctx)
+	if !ok {
+		return
+	}
+
+	export, err := h.manager.RequestExport(ctx.Request.Context(), principal.Subject)
+	if err != nil {
+		if errors.Is(err, ErrExportInProgress) {
+			respond.Error(ctx, http.StatusConflict, "export already in progress", nil)
+			return
+		}
+		respond.Error(ctx, http.StatusInternalServerError, "failed to request export", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	respond.Success(ctx, http.StatusAccepted, export, nil)
+}
+
+// 1.- ExportStatus reports the state of the caller's latest export.
+func (h Handler) ExportStatus(ctx *gin.Context) {
+	principal, ok := internalauth.PrincipalFromContext(ctx)
+	if !ok {
+		respond.Error(ctx, http.StatusUnauthorized, "authentication required", map[string]interface{}{"reason": "principal missing"})
+		return
+	}
+
+	export, err := h.manager.LatestExport(ctx.Request.Context(), principal.Subject)
+	if err != nil {
+		if errors.Is(err, ErrExportNotFound) {
+			respond.Error(ctx, http.StatusNotFound, "export not found", nil)
+			return
+		}
+		respond.Error(ctx, http.StatusInternalServerError, "failed to load export", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	respond.Success(ctx, http.StatusOK, export, nil)
+}
+
+// 1.- DownloadExport streams the archive of the caller's latest completed export.
+func (h Handler) DownloadExport(ctx *gin.Context) {
+	principal, ok := interactivePrincipal(ctx)
+	if !ok {
+		return
+	}
+
+	export, reader, err := h.manager.OpenExport(ctx.Request.Context(), principal.Subject)
+	if err != nil {
+		if errors.Is(err, ErrExportNotFound) {
+			respond.Error(ctx, http.StatusNotFound, "no completed export available", nil)
+			return
+		}
+		respond.Error(ctx, http.StatusInternalServerError, "failed to open export", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	defer reader.Close()
+
+	ctx.DataFromReader(http.StatusOK, -1, "application/zip", reader, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="personal-data-%s.zip"`, export.ID),
+		"Cache-Control":       "no-store",
+	})
+}
+
+// 1.- DeleteAccount soft-deletes the caller's account after re-authenticating the owner.
+func (h Handler) DeleteAccount(ctx *gin.Context) {
+	principal, ok := interactivePrincipal(ctx)
+	if !ok {
+		return
+	}
+
+	// 2.- Bind the payload; the password may be omitted by sessions that signed in recently.
+	var req deleteAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respond.Error(ctx, http.StatusBadRequest, "invalid request payload", map[string]interface{}{"details": err.Error()})
+		return
+	}
+
+	// 3.- Re-authenticate so a borrowed session cannot erase the account.
+	user, err := h.users.FindByID(ctx.Request.Context(), principal.Subject)
+	if err != nil {
+		if errors.Is(err, authhttp.ErrUserNotFound) {
+			respond.Error(ctx, http.StatusNotFound, "user not found", nil)
+			return
+		}
+		respond.Error(ctx, http.StatusInternalServerError, "failed to load user", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	if !authhttp.ConfirmIdentity(ctx, principal, user, req.Password, h.passwords.CheckPassword) {
+		return
+	}
+
+	// 4.- Soft-delete the account and sign out every session.
+	purgeAfter, err := h.manager.DeleteAccount(ctx.Request.Context(), user.ID)
+	if err != nil {
+		if errors.Is(err, authhttp.ErrUserNotFound) {
+			respond.Error(ctx, http.StatusNotFound, "user not found", nil)
+			return
+		}
+		respond.Error(ctx, http.StatusInternalServerError, "failed to delete account", map[string]interface{}{"details": err.Error()})
+		return
+	}
+	if h.sessions != nil {
+		if err := h.sessions.RevokeAll(ctx.Request.Context(), user.ID); err != nil {
+			respond.Error(ctx, http.StatusInternalServerError, "failed to revoke sessions", map[string]interface{}{"details": err.Error()})
+			return
+		}
+	}
+
+	respond.Success(ctx, http.StatusAccepted, map[string]any{
+		"status":      "Account scheduled for deletion.",
+		"purge_after": purgeAfter.UTC().Format(time.RFC3339),
+	}, nil)
+}
+
+// 1.- interactivePrincipal admits only password-backed sessions, not personal access tokens.
+func interactivePrincipal(ctx *gin.Context) (internalauth.Principal, bool) {
+	principal, ok := internalauth.PrincipalFromContext(ctx)
+	if !ok {
+		respond.Error(ctx, http.StatusUnauthorized, "authentication required", map[string]interface{}{"reason": "principal missing"})
+		return internalauth.Principal{}, false
+	}
+	if principal.TokenID != "" {
+		respond.Error(ctx, http.StatusForbidden, "access tokens cannot manage personal data", map[string]interface{}{"reason": "interactive session required"})
+		return internalauth.Principal{}, false
+	}
+	return principal, true
+}
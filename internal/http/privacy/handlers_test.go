@@ -0,0 +1,270 @@
+package privacy
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/middleware"
+	memoryplatform "github.com/example/Yamato-Go-Gin-API/internal/platform/memory"
+	"github.com/example/Yamato-Go-Gin-API/internal/queue"
+	"github.com/example/Yamato-Go-Gin-API/internal/storage"
+)
+
+// 1.- memoryExports keeps export requests in insertion order.
+type memoryExports struct {
+	items []Export
+}
+
+func (m *memoryExports) Create(_ context.Context, userID string) (Export, error) {
+	export := Export{ID: strconv.Itoa(len(m.items) + 1), UserID: userID, Status: ExportPending, CreatedAt: time.Now()}
+	m.items = append(m.items, export)
+	return export, nil
+}
+
+func (m *memoryExports) Find(_ context.Context, id string) (Export, error) {
+	for _, export := range m.items {
+		if export.ID == id {
+			return export, nil
+		}
+	}
+	return Export{}, ErrExportNotFound
+}
+
+func (m *memoryExports) Latest(_ context.Context, userID string) (Export, error) {
+	for i := len(m.items) - 1; i >= 0; i-- {
+		if m.items[i].UserID == userID {
+			return m.items[i], nil
+		}
+	}
+	return Export{}, ErrExportNotFound
+}
+
+func (m *memoryExports) Complete(_ context.Context, id string, filePath string, completedAt time.Time) error {
+	for i := range m.items {
+		if m.items[i].ID == id {
+			m.items[i].Status, m.items[i].FilePath, m.items[i].CompletedAt = ExportCompleted, filePath, &completedAt
+			return nil
+		}
+	}
+	return ErrExportNotFound
+}
+
+func (m *memoryExports) Fail(_ context.Context, id string, reason string) error {
+	for i := range m.items {
+		if m.items[i].ID == id {
+			m.items[i].Status, m.items[i].Error = ExportFailed, reason
+			return nil
+		}
+	}
+	return ErrExportNotFound
+}
+
+// 1.- staticData returns the same data set for every user.
+type staticData struct {
+	data UserData
+}
+
+func (s staticData) CollectUserData(context.Context, string) (UserData, error) {
+	return s.data, nil
+}
+
+// 1.- memoryAccounts records soft deletions and purges them against the export list.
+type memoryAccounts struct {
+	deleted map[string]time.Time
+	exports *memoryExports
+}
+
+func (m *memoryAccounts) SoftDelete(_ context.Context, userID string, deletedAt time.Time) error {
+	if _, ok := m.deleted[userID]; ok {
+		return authhttp.ErrUserNotFound
+	}
+	m.deleted[userID] = deletedAt
+	return nil
+}
+
+func (m *memoryAccounts) PurgeDeleted(_ context.Context, before time.Time) (PurgeResult, error) {
+	result := PurgeResult{ExportFiles: []string{}}
+	for userID, deletedAt := range m.deleted {
+		if !deletedAt.Before(before) {
+			continue
+		}
+		for _, export := range m.exports.items {
+			if export.UserID == userID && export.FilePath != "" {
+				result.ExportFiles = append(result.ExportFiles, export.FilePath)
+			}
+		}
+		delete(m.deleted, userID)
+		result.Users++
+	}
+	return result, nil
+}
+
+// 1.- recordingQueue captures queued jobs so the test can run them.
+type recordingQueue struct {
+	jobs []queue.Message
+}
+
+func (q *recordingQueue) Enqueue(_ context.Context, jobName string, payload map[string]any) (queue.Message, error) {
+	message := queue.Message{Job: jobName, Payload: payload}
+	q.jobs = append(q.jobs, message)
+	return message, nil
+}
+
+// 1.- plainPasswords treats "hash:<password>" as the stored hash.
+type plainPasswords struct{}
+
+func (plainPasswords) CheckPassword(hash string, password string) error {
+	if hash != "hash:"+password {
+		return errors.New("mismatch")
+	}
+	return nil
+}
+
+// 1.- recordingRevoker remembers whose sessions were revoked.
+type recordingRevoker struct {
+	subjects []string
+}
+
+func (r *recordingRevoker) RevokeAll(_ context.Context, subject string) error {
+	r.subjects = append(r.subjects, subject)
+	return nil
+}
+
+// 1.- TestExportAndDeletionLifecycle walks an export from request to download, then deletes and purges the account.
+func TestExportAndDeletionLifecycle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	// 2.- Wire the service with in-memory stores and a temp-dir file service.
+	users := memoryplatform.NewUserStore()
+	user, err := users.Create(ctx, authhttp.User{Email: "owner@example.com", PasswordHash: "hash:secret"})
+	require.NoError(t, err)
+	files, err := storage.NewFileService("local", t.TempDir(), storage.StaticSettingsProvider{Settings: storage.FileUploadSettings{AllowedMIMETypes: []string{"application/zip"}}})
+	require.NoError(t, err)
+	exports := &memoryExports{}
+	accounts := &memoryAccounts{deleted: map[string]time.Time{}, exports: exports}
+	jobs := &recordingQueue{}
+	data := staticData{data: UserData{
+		Profile:            Profile{ID: user.ID, Email: user.Email, Phone: "+5215550000000"},
+		PhoneVerifications: []PhoneVerificationRecord{{ID: "1", Phone: "+5215550000000", Status: "verified"}},
+	}}
+	svc := NewService(exports, data, accounts, files, jobs, Config{GracePeriod: 24 * time.Hour})
+	revoker := &recordingRevoker{}
+	handler := NewHandler(svc, users, plainPasswords{}, revoker)
+
+	engine := gin.New()
+	engine.Use(middleware.ErrorHandler())
+	group := engine.Group("/v1/user", func(ctx *gin.Context) {
+		principal := internalauth.Principal{Subject: user.ID, TokenID: ctx.GetHeader("X-Token-ID")}
+		if age, err := time.ParseDuration(ctx.GetHeader("X-Signed-In")); err == nil {
+			principal.AuthenticatedAt = time.Now().Add(-age)
+		}
+		internalauth.SetPrincipal(ctx, principal)
+	})
+	group.POST("/export", handler.RequestExport)
+	group.GET("/export", handler.ExportStatus)
+	group.GET("/export/download", handler.DownloadExport)
+	group.POST("/delete", handler.DeleteAccount)
+
+	signedIn := ""
+	perform := func(method string, path string, body string, tokenID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if tokenID != "" {
+			req.Header.Set("X-Token-ID", tokenID)
+		}
+		if signedIn != "" {
+			req.Header.Set("X-Signed-In", signedIn)
+		}
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// 3.- Requesting an export queues the job; a second request waits for the first.
+	require.Equal(t, http.StatusNotFound, perform(http.MethodGet, "/v1/user/export", "", "").Code)
+	require.Equal(t, http.StatusAccepted, perform(http.MethodPost, "/v1/user/export", "", "").Code)
+	require.Equal(t, http.StatusConflict, perform(http.MethodPost, "/v1/user/export", "", "").Code)
+	require.Equal(t, http.StatusForbidden, perform(http.MethodPost, "/v1/user/export", "", "pat-1").Code)
+	require.Len(t, jobs.jobs, 1)
+	require.Equal(t, queue.UserExportJobName, jobs.jobs[0].Job)
+	require.Equal(t, http.StatusNotFound, perform(http.MethodGet, "/v1/user/export/download", "", "").Code)
+
+	// 4.- The worker job builds the archive.
+	job := queue.NewUserExportJob(svc)
+	require.NoError(t, job.Handler(ctx, &jobs.jobs[0]))
+	status := perform(http.MethodGet, "/v1/user/export", "", "")
+	require.Equal(t, http.StatusOK, status.Code)
+	require.Contains(t, status.Body.String(), `"status":"completed"`)
+
+	// 5.- The download is a ZIP with one JSON file per section and no verification codes.
+	download := perform(http.MethodGet, "/v1/user/export/download", "", "")
+	require.Equal(t, http.StatusOK, download.Code)
+	require.Equal(t, "application/zip", download.Header().Get("Content-Type"))
+	archive, err := zip.NewReader(bytes.NewReader(download.Body.Bytes()), int64(download.Body.Len()))
+	require.NoError(t, err)
+	contents := map[string]string{}
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		require.NoError(t, err)
+		raw, err := io.ReadAll(reader)
+		reader.Close()
+		require.NoError(t, err)
+		contents[file.Name] = string(raw)
+	}
+	require.Contains(t, contents, "manifest.json")
+	require.Contains(t, contents, "notifications.json")
+	require.Contains(t, contents, "tasks.json")
+	require.Contains(t, contents, "join_requests.json")
+	var profile Profile
+	require.NoError(t, json.Unmarshal([]byte(contents["profile.json"]), &profile))
+	require.Equal(t, "owner@example.com", profile.Email)
+	require.JSONEq(t, "[]", contents["notifications.json"])
+	require.NotContains(t, contents["phone_verifications.json"], "code")
+
+	// 6.- Deletion needs an interactive session and either the current password or a recent sign-in.
+	require.Equal(t, http.StatusForbidden, perform(http.MethodPost, "/v1/user/delete", `{"password":"secret"}`, "pat-1").Code)
+	require.Equal(t, http.StatusUnauthorized, perform(http.MethodPost, "/v1/user/delete", `{}`, "").Code)
+	require.Equal(t, http.StatusUnauthorized, perform(http.MethodPost, "/v1/user/delete", `{"password":"wrong"}`, "").Code)
+	signedIn = "1h"
+	stale := perform(http.MethodPost, "/v1/user/delete", `{}`, "")
+	require.Equal(t, http.StatusUnauthorized, stale.Code)
+	require.Contains(t, stale.Body.String(), "recent authentication required")
+
+	// 6.1.- A user without a usable password (SSO, magic link, passkey) signs in again and retries without one.
+	signedIn = "1m"
+	require.Equal(t, http.StatusUnauthorized, perform(http.MethodPost, "/v1/user/delete", `{"password":"wrong"}`, "").Code)
+	deleted := perform(http.MethodPost, "/v1/user/delete", ``, "")
+	require.Equal(t, http.StatusAccepted, deleted.Code)
+	require.Contains(t, deleted.Body.String(), "purge_after")
+	require.Equal(t, []string{user.ID}, revoker.subjects)
+	require.Contains(t, accounts.deleted, user.ID)
+
+	// 7.- The purge waits for the grace period, then removes the account and its archive.
+	svc.now = func() time.Time { return time.Now().Add(23 * time.Hour) }
+	purged, err := svc.PurgeDeleted(ctx)
+	require.NoError(t, err)
+	require.Zero(t, purged)
+	svc.now = func() time.Time { return time.Now().Add(25 * time.Hour) }
+	purgeJob := queue.NewUserPurgeJob(svc)
+	message := &queue.Message{Job: queue.UserPurgeJobName, Payload: map[string]any{}}
+	require.NoError(t, purgeJob.Handler(ctx, message))
+	require.Equal(t, 1, message.Metadata["purged"])
+	_, err = files.Open(ctx, exports.items[0].FilePath)
+	require.ErrorIs(t, err, storage.ErrFileNotFound)
+}
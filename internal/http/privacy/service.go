@@ -0,0 +1,314 @@
+package privacy
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/example/Yamato-Go-Gin-API/internal/queue"
+)
+
+// 1.- ErrExportNotFound signals that the user has no export matching the request.
+var ErrExportNotFound = errors.New("http/privacy: export not found")
+
+// 1.- ErrExportInProgress is returned while an earlier export for the user is still being built.
+var ErrExportInProgress = errors.New("http/privacy: export already in progress")
+
+// 1.- Export statuses tracked in the data_exports table.
+const (
+	ExportPending   = "pending"
+	ExportCompleted = "completed"
+	ExportFailed    = "failed"
+)
+
+// 1.- Export describes one personal data export request and the archive it produced.
+type Export struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"user_id"`
+	Status      string     `json:"status"`
+	FilePath    string     `json:"-"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// 1.- Profile holds the account columns included in an export.
+type Profile struct {
+	ID          string     `json:"id"`
+	Email       string     `json:"email"`
+	FirstName   string     `json:"first_name"`
+	LastName    string     `json:"last_name"`
+	Phone       string     `json:"phone,omitempty"`
+	Status      string     `json:"status"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// 1.- NotificationRecord is a notification addressed to the user.
+type NotificationRecord struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title"`
+	Message   string     `json:"message"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// 1.- TaskRecord is a task assigned to the user.
+type TaskRecord struct {
+	ID       string    `json:"id"`
+	Title    string    `json:"title"`
+	Status   string    `json:"status"`
+	Priority string    `json:"priority"`
+	Assignee string    `json:"assignee"`
+	DueDate  time.Time `json:"due_date"`
+}
+
+// 1.- JoinRequestRecord is a request the user filed to join a team.
+type JoinRequestRecord struct {
+	ID        string          `json:"id"`
+	TeamID    string          `json:"team_id"`
+	Status    string          `json:"status"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// 1.- PhoneVerificationRecord is a verification attempt for the user's phone; codes are never exported.
+type PhoneVerificationRecord struct {
+	ID        string    `json:"id"`
+	Phone     string    `json:"phone"`
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// 1.- UserData gathers every section written to the export archive.
+type UserData struct {
+	Profile            Profile
+	Notifications      []NotificationRecord
+	Tasks              []TaskRecord
+	JoinRequests       []JoinRequestRecord
+	PhoneVerifications []PhoneVerificationRecord
+}
+
+// 1.- PurgeResult reports what a hard purge removed so stored archives can be cleaned up.
+type PurgeResult struct {
+	Users       int
+	ExportFiles []string
+}
+
+// 1.- ExportStore persists export requests and their outcome.
+type ExportStore interface {
+	Create(ctx context.Context, userID string) (Export, error)
+	Find(ctx context.Context, id string) (Export, error)
+	Latest(ctx context.Context, userID string) (Export, error)
+	Complete(ctx context.Context, id string, filePath string, completedAt time.Time) error
+	Fail(ctx context.Context, id string, reason string) error
+}
+
+// 1.- DataSource collects the personal data held about a user across tables.
+type DataSource interface {
+	CollectUserData(ctx context.Context, userID string) (UserData, error)
+}
+
+// 1.- AccountStore soft-deletes accounts and purges them once the grace period is over.
+type AccountStore interface {
+	// 2.- SoftDelete stamps deleted_at and drops credentials that bypass the session store.
+	SoftDelete(ctx context.Context, userID string, deletedAt time.Time) error
+	// 3.- PurgeDeleted hard-deletes accounts soft-deleted before the cutoff.
+	PurgeDeleted(ctx context.Context, before time.Time) (PurgeResult, error)
+}
+
+// 1.- FileStore is the subset of storage.FileService used to keep archives.
+type FileStore interface {
+	Save(ctx context.Context, originalName string, content io.Reader, size int64, mimeType string) (string, error)
+	Open(ctx context.Context, relativePath string) (io.ReadCloser, error)
+	Delete(ctx context.Context, relativePath string) error
+}
+
+// 1.- JobQueue hands export requests to the worker.
+type JobQueue interface {
+	Enqueue(ctx context.Context, jobName string, payload map[string]any) (queue.Message, error)
+}
+
+// 1.- Manager defines the export and deletion workflow consumed by the handlers.
+type Manager interface {
+	RequestExport(ctx context.Context, userID string) (Export, error)
+	LatestExport(ctx context.Context, userID string) (Export, error)
+	OpenExport(ctx context.Context, userID string) (Export, io.ReadCloser, error)
+	DeleteAccount(ctx context.Context, userID string) (time.Time, error)
+}
+
+// 1.- Config tunes the purge grace period and how long a pending export blocks a new one.
+type Config struct {
+	GracePeriod  time.Duration
+	PendingLimit time.Duration
+}
+
+// 1.- Service builds personal data exports and manages account deletion.
+type Service struct {
+	exports  ExportStore
+	data     DataSource
+	accounts AccountStore
+	files    FileStore
+	jobs     JobQueue
+	cfg      Config
+	now      func() time.Time
+}
+
+// 1.- NewService wires the privacy workflow with defaults for unset configuration.
+func NewService(exports ExportStore, data DataSource, accounts AccountStore, files FileStore, jobs JobQueue, cfg Config) *Service {
+	if cfg.GracePeriod <= 0 {
+		cfg.GracePeriod = 30 * 24 * time.Hour
+	}
+	if cfg.PendingLimit <= 0 {
+		cfg.PendingLimit = time.Hour
+	}
+	return &Service{exports: exports, data: data, accounts: accounts, files: files, jobs: jobs, cfg: cfg, now: time.Now}
+}
+
+// 1.- RequestExport records a new export and queues the job that builds it.
+func (s *Service) RequestExport(ctx context.Context, userID string) (Export, error) {
+	//1.- Refuse to stack exports while a recent one is still pending.
+	latest, err := s.exports.Latest(ctx, userID)
+	if err != nil && !errors.Is(err, ErrExportNotFound) {
+		return Export{}, err
+	}
+	if err == nil && latest.Status == ExportPending && s.now().Sub(latest.CreatedAt) < s.cfg.PendingLimit {
+		return Export{}, ErrExportInProgress
+	}
+
+	//2.- Persist the request before queueing so the worker always finds it.
+	export, err := s.exports.Create(ctx, userID)
+	if err != nil {
+		return Export{}, fmt.Errorf("http/privacy: create export: %w", err)
+	}
+	if _, err := s.jobs.Enqueue(ctx, queue.UserExportJobName, map[string]any{"export_id": export.ID}); err != nil {
+		_ = s.exports.Fail(ctx, export.ID, "enqueue failed")
+		return Export{}, fmt.Errorf("http/privacy: enqueue export: %w", err)
+	}
+	return export, nil
+}
+
+// 1.- LatestExport returns the user's most recent export request.
+func (s *Service) LatestExport(ctx context.Context, userID string) (Export, error) {
+	return s.exports.Latest(ctx, userID)
+}
+
+// 1.- OpenExport streams the archive of the user's latest export once it has completed.
+func (s *Service) OpenExport(ctx context.Context, userID string) (Export, io.ReadCloser, error) {
+	export, err := s.exports.Latest(ctx, userID)
+	if err != nil {
+		return Export{}, nil, err
+	}
+	if export.Status != ExportCompleted || export.FilePath == "" {
+		return Export{}, nil, ErrExportNotFound
+	}
+	reader, err := s.files.Open(ctx, export.FilePath)
+	if err != nil {
+		return Export{}, nil, fmt.Errorf("http/privacy: open export: %w", err)
+	}
+	return export, reader, nil
+}
+
+// 1.- RunExport builds the archive for a pending export; it is safe to retry.
+func (s *Service) RunExport(ctx context.Context, exportID string) error {
+	export, err := s.exports.Find(ctx, exportID)
+	if err != nil {
+		return err
+	}
+	if export.Status == ExportCompleted {
+		return nil
+	}
+
+	//1.- Collect and archive the data, recording failures so the user sees them.
+	path, err := s.buildArchive(ctx, export)
+	if err != nil {
+		_ = s.exports.Fail(ctx, export.ID, err.Error())
+		return err
+	}
+	if err := s.exports.Complete(ctx, export.ID, path, s.now()); err != nil {
+		return fmt.Errorf("http/privacy: complete export: %w", err)
+	}
+	return nil
+}
+
+// 1.- buildArchive writes one JSON file per data section into a ZIP and stores it.
+func (s *Service) buildArchive(ctx context.Context, export Export) (string, error) {
+	data, err := s.data.CollectUserData(ctx, export.UserID)
+	if err != nil {
+		return "", fmt.Errorf("http/privacy: collect user data: %w", err)
+	}
+
+	sections := []struct {
+		name  string
+		value interface{}
+	}{
+		{"manifest.json", map[string]interface{}{"export_id": export.ID, "user_id": export.UserID, "generated_at": s.now().UTC()}},
+		{"profile.json", data.Profile},
+		{"notifications.json", nonNil(data.Notifications)},
+		{"tasks.json", nonNil(data.Tasks)},
+		{"join_requests.json", nonNil(data.JoinRequests)},
+		{"phone_verifications.json", nonNil(data.PhoneVerifications)},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, section := range sections {
+		entry, err := archive.Create(section.name)
+		if err != nil {
+			return "", fmt.Errorf("http/privacy: write %s: %w", section.name, err)
+		}
+		encoder := json.NewEncoder(entry)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(section.value); err != nil {
+			return "", fmt.Errorf("http/privacy: encode %s: %w", section.name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return "", fmt.Errorf("http/privacy: finish archive: %w", err)
+	}
+
+	name := fmt.Sprintf("user-%s-export-%s.zip", export.UserID, export.ID)
+	path, err := s.files.Save(ctx, name, bytes.NewReader(buf.Bytes()), int64(buf.Len()), "application/zip")
+	if err != nil {
+		return "", fmt.Errorf("http/privacy: store archive: %w", err)
+	}
+	return path, nil
+}
+
+// 1.- DeleteAccount soft-deletes the account and returns when the hard purge becomes due.
+func (s *Service) DeleteAccount(ctx context.Context, userID string) (time.Time, error) {
+	now := s.now()
+	if err := s.accounts.SoftDelete(ctx, userID, now); err != nil {
+		return time.Time{}, err
+	}
+	return now.Add(s.cfg.GracePeriod), nil
+}
+
+// 1.- PurgeDeleted hard-deletes accounts past the grace period and removes their stored archives.
+func (s *Service) PurgeDeleted(ctx context.Context) (int, error) {
+	result, err := s.accounts.PurgeDeleted(ctx, s.now().Add(-s.cfg.GracePeriod))
+	if err != nil {
+		return 0, fmt.Errorf("http/privacy: purge accounts: %w", err)
+	}
+	for _, path := range result.ExportFiles {
+		if err := s.files.Delete(ctx, path); err != nil {
+			return result.Users, fmt.Errorf("http/privacy: delete export archive: %w", err)
+		}
+	}
+	return result.Users, nil
+}
+
+// 1.- nonNil keeps empty sections as [] rather than null in the archive.
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
@@ -7,6 +7,8 @@ import (
 	"strings"
 
 	"github.com/go-playground/validator/v10"
+
+	"github.com/example/Yamato-Go-Gin-API/internal/phone"
 )
 
 // 1.- FieldError represents a single validation failure for a struct field.
@@ -38,10 +40,26 @@ func (e Errors) ToMap() map[string]interface{} {
 // 1.- Validator wraps go-playground/validator with JSON tag awareness and friendly messages.
 type Validator struct {
 	engine *validator.Validate
+	// 2.- phoneRegion is the default region for a bare `phone` rule.
+	phoneRegion string
+}
+
+// 1.- Option customises a Validator at construction time.
+type Option func(*Validator)
+
+// 1.- WithPhoneRegion lets a bare `phone` rule accept national formats for the ISO 3166-1 region, e.g. "MX".
+func WithPhoneRegion(region string) Option {
+	return func(v *Validator) {
+		v.phoneRegion = strings.ToUpper(strings.TrimSpace(region))
+	}
 }
 
 // 1.- New constructs a Validator configured to honour JSON struct tags for field names.
-func New() (*Validator, error) {
+func New(opts ...Option) (*Validator, error) {
+	v := &Validator{}
+	for _, opt := range opts {
+		opt(v)
+	}
 	engine := validator.New(validator.WithRequiredStructEnabled())
 
 	// 2.- Ensure validation errors surface JSON tag names rather than struct field names.
@@ -53,7 +71,22 @@ func New() (*Validator, error) {
 		return name
 	})
 
-	return &Validator{engine: engine}, nil
+	// 3.- Register the offline phone rule: `phone` requires an international number unless WithPhoneRegion is set, `phone=MX` also accepts national formats.
+	if err := engine.RegisterValidation("phone", v.validatePhone); err != nil {
+		return nil, err
+	}
+
+	v.engine = engine
+	return v, nil
+}
+
+// 1.- validatePhone accepts numbers that normalise to E.164 under the rule parameter or the configured default region.
+func (v *Validator) validatePhone(fl validator.FieldLevel) bool {
+	region := fl.Param()
+	if region == "" {
+		region = v.phoneRegion
+	}
+	return phone.Valid(fl.Field().String(), region)
 }
 
 // 1.- ValidateStruct inspects the provided payload and returns structured field errors.
@@ -102,6 +135,8 @@ func describeFailure(field string, err validator.FieldError) string {
 		return fmt.Sprintf("%s must be at least %s characters", field, err.Param())
 	case "max":
 		return fmt.Sprintf("%s must be at most %s characters", field, err.Param())
+	case "phone":
+		return fmt.Sprintf("%s must be a valid phone number including the country code", field)
 	default:
 		return fmt.Sprintf("%s failed the %s validation", field, err.Tag())
 	}
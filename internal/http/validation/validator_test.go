@@ -0,0 +1,49 @@
+package validation
+
+import "testing"
+
+// 1.- TestPhoneRule accepts international numbers, national numbers with a default region, and rejects the rest.
+func TestPhoneRule(t *testing.T) {
+	v, err := New()
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	type payload struct {
+		Phone    string `json:"phone" validate:"required,phone"`
+		Domestic string `json:"domestic" validate:"omitempty,phone=MX"`
+	}
+
+	errs, err := v.ValidateStruct(payload{Phone: "+52 55 1234 5678", Domestic: "55 1234 5678"})
+	if err != nil || !errs.Empty() {
+		t.Fatalf("expected valid numbers, got %+v (%v)", errs.Fields, err)
+	}
+
+	errs, err = v.ValidateStruct(payload{Phone: "55 1234 5678", Domestic: "+52 55-1234"})
+	if err != nil {
+		t.Fatalf("ValidateStruct returned error: %v", err)
+	}
+	if len(errs.Fields["phone"]) != 1 || errs.Fields["phone"][0].Rule != "phone" {
+		t.Fatalf("expected phone rule failure, got %+v", errs.Fields)
+	}
+	if len(errs.Fields["domestic"]) != 1 || errs.Fields["domestic"][0].Param != "MX" {
+		t.Fatalf("expected domestic rule failure with param, got %+v", errs.Fields)
+	}
+}
+
+// 2.- TestPhoneRuleDefaultRegion lets a bare `phone` rule read national numbers for the configured region.
+func TestPhoneRuleDefaultRegion(t *testing.T) {
+	v, err := New(WithPhoneRegion("mx"))
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	type payload struct {
+		Phone string `json:"phone" validate:"phone"`
+	}
+
+	if errs, err := v.ValidateStruct(payload{Phone: "55 1234 5678"}); err != nil || !errs.Empty() {
+		t.Fatalf("expected national number to pass, got %+v (%v)", errs.Fields, err)
+	}
+	if errs, _ := v.ValidateStruct(payload{Phone: "55-1234"}); len(errs.Fields["phone"]) != 1 {
+		t.Fatalf("expected short number to fail, got %+v", errs.Fields)
+	}
+}
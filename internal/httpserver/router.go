@@ -3,7 +3,9 @@ package httpserver
 import (
 	"github.com/gin-gonic/gin"
 
+	adminhttp "github.com/example/Yamato-Go-Gin-API/internal/http/admin"
 	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+	oauthhttp "github.com/example/Yamato-Go-Gin-API/internal/http/oauth"
 )
 
 // 1.- RegisterAuthRoutes wires authentication HTTP handlers into the Gin router tree.
@@ -17,21 +19,118 @@ func RegisterAuthRoutes(router gin.IRouter, handler authhttp.Handler, authMiddle
 	authGroup.POST("/login", handler.Login)
 	authGroup.POST("/logout", handler.Logout)
 	authGroup.POST("/refresh", handler.Refresh)
+	authGroup.POST("/password/forgot", handler.ForgotPassword)
+	authGroup.POST("/password/reset", handler.ResetPassword)
+	authGroup.POST("/magic-link", handler.RequestMagicLink)
+	authGroup.POST("/magic-link/consume", handler.ConsumeMagicLink)
+	authGroup.POST("/device", handler.DeviceLogin)
+	authGroup.POST("/device/rotate", handler.RotateDeviceToken)
+	authGroup.POST("/email/confirm", handler.ConfirmEmailChange)
+	authGroup.POST("/email/cancel", handler.CancelEmailChange)
+	authGroup.POST("/mfa/verify", handler.VerifyMFA)
+	authGroup.GET("/sso/:provider", handler.SSORedirect)
+	authGroup.GET("/sso/:provider/callback", handler.SSOCallback)
+	authGroup.POST("/passkeys/options", handler.PasskeyLoginOptions)
+	authGroup.POST("/passkeys/login", handler.PasskeyLogin)
 
-	// 4.- Expose a user endpoint under /v1/user for principal introspection.
+	// 4.- Expose a user endpoint under /v1/user for principal introspection; credential and session changes are barred while impersonating.
+	// Service accounts have no user account, so they are turned away from the whole group.
 	userGroup := v1.Group("/user")
 	if authMiddleware != nil {
-		userGroup.Use(authMiddleware)
+		userGroup.Use(authMiddleware, authhttp.DenyServiceAccounts)
 	}
 	userGroup.GET("", handler.CurrentUser)
+	userGroup.POST("/mfa/enroll", authhttp.DenyImpersonation, handler.EnrollMFA)
+	userGroup.POST("/mfa/confirm", authhttp.DenyImpersonation, handler.ConfirmMFA)
+	userGroup.POST("/mfa/disable", authhttp.DenyImpersonation, handler.DisableMFA)
+	userGroup.GET("/sessions", handler.ListSessions)
+	userGroup.DELETE("/sessions", authhttp.DenyImpersonation, handler.RevokeOtherSessions)
+	userGroup.DELETE("/sessions/:id", authhttp.DenyImpersonation, handler.RevokeSession)
+	userGroup.GET("/tokens", handler.ListAccessTokens)
+	userGroup.POST("/tokens", authhttp.DenyImpersonation, handler.CreateAccessToken)
+	userGroup.PATCH("/tokens/:id", authhttp.DenyImpersonation, handler.RenameAccessToken)
+	userGroup.DELETE("/tokens/:id", authhttp.DenyImpersonation, handler.RevokeAccessToken)
+	userGroup.POST("/email", authhttp.DenyImpersonation, handler.RequestEmailChange)
+	userGroup.POST("/phone", authhttp.DenyImpersonation, handler.LinkPhone)
+	userGroup.GET("/passkeys", handler.ListPasskeys)
+	userGroup.POST("/passkeys/options", authhttp.DenyImpersonation, handler.PasskeyRegistrationOptions)
+	userGroup.POST("/passkeys", authhttp.DenyImpersonation, handler.RegisterPasskey)
+	userGroup.DELETE("/passkeys/:id", authhttp.DenyImpersonation, handler.DeletePasskey)
+
+	// 4.1.- Phone-bound sessions manage the device tokens of their own phone; service accounts have none.
+	deviceGroup := v1.Group("/devices")
+	if authMiddleware != nil {
+		deviceGroup.Use(authMiddleware, authhttp.DenyServiceAccounts)
+	}
+	deviceGroup.GET("", handler.ListDevices)
+	deviceGroup.DELETE("", handler.RevokeDevices)
+	deviceGroup.DELETE("/:id", handler.RevokeDevice)
 
 	// 5.- Publish Laravel-compatible verification routes outside the versioned prefix.
 	router.GET("/email/verify/:id/:hash", handler.VerifyEmail)
 
 	// 6.- Provide an endpoint to resend verification e-mails for authenticated users.
 	if authMiddleware != nil {
-		router.POST("/email/verification-notification", authMiddleware, handler.ResendVerification)
+		router.POST("/email/verification-notification", authMiddleware, authhttp.DenyServiceAccounts, handler.ResendVerification)
 	} else {
 		router.POST("/email/verification-notification", handler.ResendVerification)
 	}
 }
+
+// 1.- RegisterAuthAdminRoutes mounts account security administration under /v1/admin behind the supplied guards.
+func RegisterAuthAdminRoutes(router gin.IRouter, handler authhttp.Handler, guards ...gin.HandlerFunc) {
+	adminGroup := router.Group("/v1/admin", guards...)
+	adminGroup.POST("/users/:id/unlock", handler.UnlockAccount)
+	adminGroup.GET("/phones/:phone/devices", handler.AdminListDevices)
+	adminGroup.DELETE("/phones/:phone/devices", handler.AdminRevokeDevices)
+	adminGroup.DELETE("/phones/:phone/devices/:id", handler.AdminRevokeDevice)
+}
+
+// 1.- RegisterAdminRoutes mounts user, role, permission, and team management under /v1/admin, each guarded by its own permission.
+func RegisterAdminRoutes(router gin.IRouter, handler adminhttp.Handler, guards ...gin.HandlerFunc) {
+	adminGroup := router.Group("/v1/admin", guards...)
+
+	users := handler.RBAC(adminhttp.PermissionManageUsers)
+	adminGroup.GET("/users", users, handler.ListUsers)
+	adminGroup.POST("/users", users, handler.CreateUser)
+	adminGroup.PUT("/users/:id", users, handler.UpdateUser)
+	adminGroup.DELETE("/users/:id", users, handler.DeleteUser)
+
+	roles := handler.RBAC(adminhttp.PermissionManageRoles)
+	adminGroup.GET("/roles", roles, handler.ListRoles)
+	adminGroup.POST("/roles", roles, handler.CreateRole)
+	adminGroup.PUT("/roles/:id", roles, handler.UpdateRole)
+	adminGroup.DELETE("/roles/:id", roles, handler.DeleteRole)
+
+	permissions := handler.RBAC(adminhttp.PermissionManagePermissions)
+	adminGroup.GET("/permissions", permissions, handler.ListPermissions)
+	adminGroup.POST("/permissions", permissions, handler.CreatePermission)
+	adminGroup.PUT("/permissions/:id", permissions, handler.UpdatePermission)
+	adminGroup.DELETE("/permissions/:id", permissions, handler.DeletePermission)
+
+	teams := handler.RBAC(adminhttp.PermissionManageTeams)
+	adminGroup.GET("/teams", teams, handler.ListTeams)
+	adminGroup.POST("/teams", teams, handler.CreateTeam)
+	adminGroup.PUT("/teams/:id", teams, handler.UpdateTeam)
+	adminGroup.DELETE("/teams/:id", teams, handler.DeleteTeam)
+}
+
+// 1.- RegisterImpersonationRoutes mounts the admin impersonation endpoints under /v1/admin behind the supplied guards.
+func RegisterImpersonationRoutes(router gin.IRouter, handler authhttp.Handler, guards ...gin.HandlerFunc) {
+	adminGroup := router.Group("/v1/admin", guards...)
+	adminGroup.POST("/users/:id/impersonate", handler.Impersonate)
+	adminGroup.DELETE("/impersonations/:token_id", handler.EndImpersonation)
+}
+
+// 1.- RegisterJWKSRoute publishes the public signing keys at the well-known discovery path.
+func RegisterJWKSRoute(router gin.IRouter, handler gin.HandlerFunc) {
+	router.GET("/.well-known/jwks.json", handler)
+}
+
+// 1.- RegisterOAuthRoutes mounts the client-authenticated OAuth 2.0 token, introspection, and revocation endpoints under /v1/oauth.
+func RegisterOAuthRoutes(router gin.IRouter, handler oauthhttp.Handler) {
+	oauthGroup := router.Group("/v1/oauth")
+	oauthGroup.POST("/token", handler.Token)
+	oauthGroup.POST("/introspect", handler.Introspect)
+	oauthGroup.POST("/revoke", handler.Revoke)
+}
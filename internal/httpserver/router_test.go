@@ -12,7 +12,10 @@ import (
 	"github.com/gin-gonic/gin"
 
 	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/authorization"
+	adminhttp "github.com/example/Yamato-Go-Gin-API/internal/http/admin"
 	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/middleware"
 )
 
 // 1.- stubAuthService implements authhttp.AuthService while recording invocations for assertions.
@@ -123,6 +126,31 @@ func (s *stubUserStore) FindByID(_ context.Context, id string) (authhttp.User, e
 	return authhttp.User{}, authhttp.ErrUserNotFound
 }
 
+// 1.- UpdatePassword replaces the stored hash for the identified user.
+func (s *stubUserStore) UpdatePassword(_ context.Context, id string, passwordHash string) error {
+	for email, user := range s.users {
+		if user.ID == id {
+			user.PasswordHash = passwordHash
+			s.users[email] = user
+			return nil
+		}
+	}
+	return authhttp.ErrUserNotFound
+}
+
+// 1.- UpdateEmail moves the identified user to a new address.
+func (s *stubUserStore) UpdateEmail(_ context.Context, id string, email string) error {
+	for current, user := range s.users {
+		if user.ID == id {
+			delete(s.users, current)
+			user.Email = email
+			s.users[email] = user
+			return nil
+		}
+	}
+	return authhttp.ErrUserNotFound
+}
+
 // 1.- TestRegisterAuthRoutesWiresEndpoints verifies that the router exposes the expected auth endpoints.
 func TestRegisterAuthRoutesWiresEndpoints(t *testing.T) {
 	// 2.- Configure Gin for deterministic testing and prepare dependencies.
@@ -225,3 +253,115 @@ func TestRegisterAuthRoutesWiresEndpoints(t *testing.T) {
 		t.Fatalf("expected resend to capture the authenticated subject")
 	}
 }
+
+// 1.- TestRegisterAuthRoutesDeniesServiceAccounts keeps client_credentials principals off account and device routes.
+func TestRegisterAuthRoutesDeniesServiceAccounts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	handler := authhttp.NewHandler(&stubAuthService{}, newStubUserStore(), &stubVerificationService{})
+	authMiddleware := func(ctx *gin.Context) {
+		internalauth.SetPrincipal(ctx, internalauth.Principal{Subject: internalauth.ClientSubjectPrefix + "billing", ClientID: "billing", Roles: []string{}, Permissions: []string{}})
+		ctx.Next()
+	}
+	RegisterAuthRoutes(router, handler, authMiddleware)
+
+	for _, route := range []struct{ method, path string }{
+		{http.MethodGet, "/v1/user"},
+		{http.MethodGet, "/v1/devices"},
+		{http.MethodDelete, "/v1/devices/device-1"},
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(route.method, route.path, nil))
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("expected %s %s to return %d for a service account, got %d", route.method, route.path, http.StatusForbidden, rec.Code)
+		}
+	}
+}
+
+// 1.- stubJWKSProvider returns a fixed key set for route assertions.
+type stubJWKSProvider struct{}
+
+// 1.- JWKS returns a single Ed25519 key description.
+func (stubJWKSProvider) JWKS() internalauth.JWKS {
+	return internalauth.JWKS{Keys: []internalauth.JWK{{Kty: "OKP", Kid: "key-1", Use: "sig", Alg: "EdDSA", Crv: "Ed25519", X: "abc"}}}
+}
+
+// 1.- TestRegisterJWKSRouteServesKeySet ensures the well-known path exposes the raw key set.
+func TestRegisterJWKSRouteServesKeySet(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	RegisterJWKSRoute(router, authhttp.JWKS(stubJWKSProvider{}))
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected jwks endpoint to return %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var body internalauth.JWKS
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode jwks: %v", err)
+	}
+	if len(body.Keys) != 1 || body.Keys[0].Kid != "key-1" {
+		t.Fatalf("unexpected jwks payload: %s", rec.Body.String())
+	}
+	if rec.Header().Get("Cache-Control") == "" {
+		t.Fatalf("expected jwks response to be cacheable")
+	}
+}
+
+// 1.- stubTeamService serves a fixed team list for the admin route assertions.
+type stubTeamService struct {
+	adminhttp.TeamService
+}
+
+// 1.- List returns a single team.
+func (stubTeamService) List(_ context.Context, _ adminhttp.Pagination) ([]adminhttp.Team, int, error) {
+	return []adminhttp.Team{{ID: "1", Name: "core"}}, 1, nil
+}
+
+// 1.- TestRegisterAdminRoutesGuardsEachResource mounts the admin CRUD next to the account admin routes and checks per-resource permissions.
+func TestRegisterAdminRoutesGuardsEachResource(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	// 2.- The principal may manage teams only.
+	authMiddleware := func(ctx *gin.Context) {
+		internalauth.SetPrincipal(ctx, internalauth.Principal{Subject: "admin-1", Permissions: []string{adminhttp.PermissionManageTeams}})
+		ctx.Next()
+	}
+	authHandler := authhttp.NewHandler(&stubAuthService{}, newStubUserStore(), &stubVerificationService{})
+	adminHandler := adminhttp.NewHandler(authorization.NewPolicy(), nil, nil, nil, stubTeamService{})
+
+	// 3.- Both groups share /v1/admin/users/:id, so registering them together must not panic.
+	RegisterAuthAdminRoutes(router, authHandler, authMiddleware)
+	RegisterImpersonationRoutes(router, authHandler, authMiddleware)
+	RegisterAdminRoutes(router, adminHandler, authMiddleware)
+
+	teamsRec := httptest.NewRecorder()
+	router.ServeHTTP(teamsRec, httptest.NewRequest(http.MethodGet, "/v1/admin/teams?per_page=5", nil))
+	if teamsRec.Code != http.StatusOK {
+		t.Fatalf("expected teams listing to return %d, got %d", http.StatusOK, teamsRec.Code)
+	}
+	var body struct {
+		Data []adminhttp.Team `json:"data"`
+		Meta map[string]any   `json:"meta"`
+	}
+	if err := json.Unmarshal(teamsRec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode teams: %v", err)
+	}
+	if len(body.Data) != 1 || body.Meta["per_page"] != float64(5) {
+		t.Fatalf("unexpected teams payload: %s", teamsRec.Body.String())
+	}
+
+	// 4.- Every other resource requires its own permission.
+	for _, path := range []string{"/v1/admin/users", "/v1/admin/roles", "/v1/admin/permissions"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("expected %s to return %d, got %d", path, http.StatusForbidden, rec.Code)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -11,11 +12,77 @@ import (
 	"github.com/example/Yamato-Go-Gin-API/internal/http/respond"
 )
 
+// AuthenticationOption customises the authentication middleware during construction.
+type AuthenticationOption func(*authenticationSettings)
+
+type authenticationSettings struct {
+	accessTokens  authhttp.AccessTokenAuthenticator
+	access        internalauth.AccessLoader
+	audit         authhttp.ImpersonationAuditor
+	serviceScopes map[string][]string
+	cookie        string
+}
+
+// WithAccessTokens accepts personal access tokens as a second bearer credential type.
+func WithAccessTokens(tokens authhttp.AccessTokenAuthenticator) AuthenticationOption {
+	// 1.- Capture the authenticator so yat_ tokens bypass JWT validation.
+	return func(settings *authenticationSettings) {
+		settings.accessTokens = tokens
+	}
+}
+
+// WithAccessLoader resolves the principal's roles and permissions instead of the static member role.
+func WithAccessLoader(loader internalauth.AccessLoader) AuthenticationOption {
+	// 1.- Capture the loader, typically an internalauth.AccessCache over the RBAC tables.
+	return func(settings *authenticationSettings) {
+		settings.access = loader
+	}
+}
+
+// WithImpersonationAudit accepts impersonation tokens and records every request made with them.
+func WithImpersonationAudit(audit authhttp.ImpersonationAuditor) AuthenticationOption {
+	// 1.- Without an auditor impersonation tokens are rejected outright.
+	return func(settings *authenticationSettings) {
+		settings.audit = audit
+	}
+}
+
+// WithServiceAccounts accepts client_credentials tokens, granting the permission slugs mapped from their scopes.
+func WithServiceAccounts(scopes map[string][]string) AuthenticationOption {
+	// 1.- Without a scope catalogue service tokens are rejected, since they would carry no permissions.
+	return func(settings *authenticationSettings) {
+		if scopes == nil {
+			scopes = map[string][]string{}
+		}
+		settings.serviceScopes = scopes
+	}
+}
+
+// WithSessionCookie falls back to the named access token cookie when no Authorization header is sent.
+// Pair it with CSRF so cookie-authenticated mutations require the double-submit header.
+func WithSessionCookie(name string) AuthenticationOption {
+	// 1.- Only JWT access tokens travel in the cookie; personal access tokens stay header-only.
+	return func(settings *authenticationSettings) {
+		settings.cookie = strings.TrimSpace(name)
+	}
+}
+
 // Authentication validates Bearer tokens and exposes the authenticated principal to handlers.
-func Authentication(authSvc *internalauth.Service, users authhttp.UserStore) gin.HandlerFunc {
+func Authentication(authSvc *internalauth.Service, users authhttp.UserStore, opts ...AuthenticationOption) gin.HandlerFunc {
+	settings := authenticationSettings{}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
 	// 1.- Return a Gin middleware that enforces Authorization headers.
 	return func(ctx *gin.Context) {
 		header := ctx.GetHeader("Authorization")
+		if header == "" && settings.cookie != "" {
+			// 1.1.- Browser sessions present the access token as an HttpOnly cookie instead.
+			if value, err := ctx.Cookie(settings.cookie); err == nil && strings.TrimSpace(value) != "" && !strings.HasPrefix(value, authhttp.AccessTokenPrefix) {
+				header = "Bearer " + value
+			}
+		}
 		if header == "" {
 			respond.Error(ctx, http.StatusUnauthorized, "missing authorization header", map[string]interface{}{"reason": "bearer token required"})
 			return
@@ -33,17 +100,94 @@ func Authentication(authSvc *internalauth.Service, users authhttp.UserStore) gin
 			return
 		}
 
-		claims, err := authSvc.ValidateAccessToken(ctx.Request.Context(), token)
-		if err != nil {
-			respond.Error(ctx, http.StatusUnauthorized, "invalid or expired token", map[string]interface{}{"details": err.Error()})
-			return
+		var principal internalauth.Principal
+		if settings.accessTokens != nil && strings.HasPrefix(token, authhttp.AccessTokenPrefix) {
+			// 2.- Personal access tokens carry exactly the permissions their scopes map to.
+			record, permissions, err := settings.accessTokens.AuthenticateAccessToken(ctx.Request.Context(), token)
+			if err != nil {
+				respond.Error(ctx, http.StatusUnauthorized, "invalid or expired token", map[string]interface{}{"details": err.Error()})
+				return
+			}
+			principal = internalauth.Principal{Subject: record.UserID, Roles: []string{"member"}, Permissions: permissions, TokenID: record.ID}
+		} else {
+			claims, err := authSvc.ValidateAccessToken(ctx.Request.Context(), token)
+			if err != nil {
+				respond.Error(ctx, http.StatusUnauthorized, "invalid or expired token", map[string]interface{}{"details": err.Error()})
+				return
+			}
+			principal = internalauth.Principal{Subject: claims.Subject, Roles: []string{"member"}, Permissions: []string{}, SessionID: claims.FamilyID}
+			if claims.AuthTime != nil {
+				principal.AuthenticatedAt = claims.AuthTime.Time
+			}
+			if claims.ClientID != "" {
+				// 3.- Service accounts hold no roles and only the permissions their scopes map to.
+				if settings.serviceScopes == nil {
+					respond.Error(ctx, http.StatusUnauthorized, "invalid or expired token", map[string]interface{}{"details": "service tokens are not accepted"})
+					return
+				}
+				principal = internalauth.Principal{
+					Subject:        claims.Subject,
+					ClientID:       claims.ClientID,
+					Roles:          []string{},
+					Permissions:    scopePermissions(settings.serviceScopes, strings.Fields(claims.Scope)),
+					AccessRevision: claims.Scope,
+				}
+			}
+			if claims.Actor != nil {
+				// 4.- Impersonation tokens are only honoured when each request can be audited first.
+				if settings.audit == nil || settings.access == nil {
+					respond.Error(ctx, http.StatusUnauthorized, "invalid or expired token", map[string]interface{}{"details": "impersonation tokens are not accepted"})
+					return
+				}
+				// 4.1.- The actor must still hold the impersonation grant, so revoking it ends every token already issued.
+				actorAccess, err := settings.access.LoadAccess(ctx.Request.Context(), claims.Actor.Subject)
+				if err != nil {
+					respond.Error(ctx, http.StatusInternalServerError, "failed to resolve permissions", map[string]interface{}{"details": err.Error()})
+					return
+				}
+				if !hasPermission(actorAccess.Permissions, internalauth.PermissionImpersonate) {
+					respond.Error(ctx, http.StatusForbidden, "impersonation no longer permitted", map[string]interface{}{"reason": "actor lacks " + internalauth.PermissionImpersonate})
+					return
+				}
+				principal.Actor = claims.Actor.Subject
+				event := authhttp.ImpersonationEvent{
+					Actor:     claims.Actor.Subject,
+					Subject:   claims.Subject,
+					TokenID:   claims.ID,
+					Action:    authhttp.ImpersonationActionRequest,
+					Method:    ctx.Request.Method,
+					Path:      ctx.Request.URL.Path,
+					IP:        ctx.ClientIP(),
+					UserAgent: ctx.Request.UserAgent(),
+					CreatedAt: time.Now().UTC(),
+				}
+				if err := settings.audit.RecordImpersonation(ctx.Request.Context(), event); err != nil {
+					respond.Error(ctx, http.StatusServiceUnavailable, "impersonation audit unavailable", map[string]interface{}{"details": err.Error()})
+					return
+				}
+			}
 		}
 
-		principal := internalauth.Principal{Subject: claims.Subject, Roles: []string{"member"}, Permissions: []string{}}
+		if settings.access != nil && !principal.IsServiceAccount() {
+			// 5.- Replace the defaults with the grants currently stored for the subject.
+			access, err := settings.access.LoadAccess(ctx.Request.Context(), principal.Subject)
+			if err != nil {
+				respond.Error(ctx, http.StatusInternalServerError, "failed to resolve permissions", map[string]interface{}{"details": err.Error()})
+				return
+			}
+			principal.Roles = access.Roles
+			principal.AccessRevision = access.Revision()
+			if principal.TokenID != "" {
+				// 6.- Token scopes never outlive the permissions the owner still holds.
+				principal.Permissions = intersectPermissions(principal.Permissions, access.Permissions)
+			} else {
+				principal.Permissions = access.Permissions
+			}
+		}
 		internalauth.SetPrincipal(ctx, principal)
 
-		if users != nil {
-			if user, err := users.FindByID(ctx.Request.Context(), claims.Subject); err == nil {
+		if users != nil && !principal.IsServiceAccount() {
+			if user, err := users.FindByID(ctx.Request.Context(), principal.Subject); err == nil {
 				ctx.Set("auth.user.email", user.Email)
 				ctx.Set("auth.user.name", user.Name)
 			}
@@ -52,3 +196,44 @@ func Authentication(authSvc *internalauth.Service, users authhttp.UserStore) gin
 		ctx.Next()
 	}
 }
+
+// hasPermission reports whether the grant set contains the permission slug.
+func hasPermission(granted []string, permission string) bool {
+	for _, candidate := range granted {
+		if candidate == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// intersectPermissions keeps the scoped permissions that the owner is still granted.
+func intersectPermissions(scoped []string, granted []string) []string {
+	allowed := make(map[string]struct{}, len(granted))
+	for _, permission := range granted {
+		allowed[permission] = struct{}{}
+	}
+	result := make([]string, 0, len(scoped))
+	for _, permission := range scoped {
+		if _, ok := allowed[permission]; ok {
+			result = append(result, permission)
+		}
+	}
+	return result
+}
+
+// scopePermissions expands service account scopes into the distinct permission slugs they grant.
+func scopePermissions(catalogue map[string][]string, scopes []string) []string {
+	seen := map[string]struct{}{}
+	permissions := []string{}
+	for _, scope := range scopes {
+		for _, permission := range catalogue[scope] {
+			if _, ok := seen[permission]; ok {
+				continue
+			}
+			seen[permission] = struct{}{}
+			permissions = append(permissions, permission)
+		}
+	}
+	return permissions
+}
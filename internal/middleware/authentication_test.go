@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/authorization"
+	"github.com/example/Yamato-Go-Gin-API/internal/config"
+)
+
+// 1.- staticAccess serves a mutable grant set in place of the RBAC tables.
+type staticAccess struct {
+	access internalauth.Access
+}
+
+func (s *staticAccess) LoadAccess(_ context.Context, _ string) (internalauth.Access, error) {
+	return s.access, nil
+}
+
+// 1.- TestAuthenticationLoadsRolesAndPermissions verifies that principals reflect the stored grants.
+func TestAuthenticationLoadsRolesAndPermissions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// 2.- Back the auth service and access cache with an in-memory Redis.
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	authSvc, err := internalauth.NewService(config.JWTConfig{Secret: "test-secret", Issuer: "yamato-test"}, client)
+	if err != nil {
+		t.Fatalf("NewService returned error: %v", err)
+	}
+	loader := &staticAccess{access: internalauth.Access{Roles: []string{"editor"}, Permissions: []string{"posts.write"}}}
+	cache, err := internalauth.NewAccessCache(loader, client, 0)
+	if err != nil {
+		t.Fatalf("NewAccessCache returned error: %v", err)
+	}
+
+	// 3.- Guard a route with the shared policy and echo the resolved roles.
+	policy := authorization.NewPolicy()
+	router := gin.New()
+	router.Use(Authentication(authSvc, nil, WithAccessLoader(cache)))
+	router.GET("/posts", RequirePermission(policy, "posts.write"), func(ctx *gin.Context) {
+		principal, _ := internalauth.PrincipalFromContext(ctx)
+		ctx.JSON(http.StatusOK, gin.H{"roles": principal.Roles})
+	})
+
+	pair, err := authSvc.Login(context.Background(), "42")
+	if err != nil {
+		t.Fatalf("Login returned error: %v", err)
+	}
+	call := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/posts", nil)
+		req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+		return res.Code
+	}
+
+	if code := call(); code != http.StatusOK {
+		t.Fatalf("expected editor to pass, got %d", code)
+	}
+
+	// 4.- Revoke the permission; the cached grant holds until the cache is invalidated.
+	loader.access = internalauth.Access{Roles: []string{"viewer"}, Permissions: []string{}}
+	if code := call(); code != http.StatusOK {
+		t.Fatalf("expected cached grant to pass, got %d", code)
+	}
+	if err := cache.InvalidateSubject(context.Background(), "42"); err != nil {
+		t.Fatalf("InvalidateSubject returned error: %v", err)
+	}
+	if code := call(); code != http.StatusForbidden {
+		t.Fatalf("expected revoked permission to be forbidden, got %d", code)
+	}
+}
+
+// 1.- TestAuthenticationResolvesServiceAccounts verifies client_credentials tokens become scoped service principals.
+func TestAuthenticationResolvesServiceAccounts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// 2.- Issue a client token; the access loader must never be consulted for it.
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	authSvc, err := internalauth.NewService(config.JWTConfig{Secret: "test-secret", Issuer: "yamato-test"}, client)
+	if err != nil {
+		t.Fatalf("NewService returned error: %v", err)
+	}
+	token, err := authSvc.IssueClientToken(context.Background(), "billing", []string{"posts:write"})
+	if err != nil {
+		t.Fatalf("IssueClientToken returned error: %v", err)
+	}
+	loader := &staticAccess{access: internalauth.Access{Roles: []string{"admin"}, Permissions: []string{"posts.write", "posts.delete"}}}
+
+	policy := authorization.NewPolicy()
+	call := func(opts ...AuthenticationOption) (int, internalauth.Principal) {
+		var seen internalauth.Principal
+		router := gin.New()
+		router.Use(ErrorHandler())
+		router.Use(Authentication(authSvc, nil, append(opts, WithAccessLoader(loader))...))
+		router.DELETE("/posts", RequirePermission(policy, "posts.delete"), func(ctx *gin.Context) { ctx.Status(http.StatusNoContent) })
+		router.POST("/posts", RequirePermission(policy, "posts.write"), func(ctx *gin.Context) {
+			seen, _ = internalauth.PrincipalFromContext(ctx)
+			ctx.Status(http.StatusNoContent)
+		})
+		req := httptest.NewRequest(http.MethodPost, "/posts", nil)
+		req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+		if res.Code != http.StatusNoContent {
+			return res.Code, seen
+		}
+		req = httptest.NewRequest(http.MethodDelete, "/posts", nil)
+		req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+		res = httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+		return res.Code, seen
+	}
+
+	// 3.- Without a scope catalogue the token is refused outright.
+	if code, _ := call(); code != http.StatusUnauthorized {
+		t.Fatalf("expected service token to be refused, got %d", code)
+	}
+
+	// 4.- With one, the principal carries only the scoped permission and no roles.
+	code, principal := call(WithServiceAccounts(map[string][]string{"posts:write": {"posts.write"}, "posts:delete": {"posts.delete"}}))
+	if code != http.StatusForbidden {
+		t.Fatalf("expected unscoped permission to be forbidden, got %d", code)
+	}
+	if !principal.IsServiceAccount() || principal.Subject != internalauth.ClientSubjectPrefix+"billing" || principal.ClientID != "billing" || len(principal.Roles) != 0 {
+		t.Fatalf("unexpected service principal: %#v", principal)
+	}
+}
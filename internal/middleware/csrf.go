@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/Yamato-Go-Gin-API/internal/http/respond"
+)
+
+// CSRFConfig names the double-submit cookie, the header that must echo it, and the cookies that carry credentials.
+type CSRFConfig struct {
+	CookieName     string
+	HeaderName     string
+	SessionCookies []string
+}
+
+// CSRF enforces the double-submit pattern on mutating requests that authenticate through session cookies.
+// Requests with an Authorization header, or without any session cookie, are not exposed to CSRF and pass through.
+func CSRF(cfg CSRFConfig) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// 1.- Safe methods never change state.
+		switch ctx.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			ctx.Next()
+			return
+		}
+
+		// 2.- Only requests where the browser attaches credentials automatically need the check.
+		if ctx.GetHeader("Authorization") != "" || !hasSessionCookie(ctx, cfg.SessionCookies) {
+			ctx.Next()
+			return
+		}
+
+		// 3.- A cross-site page can trigger the request but cannot read the cookie to echo it.
+		cookie, err := ctx.Cookie(cfg.CookieName)
+		header := strings.TrimSpace(ctx.GetHeader(cfg.HeaderName))
+		if err != nil || cookie == "" || header == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) != 1 {
+			respond.Error(ctx, http.StatusForbidden, "csrf token mismatch", map[string]interface{}{"reason": cfg.HeaderName + " must match the " + cfg.CookieName + " cookie"})
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// hasSessionCookie reports whether any credential-bearing cookie accompanies the request.
+func hasSessionCookie(ctx *gin.Context, names []string) bool {
+	for _, name := range names {
+		if value, err := ctx.Cookie(name); err == nil && value != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/config"
+)
+
+// 1.- TestCSRFProtectsCookieSessions authenticates through the access cookie and requires the double-submit header on writes.
+func TestCSRFProtectsCookieSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// 2.- Back the auth service with an in-memory Redis and issue a session.
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	authSvc, err := internalauth.NewService(config.JWTConfig{Secret: "test-secret", Issuer: "yamato-test"}, client)
+	if err != nil {
+		t.Fatalf("NewService returned error: %v", err)
+	}
+	pair, err := authSvc.Login(context.Background(), "42")
+	if err != nil {
+		t.Fatalf("Login returned error: %v", err)
+	}
+
+	// 3.- Mount the CSRF guard ahead of cookie-aware authentication.
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.Use(CSRF(CSRFConfig{CookieName: "csrf", HeaderName: "X-CSRF-Token", SessionCookies: []string{"access"}}))
+	router.Use(Authentication(authSvc, nil, WithSessionCookie("access")))
+	handler := func(ctx *gin.Context) {
+		principal, _ := internalauth.PrincipalFromContext(ctx)
+		ctx.JSON(http.StatusOK, gin.H{"subject": principal.Subject})
+	}
+	router.GET("/posts", handler)
+	router.POST("/posts", handler)
+
+	call := func(method string, cookies map[string]string, headers map[string]string) int {
+		req := httptest.NewRequest(method, "/posts", nil)
+		for name, value := range cookies {
+			req.AddCookie(&http.Cookie{Name: name, Value: value})
+		}
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+		return res.Code
+	}
+	session := map[string]string{"access": pair.AccessToken, "csrf": "token-1"}
+
+	// 4.- Reads authenticate from the cookie without any CSRF header.
+	if code := call(http.MethodGet, session, nil); code != http.StatusOK {
+		t.Fatalf("expected cookie session read to pass, got %d", code)
+	}
+	if code := call(http.MethodGet, nil, nil); code != http.StatusUnauthorized {
+		t.Fatalf("expected anonymous read to be unauthorized, got %d", code)
+	}
+
+	// 5.- Writes need the header to echo the CSRF cookie.
+	if code := call(http.MethodPost, session, nil); code != http.StatusForbidden {
+		t.Fatalf("expected missing csrf header to be forbidden, got %d", code)
+	}
+	if code := call(http.MethodPost, session, map[string]string{"X-CSRF-Token": "forged"}); code != http.StatusForbidden {
+		t.Fatalf("expected mismatched csrf header to be forbidden, got %d", code)
+	}
+	if code := call(http.MethodPost, session, map[string]string{"X-CSRF-Token": "token-1"}); code != http.StatusOK {
+		t.Fatalf("expected matching csrf header to pass, got %d", code)
+	}
+
+	// 6.- Bearer clients are not exposed to CSRF and skip the check.
+	if code := call(http.MethodPost, nil, map[string]string{"Authorization": "Bearer " + pair.AccessToken}); code != http.StatusOK {
+		t.Fatalf("expected bearer write to pass, got %d", code)
+	}
+}
@@ -0,0 +1,149 @@
+package phone
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 1.- bundledMetadata is the per-country numbering table compiled into the binary.
+//
+//go:embed data/metadata.csv
+var bundledMetadata []byte
+
+// 1.- Region describes the numbering plan of one country or territory.
+type Region struct {
+	// 2.- Code is the ISO 3166-1 alpha-2 code, such as "MX".
+	Code        string
+	CallingCode string
+	Name        string
+	// 3.- Lengths are the accepted national significant number lengths.
+	Lengths []int
+	// 4.- NationalPrefixes are trunk digits dialled domestically but dropped from E.164.
+	NationalPrefixes []string
+	// 5.- LeadingDigits select this region among those sharing a calling code; empty marks the default.
+	LeadingDigits []string
+}
+
+// 1.- acceptsLength reports whether n digits form a valid national number in the region.
+func (r Region) acceptsLength(n int) bool {
+	for _, length := range r.Lengths {
+		if length == n {
+			return true
+		}
+	}
+	return false
+}
+
+// 1.- table indexes the metadata by region code and calling code.
+type table struct {
+	regions   map[string]Region
+	byCalling map[string][]Region
+}
+
+// 1.- Cached parse of the bundled table.
+var (
+	metadataOnce sync.Once
+	metadata     *table
+	metadataErr  error
+)
+
+// 1.- loadMetadata parses the bundled table once; it needs no network or files.
+func loadMetadata() (*table, error) {
+	metadataOnce.Do(func() {
+		metadata, metadataErr = parseMetadata(bundledMetadata)
+	})
+	return metadata, metadataErr
+}
+
+// 1.- LookupRegion returns the numbering plan of an ISO 3166-1 alpha-2 region.
+func LookupRegion(code string) (Region, bool) {
+	t, err := loadMetadata()
+	if err != nil {
+		return Region{}, false
+	}
+	region, ok := t.regions[strings.ToUpper(strings.TrimSpace(code))]
+	return region, ok
+}
+
+// 1.- parseMetadata reads "region,calling_code,lengths,national_prefixes,leading_digits,name" rows.
+func parseMetadata(data []byte) (*table, error) {
+	t := &table{regions: map[string]Region{}, byCalling: map[string][]Region{}}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, ",")
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("phone: metadata line %d: expected 6 fields, got %d", line, len(fields))
+		}
+		lengths, err := parseLengths(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("phone: metadata line %d: %w", line, err)
+		}
+		region := Region{
+			Code:             fields[0],
+			CallingCode:      fields[1],
+			Name:             fields[5],
+			Lengths:          lengths,
+			NationalPrefixes: splitList(fields[3]),
+			LeadingDigits:    splitList(fields[4]),
+		}
+		if _, err := strconv.Atoi(region.CallingCode); err != nil || len(region.CallingCode) > 3 {
+			return nil, fmt.Errorf("phone: metadata line %d: invalid calling code %q", line, region.CallingCode)
+		}
+		if _, exists := t.regions[region.Code]; exists {
+			return nil, fmt.Errorf("phone: metadata line %d: duplicate region %s", line, region.Code)
+		}
+		t.regions[region.Code] = region
+		t.byCalling[region.CallingCode] = append(t.byCalling[region.CallingCode], region)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("phone: read metadata: %w", err)
+	}
+	return t, nil
+}
+
+// 1.- parseLengths expands "8|10" and "6-11" into the accepted lengths.
+func parseLengths(raw string) ([]int, error) {
+	var lengths []int
+	for _, part := range splitList(raw) {
+		low, high, isRange := strings.Cut(part, "-")
+		from, err := strconv.Atoi(low)
+		if err != nil {
+			return nil, fmt.Errorf("invalid length %q", part)
+		}
+		to := from
+		if isRange {
+			if to, err = strconv.Atoi(high); err != nil || to < from {
+				return nil, fmt.Errorf("invalid length range %q", part)
+			}
+		}
+		for n := from; n <= to; n++ {
+			lengths = append(lengths, n)
+		}
+	}
+	if len(lengths) == 0 {
+		return nil, fmt.Errorf("missing lengths")
+	}
+	return lengths, nil
+}
+
+// 1.- splitList splits a "|" separated column, dropping empty entries.
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, "|") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,163 @@
+// Package phone parses phone numbers into E.164 using a numbering table bundled with the binary.
+package phone
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// 1.- Sentinel errors returned by Parse.
+var (
+	ErrInvalidNumber      = errors.New("phone: invalid number")
+	ErrMissingCallingCode = errors.New("phone: number has no country calling code")
+	ErrUnknownCallingCode = errors.New("phone: unknown country calling code")
+	ErrInvalidLength      = errors.New("phone: invalid length for region")
+)
+
+// 1.- maxDigits is the E.164 limit on calling code plus national number.
+const maxDigits = 15
+
+// 1.- Number is a parsed phone number.
+type Number struct {
+	// 2.- E164 is the canonical "+<calling code><national number>" form used as storage key.
+	E164           string
+	CallingCode    string
+	NationalNumber string
+	// 3.- Region is the ISO 3166-1 alpha-2 code and Country its English name.
+	Region  string
+	Country string
+}
+
+// 1.- Parse normalises raw into E.164. Numbers without "+" or "00" are read in defaultRegion's national format.
+func Parse(raw string, defaultRegion string) (Number, error) {
+	t, err := loadMetadata()
+	if err != nil {
+		return Number{}, err
+	}
+	digits, international, err := extractDigits(raw)
+	if err != nil {
+		return Number{}, err
+	}
+
+	//1.- International input names its calling code; codes are prefix-free so the first match wins.
+	if international {
+		for n := 1; n <= 3 && n < len(digits); n++ {
+			if _, ok := t.byCalling[digits[:n]]; ok {
+				return t.resolve(digits[:n], digits[n:])
+			}
+		}
+		return Number{}, ErrUnknownCallingCode
+	}
+
+	//2.- National input borrows the calling code of the default region.
+	region, ok := t.regions[strings.ToUpper(strings.TrimSpace(defaultRegion))]
+	if !ok {
+		return Number{}, ErrMissingCallingCode
+	}
+	return t.resolve(region.CallingCode, digits)
+}
+
+// 1.- Normalize returns the E.164 form of an international number.
+func Normalize(raw string) (string, error) {
+	number, err := Parse(raw, "")
+	if err != nil {
+		return "", err
+	}
+	return number.E164, nil
+}
+
+// 1.- Valid reports whether raw parses in the given default region.
+func Valid(raw string, defaultRegion string) bool {
+	_, err := Parse(raw, defaultRegion)
+	return err == nil
+}
+
+// 1.- resolve picks the region and checks the length, dropping a trunk prefix such as the "0" in "+44 (0)20".
+func (t *table) resolve(callingCode string, national string) (Number, error) {
+	regions := t.byCalling[callingCode]
+	for _, candidate := range nationalCandidates(regions, national) {
+		region := pickRegion(regions, candidate)
+		if !region.acceptsLength(len(candidate)) || len(callingCode)+len(candidate) > maxDigits {
+			continue
+		}
+		return Number{
+			E164:           "+" + callingCode + candidate,
+			CallingCode:    callingCode,
+			NationalNumber: candidate,
+			Region:         region.Code,
+			Country:        region.Name,
+		}, nil
+	}
+	return Number{}, ErrInvalidLength
+}
+
+// 1.- nationalCandidates lists the number with each trunk prefix removed, longest first, then as written.
+func nationalCandidates(regions []Region, national string) []string {
+	seen := map[string]struct{}{}
+	var prefixes []string
+	for _, region := range regions {
+		for _, prefix := range region.NationalPrefixes {
+			if _, ok := seen[prefix]; !ok {
+				seen[prefix] = struct{}{}
+				prefixes = append(prefixes, prefix)
+			}
+		}
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+
+	var candidates []string
+	for _, prefix := range prefixes {
+		if stripped, ok := strings.CutPrefix(national, prefix); ok && stripped != "" {
+			candidates = append(candidates, stripped)
+		}
+	}
+	return append(candidates, national)
+}
+
+// 1.- pickRegion matches leading digits among regions sharing a calling code, falling back to the default row.
+func pickRegion(regions []Region, national string) Region {
+	fallback := regions[0]
+	for _, region := range regions {
+		if len(region.LeadingDigits) == 0 {
+			fallback = region
+			continue
+		}
+		for _, leading := range region.LeadingDigits {
+			if strings.HasPrefix(national, leading) {
+				return region
+			}
+		}
+	}
+	return fallback
+}
+
+// 1.- extractDigits strips formatting and reports whether the number carried an international prefix.
+func extractDigits(raw string) (string, bool, error) {
+	raw = strings.TrimSpace(raw)
+	international := strings.HasPrefix(raw, "+")
+	if international {
+		raw = raw[1:]
+	}
+
+	var builder strings.Builder
+	for _, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			builder.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')' || r == '/':
+		default:
+			return "", false, ErrInvalidNumber
+		}
+	}
+	digits := builder.String()
+
+	//1.- "00" is the international prefix in most of the world.
+	if !international && strings.HasPrefix(digits, "00") {
+		digits, international = digits[2:], true
+	}
+	if digits == "" || len(digits) > maxDigits+1 {
+		return "", false, ErrInvalidNumber
+	}
+	return digits, international, nil
+}
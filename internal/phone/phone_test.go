@@ -0,0 +1,82 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+// 1.- TestParseNormalizesFormats checks that differently formatted inputs converge on one E.164 key.
+func TestParseNormalizesFormats(t *testing.T) {
+	cases := []struct {
+		raw     string
+		region  string
+		e164    string
+		country string
+	}{
+		{raw: "+52 55 1234 5678", e164: "+525512345678", country: "MX"},
+		{raw: "+52 1 55-1234-5678", e164: "+525512345678", country: "MX"},
+		{raw: "0052 (55) 1234.5678", e164: "+525512345678", country: "MX"},
+		{raw: "55 1234 5678", region: "mx", e164: "+525512345678", country: "MX"},
+		{raw: "+1 (415) 555-0100", e164: "+14155550100", country: "US"},
+		{raw: "1-415-555-0100", region: "US", e164: "+14155550100", country: "US"},
+		{raw: "+1 416 555 0100", e164: "+14165550100", country: "CA"},
+		{raw: "+44 (0)20 7946 0018", e164: "+442079460018", country: "GB"},
+		{raw: "020 7946 0018", region: "GB", e164: "+442079460018", country: "GB"},
+		{raw: "+7 812 123 45 67", e164: "+78121234567", country: "RU"},
+		{raw: "+7 701 123 4567", e164: "+77011234567", country: "KZ"},
+		{raw: "+39 06 1234 5678", e164: "+390612345678", country: "IT"},
+	}
+	for _, tc := range cases {
+		number, err := Parse(tc.raw, tc.region)
+		if err != nil {
+			t.Fatalf("Parse(%q, %q) returned error: %v", tc.raw, tc.region, err)
+		}
+		if number.E164 != tc.e164 || number.Region != tc.country {
+			t.Fatalf("Parse(%q, %q) = %s %s, want %s %s", tc.raw, tc.region, number.E164, number.Region, tc.e164, tc.country)
+		}
+	}
+}
+
+// 2.- TestParseRejectsInvalidNumbers covers garbage, unknown codes, missing codes, and bad lengths.
+func TestParseRejectsInvalidNumbers(t *testing.T) {
+	cases := []struct {
+		raw    string
+		region string
+		err    error
+	}{
+		{raw: "", err: ErrInvalidNumber},
+		{raw: "+52 55 CALL ME", err: ErrInvalidNumber},
+		{raw: "+999 1234 5678", err: ErrUnknownCallingCode},
+		{raw: "55 1234 5678", err: ErrMissingCallingCode},
+		{raw: "+52 55-1234", err: ErrInvalidLength},
+		{raw: "+1 415 555 010", err: ErrInvalidLength},
+	}
+	for _, tc := range cases {
+		if _, err := Parse(tc.raw, tc.region); !errors.Is(err, tc.err) {
+			t.Fatalf("Parse(%q, %q) error = %v, want %v", tc.raw, tc.region, err, tc.err)
+		}
+	}
+}
+
+// 3.- TestMetadataIsConsistent guards the bundled table: every calling code has exactly one default region.
+func TestMetadataIsConsistent(t *testing.T) {
+	table, err := loadMetadata()
+	if err != nil {
+		t.Fatalf("loadMetadata returned error: %v", err)
+	}
+	for code, regions := range table.byCalling {
+		defaults := 0
+		for _, region := range regions {
+			if len(region.LeadingDigits) == 0 {
+				defaults++
+			}
+		}
+		if defaults != 1 {
+			t.Fatalf("calling code %s has %d default regions", code, defaults)
+		}
+	}
+	mexico, ok := LookupRegion("mx")
+	if !ok || mexico.CallingCode != "52" || mexico.Name != "Mexico" {
+		t.Fatalf("unexpected Mexico metadata: %+v", mexico)
+	}
+}
@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+)
+
+// 1.- AccessTokenStore keeps personal access tokens in memory keyed by identifier.
+type AccessTokenStore struct {
+	mu     sync.Mutex
+	nextID int
+	tokens map[string]authhttp.PersonalAccessToken
+}
+
+// 1.- NewAccessTokenStore prepares an empty token store.
+func NewAccessTokenStore() *AccessTokenStore {
+	return &AccessTokenStore{tokens: map[string]authhttp.PersonalAccessToken{}}
+}
+
+// 1.- Create assigns an identifier and stores a copy of the token.
+func (s *AccessTokenStore) Create(_ context.Context, token authhttp.PersonalAccessToken) (authhttp.PersonalAccessToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	token.ID = strconv.Itoa(s.nextID)
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now().UTC()
+	}
+	token.Scopes = append([]string(nil), token.Scopes...)
+	s.tokens[token.ID] = token
+	return token, nil
+}
+
+// 1.- FindByPrefix scans for the token carrying the public prefix.
+func (s *AccessTokenStore) FindByPrefix(_ context.Context, prefix string) (authhttp.PersonalAccessToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, token := range s.tokens {
+		if token.Prefix == prefix {
+			return token, nil
+		}
+	}
+	return authhttp.PersonalAccessToken{}, authhttp.ErrAccessTokenNotFound
+}
+
+// 1.- ListByUser returns the user's tokens, newest first.
+func (s *AccessTokenStore) ListByUser(_ context.Context, userID string) ([]authhttp.PersonalAccessToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens := []authhttp.PersonalAccessToken{}
+	for _, token := range s.tokens {
+		if token.UserID == userID {
+			tokens = append(tokens, token)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		left, _ := strconv.Atoi(tokens[i].ID)
+		right, _ := strconv.Atoi(tokens[j].ID)
+		return left > right
+	})
+	return tokens, nil
+}
+
+// 1.- Rename updates the label when the user owns the token.
+func (s *AccessTokenStore) Rename(_ context.Context, userID string, id string, name string) (authhttp.PersonalAccessToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[id]
+	if !ok || token.UserID != userID {
+		return authhttp.PersonalAccessToken{}, authhttp.ErrAccessTokenNotFound
+	}
+	token.Name = name
+	s.tokens[id] = token
+	return token, nil
+}
+
+// 1.- Delete removes the token when the user owns it.
+func (s *AccessTokenStore) Delete(_ context.Context, userID string, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[id]
+	if !ok || token.UserID != userID {
+		return authhttp.ErrAccessTokenNotFound
+	}
+	delete(s.tokens, id)
+	return nil
+}
+
+// 1.- TouchLastUsed records the last authentication time.
+func (s *AccessTokenStore) TouchLastUsed(_ context.Context, id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[id]
+	if !ok {
+		return authhttp.ErrAccessTokenNotFound
+	}
+	token.LastUsedAt = at
+	s.tokens[id] = token
+	return nil
+}
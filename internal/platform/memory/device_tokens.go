@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+)
+
+// 1.- DeviceTokenStore keeps phone device tokens in memory keyed by the token value.
+type DeviceTokenStore struct {
+	mu     sync.Mutex
+	nextID int
+	tokens map[string]authhttp.DeviceToken
+}
+
+// 1.- NewDeviceTokenStore prepares an empty device token store.
+func NewDeviceTokenStore() *DeviceTokenStore {
+	return &DeviceTokenStore{tokens: map[string]authhttp.DeviceToken{}}
+}
+
+// 1.- Create records a token for the phone, mirroring the insert made after a code is confirmed.
+func (s *DeviceTokenStore) Create(_ context.Context, phone string, token string) (authhttp.DeviceToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	now := time.Now()
+	device := authhttp.DeviceToken{ID: strconv.Itoa(s.nextID), Phone: phone, CreatedAt: now, LastUsedAt: now}
+	s.tokens[token] = device
+	return device, nil
+}
+
+// 1.- Use slides last_used_at on a live token that has not sat idle beyond idleTTL.
+func (s *DeviceTokenStore) Use(_ context.Context, token string, usedAt time.Time, idleTTL time.Duration) (authhttp.DeviceToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	device, ok := s.tokens[token]
+	if !ok || !deviceLive(device, usedAt, idleTTL) {
+		return authhttp.DeviceToken{}, authhttp.ErrDeviceTokenNotFound
+	}
+	device.LastUsedAt = usedAt
+	s.tokens[token] = device
+	return device, nil
+}
+
+// 1.- ListByPhone returns every token of the phone, newest first.
+func (s *DeviceTokenStore) ListByPhone(_ context.Context, phone string) ([]authhttp.DeviceToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	devices := []authhttp.DeviceToken{}
+	for _, device := range s.tokens {
+		if device.Phone == phone {
+			devices = append(devices, device)
+		}
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		left, _ := strconv.Atoi(devices[i].ID)
+		right, _ := strconv.Atoi(devices[j].ID)
+		return left > right
+	})
+	return devices, nil
+}
+
+// 1.- Revoke stamps revoked_at so the token can no longer be exchanged.
+func (s *DeviceTokenStore) Revoke(_ context.Context, phone string, id string, revokedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for token, device := range s.tokens {
+		if device.ID == id && device.Phone == phone && device.RevokedAt == nil {
+			device.RevokedAt = &revokedAt
+			s.tokens[token] = device
+			return nil
+		}
+	}
+	return authhttp.ErrDeviceTokenNotFound
+}
+
+// 1.- RevokeAll stamps revoked_at on every live token of the phone.
+func (s *DeviceTokenStore) RevokeAll(_ context.Context, phone string, revokedAt time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revoked := 0
+	for token, device := range s.tokens {
+		if device.Phone == phone && device.RevokedAt == nil {
+			device.RevokedAt = &revokedAt
+			s.tokens[token] = device
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+// 1.- Rotate revokes a live token and records its replacement for the same phone.
+func (s *DeviceTokenStore) Rotate(_ context.Context, token string, newToken string, rotatedAt time.Time, idleTTL time.Duration) (authhttp.DeviceToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	device, ok := s.tokens[token]
+	if !ok || !deviceLive(device, rotatedAt, idleTTL) {
+		return authhttp.DeviceToken{}, authhttp.ErrDeviceTokenNotFound
+	}
+	device.RevokedAt = &rotatedAt
+	s.tokens[token] = device
+
+	s.nextID++
+	replacement := authhttp.DeviceToken{ID: strconv.Itoa(s.nextID), Phone: device.Phone, CreatedAt: rotatedAt, LastUsedAt: rotatedAt}
+	s.tokens[newToken] = replacement
+	return replacement, nil
+}
+
+// 1.- deviceLive reports whether a token is neither revoked nor idle past its TTL.
+func deviceLive(device authhttp.DeviceToken, now time.Time, idleTTL time.Duration) bool {
+	if device.RevokedAt != nil {
+		return false
+	}
+	return idleTTL <= 0 || now.Sub(device.LastUsedAt) < idleTTL
+}
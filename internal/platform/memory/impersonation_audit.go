@@ -0,0 +1,34 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+)
+
+// 1.- ImpersonationAudit keeps impersonation audit entries in memory in arrival order.
+type ImpersonationAudit struct {
+	mu     sync.Mutex
+	events []authhttp.ImpersonationEvent
+}
+
+// 1.- NewImpersonationAudit prepares an empty audit log.
+func NewImpersonationAudit() *ImpersonationAudit {
+	return &ImpersonationAudit{}
+}
+
+// 1.- RecordImpersonation appends the entry.
+func (a *ImpersonationAudit) RecordImpersonation(_ context.Context, event authhttp.ImpersonationEvent) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = append(a.events, event)
+	return nil
+}
+
+// 1.- Events returns a snapshot of the recorded entries.
+func (a *ImpersonationAudit) Events() []authhttp.ImpersonationEvent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]authhttp.ImpersonationEvent(nil), a.events...)
+}
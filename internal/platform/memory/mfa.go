@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+)
+
+// 1.- MFAStore keeps TOTP enrollments and hashed recovery codes in memory.
+type MFAStore struct {
+	mu          sync.Mutex
+	enrollments map[string]authhttp.MFAEnrollment
+	recovery    map[string]map[string]bool
+}
+
+// 1.- NewMFAStore prepares an empty enrollment store.
+func NewMFAStore() *MFAStore {
+	return &MFAStore{enrollments: map[string]authhttp.MFAEnrollment{}, recovery: map[string]map[string]bool{}}
+}
+
+// 1.- SavePending replaces an unconfirmed enrollment while protecting confirmed ones.
+func (s *MFAStore) SavePending(_ context.Context, userID string, secret string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.enrollments[userID]; ok && existing.Enabled() {
+		return authhttp.ErrMFAAlreadyEnabled
+	}
+	s.enrollments[userID] = authhttp.MFAEnrollment{UserID: userID, Secret: secret}
+	return nil
+}
+
+// 1.- Find returns the stored enrollment for the user.
+func (s *MFAStore) Find(_ context.Context, userID string) (authhttp.MFAEnrollment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	enrollment, ok := s.enrollments[userID]
+	if !ok {
+		return authhttp.MFAEnrollment{}, authhttp.ErrMFANotEnrolled
+	}
+	return enrollment, nil
+}
+
+// 1.- Confirm activates the enrollment and replaces the recovery code hashes.
+func (s *MFAStore) Confirm(_ context.Context, userID string, confirmedAt time.Time, step int64, recoveryHashes []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	enrollment, ok := s.enrollments[userID]
+	if !ok {
+		return authhttp.ErrMFANotEnrolled
+	}
+	if enrollment.Enabled() {
+		return authhttp.ErrMFAAlreadyEnabled
+	}
+	enrollment.ConfirmedAt = confirmedAt
+	enrollment.LastUsedStep = step
+	s.enrollments[userID] = enrollment
+
+	codes := make(map[string]bool, len(recoveryHashes))
+	for _, hash := range recoveryHashes {
+		codes[hash] = false
+	}
+	s.recovery[userID] = codes
+	return nil
+}
+
+// 1.- AdvanceStep records the step when it is newer than the last accepted one.
+func (s *MFAStore) AdvanceStep(_ context.Context, userID string, step int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	enrollment, ok := s.enrollments[userID]
+	if !ok || step <= enrollment.LastUsedStep {
+		return false, nil
+	}
+	enrollment.LastUsedStep = step
+	s.enrollments[userID] = enrollment
+	return true, nil
+}
+
+// 1.- ConsumeRecoveryCode burns an unused recovery code.
+func (s *MFAStore) ConsumeRecoveryCode(_ context.Context, userID string, codeHash string, _ time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	used, ok := s.recovery[userID][codeHash]
+	if !ok || used {
+		return authhttp.ErrInvalidMFACode
+	}
+	s.recovery[userID][codeHash] = true
+	return nil
+}
+
+// 1.- Delete drops the enrollment and its recovery codes.
+func (s *MFAStore) Delete(_ context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.enrollments, userID)
+	delete(s.recovery, userID)
+	return nil
+}
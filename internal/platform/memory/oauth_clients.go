@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	oauthhttp "github.com/example/Yamato-Go-Gin-API/internal/http/oauth"
+)
+
+// 1.- OAuthClientStore keeps registered OAuth clients in memory keyed by client identifier.
+type OAuthClientStore struct {
+	mu      sync.Mutex
+	nextID  int
+	clients map[string]oauthhttp.Client
+}
+
+// 1.- NewOAuthClientStore prepares an empty client store.
+func NewOAuthClientStore() *OAuthClientStore {
+	return &OAuthClientStore{clients: map[string]oauthhttp.Client{}}
+}
+
+// 1.- Create assigns an identifier and stores a copy of the client.
+func (s *OAuthClientStore) Create(_ context.Context, client oauthhttp.Client) (oauthhttp.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.clients[client.ClientID]; exists {
+		return oauthhttp.Client{}, oauthhttp.ErrClientExists
+	}
+	s.nextID++
+	client.ID = strconv.Itoa(s.nextID)
+	if client.CreatedAt.IsZero() {
+		client.CreatedAt = time.Now().UTC()
+	}
+	s.clients[client.ClientID] = client
+	return client, nil
+}
+
+// 1.- FindByClientID returns the client registered under the identifier.
+func (s *OAuthClientStore) FindByClientID(_ context.Context, clientID string) (oauthhttp.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	client, ok := s.clients[clientID]
+	if !ok {
+		return oauthhttp.Client{}, oauthhttp.ErrClientNotFound
+	}
+	return client, nil
+}
@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+)
+
+// 1.- PasskeyStore keeps WebAuthn credentials in memory keyed by identifier.
+type PasskeyStore struct {
+	mu       sync.Mutex
+	nextID   int
+	passkeys map[string]authhttp.Passkey
+}
+
+// 1.- NewPasskeyStore prepares an empty passkey store.
+func NewPasskeyStore() *PasskeyStore {
+	return &PasskeyStore{passkeys: map[string]authhttp.Passkey{}}
+}
+
+// 1.- Create assigns an identifier and rejects duplicate credential ids.
+func (s *PasskeyStore) Create(_ context.Context, passkey authhttp.Passkey) (authhttp.Passkey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.passkeys {
+		if string(existing.CredentialID) == string(passkey.CredentialID) {
+			return authhttp.Passkey{}, authhttp.ErrPasskeyExists
+		}
+	}
+	s.nextID++
+	passkey.ID = strconv.Itoa(s.nextID)
+	if passkey.CreatedAt.IsZero() {
+		passkey.CreatedAt = time.Now().UTC()
+	}
+	passkey.Transports = append([]string(nil), passkey.Transports...)
+	s.passkeys[passkey.ID] = passkey
+	return passkey, nil
+}
+
+// 1.- FindByCredentialID scans for the passkey carrying the credential id.
+func (s *PasskeyStore) FindByCredentialID(_ context.Context, credentialID []byte) (authhttp.Passkey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, passkey := range s.passkeys {
+		if string(passkey.CredentialID) == string(credentialID) {
+			return passkey, nil
+		}
+	}
+	return authhttp.Passkey{}, authhttp.ErrPasskeyNotFound
+}
+
+// 1.- ListByUser returns the user's passkeys, newest first.
+func (s *PasskeyStore) ListByUser(_ context.Context, userID string) ([]authhttp.Passkey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	passkeys := []authhttp.Passkey{}
+	for _, passkey := range s.passkeys {
+		if passkey.UserID == userID {
+			passkeys = append(passkeys, passkey)
+		}
+	}
+	sort.Slice(passkeys, func(i, j int) bool {
+		left, _ := strconv.Atoi(passkeys[i].ID)
+		right, _ := strconv.Atoi(passkeys[j].ID)
+		return left > right
+	})
+	return passkeys, nil
+}
+
+// 1.- UpdateSignCount records the counter and time of the latest assertion.
+func (s *PasskeyStore) UpdateSignCount(_ context.Context, id string, signCount uint32, usedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	passkey, ok := s.passkeys[id]
+	if !ok {
+		return authhttp.ErrPasskeyNotFound
+	}
+	passkey.SignCount = signCount
+	passkey.LastUsedAt = usedAt
+	s.passkeys[id] = passkey
+	return nil
+}
+
+// 1.- Delete removes the passkey when the user owns it.
+func (s *PasskeyStore) Delete(_ context.Context, userID string, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	passkey, ok := s.passkeys[id]
+	if !ok || passkey.UserID != userID {
+		return authhttp.ErrPasskeyNotFound
+	}
+	delete(s.passkeys, id)
+	return nil
+}
@@ -3,6 +3,7 @@ package memory
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 // 1.- entry stores the cached value alongside its optional expiration deadline.
 type entry struct {
 	value     string
+	members   map[string]struct{}
 	expiresAt time.Time
 }
 
@@ -87,6 +89,131 @@ func (r *Redis) Del(ctx context.Context, keys ...string) *redis.IntCmd {
 	return cmd
 }
 
+// 1.- Incr atomically increments the integer stored at key, creating it when missing.
+func (r *Redis) Incr(ctx context.Context, key string) *redis.IntCmd {
+	cmd := redis.NewIntCmd(ctx, "incr", key)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, _ := r.live(key)
+	value := int64(0)
+	if current.value != "" {
+		parsed, err := strconv.ParseInt(current.value, 10, 64)
+		if err != nil {
+			cmd.SetErr(fmt.Errorf("ERR value is not an integer or out of range"))
+			return cmd
+		}
+		value = parsed
+	}
+	value++
+	current.value = strconv.FormatInt(value, 10)
+	r.values[key] = current
+
+	cmd.SetVal(value)
+	return cmd
+}
+
+// 1.- SAdd inserts members into the set stored at key and returns how many were new.
+func (r *Redis) SAdd(ctx context.Context, key string, members ...interface{}) *redis.IntCmd {
+	cmd := redis.NewIntCmd(ctx, append([]interface{}{"sadd", key}, members...)...)
+
+	r.mu.Lock()
+	current, ok := r.live(key)
+	if !ok || current.members == nil {
+		current = entry{members: map[string]struct{}{}, expiresAt: current.expiresAt}
+	}
+	added := int64(0)
+	for _, member := range members {
+		str := fmt.Sprint(member)
+		if _, exists := current.members[str]; !exists {
+			current.members[str] = struct{}{}
+			added++
+		}
+	}
+	r.values[key] = current
+	r.mu.Unlock()
+
+	cmd.SetVal(added)
+	return cmd
+}
+
+// 1.- SMembers lists the members of the set stored at key, returning an empty slice when missing.
+func (r *Redis) SMembers(ctx context.Context, key string) *redis.StringSliceCmd {
+	cmd := redis.NewStringSliceCmd(ctx, "smembers", key)
+
+	r.mu.Lock()
+	current, _ := r.live(key)
+	members := make([]string, 0, len(current.members))
+	for member := range current.members {
+		members = append(members, member)
+	}
+	r.mu.Unlock()
+
+	cmd.SetVal(members)
+	return cmd
+}
+
+// 1.- SRem removes members from the set stored at key and returns how many were present.
+func (r *Redis) SRem(ctx context.Context, key string, members ...interface{}) *redis.IntCmd {
+	cmd := redis.NewIntCmd(ctx, append([]interface{}{"srem", key}, members...)...)
+
+	removed := int64(0)
+	r.mu.Lock()
+	if current, ok := r.live(key); ok {
+		for _, member := range members {
+			str := fmt.Sprint(member)
+			if _, exists := current.members[str]; exists {
+				delete(current.members, str)
+				removed++
+			}
+		}
+		if current.members != nil && len(current.members) == 0 {
+			delete(r.values, key)
+		}
+	}
+	r.mu.Unlock()
+
+	cmd.SetVal(removed)
+	return cmd
+}
+
+// 1.- Expire updates the expiration deadline of an existing key.
+func (r *Redis) Expire(ctx context.Context, key string, expiration time.Duration) *redis.BoolCmd {
+	cmd := redis.NewBoolCmd(ctx, "expire", key, expiration)
+
+	r.mu.Lock()
+	current, ok := r.live(key)
+	if ok {
+		current.expiresAt = time.Now().Add(expiration)
+		r.values[key] = current
+	}
+	r.mu.Unlock()
+
+	cmd.SetVal(ok)
+	return cmd
+}
+
+// 1.- Ping answers PONG so health checks treat the emulator as reachable.
+func (r *Redis) Ping(ctx context.Context) *redis.StatusCmd {
+	cmd := redis.NewStatusCmd(ctx, "ping")
+	cmd.SetVal("PONG")
+	return cmd
+}
+
+// 1.- live returns the entry for key, evicting it first when already expired; callers hold the lock.
+func (r *Redis) live(key string) (entry, bool) {
+	current, ok := r.values[key]
+	if !ok {
+		return entry{}, false
+	}
+	if !current.expiresAt.IsZero() && time.Now().After(current.expiresAt) {
+		delete(r.values, key)
+		return entry{}, false
+	}
+	return current, true
+}
+
 // 1.- stringSliceToInterface converts a slice of strings to a slice of empty interfaces.
 func stringSliceToInterface(values []string) []interface{} {
 	converted := make([]interface{}, len(values))
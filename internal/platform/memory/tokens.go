@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+)
+
+// 1.- OneTimeTokenStore keeps hashed single-use tokens in memory for development and tests.
+type OneTimeTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]authhttp.OneTimeToken
+}
+
+// 1.- NewOneTimeTokenStore prepares an empty token store.
+func NewOneTimeTokenStore() *OneTimeTokenStore {
+	return &OneTimeTokenStore{tokens: map[string]authhttp.OneTimeToken{}}
+}
+
+// 1.- Save drops pending tokens for the same user and purpose before storing the new hash.
+func (s *OneTimeTokenStore) Save(_ context.Context, token authhttp.OneTimeToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for hash, existing := range s.tokens {
+		if existing.UserID == token.UserID && existing.Purpose == token.Purpose {
+			delete(s.tokens, hash)
+		}
+	}
+	s.tokens[token.TokenHash] = token
+	return nil
+}
+
+// 1.- Revoke drops pending tokens for the user and purpose.
+func (s *OneTimeTokenStore) Revoke(_ context.Context, userID string, purpose string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for hash, existing := range s.tokens {
+		if existing.UserID == userID && existing.Purpose == purpose {
+			delete(s.tokens, hash)
+		}
+	}
+	return nil
+}
+
+// 1.- Find returns a live token while leaving it redeemable.
+func (s *OneTimeTokenStore) Find(_ context.Context, purpose string, tokenHash string, now time.Time) (authhttp.OneTimeToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[tokenHash]
+	if !ok || token.Purpose != purpose || !now.Before(token.ExpiresAt) {
+		return authhttp.OneTimeToken{}, authhttp.ErrTokenNotFound
+	}
+	return token, nil
+}
+
+// 1.- Consume removes and returns a live token so it can never be redeemed twice.
+func (s *OneTimeTokenStore) Consume(_ context.Context, purpose string, tokenHash string, now time.Time) (authhttp.OneTimeToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[tokenHash]
+	if !ok || token.Purpose != purpose {
+		return authhttp.OneTimeToken{}, authhttp.ErrTokenNotFound
+	}
+	delete(s.tokens, tokenHash)
+	if !now.Before(token.ExpiresAt) {
+		return authhttp.OneTimeToken{}, authhttp.ErrTokenNotFound
+	}
+	return token, nil
+}
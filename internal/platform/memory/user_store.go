@@ -31,7 +31,7 @@ func (s *UserStore) Create(_ context.Context, user authhttp.User) (authhttp.User
 		return authhttp.User{}, errors.New("memory user store: email is required")
 	}
 	if _, exists := s.byEmail[key]; exists {
-		return authhttp.User{}, errors.New("memory user store: email already exists")
+		return authhttp.User{}, authhttp.ErrEmailTaken
 	}
 
 	copied := user
@@ -64,3 +64,37 @@ func (s *UserStore) FindByID(_ context.Context, id string) (authhttp.User, error
 	}
 	return user, nil
 }
+
+// 1.- UpdatePassword swaps the stored password hash for the identified user.
+func (s *UserStore) UpdatePassword(_ context.Context, id string, passwordHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return authhttp.ErrUserNotFound
+	}
+	user.PasswordHash = passwordHash
+	s.users[id] = user
+	return nil
+}
+
+// 1.- UpdateEmail moves the user to a new address, enforcing unique email constraints.
+func (s *UserStore) UpdateEmail(_ context.Context, id string, email string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return authhttp.ErrUserNotFound
+	}
+	key := strings.ToLower(strings.TrimSpace(email))
+	if owner, exists := s.byEmail[key]; exists && owner != id {
+		return authhttp.ErrEmailTaken
+	}
+	delete(s.byEmail, user.Email)
+	user.Email = key
+	s.users[id] = user
+	s.byEmail[key] = id
+	return nil
+}
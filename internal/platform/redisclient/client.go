@@ -0,0 +1,52 @@
+package redisclient
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/example/Yamato-Go-Gin-API/internal/config"
+)
+
+// 1.- NewClient builds a go-redis client from the configuration; TLS verifies the server against the system roots.
+func NewClient(cfg config.RedisConfig) *redis.Client {
+	options := &redis.Options{
+		Addr:         cfg.Addr(),
+		Username:     cfg.Username,
+		Password:     cfg.Password,
+		DB:           cfg.DB,
+		DialTimeout:  5 * time.Second,
+		ReadTimeout:  3 * time.Second,
+		WriteTimeout: 3 * time.Second,
+	}
+	if cfg.TLS {
+		options.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12, ServerName: cfg.Host}
+	}
+	return redis.NewClient(options)
+}
+
+// 1.- pingClient is satisfied by go-redis clients and the in-memory emulator.
+type pingClient interface {
+	Ping(ctx context.Context) *redis.StatusCmd
+}
+
+// 1.- Pinger adapts a Redis client to the error-returning probe used by diagnostics.
+type Pinger struct {
+	client pingClient
+}
+
+// 1.- NewPinger wraps the client for health checks.
+func NewPinger(client pingClient) Pinger {
+	return Pinger{client: client}
+}
+
+// 1.- Ping reports whether Redis answered PING.
+func (p Pinger) Ping(ctx context.Context) error {
+	if err := p.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("redis ping: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package redisclient
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/example/Yamato-Go-Gin-API/internal/config"
+	"github.com/example/Yamato-Go-Gin-API/internal/platform/memory"
+)
+
+// 1.- TestClientHonoursDatabaseIndexAndPings ensures keys land in the configured DB and the pinger reports outages.
+func TestClientHonoursDatabaseIndexAndPings(t *testing.T) {
+	mini := miniredis.RunT(t)
+	client := NewClient(config.RedisConfig{Host: mini.Host(), Port: atoi(t, mini.Port()), DB: 2})
+	defer client.Close()
+
+	ctx := context.Background()
+	require.NoError(t, client.Set(ctx, "auth:probe", "1", 0).Err())
+	mini.Select(2)
+	require.True(t, mini.Exists("auth:probe"))
+
+	pinger := NewPinger(client)
+	require.NoError(t, pinger.Ping(ctx))
+	mini.Close()
+	require.Error(t, pinger.Ping(ctx))
+
+	// 2.- The in-memory emulator always answers.
+	require.NoError(t, NewPinger(memory.NewRedis()).Ping(ctx))
+}
+
+// 1.- TestClientEnablesTLS ensures TLS deployments verify the configured host.
+func TestClientEnablesTLS(t *testing.T) {
+	client := NewClient(config.RedisConfig{Host: "cache.example.com", Port: 6380, TLS: true})
+	defer client.Close()
+
+	require.NotNil(t, client.Options().TLSConfig)
+	require.Equal(t, "cache.example.com", client.Options().TLSConfig.ServerName)
+	require.Equal(t, "cache.example.com:6380", client.Options().Addr)
+}
+
+// 1.- atoi converts the miniredis port for the configuration struct.
+func atoi(t *testing.T, value string) int {
+	t.Helper()
+	port, err := strconv.Atoi(value)
+	require.NoError(t, err)
+	return port
+}
@@ -6,6 +6,9 @@ import (
 	"time"
 )
 
+// EmailSendJobName identifies the email delivery job for producers enqueueing messages.
+const EmailSendJobName = "email_send"
+
 // EmailSender abstracts the delivery mechanism for email notifications.
 type EmailSender interface {
 	Send(ctx context.Context, to string, subject string, body string) error
@@ -14,7 +17,7 @@ type EmailSender interface {
 // NewEmailSendJob registers a retry-aware email sending job.
 func NewEmailSendJob(sender EmailSender) RegisteredJob {
 	return RegisteredJob{
-		Name:       "email_send",
+		Name:       EmailSendJobName,
 		MaxRetries: 5,
 		Timeout:    45 * time.Second,
 		Handler: func(ctx context.Context, message *Message) error {
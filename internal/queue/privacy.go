@@ -0,0 +1,70 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// UserExportJobName identifies the personal data export job for producers enqueueing requests.
+const UserExportJobName = "user_export"
+
+// UserPurgeJobName identifies the job that hard-deletes accounts whose grace period has elapsed.
+const UserPurgeJobName = "user_purge"
+
+// UserDataExporter builds and stores the archive for a pending export request.
+type UserDataExporter interface {
+	RunExport(ctx context.Context, exportID string) error
+}
+
+// AccountPurger removes soft-deleted accounts once they are past the grace period.
+type AccountPurger interface {
+	PurgeDeleted(ctx context.Context) (int, error)
+}
+
+// NewUserExportJob registers the job that assembles a user's data archive.
+func NewUserExportJob(exporter UserDataExporter) RegisteredJob {
+	return RegisteredJob{
+		Name:       UserExportJobName,
+		MaxRetries: 3,
+		Timeout:    5 * time.Minute,
+		Handler: func(ctx context.Context, message *Message) error {
+			// 1.- Extract the export identifier recorded by the API.
+			exportID, _ := message.Payload["export_id"].(string)
+			if exportID == "" {
+				return errors.New("missing export_id")
+			}
+			// 2.- Build and persist the archive.
+			if err := exporter.RunExport(ctx, exportID); err != nil {
+				return err
+			}
+			// 3.- Record metadata for traceability across retries.
+			message.Metadata = map[string]interface{}{
+				"export_id": exportID,
+				"status":    "completed",
+			}
+			return nil
+		},
+	}
+}
+
+// NewUserPurgeJob registers the scheduled hard purge of soft-deleted accounts.
+func NewUserPurgeJob(purger AccountPurger) RegisteredJob {
+	return RegisteredJob{
+		Name:       UserPurgeJobName,
+		MaxRetries: 1,
+		Timeout:    5 * time.Minute,
+		Handler: func(ctx context.Context, message *Message) error {
+			// 1.- Delete every account whose grace period has elapsed.
+			purged, err := purger.PurgeDeleted(ctx)
+			if err != nil {
+				return err
+			}
+			// 2.- Record how many accounts were removed.
+			message.Metadata = map[string]interface{}{
+				"purged": purged,
+			}
+			return nil
+		},
+	}
+}
@@ -161,3 +161,45 @@ var assertError = queueErr("intentional failure")
 type queueErr string
 
 func (e queueErr) Error() string { return string(e) }
+
+func TestRedisQueueDeliversSMS(t *testing.T) {
+	client, cleanup := setupRedis(t)
+	defer cleanup()
+
+	q := queue.NewRedisQueue(client, "itest")
+	sender := &recordingSMSSender{}
+	require.NoError(t, q.Register(queue.NewSMSSendJob(sender)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = q.StartConsumer(ctx) }()
+
+	_, err := q.Enqueue(ctx, queue.SMSSendJobName, map[string]any{
+		"to":   "+5215550000000",
+		"body": "Your code is 123456",
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return sender.Last() == "+5215550000000|Your code is 123456"
+	}, 10*time.Second, 50*time.Millisecond)
+}
+
+type recordingSMSSender struct {
+	mu   sync.Mutex
+	last string
+}
+
+func (r *recordingSMSSender) Send(ctx context.Context, to string, body string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// 1.- Keep the latest delivery so tests can assert the payload reached the sender.
+	r.last = to + "|" + body
+	return nil
+}
+
+func (r *recordingSMSSender) Last() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.last
+}
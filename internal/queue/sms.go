@@ -0,0 +1,42 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// SMSSendJobName identifies the SMS delivery job for producers enqueueing text messages.
+const SMSSendJobName = "sms_send"
+
+// SMSSender abstracts the carrier or gateway that delivers text messages.
+type SMSSender interface {
+	Send(ctx context.Context, to string, body string) error
+}
+
+// NewSMSSendJob registers a retry-aware SMS sending job.
+func NewSMSSendJob(sender SMSSender) RegisteredJob {
+	return RegisteredJob{
+		Name:       SMSSendJobName,
+		MaxRetries: 5,
+		Timeout:    30 * time.Second,
+		Handler: func(ctx context.Context, message *Message) error {
+			// 1.- Extract payload fields with strong validation.
+			to, _ := message.Payload["to"].(string)
+			body, _ := message.Payload["body"].(string)
+			if to == "" || body == "" {
+				return errors.New("missing sms fields")
+			}
+			// 2.- Forward the message to the sender dependency.
+			if err := sender.Send(ctx, to, body); err != nil {
+				return err
+			}
+			// 3.- Record metadata for traceability; the body is left out because it carries one-time codes.
+			message.Metadata = map[string]interface{}{
+				"to":     to,
+				"status": "sent",
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,208 @@
+package accesstokens
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+
+	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+)
+
+// 1.- Store implements authhttp.AccessTokenStore using the personal_access_tokens table.
+type Store struct {
+	db *sql.DB
+}
+
+// 1.- NewStore validates the database handle and prepares the store.
+func NewStore(db *sql.DB) (*Store, error) {
+	if db == nil {
+		return nil, errors.New("access token store requires a database connection")
+	}
+	return &Store{db: db}, nil
+}
+
+// 1.- tokenColumns lists the selected columns in scan order.
+const tokenColumns = `id, user_id, name, prefix, token_hash, scopes, expires_at, last_used_at, created_at`
+
+// 1.- Create inserts the token and returns it with the generated identifier.
+func (s *Store) Create(ctx context.Context, token authhttp.PersonalAccessToken) (authhttp.PersonalAccessToken, error) {
+	userID, err := parseID(token.UserID)
+	if err != nil {
+		return authhttp.PersonalAccessToken{}, authhttp.ErrUserNotFound
+	}
+
+	row := s.db.QueryRowContext(ctx, `
+INSERT INTO personal_access_tokens (user_id, name, prefix, token_hash, scopes, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING `+tokenColumns, userID, token.Name, token.Prefix, token.TokenHash, pq.Array(nonNil(token.Scopes)), nullTime(token.ExpiresAt))
+	created, err := scanToken(row)
+	if err != nil {
+		return authhttp.PersonalAccessToken{}, fmt.Errorf("create access token: %w", err)
+	}
+	return created, nil
+}
+
+// 1.- FindByPrefix loads a token by its unique public prefix.
+func (s *Store) FindByPrefix(ctx context.Context, prefix string) (authhttp.PersonalAccessToken, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+tokenColumns+` FROM personal_access_tokens WHERE prefix = $1`, prefix)
+	token, err := scanToken(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return authhttp.PersonalAccessToken{}, authhttp.ErrAccessTokenNotFound
+		}
+		return authhttp.PersonalAccessToken{}, fmt.Errorf("find access token: %w", err)
+	}
+	return token, nil
+}
+
+// 1.- ListByUser returns the user's tokens, newest first.
+func (s *Store) ListByUser(ctx context.Context, userID string) ([]authhttp.PersonalAccessToken, error) {
+	id, err := parseID(userID)
+	if err != nil {
+		return []authhttp.PersonalAccessToken{}, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+tokenColumns+` FROM personal_access_tokens WHERE user_id = $1 ORDER BY created_at DESC, id DESC`, id)
+	if err != nil {
+		return nil, fmt.Errorf("list access tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []authhttp.PersonalAccessToken{}
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan access token: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate access tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// 1.- Rename updates the label of a token owned by the user.
+func (s *Store) Rename(ctx context.Context, userID string, id string, name string) (authhttp.PersonalAccessToken, error) {
+	owner, tokenID, err := parseOwnedID(userID, id)
+	if err != nil {
+		return authhttp.PersonalAccessToken{}, err
+	}
+
+	row := s.db.QueryRowContext(ctx, `
+UPDATE personal_access_tokens
+SET name = $3
+WHERE id = $1 AND user_id = $2
+RETURNING `+tokenColumns, tokenID, owner, name)
+	token, err := scanToken(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return authhttp.PersonalAccessToken{}, authhttp.ErrAccessTokenNotFound
+		}
+		return authhttp.PersonalAccessToken{}, fmt.Errorf("rename access token: %w", err)
+	}
+	return token, nil
+}
+
+// 1.- Delete removes a token owned by the user.
+func (s *Store) Delete(ctx context.Context, userID string, id string) error {
+	owner, tokenID, err := parseOwnedID(userID, id)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM personal_access_tokens WHERE id = $1 AND user_id = $2`, tokenID, owner)
+	if err != nil {
+		return fmt.Errorf("delete access token: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete access token: %w", err)
+	}
+	if affected == 0 {
+		return authhttp.ErrAccessTokenNotFound
+	}
+	return nil
+}
+
+// 1.- TouchLastUsed records the last authentication time.
+func (s *Store) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	tokenID, err := parseID(id)
+	if err != nil {
+		return authhttp.ErrAccessTokenNotFound
+	}
+	if _, err := s.db.ExecContext(ctx, `UPDATE personal_access_tokens SET last_used_at = $2 WHERE id = $1`, tokenID, at.UTC()); err != nil {
+		return fmt.Errorf("touch access token: %w", err)
+	}
+	return nil
+}
+
+// 1.- rowScanner abstracts *sql.Row and *sql.Rows for scanToken.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// 1.- scanToken maps a selected row onto the domain type.
+func scanToken(row rowScanner) (authhttp.PersonalAccessToken, error) {
+	var (
+		token      authhttp.PersonalAccessToken
+		id         int64
+		userID     int64
+		scopes     []string
+		expiresAt  sql.NullTime
+		lastUsedAt sql.NullTime
+	)
+	if err := row.Scan(&id, &userID, &token.Name, &token.Prefix, &token.TokenHash, pq.Array(&scopes), &expiresAt, &lastUsedAt, &token.CreatedAt); err != nil {
+		return authhttp.PersonalAccessToken{}, err
+	}
+	token.ID = strconv.FormatInt(id, 10)
+	token.UserID = strconv.FormatInt(userID, 10)
+	token.Scopes = nonNil(scopes)
+	if expiresAt.Valid {
+		token.ExpiresAt = expiresAt.Time.UTC()
+	}
+	if lastUsedAt.Valid {
+		token.LastUsedAt = lastUsedAt.Time.UTC()
+	}
+	token.CreatedAt = token.CreatedAt.UTC()
+	return token, nil
+}
+
+// 1.- parseOwnedID converts the owner and token identifiers, hiding malformed ids as not found.
+func parseOwnedID(userID string, id string) (int64, int64, error) {
+	owner, err := parseID(userID)
+	if err != nil {
+		return 0, 0, authhttp.ErrAccessTokenNotFound
+	}
+	tokenID, err := parseID(id)
+	if err != nil {
+		return 0, 0, authhttp.ErrAccessTokenNotFound
+	}
+	return owner, tokenID, nil
+}
+
+// 1.- parseID converts the string identifiers used by handlers into BIGINT keys.
+func parseID(value string) (int64, error) {
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// 1.- nullTime stores the zero time as NULL.
+func nullTime(value time.Time) sql.NullTime {
+	if value.IsZero() {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: value.UTC(), Valid: true}
+}
+
+// 1.- nonNil normalizes nil slices so scopes always serialize as an array.
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
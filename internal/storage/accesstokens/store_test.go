@@ -0,0 +1,75 @@
+package accesstokens
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/storage"
+	"github.com/example/Yamato-Go-Gin-API/internal/testutil"
+)
+
+// 1.- TestStoreLifecycle exercises create, lookup, ownership checks, and deletion against Postgres.
+func TestStoreLifecycle(t *testing.T) {
+	container := testutil.RunPostgresContainer(t)
+	if container == nil {
+		t.Skip("postgres container unavailable")
+		return
+	}
+
+	db, err := sql.Open("postgres", container.DSN)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	migrator, err := storage.NewMigrator(db)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply(ctx))
+
+	// 2.- Seed the owning user.
+	var userID int64
+	require.NoError(t, db.QueryRowContext(ctx, `
+INSERT INTO users (email, password_hash, first_name, last_name)
+VALUES ('pat@example.com', 'hash', 'Token', 'Owner')
+RETURNING id`).Scan(&userID))
+	owner := strconv.FormatInt(userID, 10)
+
+	store, err := NewStore(db)
+	require.NoError(t, err)
+
+	// 3.- Create a token and find it by prefix with scopes and expiry intact.
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	created, err := store.Create(ctx, authhttp.PersonalAccessToken{UserID: owner, Name: "ci", Prefix: "abcdef012345", TokenHash: authhttp.HashAccessToken("secret"), Scopes: []string{"admin:users"}, ExpiresAt: expires})
+	require.NoError(t, err)
+	found, err := store.FindByPrefix(ctx, "abcdef012345")
+	require.NoError(t, err)
+	require.Equal(t, created.ID, found.ID)
+	require.Equal(t, []string{"admin:users"}, found.Scopes)
+	require.True(t, found.ExpiresAt.Equal(expires))
+	require.True(t, found.LastUsedAt.IsZero())
+
+	// 4.- Touch, rename, and list.
+	require.NoError(t, store.TouchLastUsed(ctx, created.ID, time.Now()))
+	renamed, err := store.Rename(ctx, owner, created.ID, "deploy")
+	require.NoError(t, err)
+	require.Equal(t, "deploy", renamed.Name)
+	require.False(t, renamed.LastUsedAt.IsZero())
+	tokens, err := store.ListByUser(ctx, owner)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+
+	// 5.- Other users cannot delete the token; the owner can, once.
+	require.ErrorIs(t, store.Delete(ctx, strconv.FormatInt(userID+1, 10), created.ID), authhttp.ErrAccessTokenNotFound)
+	require.NoError(t, store.Delete(ctx, owner, created.ID))
+	require.ErrorIs(t, store.Delete(ctx, owner, created.ID), authhttp.ErrAccessTokenNotFound)
+	_, err = store.FindByPrefix(ctx, "abcdef012345")
+	require.ErrorIs(t, err, authhttp.ErrAccessTokenNotFound)
+}
@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+)
+
+// 1.- Store implements authhttp.ImpersonationAuditor using the impersonation_audit_logs table.
+type Store struct {
+	db *sql.DB
+}
+
+// 1.- NewStore validates the database handle and prepares the store.
+func NewStore(db *sql.DB) (*Store, error) {
+	if db == nil {
+		return nil, errors.New("audit store requires a database connection")
+	}
+	return &Store{db: db}, nil
+}
+
+// 1.- RecordImpersonation appends the entry to the audit trail.
+func (s *Store) RecordImpersonation(ctx context.Context, event authhttp.ImpersonationEvent) error {
+	actorID, err := strconv.ParseInt(event.Actor, 10, 64)
+	if err != nil {
+		return fmt.Errorf("record impersonation: invalid actor %q", event.Actor)
+	}
+	subjectID, err := strconv.ParseInt(event.Subject, 10, 64)
+	if err != nil {
+		return fmt.Errorf("record impersonation: invalid subject %q", event.Subject)
+	}
+	createdAt := event.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+INSERT INTO impersonation_audit_logs (actor_id, subject_id, token_id, action, reason, method, path, ip_address, user_agent, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		actorID, subjectID, event.TokenID, event.Action, nullString(event.Reason), nullString(event.Method), nullString(event.Path), nullString(event.IP), nullString(event.UserAgent), createdAt.UTC())
+	if err != nil {
+		return fmt.Errorf("record impersonation: %w", err)
+	}
+	return nil
+}
+
+// 1.- ListByToken returns the entries written for one impersonation token, oldest first.
+func (s *Store) ListByToken(ctx context.Context, tokenID string) ([]authhttp.ImpersonationEvent, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT actor_id, subject_id, token_id, action, COALESCE(reason, ''), COALESCE(method, ''), COALESCE(path, ''), COALESCE(ip_address, ''), COALESCE(user_agent, ''), created_at
+FROM impersonation_audit_logs
+WHERE token_id = $1
+ORDER BY created_at, id`, tokenID)
+	if err != nil {
+		return nil, fmt.Errorf("list impersonation audit: %w", err)
+	}
+	defer rows.Close()
+
+	events := []authhttp.ImpersonationEvent{}
+	for rows.Next() {
+		var (
+			event              authhttp.ImpersonationEvent
+			actorID, subjectID int64
+		)
+		if err := rows.Scan(&actorID, &subjectID, &event.TokenID, &event.Action, &event.Reason, &event.Method, &event.Path, &event.IP, &event.UserAgent, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan impersonation audit: %w", err)
+		}
+		event.Actor = strconv.FormatInt(actorID, 10)
+		event.Subject = strconv.FormatInt(subjectID, 10)
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate impersonation audit: %w", err)
+	}
+	return events, nil
+}
+
+// 1.- nullString stores empty strings as NULL.
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/storage"
+	"github.com/example/Yamato-Go-Gin-API/internal/testutil"
+)
+
+// 1.- TestStoreRecordsImpersonationTrail persists issuance and request entries against Postgres.
+func TestStoreRecordsImpersonationTrail(t *testing.T) {
+	container := testutil.RunPostgresContainer(t)
+	if container == nil {
+		t.Skip("postgres container unavailable")
+		return
+	}
+
+	db, err := sql.Open("postgres", container.DSN)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	migrator, err := storage.NewMigrator(db)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply(ctx))
+
+	// 2.- Seed the administrator and the impersonated user.
+	ids := make([]string, 0, 2)
+	for _, email := range []string{"support@example.com", "customer@example.com"} {
+		var id int64
+		require.NoError(t, db.QueryRowContext(ctx, `
+INSERT INTO users (email, password_hash, first_name, last_name)
+VALUES ($1, 'hash', 'Audit', 'User')
+RETURNING id`, email).Scan(&id))
+		ids = append(ids, strconv.FormatInt(id, 10))
+	}
+
+	store, err := NewStore(db)
+	require.NoError(t, err)
+
+	// 3.- Record the issuance and one request, then read them back in order.
+	start := authhttp.ImpersonationEvent{Actor: ids[0], Subject: ids[1], TokenID: "jti-1", Action: authhttp.ImpersonationActionStart, Reason: "ticket 42", CreatedAt: time.Now().Add(-time.Second)}
+	request := authhttp.ImpersonationEvent{Actor: ids[0], Subject: ids[1], TokenID: "jti-1", Action: authhttp.ImpersonationActionRequest, Method: "GET", Path: "/v1/user", IP: "10.0.0.1", UserAgent: "curl"}
+	require.NoError(t, store.RecordImpersonation(ctx, start))
+	require.NoError(t, store.RecordImpersonation(ctx, request))
+	require.Error(t, store.RecordImpersonation(ctx, authhttp.ImpersonationEvent{Actor: "x", Subject: ids[1], TokenID: "jti-1", Action: authhttp.ImpersonationActionRequest}))
+
+	events, err := store.ListByToken(ctx, "jti-1")
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, authhttp.ImpersonationActionStart, events[0].Action)
+	require.Equal(t, "ticket 42", events[0].Reason)
+	require.Equal(t, "/v1/user", events[1].Path)
+	require.Equal(t, ids[0], events[1].Actor)
+}
@@ -0,0 +1,186 @@
+package devicetokens
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+)
+
+// 1.- Store implements authhttp.DeviceTokenStore on top of the device_tokens table.
+type Store struct {
+	db *sql.DB
+}
+
+// 1.- NewStore validates the database handle and prepares the store.
+func NewStore(db *sql.DB) (*Store, error) {
+	if db == nil {
+		return nil, errors.New("device token store requires a database connection")
+	}
+	return &Store{db: db}, nil
+}
+
+// 1.- deviceColumns lists the selected columns in scan order.
+const deviceColumns = `id, phone, created_at, last_used_at, revoked_at`
+
+// 1.- Create records a token for the phone, matching the insert made by the phone verification controller.
+func (s *Store) Create(ctx context.Context, phone string, token string) (authhttp.DeviceToken, error) {
+	row := s.db.QueryRowContext(ctx, `
+INSERT INTO device_tokens (phone, token)
+VALUES ($1, $2)
+RETURNING `+deviceColumns, phone, token)
+	device, err := scanDevice(row)
+	if err != nil {
+		return authhttp.DeviceToken{}, fmt.Errorf("create device token: %w", err)
+	}
+	return device, nil
+}
+
+// 1.- Use slides last_used_at in the same statement that checks revocation and idle expiry, so a dead token never refreshes.
+func (s *Store) Use(ctx context.Context, token string, usedAt time.Time, idleTTL time.Duration) (authhttp.DeviceToken, error) {
+	row := s.db.QueryRowContext(ctx, `
+UPDATE device_tokens
+SET last_used_at = $2
+WHERE token = $1 AND revoked_at IS NULL AND last_used_at > $3
+RETURNING `+deviceColumns, token, usedAt.UTC(), idleCutoff(usedAt, idleTTL))
+	device, err := scanDevice(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return authhttp.DeviceToken{}, authhttp.ErrDeviceTokenNotFound
+		}
+		return authhttp.DeviceToken{}, fmt.Errorf("use device token: %w", err)
+	}
+	return device, nil
+}
+
+// 1.- ListByPhone returns every token of the phone, newest first.
+func (s *Store) ListByPhone(ctx context.Context, phone string) ([]authhttp.DeviceToken, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT `+deviceColumns+`
+FROM device_tokens
+WHERE phone = $1
+ORDER BY created_at DESC, id DESC`, phone)
+	if err != nil {
+		return nil, fmt.Errorf("list device tokens: %w", err)
+	}
+	defer rows.Close()
+
+	devices := []authhttp.DeviceToken{}
+	for rows.Next() {
+		device, err := scanDevice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan device token: %w", err)
+		}
+		devices = append(devices, device)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list device tokens: %w", err)
+	}
+	return devices, nil
+}
+
+// 1.- Revoke stamps revoked_at on a live token of the phone.
+func (s *Store) Revoke(ctx context.Context, phone string, id string, revokedAt time.Time) error {
+	rowID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return authhttp.ErrDeviceTokenNotFound
+	}
+	result, err := s.db.ExecContext(ctx, `UPDATE device_tokens SET revoked_at = $3 WHERE id = $1 AND phone = $2 AND revoked_at IS NULL`, rowID, phone, revokedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("revoke device token: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("revoke device token: %w", err)
+	}
+	if affected == 0 {
+		return authhttp.ErrDeviceTokenNotFound
+	}
+	return nil
+}
+
+// 1.- RevokeAll stamps revoked_at on every live token of the phone.
+func (s *Store) RevokeAll(ctx context.Context, phone string, revokedAt time.Time) (int, error) {
+	result, err := s.db.ExecContext(ctx, `UPDATE device_tokens SET revoked_at = $2 WHERE phone = $1 AND revoked_at IS NULL`, phone, revokedAt.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("revoke device tokens: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("revoke device tokens: %w", err)
+	}
+	return int(affected), nil
+}
+
+// 1.- Rotate revokes a live token and inserts its replacement inside one transaction.
+func (s *Store) Rotate(ctx context.Context, token string, newToken string, rotatedAt time.Time, idleTTL time.Duration) (authhttp.DeviceToken, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return authhttp.DeviceToken{}, fmt.Errorf("rotate device token: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	//1.- Revoking first locks the row, so two concurrent rotations cannot both succeed.
+	var phone string
+	err = tx.QueryRowContext(ctx, `
+UPDATE device_tokens
+SET revoked_at = $2
+WHERE token = $1 AND revoked_at IS NULL AND last_used_at > $3
+RETURNING phone`, token, rotatedAt.UTC(), idleCutoff(rotatedAt, idleTTL)).Scan(&phone)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return authhttp.DeviceToken{}, authhttp.ErrDeviceTokenNotFound
+		}
+		return authhttp.DeviceToken{}, fmt.Errorf("rotate device token: %w", err)
+	}
+
+	//2.- The replacement starts a fresh idle window.
+	device, err := scanDevice(tx.QueryRowContext(ctx, `
+INSERT INTO device_tokens (phone, token, created_at, last_used_at)
+VALUES ($1, $2, $3, $3)
+RETURNING `+deviceColumns, phone, newToken, rotatedAt.UTC()))
+	if err != nil {
+		return authhttp.DeviceToken{}, fmt.Errorf("rotate device token: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return authhttp.DeviceToken{}, fmt.Errorf("rotate device token: %w", err)
+	}
+	return device, nil
+}
+
+// 1.- idleCutoff is the oldest last_used_at still accepted; a zero TTL accepts every token.
+func idleCutoff(now time.Time, idleTTL time.Duration) time.Time {
+	if idleTTL <= 0 {
+		return time.Unix(0, 0).UTC()
+	}
+	return now.Add(-idleTTL).UTC()
+}
+
+// 1.- rowScanner abstracts *sql.Row for scanning helpers.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// 1.- scanDevice converts a row into the handler model.
+func scanDevice(row rowScanner) (authhttp.DeviceToken, error) {
+	var (
+		device    authhttp.DeviceToken
+		id        int64
+		revokedAt sql.NullTime
+	)
+	if err := row.Scan(&id, &device.Phone, &device.CreatedAt, &device.LastUsedAt, &revokedAt); err != nil {
+		return authhttp.DeviceToken{}, err
+	}
+	device.ID = strconv.FormatInt(id, 10)
+	device.CreatedAt = device.CreatedAt.UTC()
+	device.LastUsedAt = device.LastUsedAt.UTC()
+	if revokedAt.Valid {
+		revoked := revokedAt.Time.UTC()
+		device.RevokedAt = &revoked
+	}
+	return device, nil
+}
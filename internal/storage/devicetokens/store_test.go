@@ -0,0 +1,113 @@
+package devicetokens
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/storage"
+	"github.com/example/Yamato-Go-Gin-API/internal/testutil"
+)
+
+// 1.- TestStoreTracksUseAndRevocation exercises last_used_at updates and revocation against Postgres.
+func TestStoreTracksUseAndRevocation(t *testing.T) {
+	container := testutil.RunPostgresContainer(t)
+	if container == nil {
+		t.Skip("postgres container unavailable")
+		return
+	}
+
+	db, err := sql.Open("postgres", container.DSN)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	migrator, err := storage.NewMigrator(db)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply(ctx))
+
+	store, err := NewStore(db)
+	require.NoError(t, err)
+
+	// 2.- Using a live token moves last_used_at forward.
+	created, err := store.Create(ctx, "+5215550000000", "device-secret")
+	require.NoError(t, err)
+	usedAt := created.LastUsedAt.Add(time.Hour)
+	used, err := store.Use(ctx, "device-secret", usedAt, 0)
+	require.NoError(t, err)
+	require.Equal(t, created.ID, used.ID)
+	require.Equal(t, "+5215550000000", used.Phone)
+	require.WithinDuration(t, usedAt, used.LastUsedAt, time.Millisecond)
+
+	// 3.- Unknown and revoked tokens are rejected the same way.
+	_, err = store.Use(ctx, "missing", usedAt, 0)
+	require.ErrorIs(t, err, authhttp.ErrDeviceTokenNotFound)
+	require.NoError(t, store.Revoke(ctx, created.Phone, created.ID, time.Now()))
+	require.ErrorIs(t, store.Revoke(ctx, created.Phone, created.ID, time.Now()), authhttp.ErrDeviceTokenNotFound)
+	_, err = store.Use(ctx, "device-secret", usedAt, 0)
+	require.ErrorIs(t, err, authhttp.ErrDeviceTokenNotFound)
+}
+
+// 1.- TestStoreManagesDevicesPerPhone covers listing, rotation, bulk revocation, and idle expiry.
+func TestStoreManagesDevicesPerPhone(t *testing.T) {
+	container := testutil.RunPostgresContainer(t)
+	if container == nil {
+		t.Skip("postgres container unavailable")
+		return
+	}
+
+	db, err := sql.Open("postgres", container.DSN)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	migrator, err := storage.NewMigrator(db)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply(ctx))
+
+	store, err := NewStore(db)
+	require.NoError(t, err)
+
+	// 2.- Listing is scoped to the phone and revocation cannot cross phones.
+	const phone = "+5215551111111"
+	first, err := store.Create(ctx, phone, "phone-one")
+	require.NoError(t, err)
+	_, err = store.Create(ctx, phone, "phone-two")
+	require.NoError(t, err)
+	other, err := store.Create(ctx, "+5215552222222", "phone-other")
+	require.NoError(t, err)
+	devices, err := store.ListByPhone(ctx, phone)
+	require.NoError(t, err)
+	require.Len(t, devices, 2)
+	require.ErrorIs(t, store.Revoke(ctx, phone, other.ID, time.Now()), authhttp.ErrDeviceTokenNotFound)
+
+	// 3.- Rotation retires the old secret and the replacement starts a fresh idle window.
+	rotatedAt := time.Now()
+	rotated, err := store.Rotate(ctx, "phone-two", "phone-three", rotatedAt, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, phone, rotated.Phone)
+	_, err = store.Use(ctx, "phone-two", rotatedAt, time.Hour)
+	require.ErrorIs(t, err, authhttp.ErrDeviceTokenNotFound)
+	_, err = store.Rotate(ctx, "phone-two", "phone-four", rotatedAt, time.Hour)
+	require.ErrorIs(t, err, authhttp.ErrDeviceTokenNotFound)
+
+	// 4.- Tokens idle beyond the TTL are rejected without being renewed.
+	_, err = store.Use(ctx, "phone-one", first.LastUsedAt.Add(2*time.Hour), time.Hour)
+	require.ErrorIs(t, err, authhttp.ErrDeviceTokenNotFound)
+
+	// 5.- Bulk revocation only touches live tokens of the phone.
+	revoked, err := store.RevokeAll(ctx, phone, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 2, revoked)
+	_, err = store.Use(ctx, "phone-other", time.Now(), time.Hour)
+	require.NoError(t, err)
+}
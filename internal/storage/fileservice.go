@@ -20,6 +20,9 @@ var ErrMIMENotAllowed = errors.New("mime type not allowed")
 // ErrFileTooLarge is returned when the payload exceeds the configured limit.
 var ErrFileTooLarge = errors.New("file exceeds maximum allowed size")
 
+// ErrFileNotFound is returned when a stored file is missing or the path escapes the storage root.
+var ErrFileNotFound = errors.New("file not found")
+
 // FileUploadSettings describes the validation constraints retrieved from the settings store.
 type FileUploadSettings struct {
 	AllowedMIMETypes []string
@@ -31,6 +34,16 @@ type FileSettingsProvider interface {
 	FileUploadSettings(ctx context.Context) (FileUploadSettings, error)
 }
 
+// StaticSettingsProvider serves fixed limits for callers that do not read them from the settings store.
+type StaticSettingsProvider struct {
+	Settings FileUploadSettings
+}
+
+// FileUploadSettings returns the configured limits unchanged.
+func (p StaticSettingsProvider) FileUploadSettings(context.Context) (FileUploadSettings, error) {
+	return p.Settings, nil
+}
+
 // SignedURL represents a placeholder response for future integrations with secure URL generation.
 type SignedURL struct {
 	URL     string
@@ -164,6 +177,58 @@ func (s *FileService) Save(ctx context.Context, originalName string, content io.
 	return relPath, nil
 }
 
+// Open returns a reader for a file previously stored under the relative path.
+func (s *FileService) Open(ctx context.Context, relativePath string) (io.ReadCloser, error) {
+	//1.- Resolve the path inside the storage root, rejecting traversal attempts.
+	fullPath, err := s.resolve(relativePath)
+	if err != nil {
+		return nil, err
+	}
+
+	//2.- Open the file read-only and translate missing files into the sentinel error.
+	file, err := os.Open(fullPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrFileNotFound
+		}
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return file, nil
+}
+
+// Delete removes a stored file; deleting a missing file is not an error.
+func (s *FileService) Delete(ctx context.Context, relativePath string) error {
+	//1.- Resolve the path inside the storage root, rejecting traversal attempts.
+	fullPath, err := s.resolve(relativePath)
+	if err != nil {
+		return err
+	}
+
+	//2.- Remove the file while treating an already missing file as success.
+	if err := os.Remove(fullPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+// resolve maps a relative path returned by Save onto the filesystem without leaving the root.
+func (s *FileService) resolve(relativePath string) (string, error) {
+	//1.- Guard against incorrect instantiation and empty paths.
+	if s == nil {
+		return "", errors.New("file service is not initialized")
+	}
+	cleaned := filepath.Clean(strings.TrimSpace(relativePath))
+	if cleaned == "." || cleaned == "" || filepath.IsAbs(cleaned) {
+		return "", ErrFileNotFound
+	}
+
+	//2.- Refuse paths that climb out of the storage directory.
+	if cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", ErrFileNotFound
+	}
+	return filepath.Join(s.root, cleaned), nil
+}
+
 // GenerateDownloadURL returns a placeholder signed URL for future HTTP integrations.
 func (s *FileService) GenerateDownloadURL(ctx context.Context, relativePath string, ttl time.Duration) (SignedURL, error) {
 	//1.- Require a path so the future signer can derive the canonical resource representation.
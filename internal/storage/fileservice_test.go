@@ -111,6 +111,53 @@ func TestSaveGeneratesDeterministicPath(t *testing.T) {
 	}
 }
 
+// 1.- TestOpenAndDeleteStayInsideRoot round-trips a stored file and rejects traversal.
+func TestOpenAndDeleteStayInsideRoot(t *testing.T) {
+	t.Parallel()
+
+	//2.- Store a file through a static provider that accepts ZIP archives.
+	svc, err := NewFileService("local", t.TempDir(), StaticSettingsProvider{Settings: FileUploadSettings{
+		AllowedMIMETypes: []string{"application/zip"},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error creating file service: %v", err)
+	}
+	data := []byte("archive")
+	rel, err := svc.Save(context.Background(), "export.zip", bytes.NewReader(data), int64(len(data)), "application/zip")
+	if err != nil {
+		t.Fatalf("unexpected error saving file: %v", err)
+	}
+
+	//3.- Read the file back through Open.
+	reader, err := svc.Open(context.Background(), rel)
+	if err != nil {
+		t.Fatalf("unexpected error opening file: %v", err)
+	}
+	contents, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil || !bytes.Equal(contents, data) {
+		t.Fatalf("expected %q, got %q (%v)", data, contents, err)
+	}
+
+	//4.- Paths escaping the root are reported as missing.
+	for _, path := range []string{"../secret", "/etc/passwd", ""} {
+		if _, err := svc.Open(context.Background(), path); !errors.Is(err, ErrFileNotFound) {
+			t.Fatalf("expected ErrFileNotFound for %q, got %v", path, err)
+		}
+	}
+
+	//5.- Delete removes the file and is idempotent.
+	if err := svc.Delete(context.Background(), rel); err != nil {
+		t.Fatalf("unexpected error deleting file: %v", err)
+	}
+	if err := svc.Delete(context.Background(), rel); err != nil {
+		t.Fatalf("expected idempotent delete, got %v", err)
+	}
+	if _, err := svc.Open(context.Background(), rel); !errors.Is(err, ErrFileNotFound) {
+		t.Fatalf("expected ErrFileNotFound after delete, got %v", err)
+	}
+}
+
 // 1.- TestGenerateDownloadURLProvidesPlaceholder validates the temporary signed URL implementation.
 func TestGenerateDownloadURLProvidesPlaceholder(t *testing.T) {
 	t.Parallel()
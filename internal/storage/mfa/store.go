@@ -0,0 +1,199 @@
+package mfa
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+)
+
+// 1.- Store implements authhttp.MFAStore using the user_mfa and mfa_recovery_codes tables.
+type Store struct {
+	db *sql.DB
+}
+
+// 1.- NewStore validates the database handle and prepares the store.
+func NewStore(db *sql.DB) (*Store, error) {
+	if db == nil {
+		return nil, errors.New("mfa store requires a database connection")
+	}
+	return &Store{db: db}, nil
+}
+
+// 1.- SavePending upserts an unconfirmed secret without touching confirmed enrollments.
+func (s *Store) SavePending(ctx context.Context, userID string, secret string) error {
+	id, err := parseUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	const query = `
+INSERT INTO user_mfa (user_id, secret)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET secret = EXCLUDED.secret, last_used_step = 0, updated_at = NOW()
+WHERE user_mfa.confirmed_at IS NULL`
+
+	result, err := s.db.ExecContext(ctx, query, id, secret)
+	if err != nil {
+		return fmt.Errorf("save mfa enrollment: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return authhttp.ErrMFAAlreadyEnabled
+	}
+	return nil
+}
+
+// 1.- Find loads the enrollment for the user.
+func (s *Store) Find(ctx context.Context, userID string) (authhttp.MFAEnrollment, error) {
+	id, err := parseUserID(userID)
+	if err != nil {
+		return authhttp.MFAEnrollment{}, authhttp.ErrMFANotEnrolled
+	}
+
+	const query = `
+SELECT secret, confirmed_at, last_used_step
+FROM user_mfa
+WHERE user_id = $1`
+
+	var (
+		enrollment  = authhttp.MFAEnrollment{UserID: userID}
+		confirmedAt sql.NullTime
+	)
+	if err := s.db.QueryRowContext(ctx, query, id).Scan(&enrollment.Secret, &confirmedAt, &enrollment.LastUsedStep); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return authhttp.MFAEnrollment{}, authhttp.ErrMFANotEnrolled
+		}
+		return authhttp.MFAEnrollment{}, fmt.Errorf("find mfa enrollment: %w", err)
+	}
+	if confirmedAt.Valid {
+		enrollment.ConfirmedAt = confirmedAt.Time.UTC()
+	}
+	return enrollment, nil
+}
+
+// 1.- Confirm activates the enrollment and swaps in the new recovery code hashes atomically.
+func (s *Store) Confirm(ctx context.Context, userID string, confirmedAt time.Time, step int64, recoveryHashes []string) error {
+	id, err := parseUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin mfa transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	result, err := tx.ExecContext(ctx, `
+UPDATE user_mfa
+SET confirmed_at = $2, last_used_step = $3, updated_at = NOW()
+WHERE user_id = $1 AND confirmed_at IS NULL`, id, confirmedAt.UTC(), step)
+	if err != nil {
+		return fmt.Errorf("confirm mfa enrollment: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return authhttp.ErrMFAAlreadyEnabled
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, id); err != nil {
+		return fmt.Errorf("clear recovery codes: %w", err)
+	}
+	for _, hash := range recoveryHashes {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, id, hash); err != nil {
+			return fmt.Errorf("insert recovery code: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit mfa transaction: %w", err)
+	}
+	return nil
+}
+
+// 1.- AdvanceStep moves last_used_step forward only when the new step is strictly newer.
+func (s *Store) AdvanceStep(ctx context.Context, userID string, step int64) (bool, error) {
+	id, err := parseUserID(userID)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+UPDATE user_mfa
+SET last_used_step = $2, updated_at = NOW()
+WHERE user_id = $1 AND last_used_step < $2`, id, step)
+	if err != nil {
+		return false, fmt.Errorf("advance mfa step: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("advance mfa step: %w", err)
+	}
+	return affected == 1, nil
+}
+
+// 1.- ConsumeRecoveryCode marks an unused recovery code as spent in a single statement.
+func (s *Store) ConsumeRecoveryCode(ctx context.Context, userID string, codeHash string, now time.Time) error {
+	id, err := parseUserID(userID)
+	if err != nil {
+		return authhttp.ErrInvalidMFACode
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+UPDATE mfa_recovery_codes
+SET used_at = $3
+WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`, id, codeHash, now.UTC())
+	if err != nil {
+		return fmt.Errorf("consume recovery code: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("consume recovery code: %w", err)
+	}
+	if affected == 0 {
+		return authhttp.ErrInvalidMFACode
+	}
+	return nil
+}
+
+// 1.- Delete removes the enrollment and its recovery codes.
+func (s *Store) Delete(ctx context.Context, userID string) error {
+	id, err := parseUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin mfa transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, id); err != nil {
+		return fmt.Errorf("delete recovery codes: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, id); err != nil {
+		return fmt.Errorf("delete mfa enrollment: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit mfa transaction: %w", err)
+	}
+	return nil
+}
+
+// 1.- parseUserID converts the string identifier used by handlers into the BIGINT key.
+func parseUserID(userID string) (int64, error) {
+	id, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return 0, authhttp.ErrUserNotFound
+	}
+	return id, nil
+}
@@ -41,6 +41,7 @@ func (m *Migrator) Apply(ctx context.Context) error {
                 "0002_join_requests",
                 "0003_tasks",
                 "0004_verification",
+                "0005_auth",
         }
 
 	for _, migrationDir := range migrationDirs {
@@ -86,6 +87,11 @@ func (m *Migrator) Apply(ctx context.Context) error {
 		}
 	}
 
-	//5.- Nothing failed so we can report success to the caller.
+	//5.- Bring phone keys written before E.164 normalization in line with current lookups.
+	if err := normalizePhones(ctx, m.db); err != nil {
+		return err
+	}
+
+	//6.- Nothing failed so we can report success to the caller.
 	return nil
 }
@@ -102,6 +102,14 @@ func TestMigratorApply(t *testing.T) {
                 "notifications",
                 "join_requests",
                 "tasks",
+                "one_time_tokens",
+                "user_mfa",
+                "mfa_recovery_codes",
+                "personal_access_tokens",
+                "impersonation_audit_logs",
+                "webauthn_credentials",
+                "data_exports",
+                "oauth_clients",
         }
 
 	for _, table := range requiredTables {
@@ -207,4 +215,39 @@ func TestMigratorApply(t *testing.T) {
 	if !constraintName.Valid {
 		t.Fatalf("expected join_requests_unique_requester constraint to exist")
 	}
+
+	//11.- Legacy phone keys are rewritten to E.164; unparseable values and taken account phones stay put.
+	seed := []string{
+		`INSERT INTO phone_verifications (phone, code_hash, status, name, expires_at) VALUES ('+52 1 55 5000 0000', 'digest', 'verified', 'Legacy', NOW())`,
+		`INSERT INTO phone_verifications (phone, code_hash, status, name, expires_at) VALUES ('not a phone', 'digest', 'verified', 'Broken', NOW())`,
+		`INSERT INTO device_tokens (phone, token) VALUES ('0052 55 5000 0000', 'legacy-device')`,
+		`INSERT INTO users (email, password_hash, first_name, last_name, phone) VALUES ('legacy@example.com', 'hash', 'Legacy', 'Owner', '+52 (55) 5000-0000')`,
+		`INSERT INTO users (email, password_hash, first_name, last_name, phone) VALUES ('canonical@example.com', 'hash', 'Canonical', 'Owner', '+525551111111')`,
+		`INSERT INTO users (email, password_hash, first_name, last_name, phone) VALUES ('clash@example.com', 'hash', 'Clash', 'Owner', '+52 55 5111 1111')`,
+	}
+	for _, statement := range seed {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			t.Fatalf("failed to seed legacy phones: %v", err)
+		}
+	}
+	if err := normalizePhones(ctx, db); err != nil {
+		t.Fatalf("failed to normalize phones: %v", err)
+	}
+	expectedPhones := map[string]string{
+		"SELECT phone FROM phone_verifications WHERE name = 'Legacy'":   "+525550000000",
+		"SELECT phone FROM phone_verifications WHERE name = 'Broken'":   "not a phone",
+		"SELECT phone FROM device_tokens WHERE token = 'legacy-device'": "+525550000000",
+		"SELECT phone FROM users WHERE email = 'legacy@example.com'":    "+525550000000",
+		"SELECT phone FROM users WHERE email = 'canonical@example.com'": "+525551111111",
+		"SELECT phone FROM users WHERE email = 'clash@example.com'":     "+52 55 5111 1111",
+	}
+	for query, expected := range expectedPhones {
+		var stored string
+		if err := db.QueryRowContext(ctx, query).Scan(&stored); err != nil {
+			t.Fatalf("failed to read phone with %q: %v", query, err)
+		}
+		if stored != expected {
+			t.Fatalf("unexpected phone for %q: got %s want %s", query, stored, expected)
+		}
+	}
 }
@@ -0,0 +1,95 @@
+package oauthclients
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/lib/pq"
+
+	oauthhttp "github.com/example/Yamato-Go-Gin-API/internal/http/oauth"
+)
+
+// 1.- uniqueViolation is the Postgres error code raised for duplicate client identifiers.
+const uniqueViolation = "23505"
+
+// 1.- Store implements oauthhttp.ClientStore using the oauth_clients table.
+type Store struct {
+	db *sql.DB
+}
+
+// 1.- NewStore validates the database handle and prepares the store.
+func NewStore(db *sql.DB) (*Store, error) {
+	if db == nil {
+		return nil, errors.New("oauth client store requires a database connection")
+	}
+	return &Store{db: db}, nil
+}
+
+// 1.- clientColumns lists the selected columns in scan order.
+const clientColumns = `id, client_id, name, secret_hash, scopes, created_at, revoked_at`
+
+// 1.- Create inserts the client and returns it with the generated identifier.
+func (s *Store) Create(ctx context.Context, client oauthhttp.Client) (oauthhttp.Client, error) {
+	row := s.db.QueryRowContext(ctx, `
+INSERT INTO oauth_clients (client_id, name, secret_hash, scopes)
+VALUES ($1, $2, $3, $4)
+RETURNING `+clientColumns, client.ClientID, client.Name, client.SecretHash, pq.Array(nonNil(client.Scopes)))
+	created, err := scanClient(row)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return oauthhttp.Client{}, oauthhttp.ErrClientExists
+		}
+		return oauthhttp.Client{}, fmt.Errorf("create oauth client: %w", err)
+	}
+	return created, nil
+}
+
+// 1.- FindByClientID loads a client by its public identifier.
+func (s *Store) FindByClientID(ctx context.Context, clientID string) (oauthhttp.Client, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+clientColumns+` FROM oauth_clients WHERE client_id = $1`, clientID)
+	client, err := scanClient(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return oauthhttp.Client{}, oauthhttp.ErrClientNotFound
+		}
+		return oauthhttp.Client{}, fmt.Errorf("find oauth client: %w", err)
+	}
+	return client, nil
+}
+
+// 1.- rowScanner abstracts *sql.Row for scanning helpers.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// 1.- scanClient converts a row into the handler model.
+func scanClient(row rowScanner) (oauthhttp.Client, error) {
+	var (
+		client    oauthhttp.Client
+		id        int64
+		scopes    []string
+		revokedAt sql.NullTime
+	)
+	if err := row.Scan(&id, &client.ClientID, &client.Name, &client.SecretHash, pq.Array(&scopes), &client.CreatedAt, &revokedAt); err != nil {
+		return oauthhttp.Client{}, err
+	}
+	client.ID = strconv.FormatInt(id, 10)
+	client.Scopes = nonNil(scopes)
+	client.CreatedAt = client.CreatedAt.UTC()
+	if revokedAt.Valid {
+		client.RevokedAt = revokedAt.Time.UTC()
+	}
+	return client, nil
+}
+
+// 1.- nonNil normalizes nil slices so scopes always round-trip as an array.
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,57 @@
+package oauthclients
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	oauthhttp "github.com/example/Yamato-Go-Gin-API/internal/http/oauth"
+	"github.com/example/Yamato-Go-Gin-API/internal/storage"
+	"github.com/example/Yamato-Go-Gin-API/internal/testutil"
+)
+
+// 1.- TestStoreRegistersAndAuthenticatesClients exercises creation, duplicates, and lookups against Postgres.
+func TestStoreRegistersAndAuthenticatesClients(t *testing.T) {
+	container := testutil.RunPostgresContainer(t)
+	if container == nil {
+		t.Skip("postgres container unavailable")
+		return
+	}
+
+	db, err := sql.Open("postgres", container.DSN)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	migrator, err := storage.NewMigrator(db)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply(ctx))
+
+	store, err := NewStore(db)
+	require.NoError(t, err)
+	registry := oauthhttp.NewClientRegistry(store)
+
+	// 2.- Register a client; the identifier is unique.
+	client, secret, err := registry.Register(ctx, "billing", "Billing service", []string{"tasks:read", "admin:users"})
+	require.NoError(t, err)
+	require.NotEmpty(t, client.ID)
+	require.Equal(t, []string{"admin:users", "tasks:read"}, client.Scopes)
+	_, _, err = registry.Register(ctx, "billing", "Duplicate", nil)
+	require.ErrorIs(t, err, oauthhttp.ErrClientExists)
+
+	// 3.- Only the right secret authenticates.
+	found, err := registry.AuthenticateClient(ctx, "billing", secret)
+	require.NoError(t, err)
+	require.Equal(t, "Billing service", found.Name)
+	require.Equal(t, client.Scopes, found.Scopes)
+	_, err = registry.AuthenticateClient(ctx, "billing", "wrong")
+	require.ErrorIs(t, err, oauthhttp.ErrInvalidClient)
+	_, err = store.FindByClientID(ctx, "missing")
+	require.ErrorIs(t, err, oauthhttp.ErrClientNotFound)
+}
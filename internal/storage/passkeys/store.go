@@ -0,0 +1,182 @@
+package passkeys
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+
+	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+)
+
+// 1.- uniqueViolation is the Postgres error code raised for duplicate credential ids.
+const uniqueViolation = "23505"
+
+// 1.- Store implements authhttp.PasskeyStore using the webauthn_credentials table.
+type Store struct {
+	db *sql.DB
+}
+
+// 1.- NewStore validates the database handle and prepares the store.
+func NewStore(db *sql.DB) (*Store, error) {
+	if db == nil {
+		return nil, errors.New("passkey store requires a database connection")
+	}
+	return &Store{db: db}, nil
+}
+
+// 1.- passkeyColumns lists the selected columns in scan order.
+const passkeyColumns = `id, user_id, name, credential_id, public_key, sign_count, aaguid, transports, backup_eligible, last_used_at, created_at`
+
+// 1.- Create inserts the passkey and returns it with the generated identifier.
+func (s *Store) Create(ctx context.Context, passkey authhttp.Passkey) (authhttp.Passkey, error) {
+	userID, err := parseID(passkey.UserID)
+	if err != nil {
+		return authhttp.Passkey{}, authhttp.ErrUserNotFound
+	}
+
+	row := s.db.QueryRowContext(ctx, `
+INSERT INTO webauthn_credentials (user_id, name, credential_id, public_key, sign_count, aaguid, transports, backup_eligible)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING `+passkeyColumns, userID, passkey.Name, passkey.CredentialID, passkey.PublicKey, int64(passkey.SignCount), passkey.AAGUID, pq.Array(nonNil(passkey.Transports)), passkey.BackupEligible)
+	created, err := scanPasskey(row)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return authhttp.Passkey{}, authhttp.ErrPasskeyExists
+		}
+		return authhttp.Passkey{}, fmt.Errorf("create passkey: %w", err)
+	}
+	return created, nil
+}
+
+// 1.- FindByCredentialID loads a passkey by the authenticator's credential id.
+func (s *Store) FindByCredentialID(ctx context.Context, credentialID []byte) (authhttp.Passkey, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+passkeyColumns+` FROM webauthn_credentials WHERE credential_id = $1`, credentialID)
+	passkey, err := scanPasskey(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return authhttp.Passkey{}, authhttp.ErrPasskeyNotFound
+		}
+		return authhttp.Passkey{}, fmt.Errorf("find passkey: %w", err)
+	}
+	return passkey, nil
+}
+
+// 1.- ListByUser returns the user's passkeys, newest first.
+func (s *Store) ListByUser(ctx context.Context, userID string) ([]authhttp.Passkey, error) {
+	id, err := parseID(userID)
+	if err != nil {
+		return []authhttp.Passkey{}, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+passkeyColumns+` FROM webauthn_credentials WHERE user_id = $1 ORDER BY created_at DESC, id DESC`, id)
+	if err != nil {
+		return nil, fmt.Errorf("list passkeys: %w", err)
+	}
+	defer rows.Close()
+
+	passkeys := []authhttp.Passkey{}
+	for rows.Next() {
+		passkey, err := scanPasskey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan passkey: %w", err)
+		}
+		passkeys = append(passkeys, passkey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate passkeys: %w", err)
+	}
+	return passkeys, nil
+}
+
+// 1.- UpdateSignCount records the counter and time of the latest assertion.
+func (s *Store) UpdateSignCount(ctx context.Context, id string, signCount uint32, usedAt time.Time) error {
+	passkeyID, err := parseID(id)
+	if err != nil {
+		return authhttp.ErrPasskeyNotFound
+	}
+	result, err := s.db.ExecContext(ctx, `UPDATE webauthn_credentials SET sign_count = $2, last_used_at = $3 WHERE id = $1`, passkeyID, int64(signCount), usedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("update passkey sign count: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("update passkey sign count: %w", err)
+	}
+	if affected == 0 {
+		return authhttp.ErrPasskeyNotFound
+	}
+	return nil
+}
+
+// 1.- Delete removes a passkey owned by the user.
+func (s *Store) Delete(ctx context.Context, userID string, id string) error {
+	owner, err := parseID(userID)
+	if err != nil {
+		return authhttp.ErrPasskeyNotFound
+	}
+	passkeyID, err := parseID(id)
+	if err != nil {
+		return authhttp.ErrPasskeyNotFound
+	}
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM webauthn_credentials WHERE id = $1 AND user_id = $2`, passkeyID, owner)
+	if err != nil {
+		return fmt.Errorf("delete passkey: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete passkey: %w", err)
+	}
+	if affected == 0 {
+		return authhttp.ErrPasskeyNotFound
+	}
+	return nil
+}
+
+// 1.- rowScanner abstracts *sql.Row and *sql.Rows for scanPasskey.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// 1.- scanPasskey maps a selected row onto the domain type.
+func scanPasskey(row rowScanner) (authhttp.Passkey, error) {
+	var (
+		passkey    authhttp.Passkey
+		id         int64
+		userID     int64
+		signCount  int64
+		transports []string
+		lastUsedAt sql.NullTime
+	)
+	if err := row.Scan(&id, &userID, &passkey.Name, &passkey.CredentialID, &passkey.PublicKey, &signCount, &passkey.AAGUID, pq.Array(&transports), &passkey.BackupEligible, &lastUsedAt, &passkey.CreatedAt); err != nil {
+		return authhttp.Passkey{}, err
+	}
+	passkey.ID = strconv.FormatInt(id, 10)
+	passkey.UserID = strconv.FormatInt(userID, 10)
+	passkey.SignCount = uint32(signCount)
+	passkey.Transports = nonNil(transports)
+	if lastUsedAt.Valid {
+		passkey.LastUsedAt = lastUsedAt.Time.UTC()
+	}
+	passkey.CreatedAt = passkey.CreatedAt.UTC()
+	return passkey, nil
+}
+
+// 1.- parseID converts the string identifiers used by handlers into BIGINT keys.
+func parseID(value string) (int64, error) {
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// 1.- nonNil normalizes nil slices so transports always serialize as an array.
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
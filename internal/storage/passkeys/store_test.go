@@ -0,0 +1,75 @@
+package passkeys
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/storage"
+	"github.com/example/Yamato-Go-Gin-API/internal/testutil"
+)
+
+// 1.- TestStoreLifecycle exercises create, duplicate detection, sign-count updates, and deletion against Postgres.
+func TestStoreLifecycle(t *testing.T) {
+	container := testutil.RunPostgresContainer(t)
+	if container == nil {
+		t.Skip("postgres container unavailable")
+		return
+	}
+
+	db, err := sql.Open("postgres", container.DSN)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	migrator, err := storage.NewMigrator(db)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply(ctx))
+
+	// 2.- Seed the owning user.
+	var userID int64
+	require.NoError(t, db.QueryRowContext(ctx, `
+INSERT INTO users (email, password_hash, first_name, last_name)
+VALUES ('passkey@example.com', 'hash', 'Passkey', 'Owner')
+RETURNING id`).Scan(&userID))
+	owner := strconv.FormatInt(userID, 10)
+
+	store, err := NewStore(db)
+	require.NoError(t, err)
+
+	// 3.- Create a passkey and find it by credential id; duplicates are rejected.
+	passkey := authhttp.Passkey{UserID: owner, Name: "Laptop", CredentialID: []byte{1, 2, 3}, PublicKey: []byte{4, 5, 6}, SignCount: 2, Transports: []string{"internal"}}
+	created, err := store.Create(ctx, passkey)
+	require.NoError(t, err)
+	_, err = store.Create(ctx, passkey)
+	require.ErrorIs(t, err, authhttp.ErrPasskeyExists)
+	found, err := store.FindByCredentialID(ctx, []byte{1, 2, 3})
+	require.NoError(t, err)
+	require.Equal(t, created.ID, found.ID)
+	require.Equal(t, uint32(2), found.SignCount)
+	require.Equal(t, []string{"internal"}, found.Transports)
+	require.True(t, found.LastUsedAt.IsZero())
+
+	// 4.- Advance the counter and list.
+	require.NoError(t, store.UpdateSignCount(ctx, created.ID, 9, time.Now()))
+	found, err = store.FindByCredentialID(ctx, []byte{1, 2, 3})
+	require.NoError(t, err)
+	require.Equal(t, uint32(9), found.SignCount)
+	require.False(t, found.LastUsedAt.IsZero())
+	listed, err := store.ListByUser(ctx, owner)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+
+	// 5.- Other users cannot delete the passkey; the owner can, once.
+	require.ErrorIs(t, store.Delete(ctx, strconv.FormatInt(userID+1, 10), created.ID), authhttp.ErrPasskeyNotFound)
+	require.NoError(t, store.Delete(ctx, owner, created.ID))
+	require.ErrorIs(t, store.Delete(ctx, owner, created.ID), authhttp.ErrPasskeyNotFound)
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/example/Yamato-Go-Gin-API/internal/phone"
+)
+
+// phoneColumns lists every column keyed by phone number; rows written before numbers were
+// normalized may still hold the raw input, such as "+52 1 55 5000 0000" or "0052...".
+var phoneColumns = []struct {
+	table  string
+	column string
+	// unique limits the rewrite to rows whose canonical form is not already held by another live row.
+	unique bool
+}{
+	{table: "phone_verifications", column: "phone"},
+	{table: "device_tokens", column: "phone"},
+	{table: "users", column: "phone", unique: true},
+}
+
+// normalizePhones rewrites stored phone numbers to the E.164 keys used by the handlers.
+// The rules live in Go, so this runs after the SQL bundles; numbers that do not parse are left untouched.
+func normalizePhones(ctx context.Context, db *sql.DB) error {
+	for _, target := range phoneColumns {
+		//1.- Collect the distinct stored values first so each rewrite is a single keyed UPDATE.
+		values, err := distinctPhones(ctx, db, target.table, target.column)
+		if err != nil {
+			return err
+		}
+
+		//2.- Rewrite every value whose canonical form differs; the table and column names are fixed above.
+		update := fmt.Sprintf(`UPDATE %[1]s SET %[2]s = $2 WHERE %[2]s = $1`, target.table, target.column)
+		if target.unique {
+			update += fmt.Sprintf(` AND deleted_at IS NULL AND NOT EXISTS (SELECT 1 FROM %[1]s other WHERE other.%[2]s = $2 AND other.deleted_at IS NULL)`, target.table, target.column)
+		}
+		for _, value := range values {
+			normalized, err := phone.Normalize(value)
+			if err != nil || normalized == value {
+				continue
+			}
+			if _, err := db.ExecContext(ctx, update, value, normalized); err != nil {
+				return fmt.Errorf("failed to normalize %s.%s: %w", target.table, target.column, err)
+			}
+		}
+	}
+	return nil
+}
+
+// distinctPhones returns the non-empty values stored in the phone column.
+func distinctPhones(ctx context.Context, db *sql.DB, table string, column string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(`SELECT DISTINCT %[2]s FROM %[1]s WHERE %[2]s IS NOT NULL AND %[2]s <> ''`, table, column))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s.%s: %w", table, column, err)
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, fmt.Errorf("failed to scan %s.%s: %w", table, column, err)
+		}
+		values = append(values, value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate %s.%s: %w", table, column, err)
+	}
+	return values, nil
+}
@@ -0,0 +1,435 @@
+package privacy
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+
+	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+	privacyhttp "github.com/example/Yamato-Go-Gin-API/internal/http/privacy"
+)
+
+// 1.- Store implements privacyhttp.ExportStore, DataSource, and AccountStore on Postgres.
+type Store struct {
+	db *sql.DB
+}
+
+// 1.- NewStore validates the database handle and prepares the store.
+func NewStore(db *sql.DB) (*Store, error) {
+	if db == nil {
+		return nil, errors.New("privacy store requires a database connection")
+	}
+	return &Store{db: db}, nil
+}
+
+// 1.- exportColumns lists the selected columns in scan order.
+const exportColumns = `id, user_id, status, file_path, error, created_at, completed_at`
+
+// 1.- personalTables hold rows keyed by user_id that must go when an account is anonymised instead of deleted.
+var personalTables = []string{
+	"notifications",
+	"one_time_tokens",
+	"user_mfa",
+	"mfa_recovery_codes",
+	"personal_access_tokens",
+	"webauthn_credentials",
+	"data_exports",
+	"user_roles",
+	"team_members",
+}
+
+// 1.- Create records a pending export for the user.
+func (s *Store) Create(ctx context.Context, userID string) (privacyhttp.Export, error) {
+	id, err := parseID(userID)
+	if err != nil {
+		return privacyhttp.Export{}, authhttp.ErrUserNotFound
+	}
+	row := s.db.QueryRowContext(ctx, `
+INSERT INTO data_exports (user_id, status)
+VALUES ($1, $2)
+RETURNING `+exportColumns, id, privacyhttp.ExportPending)
+	export, err := scanExport(row)
+	if err != nil {
+		return privacyhttp.Export{}, fmt.Errorf("create export: %w", err)
+	}
+	return export, nil
+}
+
+// 1.- Find loads an export by identifier.
+func (s *Store) Find(ctx context.Context, id string) (privacyhttp.Export, error) {
+	exportID, err := parseID(id)
+	if err != nil {
+		return privacyhttp.Export{}, privacyhttp.ErrExportNotFound
+	}
+	export, err := scanExport(s.db.QueryRowContext(ctx, `SELECT `+exportColumns+` FROM data_exports WHERE id = $1`, exportID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return privacyhttp.Export{}, privacyhttp.ErrExportNotFound
+		}
+		return privacyhttp.Export{}, fmt.Errorf("find export: %w", err)
+	}
+	return export, nil
+}
+
+// 1.- Latest returns the user's most recent export.
+func (s *Store) Latest(ctx context.Context, userID string) (privacyhttp.Export, error) {
+	id, err := parseID(userID)
+	if err != nil {
+		return privacyhttp.Export{}, privacyhttp.ErrExportNotFound
+	}
+	export, err := scanExport(s.db.QueryRowContext(ctx, `SELECT `+exportColumns+` FROM data_exports WHERE user_id = $1 ORDER BY created_at DESC, id DESC LIMIT 1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return privacyhttp.Export{}, privacyhttp.ErrExportNotFound
+		}
+		return privacyhttp.Export{}, fmt.Errorf("latest export: %w", err)
+	}
+	return export, nil
+}
+
+// 1.- Complete stores the archive location and marks the export completed.
+func (s *Store) Complete(ctx context.Context, id string, filePath string, completedAt time.Time) error {
+	return s.finish(ctx, id, `UPDATE data_exports SET status = $2, file_path = $3, error = NULL, completed_at = $4 WHERE id = $1`, privacyhttp.ExportCompleted, filePath, completedAt)
+}
+
+// 1.- Fail records why the export could not be built.
+func (s *Store) Fail(ctx context.Context, id string, reason string) error {
+	return s.finish(ctx, id, `UPDATE data_exports SET status = $2, error = $3 WHERE id = $1`, privacyhttp.ExportFailed, reason)
+}
+
+// 1.- finish runs a status update and reports missing rows.
+func (s *Store) finish(ctx context.Context, id string, query string, args ...interface{}) error {
+	exportID, err := parseID(id)
+	if err != nil {
+		return privacyhttp.ErrExportNotFound
+	}
+	result, err := s.db.ExecContext(ctx, query, append([]interface{}{exportID}, args...)...)
+	if err != nil {
+		return fmt.Errorf("update export: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("update export: %w", err)
+	}
+	if affected == 0 {
+		return privacyhttp.ErrExportNotFound
+	}
+	return nil
+}
+
+// 1.- CollectUserData reads every section of the export for the user.
+func (s *Store) CollectUserData(ctx context.Context, userID string) (privacyhttp.UserData, error) {
+	id, err := parseID(userID)
+	if err != nil {
+		return privacyhttp.UserData{}, authhttp.ErrUserNotFound
+	}
+
+	//1.- Profile first; the phone and e-mail it returns key the remaining lookups.
+	var (
+		data        privacyhttp.UserData
+		dbID        int64
+		phone       sql.NullString
+		lastLoginAt sql.NullTime
+	)
+	err = s.db.QueryRowContext(ctx, `
+SELECT id, email, first_name, last_name, phone, status, last_login_at, created_at
+FROM users
+WHERE id = $1`, id).Scan(&dbID, &data.Profile.Email, &data.Profile.FirstName, &data.Profile.LastName, &phone, &data.Profile.Status, &lastLoginAt, &data.Profile.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return privacyhttp.UserData{}, authhttp.ErrUserNotFound
+		}
+		return privacyhttp.UserData{}, fmt.Errorf("collect profile: %w", err)
+	}
+	data.Profile.ID = strconv.FormatInt(dbID, 10)
+	data.Profile.Phone = phone.String
+	if lastLoginAt.Valid {
+		at := lastLoginAt.Time.UTC()
+		data.Profile.LastLoginAt = &at
+	}
+
+	//2.- Notifications addressed to the user that were not deleted.
+	if data.Notifications, err = collect(ctx, s.db, `
+SELECT id, title, message, read_at, created_at
+FROM notifications
+WHERE user_id = $1 AND deleted_at IS NULL
+ORDER BY created_at, id`, func(rows *sql.Rows) (privacyhttp.NotificationRecord, error) {
+		var (
+			record privacyhttp.NotificationRecord
+			rowID  int64
+			readAt sql.NullTime
+		)
+		if err := rows.Scan(&rowID, &record.Title, &record.Message, &readAt, &record.CreatedAt); err != nil {
+			return record, err
+		}
+		record.ID = strconv.FormatInt(rowID, 10)
+		if readAt.Valid {
+			at := readAt.Time.UTC()
+			record.ReadAt = &at
+		}
+		return record, nil
+	}, id); err != nil {
+		return privacyhttp.UserData{}, fmt.Errorf("collect notifications: %w", err)
+	}
+
+	//3.- Tasks reference their assignee by id or e-mail.
+	if data.Tasks, err = collect(ctx, s.db, `
+SELECT id, title, status, priority, assignee, due_date
+FROM tasks
+WHERE assignee = $1 OR assignee = $2
+ORDER BY due_date, id`, func(rows *sql.Rows) (privacyhttp.TaskRecord, error) {
+		var record privacyhttp.TaskRecord
+		err := rows.Scan(&record.ID, &record.Title, &record.Status, &record.Priority, &record.Assignee, &record.DueDate)
+		return record, err
+	}, data.Profile.ID, data.Profile.Email); err != nil {
+		return privacyhttp.UserData{}, fmt.Errorf("collect tasks: %w", err)
+	}
+
+	//4.- Join requests the user filed.
+	if data.JoinRequests, err = collect(ctx, s.db, `
+SELECT id, team_id, status, payload, created_at
+FROM join_requests
+WHERE requester_id = $1
+ORDER BY created_at, id`, func(rows *sql.Rows) (privacyhttp.JoinRequestRecord, error) {
+		var (
+			record  privacyhttp.JoinRequestRecord
+			rowID   int64
+			teamID  int64
+			payload []byte
+		)
+		if err := rows.Scan(&rowID, &teamID, &record.Status, &payload, &record.CreatedAt); err != nil {
+			return record, err
+		}
+		record.ID = strconv.FormatInt(rowID, 10)
+		record.TeamID = strconv.FormatInt(teamID, 10)
+		record.Payload = json.RawMessage(payload)
+		return record, nil
+	}, id); err != nil {
+		return privacyhttp.UserData{}, fmt.Errorf("collect join requests: %w", err)
+	}
+
+	//5.- Phone verifications for the linked phone, deliberately without the code column.
+	if data.Profile.Phone != "" {
+		if data.PhoneVerifications, err = collect(ctx, s.db, `
+SELECT id, phone, name, status, expires_at, created_at
+FROM phone_verifications
+WHERE phone = $1
+ORDER BY created_at, id`, func(rows *sql.Rows) (privacyhttp.PhoneVerificationRecord, error) {
+			var (
+				record privacyhttp.PhoneVerificationRecord
+				rowID  int64
+			)
+			if err := rows.Scan(&rowID, &record.Phone, &record.Name, &record.Status, &record.ExpiresAt, &record.CreatedAt); err != nil {
+				return record, err
+			}
+			record.ID = strconv.FormatInt(rowID, 10)
+			return record, nil
+		}, data.Profile.Phone); err != nil {
+			return privacyhttp.UserData{}, fmt.Errorf("collect phone verifications: %w", err)
+		}
+	}
+	return data, nil
+}
+
+// 1.- SoftDelete stamps deleted_at and drops personal access tokens, which the session revocation does not reach.
+func (s *Store) SoftDelete(ctx context.Context, userID string, deletedAt time.Time) error {
+	id, err := parseID(userID)
+	if err != nil {
+		return authhttp.ErrUserNotFound
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("soft delete user: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE users SET deleted_at = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`, id, deletedAt)
+	if err != nil {
+		return fmt.Errorf("soft delete user: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("soft delete user: %w", err)
+	}
+	if affected == 0 {
+		return authhttp.ErrUserNotFound
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM personal_access_tokens WHERE user_id = $1`, id); err != nil {
+		return fmt.Errorf("soft delete user tokens: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("soft delete user: %w", err)
+	}
+	return nil
+}
+
+// 1.- PurgeDeleted removes accounts soft-deleted before the cutoff together with data not covered by foreign keys.
+// Accounts referenced by the impersonation audit trail cannot be deleted, so they are anonymised and stripped instead.
+func (s *Store) PurgeDeleted(ctx context.Context, before time.Time) (privacyhttp.PurgeResult, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return privacyhttp.PurgeResult{}, fmt.Errorf("purge users: %w", err)
+	}
+	defer tx.Rollback()
+
+	//1.- Lock the accounts that are due.
+	rows, err := tx.QueryContext(ctx, `
+SELECT id, email, COALESCE(phone, '')
+FROM users
+WHERE deleted_at IS NOT NULL AND deleted_at < $1 AND status <> 'purged'
+FOR UPDATE`, before)
+	if err != nil {
+		return privacyhttp.PurgeResult{}, fmt.Errorf("purge users: %w", err)
+	}
+	var (
+		ids         []int64
+		assignees   []string
+		phones      []string
+		exportFiles []string
+	)
+	for rows.Next() {
+		var (
+			id    int64
+			email string
+			phone string
+		)
+		if err := rows.Scan(&id, &email, &phone); err != nil {
+			rows.Close()
+			return privacyhttp.PurgeResult{}, fmt.Errorf("purge users: %w", err)
+		}
+		ids = append(ids, id)
+		assignees = append(assignees, strconv.FormatInt(id, 10), email)
+		if phone != "" {
+			phones = append(phones, phone)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return privacyhttp.PurgeResult{}, fmt.Errorf("purge users: %w", err)
+	}
+	if len(ids) == 0 {
+		return privacyhttp.PurgeResult{ExportFiles: []string{}}, nil
+	}
+
+	//2.- Remember stored archives so the caller can remove the files after commit.
+	if exportFiles, err = collect(ctx, tx, `SELECT file_path FROM data_exports WHERE user_id = ANY($1) AND file_path IS NOT NULL`, func(rows *sql.Rows) (string, error) {
+		var path string
+		err := rows.Scan(&path)
+		return path, err
+	}, pq.Array(ids)); err != nil {
+		return privacyhttp.PurgeResult{}, fmt.Errorf("purge export files: %w", err)
+	}
+
+	//3.- Phone-keyed rows and task assignments have no foreign key to users.
+	for _, statement := range []struct {
+		query string
+		arg   interface{}
+	}{
+		{`DELETE FROM phone_verifications WHERE phone = ANY($1)`, pq.Array(phones)},
+		{`DELETE FROM device_tokens WHERE phone = ANY($1)`, pq.Array(phones)},
+		{`UPDATE tasks SET assignee = '', updated_at = NOW() WHERE assignee = ANY($1)`, pq.Array(assignees)},
+	} {
+		if _, err := tx.ExecContext(ctx, statement.query, statement.arg); err != nil {
+			return privacyhttp.PurgeResult{}, fmt.Errorf("purge related rows: %w", err)
+		}
+	}
+
+	//4.- Anonymise audited accounts and strip their personal rows.
+	const audited = `EXISTS (SELECT 1 FROM impersonation_audit_logs l WHERE l.actor_id = users.id OR l.subject_id = users.id)`
+	for _, table := range append(append([]string{}, personalTables...), "join_requests") {
+		column := "user_id"
+		if table == "join_requests" {
+			column = "requester_id"
+		}
+		query := fmt.Sprintf(`DELETE FROM %s WHERE %s IN (SELECT id FROM users WHERE id = ANY($1) AND %s)`, table, column, audited)
+		if _, err := tx.ExecContext(ctx, query, pq.Array(ids)); err != nil {
+			return privacyhttp.PurgeResult{}, fmt.Errorf("purge %s: %w", table, err)
+		}
+	}
+	anonymised, err := tx.ExecContext(ctx, `
+UPDATE users
+SET email = 'deleted-' || id || '@invalid', password_hash = '', first_name = '', last_name = '', phone = NULL, status = 'purged', updated_at = NOW()
+WHERE id = ANY($1) AND `+audited, pq.Array(ids))
+	if err != nil {
+		return privacyhttp.PurgeResult{}, fmt.Errorf("anonymise users: %w", err)
+	}
+
+	//5.- Delete everything else; foreign keys cascade to the remaining per-user tables.
+	deleted, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = ANY($1) AND status <> 'purged'`, pq.Array(ids))
+	if err != nil {
+		return privacyhttp.PurgeResult{}, fmt.Errorf("purge users: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return privacyhttp.PurgeResult{}, fmt.Errorf("purge users: %w", err)
+	}
+
+	anonymisedCount, _ := anonymised.RowsAffected()
+	deletedCount, _ := deleted.RowsAffected()
+	return privacyhttp.PurgeResult{Users: int(anonymisedCount + deletedCount), ExportFiles: exportFiles}, nil
+}
+
+// 1.- rowScanner abstracts *sql.Row for scanning helpers.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// 1.- querier is satisfied by both *sql.DB and *sql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// 1.- scanExport converts a data_exports row into the handler model.
+func scanExport(row rowScanner) (privacyhttp.Export, error) {
+	var (
+		export      privacyhttp.Export
+		id          int64
+		userID      int64
+		filePath    sql.NullString
+		reason      sql.NullString
+		completedAt sql.NullTime
+	)
+	if err := row.Scan(&id, &userID, &export.Status, &filePath, &reason, &export.CreatedAt, &completedAt); err != nil {
+		return privacyhttp.Export{}, err
+	}
+	export.ID = strconv.FormatInt(id, 10)
+	export.UserID = strconv.FormatInt(userID, 10)
+	export.FilePath = filePath.String
+	export.Error = reason.String
+	export.CreatedAt = export.CreatedAt.UTC()
+	if completedAt.Valid {
+		at := completedAt.Time.UTC()
+		export.CompletedAt = &at
+	}
+	return export, nil
+}
+
+// 1.- collect runs a query and maps every row, always returning a non-nil slice.
+func collect[T any](ctx context.Context, db querier, query string, scan func(*sql.Rows) (T, error), args ...interface{}) ([]T, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []T{}
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// 1.- parseID converts the string identifiers used by handlers into BIGINT keys.
+func parseID(value string) (int64, error) {
+	return strconv.ParseInt(value, 10, 64)
+}
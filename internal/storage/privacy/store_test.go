@@ -0,0 +1,116 @@
+package privacy
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+	privacyhttp "github.com/example/Yamato-Go-Gin-API/internal/http/privacy"
+	"github.com/example/Yamato-Go-Gin-API/internal/storage"
+	"github.com/example/Yamato-Go-Gin-API/internal/storage/devicetokens"
+	"github.com/example/Yamato-Go-Gin-API/internal/storage/users"
+	"github.com/example/Yamato-Go-Gin-API/internal/testutil"
+)
+
+// 1.- TestStoreExportAndPurge exercises export bookkeeping, data collection, soft deletion, and purging against Postgres.
+func TestStoreExportAndPurge(t *testing.T) {
+	container := testutil.RunPostgresContainer(t)
+	if container == nil {
+		t.Skip("postgres container unavailable")
+		return
+	}
+
+	db, err := sql.Open("postgres", container.DSN)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	migrator, err := storage.NewMigrator(db)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply(ctx))
+
+	// 2.- Seed a user with a notification, a task, and a verified phone linked through its device token.
+	var userID int64
+	require.NoError(t, db.QueryRowContext(ctx, `
+INSERT INTO users (email, password_hash, first_name, last_name)
+VALUES ('privacy@example.com', 'hash', 'Privacy', 'Owner')
+RETURNING id`).Scan(&userID))
+	owner := strconv.FormatInt(userID, 10)
+	devices, err := devicetokens.NewStore(db)
+	require.NoError(t, err)
+	_, err = devices.Create(ctx, "+525550000000", "device-secret")
+	require.NoError(t, err)
+	accounts := users.NewStore(db)
+	require.NoError(t, accounts.LinkPhone(ctx, owner, "+525550000000"))
+	_, err = db.ExecContext(ctx, `INSERT INTO notifications (user_id, title, message) VALUES ($1, 'Hello', 'World')`, userID)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `INSERT INTO tasks (id, title, status, priority, assignee, due_date) VALUES ('t-1', 'Review', 'open', 'high', 'privacy@example.com', NOW())`)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `INSERT INTO phone_verifications (phone, code_hash, status, name, expires_at) VALUES ('+525550000000', 'digest', 'verified', 'Privacy', NOW())`)
+	require.NoError(t, err)
+
+	store, err := NewStore(db)
+	require.NoError(t, err)
+
+	// 3.- Exports move from pending to completed.
+	export, err := store.Create(ctx, owner)
+	require.NoError(t, err)
+	require.Equal(t, privacyhttp.ExportPending, export.Status)
+	require.NoError(t, store.Complete(ctx, export.ID, "2024/01/01/export.zip", time.Now()))
+	latest, err := store.Latest(ctx, owner)
+	require.NoError(t, err)
+	require.Equal(t, privacyhttp.ExportCompleted, latest.Status)
+	require.Equal(t, "2024/01/01/export.zip", latest.FilePath)
+	require.NotNil(t, latest.CompletedAt)
+
+	// 4.- Collected data covers every section.
+	data, err := store.CollectUserData(ctx, owner)
+	require.NoError(t, err)
+	require.Equal(t, "privacy@example.com", data.Profile.Email)
+	require.Len(t, data.Notifications, 1)
+	require.Len(t, data.Tasks, 1)
+	require.Equal(t, "+525550000000", data.Profile.Phone)
+	require.Len(t, data.PhoneVerifications, 1)
+	require.Empty(t, data.JoinRequests)
+
+	// 4.1.- Another live account cannot claim the same phone.
+	var otherID int64
+	require.NoError(t, db.QueryRowContext(ctx, `
+INSERT INTO users (email, password_hash, first_name, last_name)
+VALUES ('other@example.com', 'hash', 'Other', 'Owner')
+RETURNING id`).Scan(&otherID))
+	require.ErrorIs(t, accounts.LinkPhone(ctx, strconv.FormatInt(otherID, 10), "+525550000000"), authhttp.ErrPhoneTaken)
+
+	// 5.- Soft deletion hides the user; a second call reports it missing.
+	deletedAt := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, store.SoftDelete(ctx, owner, deletedAt))
+	require.ErrorIs(t, store.SoftDelete(ctx, owner, deletedAt), authhttp.ErrUserNotFound)
+
+	// 6.- Purging before the cutoff leaves the account; after it removes the account and unlinked rows.
+	result, err := store.PurgeDeleted(ctx, deletedAt.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, result.Users)
+	result, err = store.PurgeDeleted(ctx, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Users)
+	require.Equal(t, []string{"2024/01/01/export.zip"}, result.ExportFiles)
+
+	var remaining int
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE id = $1`, userID).Scan(&remaining))
+	require.Zero(t, remaining)
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM phone_verifications WHERE phone = '+525550000000'`).Scan(&remaining))
+	require.Zero(t, remaining)
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM device_tokens WHERE phone = '+525550000000'`).Scan(&remaining))
+	require.Zero(t, remaining)
+	var assignee string
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT assignee FROM tasks WHERE id = 't-1'`).Scan(&assignee))
+	require.Empty(t, assignee)
+}
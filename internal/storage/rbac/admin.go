@@ -0,0 +1,197 @@
+package rbac
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+
+	adminhttp "github.com/example/Yamato-Go-Gin-API/internal/http/admin"
+)
+
+// 1.- uniqueViolation is the Postgres error code raised by UNIQUE constraints.
+const uniqueViolation = "23505"
+
+// 1.- parseID converts a path identifier into a row id; malformed ids can never match a row.
+func parseID(id string) (int64, error) {
+	value, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64)
+	if err != nil || value <= 0 {
+		return 0, adminhttp.ErrNotFound
+	}
+	return value, nil
+}
+
+// 1.- mapWriteError turns unique violations into adminhttp.ErrConflict and wraps everything else.
+func mapWriteError(err error, action string) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return adminhttp.ErrConflict
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
+
+// 1.- offset converts 1-based pagination into a SQL offset.
+func offset(pagination adminhttp.Pagination) int {
+	return (pagination.Page - 1) * pagination.PerPage
+}
+
+// 1.- resolveNames maps live names from table to ids, failing with adminhttp.ErrUnknownReference on any miss.
+func resolveNames(ctx context.Context, tx *sql.Tx, table string, names []string) ([]int64, error) {
+	if len(names) == 0 {
+		return []int64{}, nil
+	}
+
+	//2.- table is one of the fixed RBAC tables, never caller input.
+	query := fmt.Sprintf(`SELECT id, name FROM %s WHERE name = ANY($1) AND deleted_at IS NULL`, table)
+	rows, err := tx.QueryContext(ctx, query, pq.Array(names))
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	found := make(map[string]int64, len(names))
+	for rows.Next() {
+		var (
+			id   int64
+			name string
+		)
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, fmt.Errorf("scan %s: %w", table, err)
+		}
+		found[name] = id
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate %s: %w", table, err)
+	}
+
+	//3.- Keep the payload order and report every unknown name at once.
+	ids := make([]int64, 0, len(names))
+	var missing []string
+	for _, name := range names {
+		id, ok := found[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s %s", adminhttp.ErrUnknownReference, table, strings.Join(missing, ", "))
+	}
+	return ids, nil
+}
+
+// 1.- withTx runs fn in a transaction, committing only when it succeeds.
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// 1.- requireRow converts an update that matched nothing into adminhttp.ErrNotFound.
+func requireRow(result sql.Result, action string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", action, err)
+	}
+	if affected == 0 {
+		return adminhttp.ErrNotFound
+	}
+	return nil
+}
+
+// 1.- insertName creates a named row, reviving a soft-deleted row of the same name; a live duplicate is a conflict.
+func insertName(ctx context.Context, tx *sql.Tx, table string, name string) (int64, error) {
+	query := fmt.Sprintf(`
+INSERT INTO %[1]s (name)
+VALUES ($1)
+ON CONFLICT (name) DO UPDATE
+SET deleted_at = NULL, created_at = NOW(), updated_at = NOW()
+WHERE %[1]s.deleted_at IS NOT NULL
+RETURNING id`, table)
+
+	var id int64
+	err := tx.QueryRowContext(ctx, query, name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, adminhttp.ErrConflict
+	}
+	if err != nil {
+		return 0, mapWriteError(err, "insert "+table)
+	}
+	return id, nil
+}
+
+// 1.- renameRow updates the name of a live row, reporting unknown ids and taken names.
+func renameRow(ctx context.Context, tx *sql.Tx, table string, id int64, name string) error {
+	query := fmt.Sprintf(`UPDATE %s SET name = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`, table)
+	result, err := tx.ExecContext(ctx, query, id, name)
+	if err != nil {
+		return mapWriteError(err, "update "+table)
+	}
+	return requireRow(result, "update "+table)
+}
+
+// 1.- softDelete stamps deleted_at on a live row and drops its links so a revived name starts without grants.
+func softDelete(ctx context.Context, tx *sql.Tx, table string, id int64, links ...[2]string) error {
+	result, err := tx.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET deleted_at = NOW(), updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`, table), id)
+	if err != nil {
+		return fmt.Errorf("delete %s: %w", table, err)
+	}
+	if err := requireRow(result, "delete "+table); err != nil {
+		return err
+	}
+	for _, link := range links {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE %s = $1`, link[0], link[1]), id); err != nil {
+			return fmt.Errorf("unlink %s: %w", link[0], err)
+		}
+	}
+	return nil
+}
+
+// 1.- replaceLinks makes the owner's rows in a join table match ids exactly, keeping rows that already exist.
+func replaceLinks(ctx context.Context, tx *sql.Tx, table string, ownerColumn string, ownerID int64, refColumn string, ids []int64) error {
+	//2.- An empty array, not NULL, so "= ANY" is false and every old link is removed.
+	if ids == nil {
+		ids = []int64{}
+	}
+	remove := fmt.Sprintf(`DELETE FROM %s WHERE %s = $1 AND NOT (%s = ANY($2::BIGINT[]))`, table, ownerColumn, refColumn)
+	if _, err := tx.ExecContext(ctx, remove, ownerID, pq.Array(ids)); err != nil {
+		return fmt.Errorf("replace %s: %w", table, err)
+	}
+	insert := fmt.Sprintf(`
+INSERT INTO %s (%s, %s)
+SELECT $1::BIGINT, ref FROM UNNEST($2::BIGINT[]) AS ref
+ON CONFLICT DO NOTHING`, table, ownerColumn, refColumn)
+	if _, err := tx.ExecContext(ctx, insert, ownerID, pq.Array(ids)); err != nil {
+		return fmt.Errorf("replace %s: %w", table, err)
+	}
+	return nil
+}
+
+// 1.- countLive returns the number of rows in table that are not soft-deleted.
+func countLive(ctx context.Context, db *sql.DB, table string) (int, error) {
+	var total int
+	if err := db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE deleted_at IS NULL`, table)).Scan(&total); err != nil {
+		return 0, fmt.Errorf("count %s: %w", table, err)
+	}
+	return total, nil
+}
+
+// 1.- nameList copies a scanned array into a non-nil slice so JSON renders [] rather than null.
+func nameList(values pq.StringArray) []string {
+	return append([]string{}, values...)
+}
@@ -0,0 +1,105 @@
+package rbac
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	adminhttp "github.com/example/Yamato-Go-Gin-API/internal/http/admin"
+)
+
+// 1.- PermissionStore implements adminhttp.PermissionService on the permissions table.
+type PermissionStore struct {
+	db *sql.DB
+}
+
+// 1.- NewPermissionStore validates the database handle and prepares the store.
+func NewPermissionStore(db *sql.DB) (*PermissionStore, error) {
+	if db == nil {
+		return nil, errors.New("rbac permission store requires a database connection")
+	}
+	return &PermissionStore{db: db}, nil
+}
+
+// 1.- Create inserts the permission, reviving a soft-deleted one with the same name.
+func (s *PermissionStore) Create(ctx context.Context, payload adminhttp.Permission) (adminhttp.Permission, error) {
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		id, err := insertName(ctx, tx, "permissions", payload.Name)
+		if err != nil {
+			return err
+		}
+		payload.ID = strconv.FormatInt(id, 10)
+		return nil
+	})
+	if err != nil {
+		return adminhttp.Permission{}, err
+	}
+	return payload, nil
+}
+
+// 1.- Update renames a live permission.
+func (s *PermissionStore) Update(ctx context.Context, id string, payload adminhttp.Permission) (adminhttp.Permission, error) {
+	permissionID, err := parseID(id)
+	if err != nil {
+		return adminhttp.Permission{}, err
+	}
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		return renameRow(ctx, tx, "permissions", permissionID, payload.Name)
+	})
+	if err != nil {
+		return adminhttp.Permission{}, err
+	}
+	payload.ID = strconv.FormatInt(permissionID, 10)
+	return payload, nil
+}
+
+// 1.- Delete soft-deletes the permission and revokes it from every role.
+func (s *PermissionStore) Delete(ctx context.Context, id string) error {
+	permissionID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		return softDelete(ctx, tx, "permissions", permissionID, [2]string{"role_permissions", "permission_id"})
+	})
+}
+
+// 1.- List pages through live permissions ordered by id.
+func (s *PermissionStore) List(ctx context.Context, pagination adminhttp.Pagination) ([]adminhttp.Permission, int, error) {
+	total, err := countLive(ctx, s.db, "permissions")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	const query = `
+SELECT id, name
+FROM permissions
+WHERE deleted_at IS NULL
+ORDER BY id
+LIMIT $1 OFFSET $2`
+
+	rows, err := s.db.QueryContext(ctx, query, pagination.PerPage, offset(pagination))
+	if err != nil {
+		return nil, 0, fmt.Errorf("list permissions: %w", err)
+	}
+	defer rows.Close()
+
+	permissions := make([]adminhttp.Permission, 0)
+	for rows.Next() {
+		var (
+			id         int64
+			permission adminhttp.Permission
+		)
+		if err := rows.Scan(&id, &permission.Name); err != nil {
+			return nil, 0, fmt.Errorf("scan permission: %w", err)
+		}
+		permission.ID = strconv.FormatInt(id, 10)
+		permissions = append(permissions, permission)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate permissions: %w", err)
+	}
+	return permissions, total, nil
+}
@@ -0,0 +1,126 @@
+package rbac
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/lib/pq"
+
+	adminhttp "github.com/example/Yamato-Go-Gin-API/internal/http/admin"
+)
+
+// 1.- RoleStore implements adminhttp.RoleService on the roles and role_permissions tables.
+type RoleStore struct {
+	db *sql.DB
+}
+
+// 1.- NewRoleStore validates the database handle and prepares the store.
+func NewRoleStore(db *sql.DB) (*RoleStore, error) {
+	if db == nil {
+		return nil, errors.New("rbac role store requires a database connection")
+	}
+	return &RoleStore{db: db}, nil
+}
+
+// 1.- Create inserts the role and grants its permissions in one transaction.
+func (s *RoleStore) Create(ctx context.Context, payload adminhttp.Role) (adminhttp.Role, error) {
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		id, err := insertName(ctx, tx, "roles", payload.Name)
+		if err != nil {
+			return err
+		}
+		payload.ID = strconv.FormatInt(id, 10)
+		return grantPermissions(ctx, tx, id, payload.Permissions)
+	})
+	if err != nil {
+		return adminhttp.Role{}, err
+	}
+	return payload, nil
+}
+
+// 1.- Update renames the role and replaces its permissions atomically.
+func (s *RoleStore) Update(ctx context.Context, id string, payload adminhttp.Role) (adminhttp.Role, error) {
+	roleID, err := parseID(id)
+	if err != nil {
+		return adminhttp.Role{}, err
+	}
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := renameRow(ctx, tx, "roles", roleID, payload.Name); err != nil {
+			return err
+		}
+		return grantPermissions(ctx, tx, roleID, payload.Permissions)
+	})
+	if err != nil {
+		return adminhttp.Role{}, err
+	}
+	payload.ID = strconv.FormatInt(roleID, 10)
+	return payload, nil
+}
+
+// 1.- Delete soft-deletes the role and removes it from every user.
+func (s *RoleStore) Delete(ctx context.Context, id string) error {
+	roleID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		return softDelete(ctx, tx, "roles", roleID, [2]string{"user_roles", "role_id"}, [2]string{"role_permissions", "role_id"})
+	})
+}
+
+// 1.- List pages through live roles ordered by id, with their live permission names.
+func (s *RoleStore) List(ctx context.Context, pagination adminhttp.Pagination) ([]adminhttp.Role, int, error) {
+	total, err := countLive(ctx, s.db, "roles")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	const query = `
+SELECT r.id, r.name,
+	ARRAY(
+		SELECT p.name FROM role_permissions rp
+		JOIN permissions p ON p.id = rp.permission_id AND p.deleted_at IS NULL
+		WHERE rp.role_id = r.id ORDER BY p.name
+	)
+FROM roles r
+WHERE r.deleted_at IS NULL
+ORDER BY r.id
+LIMIT $1 OFFSET $2`
+
+	rows, err := s.db.QueryContext(ctx, query, pagination.PerPage, offset(pagination))
+	if err != nil {
+		return nil, 0, fmt.Errorf("list roles: %w", err)
+	}
+	defer rows.Close()
+
+	roles := make([]adminhttp.Role, 0)
+	for rows.Next() {
+		var (
+			id          int64
+			role        adminhttp.Role
+			permissions pq.StringArray
+		)
+		if err := rows.Scan(&id, &role.Name, &permissions); err != nil {
+			return nil, 0, fmt.Errorf("scan role: %w", err)
+		}
+		role.ID = strconv.FormatInt(id, 10)
+		role.Permissions = nameList(permissions)
+		roles = append(roles, role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate roles: %w", err)
+	}
+	return roles, total, nil
+}
+
+// 1.- grantPermissions makes the role's permissions match names; unknown names abort the transaction.
+func grantPermissions(ctx context.Context, tx *sql.Tx, roleID int64, names []string) error {
+	permissionIDs, err := resolveNames(ctx, tx, "permissions", names)
+	if err != nil {
+		return err
+	}
+	return replaceLinks(ctx, tx, "role_permissions", "role_id", roleID, "permission_id", permissionIDs)
+}
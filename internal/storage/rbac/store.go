@@ -0,0 +1,77 @@
+package rbac
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+)
+
+// 1.- Store resolves role and permission grants from the user_roles, roles, role_permissions, and permissions tables.
+type Store struct {
+	db *sql.DB
+}
+
+// 1.- NewStore validates the database handle and prepares the store.
+func NewStore(db *sql.DB) (*Store, error) {
+	if db == nil {
+		return nil, errors.New("rbac store requires a database connection")
+	}
+	return &Store{db: db}, nil
+}
+
+// 1.- LoadAccess returns the distinct, sorted role names and permission slugs of the user; soft-deleted rows are ignored.
+func (s *Store) LoadAccess(ctx context.Context, subject string) (internalauth.Access, error) {
+	access := internalauth.Access{Roles: []string{}, Permissions: []string{}}
+	userID, err := strconv.ParseInt(subject, 10, 64)
+	if err != nil {
+		return access, nil
+	}
+
+	const query = `
+SELECT r.name, p.name
+FROM user_roles ur
+JOIN roles r ON r.id = ur.role_id AND r.deleted_at IS NULL
+LEFT JOIN role_permissions rp ON rp.role_id = r.id
+LEFT JOIN permissions p ON p.id = rp.permission_id AND p.deleted_at IS NULL
+WHERE ur.user_id = $1`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return internalauth.Access{}, fmt.Errorf("load user access: %w", err)
+	}
+	defer rows.Close()
+
+	roles := map[string]struct{}{}
+	permissions := map[string]struct{}{}
+	for rows.Next() {
+		var (
+			role       string
+			permission sql.NullString
+		)
+		if err := rows.Scan(&role, &permission); err != nil {
+			return internalauth.Access{}, fmt.Errorf("scan user access: %w", err)
+		}
+		roles[role] = struct{}{}
+		if permission.Valid {
+			permissions[permission.String] = struct{}{}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return internalauth.Access{}, fmt.Errorf("iterate user access: %w", err)
+	}
+
+	for role := range roles {
+		access.Roles = append(access.Roles, role)
+	}
+	for permission := range permissions {
+		access.Permissions = append(access.Permissions, permission)
+	}
+	sort.Strings(access.Roles)
+	sort.Strings(access.Permissions)
+	return access, nil
+}
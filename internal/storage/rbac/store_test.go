@@ -0,0 +1,170 @@
+package rbac
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	adminhttp "github.com/example/Yamato-Go-Gin-API/internal/http/admin"
+	"github.com/example/Yamato-Go-Gin-API/internal/storage"
+	"github.com/example/Yamato-Go-Gin-API/internal/testutil"
+)
+
+// 1.- TestStoreLoadAccess resolves grants through role assignments and ignores soft-deleted rows.
+func TestStoreLoadAccess(t *testing.T) {
+	container := testutil.RunPostgresContainer(t)
+	if container == nil {
+		t.Skip("postgres container unavailable")
+		return
+	}
+
+	db, err := sql.Open("postgres", container.DSN)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	migrator, err := storage.NewMigrator(db)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply(ctx))
+
+	// 2.- Seed a user holding two roles, one of which is soft-deleted, plus a role without permissions.
+	var userID, editorID, retiredID, emptyID, writeID, readID, deletedPermID int64
+	require.NoError(t, db.QueryRowContext(ctx, `
+INSERT INTO users (email, password_hash, first_name, last_name)
+VALUES ('rbac@example.com', 'hash', 'Role', 'Holder')
+RETURNING id`).Scan(&userID))
+	require.NoError(t, db.QueryRowContext(ctx, `INSERT INTO roles (name) VALUES ('editor') RETURNING id`).Scan(&editorID))
+	require.NoError(t, db.QueryRowContext(ctx, `INSERT INTO roles (name, deleted_at) VALUES ('retired', NOW()) RETURNING id`).Scan(&retiredID))
+	require.NoError(t, db.QueryRowContext(ctx, `INSERT INTO roles (name) VALUES ('auditor') RETURNING id`).Scan(&emptyID))
+	require.NoError(t, db.QueryRowContext(ctx, `INSERT INTO permissions (name) VALUES ('posts.write') RETURNING id`).Scan(&writeID))
+	require.NoError(t, db.QueryRowContext(ctx, `INSERT INTO permissions (name) VALUES ('posts.read') RETURNING id`).Scan(&readID))
+	require.NoError(t, db.QueryRowContext(ctx, `INSERT INTO permissions (name, deleted_at) VALUES ('posts.purge', NOW()) RETURNING id`).Scan(&deletedPermID))
+	for _, pair := range [][2]int64{{editorID, writeID}, {editorID, readID}, {editorID, deletedPermID}, {retiredID, readID}} {
+		_, err := db.ExecContext(ctx, `INSERT INTO role_permissions (role_id, permission_id) VALUES ($1, $2)`, pair[0], pair[1])
+		require.NoError(t, err)
+	}
+	for _, roleID := range []int64{editorID, retiredID, emptyID} {
+		_, err := db.ExecContext(ctx, `INSERT INTO user_roles (user_id, role_id) VALUES ($1, $2)`, userID, roleID)
+		require.NoError(t, err)
+	}
+
+	store, err := NewStore(db)
+	require.NoError(t, err)
+
+	// 3.- Only live roles and permissions are returned, sorted and de-duplicated.
+	access, err := store.LoadAccess(ctx, strconv.FormatInt(userID, 10))
+	require.NoError(t, err)
+	require.Equal(t, []string{"auditor", "editor"}, access.Roles)
+	require.Equal(t, []string{"posts.read", "posts.write"}, access.Permissions)
+
+	// 4.- Unknown and malformed subjects resolve to empty grants.
+	access, err = store.LoadAccess(ctx, "999999")
+	require.NoError(t, err)
+	require.Empty(t, access.Roles)
+	access, err = store.LoadAccess(ctx, "not-a-number")
+	require.NoError(t, err)
+	require.Empty(t, access.Permissions)
+}
+
+// 1.- TestAdminStoresAssignGrantsTransactionally drives the admin stores end to end and checks the grants LoadAccess sees.
+func TestAdminStoresAssignGrantsTransactionally(t *testing.T) {
+	container := testutil.RunPostgresContainer(t)
+	if container == nil {
+		t.Skip("postgres container unavailable")
+		return
+	}
+
+	db, err := sql.Open("postgres", container.DSN)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	migrator, err := storage.NewMigrator(db)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply(ctx))
+
+	users, err := NewUserStore(db)
+	require.NoError(t, err)
+	roles, err := NewRoleStore(db)
+	require.NoError(t, err)
+	permissions, err := NewPermissionStore(db)
+	require.NoError(t, err)
+	teams, err := NewTeamStore(db)
+	require.NoError(t, err)
+	store, err := NewStore(db)
+	require.NoError(t, err)
+
+	// 2.- Build a role from two permissions and assign it with a team.
+	write, err := permissions.Create(ctx, adminhttp.Permission{Name: "posts.write"})
+	require.NoError(t, err)
+	_, err = permissions.Create(ctx, adminhttp.Permission{Name: "posts.read"})
+	require.NoError(t, err)
+	editor, err := roles.Create(ctx, adminhttp.Role{Name: "editor", Permissions: []string{"posts.write", "posts.read"}})
+	require.NoError(t, err)
+	_, err = teams.Create(ctx, adminhttp.Team{Name: "core"})
+	require.NoError(t, err)
+	user, err := users.Create(ctx, adminhttp.User{Email: "admin-store@example.com", Roles: []string{"editor"}, Teams: []string{"core"}})
+	require.NoError(t, err)
+
+	access, err := store.LoadAccess(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"editor"}, access.Roles)
+	require.Equal(t, []string{"posts.read", "posts.write"}, access.Permissions)
+
+	// 3.- An unknown name rolls back the whole update, including the email change.
+	_, err = users.Update(ctx, user.ID, adminhttp.User{Email: "renamed@example.com", Roles: []string{"editor", "ghost"}})
+	require.ErrorIs(t, err, adminhttp.ErrUnknownReference)
+	listed, total, err := users.List(ctx, adminhttp.Pagination{Page: 1, PerPage: 10})
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Equal(t, "admin-store@example.com", listed[0].Email)
+	require.Equal(t, []string{"core"}, listed[0].Teams)
+
+	// 4.- Conflicts and unknown ids surface as sentinels.
+	_, err = roles.Create(ctx, adminhttp.Role{Name: "editor"})
+	require.ErrorIs(t, err, adminhttp.ErrConflict)
+	_, err = teams.Update(ctx, "999999", adminhttp.Team{Name: "ghost"})
+	require.ErrorIs(t, err, adminhttp.ErrNotFound)
+	require.ErrorIs(t, roles.Delete(ctx, "not-a-number"), adminhttp.ErrNotFound)
+
+	// 5.- Deleting a permission revokes it; recreating the name does not bring the grant back.
+	require.NoError(t, permissions.Delete(ctx, write.ID))
+	_, err = permissions.Create(ctx, adminhttp.Permission{Name: "posts.write"})
+	require.NoError(t, err)
+	access, err = store.LoadAccess(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"posts.read"}, access.Permissions)
+
+	// 6.- Replacing assignments with empty lists clears them.
+	_, err = users.Update(ctx, user.ID, adminhttp.User{Email: "admin-store@example.com", Roles: []string{}, Teams: []string{}})
+	require.NoError(t, err)
+	access, err = store.LoadAccess(ctx, user.ID)
+	require.NoError(t, err)
+	require.Empty(t, access.Roles)
+
+	// 7.- Soft-deleted roles disappear from listings.
+	require.NoError(t, roles.Delete(ctx, editor.ID))
+	listedRoles, total, err := roles.List(ctx, adminhttp.Pagination{Page: 1, PerPage: 10})
+	require.NoError(t, err)
+	require.Zero(t, total)
+	require.Empty(t, listedRoles)
+
+	// 8.- Deleting a user drops its personal access tokens with the account.
+	_, err = db.ExecContext(ctx, `INSERT INTO personal_access_tokens (user_id, name, prefix, token_hash) VALUES ($1, 'ci', 'admindel', $2)`, user.ID, strings.Repeat("a", 64))
+	require.NoError(t, err)
+	require.NoError(t, users.Delete(ctx, user.ID))
+	var tokens int
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM personal_access_tokens WHERE user_id = $1`, user.ID).Scan(&tokens))
+	require.Zero(t, tokens)
+	require.ErrorIs(t, users.Delete(ctx, user.ID), adminhttp.ErrNotFound)
+}
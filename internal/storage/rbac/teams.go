@@ -0,0 +1,105 @@
+package rbac
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	adminhttp "github.com/example/Yamato-Go-Gin-API/internal/http/admin"
+)
+
+// 1.- TeamStore implements adminhttp.TeamService on the teams table.
+type TeamStore struct {
+	db *sql.DB
+}
+
+// 1.- NewTeamStore validates the database handle and prepares the store.
+func NewTeamStore(db *sql.DB) (*TeamStore, error) {
+	if db == nil {
+		return nil, errors.New("rbac team store requires a database connection")
+	}
+	return &TeamStore{db: db}, nil
+}
+
+// 1.- Create inserts the team, reviving a soft-deleted one with the same name.
+func (s *TeamStore) Create(ctx context.Context, payload adminhttp.Team) (adminhttp.Team, error) {
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		id, err := insertName(ctx, tx, "teams", payload.Name)
+		if err != nil {
+			return err
+		}
+		payload.ID = strconv.FormatInt(id, 10)
+		return nil
+	})
+	if err != nil {
+		return adminhttp.Team{}, err
+	}
+	return payload, nil
+}
+
+// 1.- Update renames a live team.
+func (s *TeamStore) Update(ctx context.Context, id string, payload adminhttp.Team) (adminhttp.Team, error) {
+	teamID, err := parseID(id)
+	if err != nil {
+		return adminhttp.Team{}, err
+	}
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		return renameRow(ctx, tx, "teams", teamID, payload.Name)
+	})
+	if err != nil {
+		return adminhttp.Team{}, err
+	}
+	payload.ID = strconv.FormatInt(teamID, 10)
+	return payload, nil
+}
+
+// 1.- Delete soft-deletes the team and removes its memberships.
+func (s *TeamStore) Delete(ctx context.Context, id string) error {
+	teamID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		return softDelete(ctx, tx, "teams", teamID, [2]string{"team_members", "team_id"})
+	})
+}
+
+// 1.- List pages through live teams ordered by id.
+func (s *TeamStore) List(ctx context.Context, pagination adminhttp.Pagination) ([]adminhttp.Team, int, error) {
+	total, err := countLive(ctx, s.db, "teams")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	const query = `
+SELECT id, name
+FROM teams
+WHERE deleted_at IS NULL
+ORDER BY id
+LIMIT $1 OFFSET $2`
+
+	rows, err := s.db.QueryContext(ctx, query, pagination.PerPage, offset(pagination))
+	if err != nil {
+		return nil, 0, fmt.Errorf("list teams: %w", err)
+	}
+	defer rows.Close()
+
+	teams := make([]adminhttp.Team, 0)
+	for rows.Next() {
+		var (
+			id   int64
+			team adminhttp.Team
+		)
+		if err := rows.Scan(&id, &team.Name); err != nil {
+			return nil, 0, fmt.Errorf("scan team: %w", err)
+		}
+		team.ID = strconv.FormatInt(id, 10)
+		teams = append(teams, team)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate teams: %w", err)
+	}
+	return teams, total, nil
+}
@@ -0,0 +1,152 @@
+package rbac
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/lib/pq"
+
+	adminhttp "github.com/example/Yamato-Go-Gin-API/internal/http/admin"
+)
+
+// 1.- UserStore implements adminhttp.UserService on the users, user_roles, and team_members tables.
+type UserStore struct {
+	db *sql.DB
+}
+
+// 1.- NewUserStore validates the database handle and prepares the store.
+func NewUserStore(db *sql.DB) (*UserStore, error) {
+	if db == nil {
+		return nil, errors.New("rbac user store requires a database connection")
+	}
+	return &UserStore{db: db}, nil
+}
+
+// 1.- Create inserts the user with its role and team assignments in one transaction.
+// Admin-created accounts have no password until the user completes a password reset.
+func (s *UserStore) Create(ctx context.Context, payload adminhttp.User) (adminhttp.User, error) {
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		var id int64
+		err := tx.QueryRowContext(ctx, `
+INSERT INTO users (email, password_hash, first_name, last_name)
+VALUES ($1, '', '', '')
+RETURNING id`, payload.Email).Scan(&id)
+		if err != nil {
+			return mapWriteError(err, "insert user")
+		}
+		payload.ID = strconv.FormatInt(id, 10)
+		return assignUser(ctx, tx, id, payload)
+	})
+	if err != nil {
+		return adminhttp.User{}, err
+	}
+	return payload, nil
+}
+
+// 1.- Update changes the email and replaces the role and team assignments atomically.
+func (s *UserStore) Update(ctx context.Context, id string, payload adminhttp.User) (adminhttp.User, error) {
+	userID, err := parseID(id)
+	if err != nil {
+		return adminhttp.User{}, err
+	}
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+UPDATE users
+SET email = $2, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL`, userID, payload.Email)
+		if err != nil {
+			return mapWriteError(err, "update user")
+		}
+		if err := requireRow(result, "update user"); err != nil {
+			return err
+		}
+		return assignUser(ctx, tx, userID, payload)
+	})
+	if err != nil {
+		return adminhttp.User{}, err
+	}
+	payload.ID = strconv.FormatInt(userID, 10)
+	return payload, nil
+}
+
+// 1.- Delete soft-deletes the user and removes its role and team memberships and personal access tokens.
+func (s *UserStore) Delete(ctx context.Context, id string) error {
+	userID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		return softDelete(ctx, tx, "users", userID, [2]string{"user_roles", "user_id"}, [2]string{"team_members", "user_id"}, [2]string{"personal_access_tokens", "user_id"})
+	})
+}
+
+// 1.- List pages through live users ordered by id, with their live role and team names.
+func (s *UserStore) List(ctx context.Context, pagination adminhttp.Pagination) ([]adminhttp.User, int, error) {
+	total, err := countLive(ctx, s.db, "users")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	const query = `
+SELECT u.id, u.email,
+	ARRAY(
+		SELECT r.name FROM user_roles ur
+		JOIN roles r ON r.id = ur.role_id AND r.deleted_at IS NULL
+		WHERE ur.user_id = u.id ORDER BY r.name
+	),
+	ARRAY(
+		SELECT t.name FROM team_members tm
+		JOIN teams t ON t.id = tm.team_id AND t.deleted_at IS NULL
+		WHERE tm.user_id = u.id ORDER BY t.name
+	)
+FROM users u
+WHERE u.deleted_at IS NULL
+ORDER BY u.id
+LIMIT $1 OFFSET $2`
+
+	rows, err := s.db.QueryContext(ctx, query, pagination.PerPage, offset(pagination))
+	if err != nil {
+		return nil, 0, fmt.Errorf("list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]adminhttp.User, 0)
+	for rows.Next() {
+		var (
+			id    int64
+			user  adminhttp.User
+			roles pq.StringArray
+			teams pq.StringArray
+		)
+		if err := rows.Scan(&id, &user.Email, &roles, &teams); err != nil {
+			return nil, 0, fmt.Errorf("scan user: %w", err)
+		}
+		user.ID = strconv.FormatInt(id, 10)
+		user.Roles = nameList(roles)
+		user.Teams = nameList(teams)
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate users: %w", err)
+	}
+	return users, total, nil
+}
+
+// 1.- assignUser makes the user's roles and teams match the payload; unknown names abort the transaction.
+func assignUser(ctx context.Context, tx *sql.Tx, userID int64, payload adminhttp.User) error {
+	roleIDs, err := resolveNames(ctx, tx, "roles", payload.Roles)
+	if err != nil {
+		return err
+	}
+	teamIDs, err := resolveNames(ctx, tx, "teams", payload.Teams)
+	if err != nil {
+		return err
+	}
+	if err := replaceLinks(ctx, tx, "user_roles", "user_id", userID, "role_id", roleIDs); err != nil {
+		return err
+	}
+	return replaceLinks(ctx, tx, "team_members", "user_id", userID, "team_id", teamIDs)
+}
@@ -0,0 +1,132 @@
+package tokens
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+)
+
+// 1.- Store implements authhttp.OneTimeTokenStore on top of the one_time_tokens table.
+type Store struct {
+	db *sql.DB
+}
+
+// 1.- NewStore validates the database handle and prepares the store.
+func NewStore(db *sql.DB) (*Store, error) {
+	if db == nil {
+		return nil, errors.New("tokens store requires a database connection")
+	}
+	return &Store{db: db}, nil
+}
+
+// 1.- Save invalidates pending tokens for the same user and purpose before inserting the new hash.
+func (s *Store) Save(ctx context.Context, token authhttp.OneTimeToken) error {
+	userID, err := strconv.ParseInt(token.UserID, 10, 64)
+	if err != nil {
+		return authhttp.ErrUserNotFound
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin token transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	//1.- Only the most recent link stays redeemable.
+	if _, err := tx.ExecContext(ctx, `
+UPDATE one_time_tokens
+SET consumed_at = NOW()
+WHERE user_id = $1 AND purpose = $2 AND consumed_at IS NULL`, userID, token.Purpose); err != nil {
+		return fmt.Errorf("invalidate pending tokens: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO one_time_tokens (purpose, user_id, token_hash, payload, expires_at)
+VALUES ($1, $2, $3, $4, $5)`, token.Purpose, userID, token.TokenHash, token.Payload, token.ExpiresAt.UTC()); err != nil {
+		return fmt.Errorf("insert token: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit token transaction: %w", err)
+	}
+	return nil
+}
+
+// 1.- Consume marks a live token as used in a single statement so concurrent redemptions cannot both win.
+func (s *Store) Consume(ctx context.Context, purpose string, tokenHash string, now time.Time) (authhttp.OneTimeToken, error) {
+	const query = `
+UPDATE one_time_tokens
+SET consumed_at = $3
+WHERE purpose = $1 AND token_hash = $2 AND consumed_at IS NULL AND expires_at > $3
+RETURNING user_id, payload, expires_at`
+
+	var (
+		userID    int64
+		payload   string
+		expiresAt time.Time
+	)
+	if err := s.db.QueryRowContext(ctx, query, purpose, tokenHash, now.UTC()).Scan(&userID, &payload, &expiresAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return authhttp.OneTimeToken{}, authhttp.ErrTokenNotFound
+		}
+		return authhttp.OneTimeToken{}, fmt.Errorf("consume token: %w", err)
+	}
+
+	return authhttp.OneTimeToken{
+		Purpose:   purpose,
+		UserID:    strconv.FormatInt(userID, 10),
+		TokenHash: tokenHash,
+		Payload:   payload,
+		ExpiresAt: expiresAt.UTC(),
+	}, nil
+}
+
+// 1.- Find loads a live token without marking it as used.
+func (s *Store) Find(ctx context.Context, purpose string, tokenHash string, now time.Time) (authhttp.OneTimeToken, error) {
+	const query = `
+SELECT user_id, payload, expires_at
+FROM one_time_tokens
+WHERE purpose = $1 AND token_hash = $2 AND consumed_at IS NULL AND expires_at > $3`
+
+	var (
+		userID    int64
+		payload   string
+		expiresAt time.Time
+	)
+	if err := s.db.QueryRowContext(ctx, query, purpose, tokenHash, now.UTC()).Scan(&userID, &payload, &expiresAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return authhttp.OneTimeToken{}, authhttp.ErrTokenNotFound
+		}
+		return authhttp.OneTimeToken{}, fmt.Errorf("find token: %w", err)
+	}
+
+	return authhttp.OneTimeToken{
+		Purpose:   purpose,
+		UserID:    strconv.FormatInt(userID, 10),
+		TokenHash: tokenHash,
+		Payload:   payload,
+		ExpiresAt: expiresAt.UTC(),
+	}, nil
+}
+
+// 1.- Revoke marks every pending token for the user and purpose as used.
+func (s *Store) Revoke(ctx context.Context, userID string, purpose string) error {
+	id, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return authhttp.ErrUserNotFound
+	}
+	if _, err := s.db.ExecContext(ctx, `
+UPDATE one_time_tokens
+SET consumed_at = NOW()
+WHERE user_id = $1 AND purpose = $2 AND consumed_at IS NULL`, id, purpose); err != nil {
+		return fmt.Errorf("revoke tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package tokens
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/storage"
+	"github.com/example/Yamato-Go-Gin-API/internal/testutil"
+)
+
+// 1.- TestStoreSaveAndConsume exercises single-use semantics against Postgres.
+func TestStoreSaveAndConsume(t *testing.T) {
+	container := testutil.RunPostgresContainer(t)
+	if container == nil {
+		t.Skip("postgres container unavailable")
+		return
+	}
+
+	db, err := sql.Open("postgres", container.DSN)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	migrator, err := storage.NewMigrator(db)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply(ctx))
+
+	// 2.- Seed the owning user.
+	var userID int64
+	require.NoError(t, db.QueryRowContext(ctx, `
+INSERT INTO users (email, password_hash, first_name, last_name)
+VALUES ('tokens@example.com', 'hash', 'Token', 'Owner')
+RETURNING id`).Scan(&userID))
+
+	store, err := NewStore(db)
+	require.NoError(t, err)
+
+	owner := strconv.FormatInt(userID, 10)
+	expires := time.Now().Add(time.Hour)
+	require.NoError(t, store.Save(ctx, authhttp.OneTimeToken{Purpose: authhttp.PurposePasswordReset, UserID: owner, TokenHash: authhttp.HashOneTimeToken("first", "s"), ExpiresAt: expires}))
+	require.NoError(t, store.Save(ctx, authhttp.OneTimeToken{Purpose: authhttp.PurposePasswordReset, UserID: owner, TokenHash: authhttp.HashOneTimeToken("second", "s"), Payload: "extra", ExpiresAt: expires}))
+
+	// 3.- The superseded token is dead and the newest one can be looked up until it redeems exactly once.
+	_, err = store.Consume(ctx, authhttp.PurposePasswordReset, authhttp.HashOneTimeToken("first", "s"), time.Now())
+	require.ErrorIs(t, err, authhttp.ErrTokenNotFound)
+
+	found, err := store.Find(ctx, authhttp.PurposePasswordReset, authhttp.HashOneTimeToken("second", "s"), time.Now())
+	require.NoError(t, err)
+	require.Equal(t, owner, found.UserID)
+	_, err = store.Find(ctx, authhttp.PurposePasswordReset, authhttp.HashOneTimeToken("second", "s"), expires.Add(time.Minute))
+	require.ErrorIs(t, err, authhttp.ErrTokenNotFound)
+
+	token, err := store.Consume(ctx, authhttp.PurposePasswordReset, authhttp.HashOneTimeToken("second", "s"), time.Now())
+	require.NoError(t, err)
+	require.Equal(t, owner, token.UserID)
+	require.Equal(t, "extra", token.Payload)
+
+	_, err = store.Consume(ctx, authhttp.PurposePasswordReset, authhttp.HashOneTimeToken("second", "s"), time.Now())
+	require.ErrorIs(t, err, authhttp.ErrTokenNotFound)
+	_, err = store.Find(ctx, authhttp.PurposePasswordReset, authhttp.HashOneTimeToken("second", "s"), time.Now())
+	require.ErrorIs(t, err, authhttp.ErrTokenNotFound)
+
+	// 4.- Revoking a purpose kills its pending tokens without touching other purposes.
+	require.NoError(t, store.Save(ctx, authhttp.OneTimeToken{Purpose: authhttp.PurposeEmailChange, UserID: owner, TokenHash: authhttp.HashOneTimeToken("confirm", "s"), ExpiresAt: expires}))
+	require.NoError(t, store.Save(ctx, authhttp.OneTimeToken{Purpose: authhttp.PurposeEmailChangeCancel, UserID: owner, TokenHash: authhttp.HashOneTimeToken("cancel", "s"), ExpiresAt: expires}))
+	require.NoError(t, store.Revoke(ctx, owner, authhttp.PurposeEmailChange))
+	_, err = store.Consume(ctx, authhttp.PurposeEmailChange, authhttp.HashOneTimeToken("confirm", "s"), time.Now())
+	require.ErrorIs(t, err, authhttp.ErrTokenNotFound)
+	_, err = store.Consume(ctx, authhttp.PurposeEmailChangeCancel, authhttp.HashOneTimeToken("cancel", "s"), time.Now())
+	require.NoError(t, err)
+}
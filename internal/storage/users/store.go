@@ -3,9 +3,12 @@ package users
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strconv"
 
+	"github.com/lib/pq"
+
 	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
 )
 
@@ -20,6 +23,7 @@ func NewStore(db *sql.DB) *Store {
 }
 
 // Create inserts a new user record and returns the created user with its ID populated.
+// It reports authhttp.ErrEmailTaken when the address belongs to another account, including one awaiting purge.
 func (s *Store) Create(ctx context.Context, user authhttp.User) (authhttp.User, error) {
 	const q = `
 INSERT INTO users (email, password_hash)
@@ -31,6 +35,11 @@ RETURNING id`
 		user.Email,
 		user.PasswordHash,
 	).Scan(&id); err != nil {
+		//1.- Soft-deleted accounts keep their address until purged, so FindByEmail alone cannot rule out a clash.
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return authhttp.User{}, authhttp.ErrEmailTaken
+		}
 		return authhttp.User{}, fmt.Errorf("create user: %w", err)
 	}
 
@@ -38,12 +47,12 @@ RETURNING id`
 	return user, nil
 }
 
-// FindByEmail retrieves a user by email. It returns a zero-value user and nil error when not found.
+// FindByEmail retrieves a user by email. Soft-deleted accounts are skipped and report authhttp.ErrUserNotFound.
 func (s *Store) FindByEmail(ctx context.Context, email string) (authhttp.User, error) {
 	const q = `
 SELECT id, email, password_hash
 FROM users
-WHERE email = $1
+WHERE email = $1 AND deleted_at IS NULL
 LIMIT 1`
 
 	var (
@@ -58,9 +67,8 @@ LIMIT 1`
 		&passwordHash,
 	)
 	if err != nil {
-		if err == sql.ErrNoRows {
-            // mimic in-memory store semantics: not found = zero user, nil error
-			return authhttp.User{}, nil
+		if errors.Is(err, sql.ErrNoRows) {
+			return authhttp.User{}, authhttp.ErrUserNotFound
 		}
 		return authhttp.User{}, fmt.Errorf("find user by email: %w", err)
 	}
@@ -71,17 +79,17 @@ LIMIT 1`
 	return u, nil
 }
 
-// FindByID retrieves a user by ID. It returns a zero-value user and nil error when not found.
+// FindByID retrieves a user by ID. Soft-deleted accounts are skipped and report authhttp.ErrUserNotFound.
 func (s *Store) FindByID(ctx context.Context, id string) (authhttp.User, error) {
 	const q = `
 SELECT id, email, password_hash
 FROM users
-WHERE id = $1
+WHERE id = $1 AND deleted_at IS NULL
 LIMIT 1`
 
 	intID, err := strconv.ParseInt(id, 10, 64)
 	if err != nil {
-		return authhttp.User{}, fmt.Errorf("invalid user id %q: %w", id, err)
+		return authhttp.User{}, authhttp.ErrUserNotFound
 	}
 
 	var (
@@ -96,8 +104,8 @@ LIMIT 1`
 		&passwordHash,
 	)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return authhttp.User{}, nil
+		if errors.Is(err, sql.ErrNoRows) {
+			return authhttp.User{}, authhttp.ErrUserNotFound
 		}
 		return authhttp.User{}, fmt.Errorf("find user by id: %w", err)
 	}
@@ -107,3 +115,97 @@ LIMIT 1`
 
 	return u, nil
 }
+
+// UpdatePassword stores a new password hash for the user. It returns authhttp.ErrUserNotFound when no row matches.
+func (s *Store) UpdatePassword(ctx context.Context, id string, passwordHash string) error {
+	const q = `
+UPDATE users
+SET password_hash = $2, updated_at = NOW()
+WHERE id = $1`
+
+	intID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return authhttp.ErrUserNotFound
+	}
+
+	result, err := s.db.ExecContext(ctx, q, intID, passwordHash)
+	if err != nil {
+		return fmt.Errorf("update user password: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("update user password: %w", err)
+	}
+	if affected == 0 {
+		return authhttp.ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdateEmail stores a new address for the user. It returns authhttp.ErrUserNotFound when no row matches
+// and authhttp.ErrEmailTaken when another account already uses the address.
+func (s *Store) UpdateEmail(ctx context.Context, id string, email string) error {
+	const q = `
+UPDATE users
+SET email = $2, updated_at = NOW()
+WHERE id = $1`
+
+	intID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return authhttp.ErrUserNotFound
+	}
+
+	result, err := s.db.ExecContext(ctx, q, intID, email)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return authhttp.ErrEmailTaken
+		}
+		return fmt.Errorf("update user email: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("update user email: %w", err)
+	}
+	if affected == 0 {
+		return authhttp.ErrUserNotFound
+	}
+	return nil
+}
+
+// LinkPhone records the verified phone of a live account. It reports authhttp.ErrPhoneTaken when another
+// live account already holds the phone and authhttp.ErrUserNotFound for unknown or deleted accounts.
+func (s *Store) LinkPhone(ctx context.Context, id string, phone string) error {
+	const q = `
+UPDATE users
+SET phone = $2, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+  AND NOT EXISTS (SELECT 1 FROM users other WHERE other.phone = $2 AND other.id <> $1 AND other.deleted_at IS NULL)`
+
+	intID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return authhttp.ErrUserNotFound
+	}
+
+	result, err := s.db.ExecContext(ctx, q, intID, phone)
+	if err != nil {
+		return fmt.Errorf("link user phone: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("link user phone: %w", err)
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	//1.- Nothing changed: tell a missing account apart from a phone held elsewhere.
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL)`, intID).Scan(&exists); err != nil {
+		return fmt.Errorf("link user phone: %w", err)
+	}
+	if !exists {
+		return authhttp.ErrUserNotFound
+	}
+	return authhttp.ErrPhoneTaken
+}
@@ -0,0 +1,13 @@
+-- 1.- Codes are stored as HMAC digests; the plaintext column is kept nullable for older rows only.
+ALTER TABLE phone_verifications ADD COLUMN IF NOT EXISTS code_hash TEXT;
+ALTER TABLE phone_verifications ALTER COLUMN code DROP NOT NULL;
+
+-- 2.- Wrong guesses per verification; the row becomes 'locked' once the limit is reached.
+ALTER TABLE phone_verifications ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0;
+
+-- 3.- Retire plaintext codes: they can no longer be confirmed and must not linger in the table.
+UPDATE phone_verifications
+SET status = CASE WHEN status = 'pending' THEN 'expired' ELSE status END,
+    code = NULL,
+    updated_at = NOW()
+WHERE code IS NOT NULL;
@@ -0,0 +1,13 @@
+-- 1.- Hashed, expiring, single-use tokens backing account recovery flows.
+CREATE TABLE IF NOT EXISTS one_time_tokens (
+    id          BIGSERIAL PRIMARY KEY,
+    purpose     VARCHAR(50) NOT NULL,
+    user_id     BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token_hash  CHAR(64) NOT NULL UNIQUE,   -- sha256 digest, never the plaintext token
+    payload     TEXT NOT NULL DEFAULT '',
+    expires_at  TIMESTAMPTZ NOT NULL,
+    consumed_at TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS one_time_tokens_user_purpose_idx ON one_time_tokens (user_id, purpose);
@@ -0,0 +1,14 @@
+-- 1.- Personal access tokens; only the sha256 digest is stored and prefix identifies the token in lists.
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name         VARCHAR(150) NOT NULL,
+    prefix       VARCHAR(16) NOT NULL UNIQUE,
+    token_hash   CHAR(64) NOT NULL,
+    scopes       TEXT[] NOT NULL DEFAULT '{}',
+    expires_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS personal_access_tokens_user_idx ON personal_access_tokens (user_id);
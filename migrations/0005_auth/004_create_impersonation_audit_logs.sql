@@ -0,0 +1,18 @@
+-- 1.- Append-only trail of impersonation sessions and every request made with their tokens.
+CREATE TABLE IF NOT EXISTS impersonation_audit_logs (
+    id          BIGSERIAL PRIMARY KEY,
+    actor_id    BIGINT NOT NULL REFERENCES users (id) ON DELETE RESTRICT,
+    subject_id  BIGINT NOT NULL REFERENCES users (id) ON DELETE RESTRICT,
+    token_id    VARCHAR(64) NOT NULL,
+    action      VARCHAR(50) NOT NULL,
+    reason      TEXT,
+    method      VARCHAR(10),
+    path        TEXT,
+    ip_address  VARCHAR(64),
+    user_agent  TEXT,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS impersonation_audit_logs_actor_idx ON impersonation_audit_logs (actor_id, created_at);
+CREATE INDEX IF NOT EXISTS impersonation_audit_logs_subject_idx ON impersonation_audit_logs (subject_id, created_at);
+CREATE INDEX IF NOT EXISTS impersonation_audit_logs_token_idx ON impersonation_audit_logs (token_id);
@@ -0,0 +1,15 @@
+-- 1.- Link accounts to the phone they verified so exports and purges can reach phone_verifications.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone TEXT;
+
+-- 2.- Personal data export requests; file_path points into the local file storage once the job completes.
+CREATE TABLE IF NOT EXISTS data_exports (
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status       VARCHAR(20) NOT NULL DEFAULT 'pending',  -- 'pending' | 'completed' | 'failed'
+    file_path    TEXT,
+    error        TEXT,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS data_exports_user_idx ON data_exports (user_id, created_at DESC);
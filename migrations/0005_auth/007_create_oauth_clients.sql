@@ -0,0 +1,9 @@
+-- 1.- Confidential OAuth clients (resource servers, services); only the sha256 digest of the secret is stored.
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    id          BIGSERIAL PRIMARY KEY,
+    client_id   VARCHAR(100) NOT NULL UNIQUE,
+    name        VARCHAR(150) NOT NULL DEFAULT '',
+    secret_hash CHAR(64) NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revoked_at  TIMESTAMPTZ
+);
@@ -0,0 +1,2 @@
+-- 1.- Scopes a confidential client may request through the client_credentials grant.
+ALTER TABLE oauth_clients ADD COLUMN IF NOT EXISTS scopes TEXT[] NOT NULL DEFAULT '{}';
@@ -4,5 +4,5 @@ import "embed"
 
 // 1.- Embed every SQL migration bundle so they are available at runtime.
 //
-//go:embed 0001_core/*.sql 0002_join_requests/*.sql 0003_tasks/*.sql 0004_verification/*.sql
+//go:embed 0001_core/*.sql 0002_join_requests/*.sql 0003_tasks/*.sql 0004_verification/*.sql 0005_auth/*.sql
 var Core embed.FS
@@ -3,23 +3,46 @@ package routes
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
 	internalauth "github.com/example/Yamato-Go-Gin-API/internal/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/authorization"
 	"github.com/example/Yamato-Go-Gin-API/internal/config"
+	adminhttp "github.com/example/Yamato-Go-Gin-API/internal/http/admin"
 	authhttp "github.com/example/Yamato-Go-Gin-API/internal/http/auth"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/auth/oidc"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/auth/passwordpolicy"
+	"github.com/example/Yamato-Go-Gin-API/internal/http/auth/webauthn"
 	"github.com/example/Yamato-Go-Gin-API/internal/http/diagnostics"
 	notificationshttp "github.com/example/Yamato-Go-Gin-API/internal/http/notifications"
+	oauthhttp "github.com/example/Yamato-Go-Gin-API/internal/http/oauth"
+	privacyhttp "github.com/example/Yamato-Go-Gin-API/internal/http/privacy"
 	taskhttp "github.com/example/Yamato-Go-Gin-API/internal/http/tasks"
 	"github.com/example/Yamato-Go-Gin-API/internal/httpserver"
 	"github.com/example/Yamato-Go-Gin-API/internal/middleware"
 	"github.com/example/Yamato-Go-Gin-API/internal/observability"
 	memoryplatform "github.com/example/Yamato-Go-Gin-API/internal/platform/memory"
+	"github.com/example/Yamato-Go-Gin-API/internal/platform/redisclient"
+	"github.com/example/Yamato-Go-Gin-API/internal/queue"
+	"github.com/example/Yamato-Go-Gin-API/internal/storage"
+	storageaccesstokens "github.com/example/Yamato-Go-Gin-API/internal/storage/accesstokens"
+	storageaudit "github.com/example/Yamato-Go-Gin-API/internal/storage/audit"
+	storagedevicetokens "github.com/example/Yamato-Go-Gin-API/internal/storage/devicetokens"
+	storagemfa "github.com/example/Yamato-Go-Gin-API/internal/storage/mfa"
+	storageoauthclients "github.com/example/Yamato-Go-Gin-API/internal/storage/oauthclients"
+	storagepasskeys "github.com/example/Yamato-Go-Gin-API/internal/storage/passkeys"
+	storageprivacy "github.com/example/Yamato-Go-Gin-API/internal/storage/privacy"
+	storagerbac "github.com/example/Yamato-Go-Gin-API/internal/storage/rbac"
 	storagetasks "github.com/example/Yamato-Go-Gin-API/internal/storage/tasks"
+	storagetokens "github.com/example/Yamato-Go-Gin-API/internal/storage/tokens"
 	userstore "github.com/example/Yamato-Go-Gin-API/internal/storage/users"
 	dbtooling "github.com/example/Yamato-Go-Gin-API/internal/tooling/db"
 
@@ -51,6 +74,16 @@ func RegisterRoutes(router *gin.Engine, opts ...Option) {
 		opt(&configured)
 	}
 
+	// 1.0.- Browser cookie sessions need CSRF protection on every route registered below.
+	cookieSessions, cookiesEnabled := cookieSessionsFromEnv()
+	if cookiesEnabled {
+		router.Use(middleware.CSRF(middleware.CSRFConfig{
+			CookieName:     cookieSessions.CSRFCookie,
+			HeaderName:     authhttp.CSRFHeader,
+			SessionCookies: []string{cookieSessions.AccessCookie, cookieSessions.RefreshCookie},
+		}))
+	}
+
 	// 1.1.- Open Postgres connection shared across HTTP services.
 	dsn, err := dbtooling.BuildPostgresDSNFromEnv()
 	if err != nil {
@@ -71,8 +104,25 @@ func RegisterRoutes(router *gin.Engine, opts ...Option) {
 		panic(err)
 	}
 
+	// 1.2.- Connect to Redis for token state and jobs; REDIS_MODE=memory swaps token state for the in-process emulator.
+	redisCfg, err := config.LoadRedis("")
+	if err != nil {
+		panic(err)
+	}
+	redisClient := redisclient.NewClient(redisCfg)
+	var redis internalauth.RedisCommander = redisClient
+	redisPinger := redisclient.NewPinger(redisClient)
+	// The job queue always talks to the real server, so it gets its own health check even in memory mode.
+	queuePinger := redisclient.NewPinger(redisClient)
+	if redisCfg.Mode == config.RedisModeMemory {
+		emulator := memoryplatform.NewRedis()
+		redis, redisPinger = emulator, redisclient.NewPinger(emulator)
+	} else if err := redisPinger.Ping(ctx); err != nil {
+		panic(err)
+	}
+
 	// 2.- Prepare diagnostics handlers responsible for service monitoring.
-	diagHandler := diagnostics.NewHandler("Larago API")
+	diagHandler := diagnostics.NewHandler("Larago API", diagnostics.WithRedis(redisPinger), diagnostics.WithQueue(queuePinger))
 
 	// 3.- Define a root route returning a welcome message.
 	router.GET("/", func(ctx *gin.Context) {
@@ -96,37 +146,322 @@ func RegisterRoutes(router *gin.Engine, opts ...Option) {
 
 	// 8.- Bootstrap auth services and repositories.
 	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "development-jwt-secret"
+	developmentMode := isDevelopmentEnv(os.Getenv("APP_ENV"))
+	jwtAlgorithm := os.Getenv("JWT_ALGORITHM")
+	if jwtAlgorithm == "" {
+		jwtAlgorithm = internalauth.AlgorithmHS256
+	}
+	symmetric := strings.EqualFold(strings.TrimSpace(jwtAlgorithm), internalauth.AlgorithmHS256)
+	// hmacSecret keys reset tokens, recovery codes, magic links, e-mail changes and phone code digests.
+	hmacSecret, err := hmacSecretFromEnv(developmentMode, symmetric)
+	if err != nil {
+		panic(err)
 	}
 	jwtIssuer := os.Getenv("JWT_ISSUER")
 	if jwtIssuer == "" {
 		jwtIssuer = "yamato"
 	}
 
-	redis := memoryplatform.NewRedis()
+	// Only fall back to the development secret for HMAC signing in development so
+	// no deployment accepts tokens signed with a well-known key.
+	signingSecret := jwtSecret
+	if symmetric && signingSecret == "" && developmentMode {
+		signingSecret = developmentSecret
+	}
+	// RS256/EdDSA deployments verify leftover HS256 tokens only when JWT_ACCEPT_HS256=true.
+	acceptHS256, _ := strconv.ParseBool(os.Getenv("JWT_ACCEPT_HS256"))
+
 	authSvc, err := internalauth.NewService(config.JWTConfig{
-		Secret:            jwtSecret,
+		Secret:            signingSecret,
 		Issuer:            jwtIssuer,
 		AccessExpiration:  15 * time.Minute,
 		RefreshExpiration: 24 * time.Hour,
+		Algorithm:         jwtAlgorithm,
+		KeyID:             os.Getenv("JWT_KEY_ID"),
+		PrivateKeyFile:    os.Getenv("JWT_PRIVATE_KEY_FILE"),
+		VerificationKeys:  config.ParseKeyValueList(os.Getenv("JWT_VERIFICATION_KEYS")),
+		AcceptHS256:       acceptHS256,
 	}, redis)
 	if err != nil {
 		panic(err)
 	}
 
+	// 8.0.- Hash passwords with Argon2id by default; bcrypt hashes keep working and are upgraded on login.
+	passwordCfg, err := config.LoadPassword("")
+	if err != nil {
+		panic(err)
+	}
+	passwordHasher, err := internalauth.NewPasswordHasher(passwordCfg)
+	if err != nil {
+		panic(err)
+	}
+	authSvc.UsePasswordHasher(passwordHasher)
+	passwordPolicy, err := passwordpolicy.NewFromConfig(passwordCfg)
+	if err != nil {
+		panic(err)
+	}
+
 	// Use Postgres-backed user store instead of in-memory.
-	var userStore authhttp.UserStore = userstore.NewStore(db)
+	accounts := userstore.NewStore(db)
+	var userStore authhttp.UserStore = accounts
+
+	verificationSvc := memoryplatform.NewVerificationService(userStore, hmacSecret, time.Minute)
+
+	// 8.1.- Hand outgoing e-mail to the worker through the shared Redis job queue.
+	// The queue always targets the Redis server because cmd/worker consumes from it, even in memory mode.
+	jobs := queue.NewRedisQueue(redisClient, "jobs")
+	// The API only produces email_send jobs; cmd/worker registers the real sender and consumes them.
+	if err := jobs.Register(queue.NewEmailSendJob(nil)); err != nil {
+		panic(err)
+	}
+	// Phone verification codes leave through sms_send jobs delivered by the worker's SMS transport.
+	if err := jobs.Register(queue.NewSMSSendJob(nil)); err != nil {
+		panic(err)
+	}
+
+	// 8.2.- Persist hashed single-use tokens for password recovery.
+	tokenStore, err := storagetokens.NewStore(db)
+	if err != nil {
+		panic(err)
+	}
+	resetTTL := 30 * time.Minute
+	if minutes, convErr := strconv.Atoi(os.Getenv("PASSWORD_RESET_TOKEN_TTL_MINUTES")); convErr == nil && minutes > 0 {
+		resetTTL = time.Duration(minutes) * time.Minute
+	}
+	passwordResets := authhttp.NewPasswordResetService(userStore, tokenStore, jobs, authSvc, authhttp.PasswordResetConfig{
+		Secret:   hmacSecret,
+		TTL:      resetTTL,
+		ResetURL: os.Getenv("PASSWORD_RESET_URL"),
+	})
+
+	// 8.3.- Offer optional TOTP second factor backed by Postgres enrollments.
+	mfaStore, err := storagemfa.NewStore(db)
+	if err != nil {
+		panic(err)
+	}
+	mfaIssuer := os.Getenv("MFA_ISSUER")
+	if mfaIssuer == "" {
+		mfaIssuer = jwtIssuer
+	}
+	mfaSvc := authhttp.NewMFAService(userStore, mfaStore, authhttp.MFAConfig{Issuer: mfaIssuer, Secret: hmacSecret})
+
+	// 8.4.- Count failed logins per account and IP to enforce progressive lockouts.
+	lockoutCfg := internalauth.LockoutConfig{}
+	if threshold, convErr := strconv.Atoi(os.Getenv("LOGIN_LOCKOUT_THRESHOLD")); convErr == nil && threshold > 0 {
+		lockoutCfg.AccountThreshold = threshold
+	}
+	if minutes, convErr := strconv.Atoi(os.Getenv("LOGIN_LOCKOUT_MAX_MINUTES")); convErr == nil && minutes > 0 {
+		lockoutCfg.MaxDelay = time.Duration(minutes) * time.Minute
+	}
+	loginThrottle, err := internalauth.NewLoginThrottle(redis, lockoutCfg)
+	if err != nil {
+		panic(err)
+	}
+
+	// 8.5.- Register corporate SSO identity providers declared in the environment.
+	ssoProviders, err := oidcProvidersFromEnv()
+	if err != nil {
+		panic(err)
+	}
+	ssoSvc := authhttp.NewSSOService(userStore, authSvc, redis, authhttp.SSOConfig{
+		AutoProvision: os.Getenv("OIDC_AUTO_PROVISION") == "true",
+	}, ssoProviders...)
+
+	// 8.6.- Issue long-lived personal access tokens whose scopes map onto admin permission slugs.
+	// The same catalogue grants permissions to service accounts using the client_credentials grant.
+	tokenScopes := map[string][]string{
+		"admin:users":       {adminhttp.PermissionManageUsers},
+		"admin:roles":       {adminhttp.PermissionManageRoles},
+		"admin:permissions": {adminhttp.PermissionManagePermissions},
+		"admin:teams":       {adminhttp.PermissionManageTeams},
+	}
+	accessTokenStore, err := storageaccesstokens.NewStore(db)
+	if err != nil {
+		panic(err)
+	}
+	accessTokens := authhttp.NewAccessTokenService(accessTokenStore, authhttp.AccessTokenConfig{Scopes: tokenScopes})
+
+	// 8.7.- Resolve principals from the RBAC tables, cached in Redis and invalidated by admin mutations.
+	rbacStore, err := storagerbac.NewStore(db)
+	if err != nil {
+		panic(err)
+	}
+	accessTTL := 5 * time.Minute
+	if seconds, convErr := strconv.Atoi(os.Getenv("ACCESS_CACHE_TTL_SECONDS")); convErr == nil && seconds > 0 {
+		accessTTL = time.Duration(seconds) * time.Second
+	}
+	accessCache, err := internalauth.NewAccessCache(rbacStore, redis, accessTTL)
+	if err != nil {
+		panic(err)
+	}
+
+	// 8.8.- Let support staff impersonate users with short-lived tokens recorded in an audit trail.
+	impersonationAudit, err := storageaudit.NewStore(db)
+	if err != nil {
+		panic(err)
+	}
+	impersonationTTL := 15 * time.Minute
+	if minutes, convErr := strconv.Atoi(os.Getenv("IMPERSONATION_TTL_MINUTES")); convErr == nil && minutes > 0 {
+		impersonationTTL = time.Duration(minutes) * time.Minute
+	}
 
-	verificationSvc := memoryplatform.NewVerificationService(userStore, jwtSecret, time.Minute)
+	// 8.9.- Offer passkey sign-in when a WebAuthn relying party id is configured.
+	var passkeys authhttp.PasskeyManager
+	if rpID := os.Getenv("WEBAUTHN_RP_ID"); rpID != "" {
+		origins := []string{}
+		for _, origin := range strings.Split(os.Getenv("WEBAUTHN_ORIGINS"), ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				origins = append(origins, origin)
+			}
+		}
+		relyingParty, err := webauthn.New(webauthn.Config{RPID: rpID, RPName: os.Getenv("WEBAUTHN_RP_NAME"), Origins: origins})
+		if err != nil {
+			panic(err)
+		}
+		passkeyStore, err := storagepasskeys.NewStore(db)
+		if err != nil {
+			panic(err)
+		}
+		passkeys = authhttp.NewPasskeyService(userStore, passkeyStore, redis, relyingParty)
+	}
+
+	// 8.10.- Sign users in through single-use e-mailed links stored alongside reset tokens.
+	magicLinkTTL := 15 * time.Minute
+	if minutes, convErr := strconv.Atoi(os.Getenv("MAGIC_LINK_TTL_MINUTES")); convErr == nil && minutes > 0 {
+		magicLinkTTL = time.Duration(minutes) * time.Minute
+	}
+	magicLinks := authhttp.NewMagicLinkService(userStore, tokenStore, jobs, authhttp.MagicLinkConfig{
+		Secret:  hmacSecret,
+		TTL:     magicLinkTTL,
+		LinkURL: os.Getenv("MAGIC_LINK_URL"),
+	})
+
+	// 8.11.- Change sign-in addresses only after the new mailbox confirms, warning the old one.
+	emailChanges := authhttp.NewEmailChangeService(userStore, tokenStore, jobs, authSvc, authhttp.EmailChangeConfig{
+		Secret:     hmacSecret,
+		ConfirmURL: os.Getenv("EMAIL_CHANGE_CONFIRM_URL"),
+		CancelURL:  os.Getenv("EMAIL_CHANGE_CANCEL_URL"),
+	})
+
+	// 8.12.- Exchange device tokens from the phone verification flow for phone-bound sessions.
+	// Tokens left unused for DEVICE_TOKEN_IDLE_TTL_DAYS expire; every exchange slides the window (0 disables expiry).
+	deviceTokens, err := storagedevicetokens.NewStore(db)
+	if err != nil {
+		panic(err)
+	}
+	deviceIdleTTL := authhttp.DefaultDeviceTokenIdleTTL
+	if days, convErr := strconv.Atoi(os.Getenv("DEVICE_TOKEN_IDLE_TTL_DAYS")); convErr == nil && days >= 0 {
+		deviceIdleTTL = time.Duration(days) * 24 * time.Hour
+	}
+
+	// 8.13.- Export personal data and soft-delete accounts; cmd/worker builds the archives and runs the hard purge.
+	privacyStore, err := storageprivacy.NewStore(db)
+	if err != nil {
+		panic(err)
+	}
+	exportFiles, err := storage.NewFileService("local", dataExportPath(), storage.StaticSettingsProvider{Settings: storage.FileUploadSettings{
+		AllowedMIMETypes: []string{"application/zip"},
+	}})
+	if err != nil {
+		panic(err)
+	}
+	purgeGrace := 30 * 24 * time.Hour
+	if days, convErr := strconv.Atoi(os.Getenv("ACCOUNT_PURGE_GRACE_DAYS")); convErr == nil && days > 0 {
+		purgeGrace = time.Duration(days) * 24 * time.Hour
+	}
+	privacySvc := privacyhttp.NewService(privacyStore, privacyStore, privacyStore, exportFiles, jobs, privacyhttp.Config{GracePeriod: purgeGrace})
+	if err := jobs.Register(queue.NewUserExportJob(nil)); err != nil {
+		panic(err)
+	}
+	privacyHandler := privacyhttp.NewHandler(privacySvc, userStore, authSvc, authSvc)
 
 	// 9.- Build HTTP handlers/controllers for auth, phone verification, notifications and tasks.
-	authHandler := authhttp.NewHandler(authSvc, userStore, verificationSvc)
-	authMiddleware := middleware.Authentication(authSvc, userStore)
+	authOptions := []authhttp.HandlerOption{
+		authhttp.WithPasswordResets(passwordResets),
+		authhttp.WithMFA(mfaSvc, authSvc),
+		authhttp.WithSessions(authSvc),
+		authhttp.WithLoginGuard(loginThrottle),
+		authhttp.WithSSO(ssoSvc),
+		authhttp.WithAccessTokens(accessTokens),
+		authhttp.WithImpersonation(authSvc, impersonationAudit, impersonationTTL),
+		authhttp.WithPasskeys(passkeys),
+		authhttp.WithMagicLinks(magicLinks),
+		authhttp.WithEmailChanges(emailChanges),
+		authhttp.WithPasswordPolicy(passwordPolicy),
+		authhttp.WithDeviceTokens(deviceTokens, deviceIdleTTL),
+		authhttp.WithPhoneLinks(accounts),
+	}
+	authnOptions := []middleware.AuthenticationOption{
+		middleware.WithAccessTokens(accessTokens),
+		middleware.WithAccessLoader(accessCache),
+		middleware.WithImpersonationAudit(impersonationAudit),
+		middleware.WithServiceAccounts(tokenScopes),
+	}
+	if cookiesEnabled {
+		authOptions = append(authOptions, authhttp.WithCookieSessions(cookieSessions))
+		authnOptions = append(authnOptions, middleware.WithSessionCookie(cookieSessions.AccessCookie))
+	}
+	authHandler := authhttp.NewHandler(authSvc, userStore, verificationSvc, authOptions...)
+	authMiddleware := middleware.Authentication(authSvc, userStore, authnOptions...)
 	httpserver.RegisterAuthRoutes(router, authHandler, authMiddleware)
+	policy := authorization.NewPolicy()
+	httpserver.RegisterAuthAdminRoutes(router, authHandler, authMiddleware, authhttp.DenyImpersonation, middleware.RequirePermission(policy, adminhttp.PermissionManageUsers))
+	httpserver.RegisterImpersonationRoutes(router, authHandler, authMiddleware, authhttp.DenyImpersonation, middleware.RequirePermission(policy, adminhttp.PermissionImpersonateUsers))
+	httpserver.RegisterJWKSRoute(router, authhttp.JWKS(authSvc))
+
+	// 9.1.- Manage users, roles, permissions, and teams in Postgres; every mutation refreshes cached grants.
+	adminUsers, err := storagerbac.NewUserStore(db)
+	if err != nil {
+		panic(err)
+	}
+	adminRoles, err := storagerbac.NewRoleStore(db)
+	if err != nil {
+		panic(err)
+	}
+	adminPermissions, err := storagerbac.NewPermissionStore(db)
+	if err != nil {
+		panic(err)
+	}
+	adminTeams, err := storagerbac.NewTeamStore(db)
+	if err != nil {
+		panic(err)
+	}
+	adminHandler := adminhttp.NewHandler(policy, adminUsers, adminRoles, adminPermissions, adminTeams, adminhttp.WithAccessInvalidator(accessCache), adminhttp.WithSessionRevoker(authSvc))
+	httpserver.RegisterAdminRoutes(router, adminHandler, authMiddleware, authhttp.DenyImpersonation)
+
+	// 9.2.- Let registered clients obtain service tokens (client_credentials), introspect (RFC 7662), and revoke (RFC 7009) tokens.
+	oauthClientStore, err := storageoauthclients.NewStore(db)
+	if err != nil {
+		panic(err)
+	}
+	oauthHandler := oauthhttp.NewHandler(oauthhttp.NewClientRegistry(oauthClientStore), authSvc,
+		oauthhttp.WithPersonalAccessTokens(accessTokens),
+		oauthhttp.WithClientCredentials(authSvc),
+	)
+	httpserver.RegisterOAuthRoutes(router, oauthHandler)
 
 	// phone verification controller (from app/http/controllers/phone_verification_controller.go)
-	phoneCtrl := appcontrollers.NewPhoneVerificationController(db)
+	// Codes are texted through the queue; PHONE_VERIFICATION_DEV_CODES=true also echoes them for local development.
+	phoneDevCodes, _ := strconv.ParseBool(os.Getenv("PHONE_VERIFICATION_DEV_CODES"))
+	// Codes are stored as HMAC digests; attempts, lifetime and resend cooldown fall back to the controller defaults.
+	var otpPolicy appcontrollers.OTPPolicy
+	if minutes, convErr := strconv.Atoi(os.Getenv("PHONE_VERIFICATION_CODE_TTL_MINUTES")); convErr == nil && minutes > 0 {
+		otpPolicy.TTL = time.Duration(minutes) * time.Minute
+	}
+	if attempts, convErr := strconv.Atoi(os.Getenv("PHONE_VERIFICATION_MAX_ATTEMPTS")); convErr == nil && attempts > 0 {
+		otpPolicy.MaxAttempts = attempts
+	}
+	if seconds, convErr := strconv.Atoi(os.Getenv("PHONE_VERIFICATION_RESEND_COOLDOWN_SECONDS")); convErr == nil && seconds > 0 {
+		otpPolicy.ResendCooldown = time.Duration(seconds) * time.Second
+	}
+	phoneCtrl := appcontrollers.NewPhoneVerificationController(db,
+		appcontrollers.WithSMSQueue(jobs),
+		appcontrollers.WithDevCodes(phoneDevCodes),
+		appcontrollers.WithCodeSecret(hmacSecret),
+		appcontrollers.WithOTPPolicy(otpPolicy),
+		// Phones are keyed in E.164; PHONE_DEFAULT_REGION (e.g. MX) also accepts numbers typed without "+".
+		appcontrollers.WithDefaultRegion(os.Getenv("PHONE_DEFAULT_REGION")),
+	)
 
 	notificationSvc := memoryplatform.NewNotificationService(memoryplatform.DefaultNotifications())
 	notificationHandler := notificationshttp.NewHandler(notificationSvc)
@@ -152,12 +487,106 @@ func RegisterRoutes(router *gin.Engine, opts ...Option) {
 	// 11.1.- Authenticated tasks.
 	protected.GET("/tasks", taskHandler.List)
 
-	// 11.2.- Authenticated notification management for the dashboard.
-	notificationsGroup := protected.Group("/notifications")
+	// 11.2.- Authenticated notification management for the dashboard; service accounts have no inbox.
+	notificationsGroup := protected.Group("/notifications", authhttp.DenyServiceAccounts)
 	notificationsGroup.GET("", notificationHandler.List)
 	notificationsGroup.PATCH(":id", notificationHandler.MarkRead)
 
 	// 11.3.- Authenticated listing of unverified phone verifications for operators.
 	// Example: GET /v1/phone-verifications/unverified
 	protected.GET("/phone-verifications/unverified", phoneCtrl.ListUnverified)
+
+	// 11.4.- Self-service personal data export and account deletion; impersonators and service accounts cannot use them.
+	privacyGroup := protected.Group("/user", authhttp.DenyServiceAccounts, authhttp.DenyImpersonation)
+	privacyGroup.POST("/export", privacyHandler.RequestExport)
+	privacyGroup.GET("/export", privacyHandler.ExportStatus)
+	privacyGroup.GET("/export/download", privacyHandler.DownloadExport)
+	privacyGroup.POST("/delete", privacyHandler.DeleteAccount)
+}
+
+// 1.- dataExportPath returns the directory holding personal data archives, shared with cmd/worker.
+func dataExportPath() string {
+	if path := strings.TrimSpace(os.Getenv("DATA_EXPORT_PATH")); path != "" {
+		return path
+	}
+	return "storage/exports"
+}
+
+// 1.- cookieSessionsFromEnv reads the optional browser cookie session settings.
+func cookieSessionsFromEnv() (authhttp.CookieSessionConfig, bool) {
+	if enabled, _ := strconv.ParseBool(os.Getenv("SESSION_COOKIES_ENABLED")); !enabled {
+		return authhttp.CookieSessionConfig{}, false
+	}
+	cfg := authhttp.CookieSessionConfig{
+		Domain: strings.TrimSpace(os.Getenv("SESSION_COOKIE_DOMAIN")),
+		Secure: true,
+	}
+	if secure, err := strconv.ParseBool(os.Getenv("SESSION_COOKIE_SECURE")); err == nil {
+		cfg.Secure = secure
+	}
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("SESSION_COOKIE_SAMESITE"))) {
+	case "strict":
+		cfg.SameSite = http.SameSiteStrictMode
+	case "none":
+		// SameSite=None is only honoured on Secure cookies; it is needed when the dashboard is on another site.
+		cfg.SameSite, cfg.Secure = http.SameSiteNoneMode, true
+	default:
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+	return cfg.WithCookieDefaults(), true
+}
+
+// 1.- oidcProvidersFromEnv builds one provider per name in OIDC_PROVIDERS using OIDC_<NAME>_* settings.
+func oidcProvidersFromEnv() ([]oidc.IdentityProvider, error) {
+	providers := []oidc.IdentityProvider{}
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		provider, err := oidc.NewProvider(oidc.Config{
+			Name:         name,
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("routes: configure oidc provider %q: %w", name, err)
+		}
+		providers = append(providers, provider)
+	}
+	return providers, nil
+}
+
+// 1.- developmentSecret is the well-known key used only when APP_ENV marks a development setup.
+const developmentSecret = "development-jwt-secret"
+
+// 1.- isDevelopmentEnv reports whether APP_ENV names a local setup; an unset APP_ENV counts as production.
+func isDevelopmentEnv(env string) bool {
+	switch strings.ToLower(strings.TrimSpace(env)) {
+	case "development", "dev", "local", "test", "testing":
+		return true
+	}
+	return false
+}
+
+// 1.- hmacSecretFromEnv prefers APP_HMAC_SECRET, then JWT_SECRET under HS256, and refuses the development key outside development.
+// 2.- With RS256/EdDSA, JWT_SECRET is only a legacy verification key, so it never doubles as the HMAC secret.
+func hmacSecretFromEnv(developmentMode bool, symmetric bool) (string, error) {
+	if secret := os.Getenv("APP_HMAC_SECRET"); secret != "" {
+		return secret, nil
+	}
+	if secret := os.Getenv("JWT_SECRET"); secret != "" && symmetric {
+		return secret, nil
+	}
+	if developmentMode {
+		return developmentSecret, nil
+	}
+	if !symmetric {
+		return "", errors.New("APP_HMAC_SECRET must be set outside development when JWT_ALGORITHM is RS256 or EdDSA")
+	}
+	return "", errors.New("APP_HMAC_SECRET (or JWT_SECRET) must be set outside development")
 }
@@ -18,6 +18,7 @@ func TestRegisterRoutesServesLaragoBanner(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	// 3.- Create a fresh router and register the Larago routes under test.
 	router := gin.New()
+	t.Setenv("APP_ENV", "test")
 	routes.RegisterRoutes(router)
 
 	// 4.- Issue a GET request against the root path served by RegisterRoutes.
@@ -37,6 +38,7 @@ func TestRegisterRoutesExposesDashboardTasks(t *testing.T) {
 	// 2.- Configure Gin for deterministic unit testing and register application routes.
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	t.Setenv("APP_ENV", "test")
 	routes.RegisterRoutes(router)
 
 	// 3.- Issue a request against the public tasks endpoint expected by the Next.js frontend.
@@ -127,6 +127,11 @@ func (s *Seeder) seedPermissionCatalog(ctx context.Context, tx *sql.Tx) (map[str
 		{Name: "teams.manage", Description: "Manage teams and team memberships"},
 		{Name: "settings.manage", Description: "Update global application settings"},
 		{Name: "notifications.send", Description: "Send system notifications"},
+		{Name: "admin.users.manage", Description: "Manage users through the admin API"},
+		{Name: "admin.roles.manage", Description: "Manage roles through the admin API"},
+		{Name: "admin.permissions.manage", Description: "Manage permissions through the admin API"},
+		{Name: "admin.teams.manage", Description: "Manage teams through the admin API"},
+		{Name: "admin.users.impersonate", Description: "Act as another user for support purposes"},
 	}
 
 	//2.- Prepare the SQL statement that keeps permission descriptions in sync.
@@ -21,6 +21,7 @@ import (
 // TestHealthRoute ensures that the health endpoint returns the expected payload.
 func TestHealthRoute(t *testing.T) {
 	// 1.- Boot the Gin router with application routes.
+	t.Setenv("APP_ENV", "test")
 	router, _ := bootstrap.SetupRouter()
 
 	// 2.- Prepare an HTTP request targeting the health endpoint.
@@ -52,6 +53,7 @@ func TestHealthRoute(t *testing.T) {
 // TestMetricsRoute exposes the Prometheus endpoint when metrics are configured.
 func TestMetricsRoute(t *testing.T) {
 	// 1.- Boot the Gin router with application routes.
+	t.Setenv("APP_ENV", "test")
 	router, _ := bootstrap.SetupRouter()
 
 	// 2.- Prepare an HTTP request targeting the Prometheus metrics endpoint.
@@ -105,6 +107,7 @@ VALUES
 
 	t.Setenv("DATABASE_URL", container.DSN)
 
+	t.Setenv("APP_ENV", "test")
 	router, _ := bootstrap.SetupRouter()
 
 	req := httptest.NewRequest(http.MethodGet, "/api/tasks", nil)